@@ -0,0 +1,257 @@
+package clients
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BalanceBalls/nekot/config"
+)
+
+const (
+	defaultBedrockRegion = "us-east-1"
+	bedrockSigningName   = "bedrock"
+	awsSigningAlgorithm  = "AWS4-HMAC-SHA256"
+)
+
+type awsCredentials struct {
+	accessKeyId     string
+	secretAccessKey string
+	sessionToken    string
+	// Bedrock API key, sent as a bearer token instead of signing requests
+	bearerToken string
+}
+
+// Credentials are taken from AWS_BEARER_TOKEN_BEDROCK, then AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY,
+// then from the shared credentials file (~/.aws/credentials) with the bedrockProfile or AWS_PROFILE profile
+func loadAwsCredentials(cfg config.Config) (awsCredentials, error) {
+	if token := os.Getenv("AWS_BEARER_TOKEN_BEDROCK"); token != "" {
+		return awsCredentials{bearerToken: token}, nil
+	}
+
+	if keyId, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); keyId != "" && secret != "" {
+		return awsCredentials{
+			accessKeyId:     keyId,
+			secretAccessKey: secret,
+			sessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+
+	profile := cfg.BedrockProfile
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+
+	creds, err := readSharedCredentials(profile)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf(
+			"AWS credentials not found: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY or AWS_BEARER_TOKEN_BEDROCK (%w)", err)
+	}
+	return creds, nil
+}
+
+// Reads a profile of the shared credentials file, an ini file with one section per profile
+func readSharedCredentials(profile string) (awsCredentials, error) {
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return awsCredentials{}, err
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	defer file.Close()
+
+	creds := awsCredentials{}
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || section != profile {
+			continue
+		}
+
+		switch strings.TrimSpace(key) {
+		case "aws_access_key_id":
+			creds.accessKeyId = strings.TrimSpace(value)
+		case "aws_secret_access_key":
+			creds.secretAccessKey = strings.TrimSpace(value)
+		case "aws_session_token":
+			creds.sessionToken = strings.TrimSpace(value)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return awsCredentials{}, err
+	}
+
+	if creds.accessKeyId == "" || creds.secretAccessKey == "" {
+		return awsCredentials{}, fmt.Errorf("profile %s has no access keys in %s", profile, path)
+	}
+	return creds, nil
+}
+
+func getBedrockRegion(cfg config.Config) string {
+	for _, region := range []string{cfg.BedrockRegion, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION")} {
+		if region != "" {
+			return region
+		}
+	}
+	return defaultBedrockRegion
+}
+
+func authorizeAwsRequest(req *http.Request, body []byte, creds awsCredentials, region string) error {
+	if creds.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+creds.bearerToken)
+		return nil
+	}
+
+	if creds.accessKeyId == "" {
+		return errors.New("AWS credentials are not set")
+	}
+
+	signAwsRequest(req, body, creds, region, bedrockSigningName, time.Now())
+	return nil
+}
+
+// Signs the request with AWS Signature Version 4.
+// See https://docs.aws.amazon.com/IAM/latest/UserGuide/create-signed-request.html
+func signAwsRequest(req *http.Request, body []byte, creds awsCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.sessionToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "content-type" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+
+	headerNames := []string{}
+	for name := range headers {
+		headerNames = append(headerNames, name)
+	}
+	slices.Sort(headerNames)
+
+	canonicalHeaders := ""
+	for _, name := range headerNames {
+		canonicalHeaders += name + ":" + headers[name] + "\n"
+	}
+	signedHeaders := strings.Join(headerNames, ";")
+
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		getCanonicalUri(req.URL.EscapedPath()),
+		getCanonicalQuery(req),
+		canonicalHeaders,
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, region, service)
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		awsSigningAlgorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	signingKey := hmacSha256([]byte("AWS4"+creds.secretAccessKey), date)
+	signingKey = hmacSha256(signingKey, region)
+	signingKey = hmacSha256(signingKey, service)
+	signingKey = hmacSha256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		awsSigningAlgorithm,
+		creds.accessKeyId,
+		scope,
+		signedHeaders,
+		signature))
+}
+
+// Services other than S3 expect every segment of the already escaped path to be escaped again,
+// e.g. model ids with a colon are sent as %3A and signed as %253A
+func getCanonicalUri(escapedPath string) string {
+	if escapedPath == "" {
+		return "/"
+	}
+
+	segments := strings.Split(escapedPath, "/")
+	for i, segment := range segments {
+		segments[i] = awsEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func getCanonicalQuery(req *http.Request) string {
+	params := []string{}
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			params = append(params, awsEscape(name)+"="+awsEscape(value))
+		}
+	}
+	slices.Sort(params)
+	return strings.Join(params, "&")
+}
+
+// Escapes everything except unreserved characters as required by the signature
+func awsEscape(value string) string {
+	var builder strings.Builder
+	for _, b := range []byte(value) {
+		if b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z' || b >= '0' && b <= '9' ||
+			b == '-' || b == '_' || b == '.' || b == '~' {
+			builder.WriteByte(b)
+			continue
+		}
+		fmt.Fprintf(&builder, "%%%02X", b)
+	}
+	return builder.String()
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,124 @@
+package clients
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// Prelude (total length, headers length, prelude crc) and the message crc
+const (
+	eventStreamPreludeLength = 12
+	eventStreamCrcLength     = 4
+	// Bedrock messages are way smaller, the limit protects from allocating garbage lengths
+	maxEventStreamMessageLength = 16 * 1024 * 1024
+)
+
+// Sizes of header values by type, strings and byte arrays have a length prefix instead
+var eventStreamHeaderSizes = map[byte]int{
+	0: 0,  // bool true
+	1: 0,  // bool false
+	2: 1,  // byte
+	3: 2,  // short
+	4: 4,  // integer
+	5: 8,  // long
+	8: 8,  // timestamp
+	9: 16, // uuid
+}
+
+const (
+	eventStreamBytesHeader  = 6
+	eventStreamStringHeader = 7
+)
+
+// A message of the AWS event stream encoding (application/vnd.amazon.eventstream).
+// Only string headers are kept, e.g. :event-type and :message-type
+type eventStreamMessage struct {
+	headers map[string]string
+	payload []byte
+}
+
+// Reads the next message of the stream, io.EOF means the stream has ended between messages
+func readEventStreamMessage(r io.Reader) (eventStreamMessage, error) {
+	prelude := make([]byte, eventStreamPreludeLength)
+	if _, err := io.ReadFull(r, prelude); err != nil {
+		return eventStreamMessage{}, err
+	}
+
+	totalLength := binary.BigEndian.Uint32(prelude[0:4])
+	headersLength := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return eventStreamMessage{}, errors.New("event stream: prelude checksum mismatch")
+	}
+
+	if totalLength > maxEventStreamMessageLength ||
+		int(totalLength) < eventStreamPreludeLength+int(headersLength)+eventStreamCrcLength {
+		return eventStreamMessage{}, fmt.Errorf("event stream: invalid message length %d", totalLength)
+	}
+
+	message := make([]byte, totalLength)
+	copy(message, prelude)
+	if _, err := io.ReadFull(r, message[eventStreamPreludeLength:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return eventStreamMessage{}, err
+	}
+
+	crcOffset := len(message) - eventStreamCrcLength
+	if crc32.ChecksumIEEE(message[:crcOffset]) != binary.BigEndian.Uint32(message[crcOffset:]) {
+		return eventStreamMessage{}, errors.New("event stream: message checksum mismatch")
+	}
+
+	headersEnd := eventStreamPreludeLength + int(headersLength)
+	headers, err := parseEventStreamHeaders(message[eventStreamPreludeLength:headersEnd])
+	if err != nil {
+		return eventStreamMessage{}, err
+	}
+
+	return eventStreamMessage{
+		headers: headers,
+		payload: message[headersEnd:crcOffset],
+	}, nil
+}
+
+// Each header is a name length byte, the name, a value type byte and the value
+func parseEventStreamHeaders(data []byte) (map[string]string, error) {
+	headers := map[string]string{}
+	malformed := errors.New("event stream: malformed headers")
+
+	for len(data) > 0 {
+		nameLength := int(data[0])
+		if len(data) < 1+nameLength+1 {
+			return nil, malformed
+		}
+		name := string(data[1 : 1+nameLength])
+		valueType := data[1+nameLength]
+		data = data[2+nameLength:]
+
+		if valueType == eventStreamBytesHeader || valueType == eventStreamStringHeader {
+			if len(data) < 2 {
+				return nil, malformed
+			}
+			valueLength := int(binary.BigEndian.Uint16(data[:2]))
+			if len(data) < 2+valueLength {
+				return nil, malformed
+			}
+			if valueType == eventStreamStringHeader {
+				headers[name] = string(data[2 : 2+valueLength])
+			}
+			data = data[2+valueLength:]
+			continue
+		}
+
+		size, ok := eventStreamHeaderSizes[valueType]
+		if !ok || len(data) < size {
+			return nil, malformed
+		}
+		data = data[size:]
+	}
+
+	return headers, nil
+}
@@ -0,0 +1,578 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var (
+	converseImageFormats    = []string{"png", "jpeg", "gif", "webp"}
+	converseDocumentFormats = []string{"pdf", "csv", "doc", "docx", "xls", "xlsx", "html", "txt", "md"}
+	// Characters not allowed in document names are replaced with hyphens. Spaces too, consecutive ones are rejected
+	documentNameRegex = regexp.MustCompile(`[^a-zA-Z0-9\-\(\)\[\]]+`)
+)
+
+// Runs models of Amazon Bedrock with the Converse API, requests are signed with AWS credentials
+type BedrockClient struct {
+	systemMessage string
+	client        http.Client
+}
+
+// Messages of the Converse API. A content block has exactly one field set
+type converseMessage struct {
+	Role    string            `json:"role"`
+	Content []converseContent `json:"content"`
+}
+
+type converseContent struct {
+	Text             string              `json:"text,omitempty"`
+	Image            *converseImage      `json:"image,omitempty"`
+	Document         *converseDocument   `json:"document,omitempty"`
+	ToolUse          *converseToolUse    `json:"toolUse,omitempty"`
+	ToolResult       *converseToolResult `json:"toolResult,omitempty"`
+	ReasoningContent *converseReasoning  `json:"reasoningContent,omitempty"`
+}
+
+type converseImage struct {
+	Format string         `json:"format"`
+	Source converseSource `json:"source"`
+}
+
+type converseDocument struct {
+	Format string         `json:"format"`
+	Name   string         `json:"name"`
+	Source converseSource `json:"source"`
+}
+
+// Bytes are base64 encoded in JSON
+type converseSource struct {
+	Bytes string `json:"bytes"`
+}
+
+type converseToolUse struct {
+	ToolUseId string            `json:"toolUseId"`
+	Name      string            `json:"name"`
+	Input     map[string]string `json:"input"`
+}
+
+type converseToolResult struct {
+	ToolUseId string            `json:"toolUseId"`
+	Content   []converseContent `json:"content"`
+}
+
+type converseReasoning struct {
+	ReasoningText converseReasoningText `json:"reasoningText"`
+}
+
+type converseReasoningText struct {
+	Text      string `json:"text"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// Payload of a ConverseStream event, fields are set according to the :event-type header
+type converseStreamEvent struct {
+	ContentBlockIndex int `json:"contentBlockIndex"`
+	Start             struct {
+		ToolUse *struct {
+			ToolUseId string `json:"toolUseId"`
+			Name      string `json:"name"`
+		} `json:"toolUse"`
+	} `json:"start"`
+	Delta struct {
+		Text    string `json:"text"`
+		ToolUse *struct {
+			Input string `json:"input"`
+		} `json:"toolUse"`
+		ReasoningContent *struct {
+			Text      string `json:"text"`
+			Signature string `json:"signature"`
+		} `json:"reasoningContent"`
+	} `json:"delta"`
+	StopReason string `json:"stopReason"`
+	Usage      struct {
+		InputTokens           int `json:"inputTokens"`
+		OutputTokens          int `json:"outputTokens"`
+		CacheReadInputTokens  int `json:"cacheReadInputTokens"`
+		CacheWriteInputTokens int `json:"cacheWriteInputTokens"`
+	} `json:"usage"`
+	Message string `json:"message"`
+}
+
+type bedrockFoundationModelsResponse struct {
+	ModelSummaries []struct {
+		ModelId                    string   `json:"modelId"`
+		ProviderName               string   `json:"providerName"`
+		InferenceTypesSupported    []string `json:"inferenceTypesSupported"`
+		ResponseStreamingSupported bool     `json:"responseStreamingSupported"`
+	} `json:"modelSummaries"`
+}
+
+type bedrockInferenceProfilesResponse struct {
+	InferenceProfileSummaries []struct {
+		InferenceProfileId string `json:"inferenceProfileId"`
+		Status             string `json:"status"`
+	} `json:"inferenceProfileSummaries"`
+	NextToken string `json:"nextToken"`
+}
+
+var converseWebSearchTool = map[string]any{
+	"toolSpec": map[string]any{
+		"name":        openAIwebSearchTool.Function.Name,
+		"description": openAIwebSearchTool.Function.Description,
+		"inputSchema": map[string]any{
+			"json": openAIwebSearchTool.Function.Parameters,
+		},
+	},
+}
+
+func NewBedrockClient(systemMessage string) *BedrockClient {
+	return &BedrockClient{
+		systemMessage: systemMessage,
+		client:        http.Client{},
+	}
+}
+
+func (c BedrockClient) RequestCompletion(
+	ctx context.Context,
+	chatMsgs []util.LocalStoreMessage,
+	modelSettings util.Settings,
+	resultChan chan util.ProcessApiCompletionResponse,
+) tea.Cmd {
+	return func() tea.Msg {
+		config, ok := config.FromContext(ctx)
+		if !ok {
+			util.Slog.Error("No config found in a context")
+			panic("No config found in context")
+		}
+
+		body, err := constructConverseRequestPayload(chatMsgs, *config, modelSettings)
+		if err != nil {
+			return util.MakeErrorMsg(err.Error())
+		}
+
+		// Model ids and inference profile ARNs contain colons and slashes, so the id is escaped as a single segment
+		requestUrl := fmt.Sprintf("%s/model/%s/converse-stream", getBedrockRuntimeUrl(*config), awsEscape(modelSettings.Model))
+		resp, err := c.sendBedrockRequest(ctx, *config, http.MethodPost, requestUrl, body)
+		if err != nil {
+			return util.MakeErrorMsg(err.Error())
+		}
+
+		processConverseResponse(ctx, resp, util.NewResponseWriter(ctx, resultChan))
+		return nil
+	}
+}
+
+// Lists text models available on demand and the cross region inference profiles,
+// newer models can only be called through an inference profile
+func (c BedrockClient) RequestModelsList(ctx context.Context) util.ProcessModelsResponse {
+	cfg := config.Config{}
+	if configFromCtx, ok := config.FromContext(ctx); ok {
+		cfg = *configFromCtx
+	}
+
+	apiUrl := getBedrockApiUrl(cfg)
+	if err := util.CheckHostAllowed(apiUrl); err != nil {
+		return util.ProcessModelsResponse{Err: err}
+	}
+
+	var foundationModels bedrockFoundationModelsResponse
+	err := c.getBedrockApi(ctx, cfg, apiUrl+"/foundation-models?byOutputModality=TEXT", &foundationModels)
+	if err != nil {
+		util.Slog.Error("Bedrock: failed to fetch a list of models", "error", err.Error())
+		return util.ProcessModelsResponse{Err: err}
+	}
+
+	var modelsList []util.ModelDescription
+	for _, model := range foundationModels.ModelSummaries {
+		if model.ResponseStreamingSupported && slices.Contains(model.InferenceTypesSupported, "ON_DEMAND") {
+			modelsList = append(modelsList, util.ModelDescription{Id: model.ModelId, OwnedBy: model.ProviderName})
+		}
+	}
+
+	nextToken := ""
+	for {
+		requestUrl := apiUrl + "/inference-profiles?maxResults=1000"
+		if nextToken != "" {
+			requestUrl += "&nextToken=" + url.QueryEscape(nextToken)
+		}
+
+		var profiles bedrockInferenceProfilesResponse
+		if err := c.getBedrockApi(ctx, cfg, requestUrl, &profiles); err != nil {
+			// Inference profiles are not available in every region
+			util.Slog.Warn("Bedrock: failed to fetch a list of inference profiles", "error", err.Error())
+			break
+		}
+
+		for _, profile := range profiles.InferenceProfileSummaries {
+			if profile.Status == "ACTIVE" {
+				modelsList = append(modelsList, util.ModelDescription{Id: profile.InferenceProfileId})
+			}
+		}
+
+		if profiles.NextToken == "" {
+			break
+		}
+		nextToken = profiles.NextToken
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return util.ProcessModelsResponse{Err: errors.New("timed out during fetching models")}
+	}
+
+	return util.ProcessModelsResponse{
+		Result: util.ModelsListResponse{
+			Data: modelsList,
+		},
+		Err: nil,
+	}
+}
+
+func (c BedrockClient) getBedrockApi(ctx context.Context, cfg config.Config, requestUrl string, result any) error {
+	resp, err := c.sendBedrockRequest(ctx, cfg, http.MethodGet, requestUrl, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("%s", string(body))
+	}
+
+	return json.Unmarshal(body, result)
+}
+
+func (c BedrockClient) sendBedrockRequest(
+	ctx context.Context,
+	cfg config.Config,
+	method, requestUrl string,
+	body []byte,
+) (*http.Response, error) {
+	creds, err := loadAwsCredentials(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestUrl, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if err := authorizeAwsRequest(req, body, creds, getBedrockRegion(cfg)); err != nil {
+		return nil, err
+	}
+
+	return c.client.Do(req)
+}
+
+func processConverseResponse(
+	ctx context.Context,
+	resp *http.Response,
+	writer *util.ResponseWriter,
+) {
+	defer resp.Body.Close()
+
+	// Dropping the connection aborts generation
+	stopAbortWatch := context.AfterFunc(ctx, func() {
+		resp.Body.Close()
+	})
+	defer stopAbortWatch()
+
+	if resp.StatusCode >= 400 {
+		bodyBytes, err := io.ReadAll(resp.Body)
+		if err != nil {
+			writer.Fail(err)
+			return
+		}
+		apiErr := &util.ApiError{
+			Provider:   util.BedrockProviderType,
+			StatusCode: resp.StatusCode,
+			RequestId:  resp.Header.Get("x-amzn-RequestId"),
+			Body:       string(bodyBytes),
+			Err:        fmt.Errorf("%s", string(bodyBytes)),
+		}
+		writer.Fail(apiErr)
+		return
+	}
+
+	stream := newContentBlockStream(writer)
+	for {
+		message, err := readEventStreamMessage(resp.Body)
+		// Usage metadata comes after messageStop, the stream is finished once it is closed
+		if err == io.EOF && stream.stopReason != "" {
+			util.Slog.Info("Bedrock: stream ended", "stop reason", stream.stopReason)
+			stream.finish()
+			return
+		}
+
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			util.Slog.Error("Bedrock: Encountered error during receiving response", "error", err.Error())
+			stream.sendError(err)
+			return
+		}
+
+		var event converseStreamEvent
+		if err := json.Unmarshal(message.payload, &event); err != nil {
+			util.Slog.Error("error unmarshalling:", "chunk data", string(message.payload), "error", err.Error())
+			stream.sendError(err)
+			return
+		}
+
+		switch message.headers[":message-type"] {
+		case "exception":
+			exceptionType := message.headers[":exception-type"]
+			util.Slog.Error("Bedrock: Received an exception", "type", exceptionType, "error", event.Message)
+			stream.sendError(&util.ApiError{
+				Provider: util.BedrockProviderType,
+				Body:     event.Message,
+				Err:      fmt.Errorf("%s: %s", exceptionType, event.Message),
+			})
+			return
+		case "error":
+			errorCode := message.headers[":error-code"]
+			util.Slog.Error("Bedrock: Received an error", "code", errorCode, "error", message.headers[":error-message"])
+			stream.sendError(fmt.Errorf("%s: %s", errorCode, message.headers[":error-message"]))
+			return
+		}
+
+		switch message.headers[":event-type"] {
+		case "contentBlockStart":
+			if toolUse := event.Start.ToolUse; toolUse != nil {
+				stream.startToolCall(event.ContentBlockIndex, toolUse.ToolUseId, toolUse.Name)
+			}
+		case "contentBlockDelta":
+			delta := event.Delta
+			switch {
+			case delta.Text != "":
+				stream.sendContent(delta.Text)
+			case delta.ToolUse != nil:
+				stream.appendToolArgs(event.ContentBlockIndex, delta.ToolUse.Input)
+			case delta.ReasoningContent != nil:
+				stream.sendReasoning(delta.ReasoningContent.Text)
+				if delta.ReasoningContent.Signature != "" {
+					stream.signature = delta.ReasoningContent.Signature
+				}
+			}
+		case "messageStop":
+			stream.stopReason = event.StopReason
+		case "metadata":
+			usage := event.Usage
+			stream.usage.Prompt = usage.InputTokens + usage.CacheReadInputTokens + usage.CacheWriteInputTokens
+			stream.usage.Completion = usage.OutputTokens
+		}
+	}
+}
+
+func constructConverseRequestPayload(
+	chatMsgs []util.LocalStoreMessage,
+	cfg config.Config,
+	settings util.Settings,
+) ([]byte, error) {
+	// Thinking and sampling restrictions are specific to Claude, other models get the preset values as is
+	isClaude := util.IsAnthropicModel(settings.Model)
+	thinkingBudget := 0
+	temperature, topP := settings.Temperature, settings.TopP
+	if isClaude {
+		thinkingBudget = getClaudeThinkingBudget(util.Bedrock, chatMsgs, settings)
+		temperature, topP = getClaudeSamplingParams(settings, thinkingBudget > 0)
+	}
+
+	messages, err := constructConverseMessages(chatMsgs, *cfg.IncludeReasoningTokensInContext, thinkingBudget > 0)
+	if err != nil {
+		return nil, err
+	}
+
+	util.Slog.Debug("Constructing message", "model", settings.Model)
+
+	inferenceConfig := map[string]any{
+		"maxTokens": settings.MaxTokens + thinkingBudget,
+	}
+	if temperature != nil {
+		inferenceConfig["temperature"] = *temperature
+	}
+	if topP != nil {
+		inferenceConfig["topP"] = *topP
+	}
+
+	reqParams := map[string]any{
+		"messages":        messages,
+		"inferenceConfig": inferenceConfig,
+	}
+
+	if systemMsg := getSystemMessage(cfg, settings); systemMsg != "" {
+		reqParams["system"] = []converseContent{{Text: systemMsg}}
+	}
+
+	if settings.WebSearchEnabled || hasToolCalls(chatMsgs) {
+		reqParams["toolConfig"] = map[string]any{
+			"tools": []any{converseWebSearchTool},
+		}
+	}
+
+	if thinkingBudget > 0 {
+		reqParams["additionalModelRequestFields"] = map[string]any{
+			"thinking": map[string]any{
+				"type":          "enabled",
+				"budget_tokens": thinkingBudget,
+			},
+		}
+	}
+
+	body, err := json.Marshal(reqParams)
+	if err != nil {
+		util.Slog.Error("error marshaling JSON", "error", err.Error())
+		return nil, err
+	}
+	return body, nil
+}
+
+// Same as with Claude: tool results are user messages and consecutive messages of the same role are merged
+func constructConverseMessages(
+	msgs []util.LocalStoreMessage,
+	includeReasoning bool,
+	thinking bool,
+) ([]converseMessage, error) {
+	messages := []converseMessage{}
+
+	for _, singleMessage := range msgs {
+		role := "user"
+		if singleMessage.Role == "assistant" {
+			role = "assistant"
+		}
+
+		content := []converseContent{}
+		hasThinkingBlock := thinking && role == "assistant" &&
+			len(singleMessage.ToolCalls) > 0 && len(singleMessage.ToolCalls[0].Signature) > 0
+
+		if hasThinkingBlock {
+			content = append(content, converseContent{ReasoningContent: &converseReasoning{
+				ReasoningText: converseReasoningText{
+					Text:      singleMessage.Resoning,
+					Signature: string(singleMessage.ToolCalls[0].Signature),
+				},
+			}})
+		}
+
+		messageContent := singleMessage.Content
+		if singleMessage.Resoning != "" && includeReasoning && !hasThinkingBlock {
+			messageContent = singleMessage.Resoning + messageContent
+		}
+
+		if strings.TrimSpace(messageContent) != "" {
+			content = append(content, converseContent{Text: messageContent})
+		}
+
+		for _, attachment := range singleMessage.Attachments {
+			block, err := constructConverseAttachment(attachment)
+			if err != nil {
+				return nil, err
+			}
+			content = append(content, block)
+		}
+
+		for _, tc := range singleMessage.ToolCalls {
+			if singleMessage.Role == "tool" {
+				result := ""
+				if tc.Result != nil {
+					result = *tc.Result
+				}
+				content = append(content, converseContent{ToolResult: &converseToolResult{
+					ToolUseId: tc.Id,
+					Content:   []converseContent{{Text: result}},
+				}})
+				continue
+			}
+
+			args := tc.Function.Args
+			if args == nil {
+				args = map[string]string{}
+			}
+			content = append(content, converseContent{ToolUse: &converseToolUse{
+				ToolUseId: tc.Id,
+				Name:      tc.Function.Name,
+				Input:     args,
+			}})
+		}
+
+		if len(content) == 0 {
+			continue
+		}
+
+		if last := len(messages) - 1; last >= 0 && messages[last].Role == role {
+			messages[last].Content = append(messages[last].Content, content...)
+			continue
+		}
+
+		messages = append(messages, converseMessage{Role: role, Content: content})
+	}
+
+	return messages, nil
+}
+
+func constructConverseAttachment(attachment util.Attachment) (converseContent, error) {
+	data, err := base64.StdEncoding.DecodeString(attachment.Content)
+	if err != nil {
+		util.Slog.Error("failed to decode file bytes", "item", attachment.Path, "error", err.Error())
+		return converseContent{}, errors.New("could not prepare attachments for request")
+	}
+
+	mimeType := getMimeType(attachment.Path, data)
+	if format, ok := strings.CutPrefix(mimeType, "image/"); ok && slices.Contains(converseImageFormats, format) {
+		return converseContent{Image: &converseImage{
+			Format: format,
+			Source: converseSource{Bytes: attachment.Content},
+		}}, nil
+	}
+
+	fileName := filepath.Base(attachment.Path)
+	extension := strings.ToLower(strings.TrimPrefix(filepath.Ext(fileName), "."))
+	if slices.Contains(converseDocumentFormats, extension) {
+		name := strings.Trim(documentNameRegex.ReplaceAllString(strings.TrimSuffix(fileName, filepath.Ext(fileName)), "-"), "-")
+		if name == "" {
+			name = "document"
+		}
+		return converseContent{Document: &converseDocument{
+			Format: extension,
+			Name:   name,
+			Source: converseSource{Bytes: attachment.Content},
+		}}, nil
+	}
+
+	return converseContent{}, fmt.Errorf("unsupported attachment type %s: %s", mimeType, fileName)
+}
+
+func getBedrockRuntimeUrl(cfg config.Config) string {
+	return fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", getBedrockRegion(cfg))
+}
+
+// Models are listed by the control plane API, it has a different host than the runtime API
+func getBedrockApiUrl(cfg config.Config) string {
+	return fmt.Sprintf("https://bedrock.%s.amazonaws.com", getBedrockRegion(cfg))
+}
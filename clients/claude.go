@@ -0,0 +1,505 @@
+package clients
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/util"
+)
+
+const (
+	anthropicVertexVersion = "vertex-2023-10-16"
+	// Anthropic rejects smaller thinking budgets
+	minClaudeThinkingBudget = 1024
+	toolUseStopReason       = "tool_use"
+)
+
+// Messages of the Anthropic Messages API. A content block is one of:
+// text, image, document, thinking, tool_use, tool_result
+type claudeMessage struct {
+	Role    string          `json:"role"`
+	Content []claudeContent `json:"content"`
+}
+
+type claudeContent struct {
+	Type      string            `json:"type"`
+	Text      string            `json:"text,omitempty"`
+	Source    *claudeSource     `json:"source,omitempty"`
+	Id        string            `json:"id,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	Input     map[string]string `json:"input,omitempty"`
+	ToolUseId string            `json:"tool_use_id,omitempty"`
+	Content   string            `json:"content,omitempty"`
+	Thinking  string            `json:"thinking,omitempty"`
+	Signature string            `json:"signature,omitempty"`
+}
+
+type claudeSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+type claudeTool struct {
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	InputSchema OpenAiFuncitonParameters `json:"input_schema"`
+}
+
+type claudeUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+type claudeStreamEvent struct {
+	Type    string `json:"type"`
+	Index   int    `json:"index"`
+	Message struct {
+		Usage claudeUsage `json:"usage"`
+	} `json:"message"`
+	ContentBlock struct {
+		Type string `json:"type"`
+		Id   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"content_block"`
+	Delta struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		Thinking    string `json:"thinking"`
+		Signature   string `json:"signature"`
+		PartialJson string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage claudeUsage `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+var claudeWebSearchTool = claudeTool{
+	Name:        openAIwebSearchTool.Function.Name,
+	Description: openAIwebSearchTool.Function.Description,
+	InputSchema: openAIwebSearchTool.Function.Parameters,
+}
+
+// Accumulates a response streamed as content blocks (Anthropic Messages API, Bedrock Converse API)
+// and sends it to the orchestrator as openai like chunks
+type contentBlockStream struct {
+	writer     *util.ResponseWriter
+	toolCalls  map[int]*util.ToolCall
+	toolArgs   map[int]string
+	signature  string
+	stopReason string
+	usage      util.TokenUsage
+}
+
+func newContentBlockStream(writer *util.ResponseWriter) *contentBlockStream {
+	return &contentBlockStream{
+		writer:    writer,
+		toolCalls: map[int]*util.ToolCall{},
+		toolArgs:  map[int]string{},
+	}
+}
+
+func (s *contentBlockStream) sendDelta(delta map[string]any) {
+	s.writer.Send(util.CompletionChunk{
+		Choices: []util.Choice{{Delta: delta}},
+	})
+}
+
+func (s *contentBlockStream) sendContent(text string) {
+	if text != "" {
+		s.sendDelta(map[string]any{"content": text})
+	}
+}
+
+func (s *contentBlockStream) sendReasoning(text string) {
+	if text != "" {
+		s.sendDelta(map[string]any{"content": "", "reasoning": text})
+	}
+}
+
+func (s *contentBlockStream) startToolCall(index int, id, name string) {
+	s.toolCalls[index] = &util.ToolCall{
+		Id:       id,
+		Type:     "function",
+		Function: util.ToolFunction{Name: name},
+	}
+}
+
+func (s *contentBlockStream) appendToolArgs(index int, partialJson string) {
+	s.toolArgs[index] += partialJson
+}
+
+func (s *contentBlockStream) sendError(err error) {
+	s.writer.Fail(err)
+}
+
+// Sends the requested tool calls, or the usage and the finishing chunks of the response
+func (s *contentBlockStream) finish() {
+	switch s.stopReason {
+	case toolUseStopReason:
+		if len(s.toolCalls) > 0 {
+			s.sendToolCalls()
+			return
+		}
+	case "refusal", "guardrail_intervened", "content_filtered":
+		s.sendError(fmt.Errorf("LLM stopped responding (%s)", formatEnumName(s.stopReason)))
+		return
+	}
+
+	s.writer.Send(util.CompletionChunk{
+		Choices: []util.Choice{{Delta: map[string]any{"content": ""}}},
+		Usage:   &s.usage,
+	})
+	s.writer.Finish()
+}
+
+// The signature of the thinking block is kept with the tool calls, it has to be sent back with them
+func (s *contentBlockStream) sendToolCalls() {
+	indexes := []int{}
+	for index := range s.toolCalls {
+		indexes = append(indexes, index)
+	}
+	slices.Sort(indexes)
+
+	toolCalls := []util.ToolCall{}
+	for _, index := range indexes {
+		tc := s.toolCalls[index]
+		args := map[string]any{}
+		if s.toolArgs[index] != "" {
+			if err := json.Unmarshal([]byte(s.toolArgs[index]), &args); err != nil {
+				util.Slog.Error("error unmarshalling tool call arguments", "data", s.toolArgs[index], "error", err.Error())
+				s.sendError(err)
+				return
+			}
+		}
+
+		tc.Function.Args = map[string]string{}
+		for name, value := range args {
+			tc.Function.Args[name] = fmt.Sprint(value)
+		}
+		if s.signature != "" {
+			tc.Signature = []byte(s.signature)
+		}
+		toolCalls = append(toolCalls, *tc)
+	}
+
+	util.Slog.Debug("tool calls requested", "data", toolCalls)
+	s.writer.Send(util.CompletionChunk{
+		Choices: []util.Choice{{ToolCalls: toolCalls}},
+		Usage:   &s.usage,
+	})
+	s.writer.Finish()
+}
+
+func processClaudeResponse(
+	ctx context.Context,
+	resp *http.Response,
+	writer *util.ResponseWriter,
+) {
+	defer resp.Body.Close()
+
+	// Dropping the connection aborts generation
+	stopAbortWatch := context.AfterFunc(ctx, func() {
+		resp.Body.Close()
+	})
+	defer stopAbortWatch()
+
+	if resp.StatusCode >= 400 {
+		bodyBytes, err := io.ReadAll(resp.Body)
+		if err != nil {
+			writer.Fail(err)
+			return
+		}
+		apiErr := &util.ApiError{
+			Provider:   util.VertexProviderType,
+			StatusCode: resp.StatusCode,
+			RequestId:  getRequestId(resp.Header),
+			Body:       string(bodyBytes),
+			Err:        fmt.Errorf("%s", string(bodyBytes)),
+		}
+		writer.Fail(apiErr)
+		return
+	}
+
+	stream := newContentBlockStream(writer)
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			// The stream is over with message_stop, EOF before it means the response is incomplete
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			util.Slog.Error("Claude: Encountered error during receiving response", "error", err.Error())
+			stream.sendError(err)
+			return
+		}
+
+		data, ok := strings.CutPrefix(strings.TrimSpace(line), "data:")
+		if !ok {
+			continue
+		}
+
+		var event claudeStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			util.Slog.Error("error unmarshalling:", "chunk data", data, "error", err.Error())
+			stream.sendError(err)
+			return
+		}
+
+		switch event.Type {
+		case "message_start":
+			usage := event.Message.Usage
+			stream.usage.Prompt = usage.InputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens
+		case "content_block_start":
+			if event.ContentBlock.Type == "tool_use" {
+				stream.startToolCall(event.Index, event.ContentBlock.Id, event.ContentBlock.Name)
+			}
+		case "content_block_delta":
+			switch event.Delta.Type {
+			case "text_delta":
+				stream.sendContent(event.Delta.Text)
+			case "thinking_delta":
+				stream.sendReasoning(event.Delta.Thinking)
+			case "signature_delta":
+				stream.signature = event.Delta.Signature
+			case "input_json_delta":
+				stream.appendToolArgs(event.Index, event.Delta.PartialJson)
+			}
+		case "message_delta":
+			stream.stopReason = event.Delta.StopReason
+			stream.usage.Completion = event.Usage.OutputTokens
+		case "message_stop":
+			util.Slog.Info("Claude: Received message_stop")
+			stream.finish()
+			return
+		case "error":
+			util.Slog.Error("Claude: Received an error event", "type", event.Error.Type, "error", event.Error.Message)
+			stream.sendError(&util.ApiError{
+				Provider: util.VertexProviderType,
+				Body:     event.Error.Message,
+				Err:      fmt.Errorf("%s: %s", event.Error.Type, event.Error.Message),
+			})
+			return
+		}
+	}
+}
+
+func constructClaudeRequestPayload(
+	chatMsgs []util.LocalStoreMessage,
+	cfg config.Config,
+	settings util.Settings,
+) ([]byte, error) {
+	thinkingBudget := getClaudeThinkingBudget(util.Vertex, chatMsgs, settings)
+
+	messages, err := constructClaudeMessages(chatMsgs, *cfg.IncludeReasoningTokensInContext, thinkingBudget > 0)
+	if err != nil {
+		return nil, err
+	}
+
+	util.Slog.Debug("Constructing message", "model", settings.Model)
+
+	reqParams := map[string]any{
+		"anthropic_version": anthropicVertexVersion,
+		"max_tokens":        settings.MaxTokens + thinkingBudget,
+		"stream":            true,
+		"messages":          messages,
+	}
+
+	if systemMsg := getSystemMessage(cfg, settings); systemMsg != "" {
+		reqParams["system"] = systemMsg
+	}
+
+	temperature, topP := getClaudeSamplingParams(settings, thinkingBudget > 0)
+	if temperature != nil {
+		reqParams["temperature"] = *temperature
+	}
+	if topP != nil {
+		reqParams["top_p"] = *topP
+	}
+
+	if thinkingBudget > 0 {
+		reqParams["thinking"] = map[string]any{
+			"type":          "enabled",
+			"budget_tokens": thinkingBudget,
+		}
+	}
+
+	if settings.WebSearchEnabled || hasToolCalls(chatMsgs) {
+		reqParams["tools"] = []claudeTool{claudeWebSearchTool}
+	}
+
+	body, err := json.Marshal(reqParams)
+	if err != nil {
+		util.Slog.Error("error marshaling JSON", "error", err.Error())
+		return nil, err
+	}
+	return body, nil
+}
+
+// Extended thinking is enabled by the reasoning budget of the preset, the budget is added to max tokens
+// so the preset value stays the limit of the answer. Thinking is turned off when the pending tool call
+// was requested without a thinking signature, Claude rejects such continuations
+func getClaudeThinkingBudget(provider util.ApiProvider, chatMsgs []util.LocalStoreMessage, settings util.Settings) int {
+	if settings.ReasoningBudget == nil || !util.GetModelCapabilities(provider, settings.Model).Reasoning {
+		return 0
+	}
+
+	budget := util.GetReasoningTokens(*settings.ReasoningBudget)
+	if budget <= 0 {
+		return 0
+	}
+
+	for i := len(chatMsgs) - 1; i >= 0; i-- {
+		if chatMsgs[i].Role != "assistant" {
+			continue
+		}
+		if slices.ContainsFunc(chatMsgs[i].ToolCalls, func(tc util.ToolCall) bool { return len(tc.Signature) == 0 }) {
+			return 0
+		}
+		break
+	}
+
+	return max(budget, minClaudeThinkingBudget)
+}
+
+// Tools must be defined when the history has tool calls, even if web search is turned off since then
+func hasToolCalls(msgs []util.LocalStoreMessage) bool {
+	return slices.ContainsFunc(msgs, func(msg util.LocalStoreMessage) bool { return len(msg.ToolCalls) > 0 })
+}
+
+// Sampling params can't be changed with extended thinking. Claude models since Sonnet 4.5
+// reject temperature and top_p set together, temperature is used in that case
+func getClaudeSamplingParams(settings util.Settings, thinking bool) (*float32, *float32) {
+	if thinking {
+		return nil, nil
+	}
+
+	if settings.Temperature != nil {
+		return settings.Temperature, nil
+	}
+	return nil, settings.TopP
+}
+
+// Tool results are sent as user messages, consecutive messages of the same role are merged
+// since Claude expects roles to alternate
+func constructClaudeMessages(
+	msgs []util.LocalStoreMessage,
+	includeReasoning bool,
+	thinking bool,
+) ([]claudeMessage, error) {
+	messages := []claudeMessage{}
+
+	for _, singleMessage := range msgs {
+		role := "user"
+		if singleMessage.Role == "assistant" {
+			role = "assistant"
+		}
+
+		content := []claudeContent{}
+		hasThinkingBlock := thinking && role == "assistant" &&
+			len(singleMessage.ToolCalls) > 0 && len(singleMessage.ToolCalls[0].Signature) > 0
+
+		if hasThinkingBlock {
+			content = append(content, claudeContent{
+				Type:      "thinking",
+				Thinking:  singleMessage.Resoning,
+				Signature: string(singleMessage.ToolCalls[0].Signature),
+			})
+		}
+
+		messageContent := singleMessage.Content
+		if singleMessage.Resoning != "" && includeReasoning && !hasThinkingBlock {
+			messageContent = singleMessage.Resoning + messageContent
+		}
+
+		// Claude rejects empty text blocks
+		if strings.TrimSpace(messageContent) != "" {
+			content = append(content, claudeContent{Type: "text", Text: messageContent})
+		}
+
+		for _, attachment := range singleMessage.Attachments {
+			block, err := constructClaudeAttachment(attachment)
+			if err != nil {
+				return nil, err
+			}
+			content = append(content, block)
+		}
+
+		for _, tc := range singleMessage.ToolCalls {
+			if singleMessage.Role == "tool" {
+				result := ""
+				if tc.Result != nil {
+					result = *tc.Result
+				}
+				content = append(content, claudeContent{Type: "tool_result", ToolUseId: tc.Id, Content: result})
+				continue
+			}
+
+			content = append(content, claudeContent{
+				Type:  "tool_use",
+				Id:    tc.Id,
+				Name:  tc.Function.Name,
+				Input: tc.Function.Args,
+			})
+		}
+
+		if len(content) == 0 {
+			continue
+		}
+
+		if last := len(messages) - 1; last >= 0 && messages[last].Role == role {
+			messages[last].Content = append(messages[last].Content, content...)
+			continue
+		}
+
+		messages = append(messages, claudeMessage{Role: role, Content: content})
+	}
+
+	return messages, nil
+}
+
+// Images and PDFs are sent as is, text files as plain text documents
+func constructClaudeAttachment(attachment util.Attachment) (claudeContent, error) {
+	data, err := base64.StdEncoding.DecodeString(attachment.Content)
+	if err != nil {
+		util.Slog.Error("failed to decode file bytes", "item", attachment.Path, "error", err.Error())
+		return claudeContent{}, errors.New("could not prepare attachments for request")
+	}
+
+	mimeType := getMimeType(attachment.Path, data)
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return claudeContent{
+			Type:   "image",
+			Source: &claudeSource{Type: "base64", MediaType: mimeType, Data: attachment.Content},
+		}, nil
+	case mimeType == "application/pdf":
+		return claudeContent{
+			Type:   "document",
+			Source: &claudeSource{Type: "base64", MediaType: mimeType, Data: attachment.Content},
+		}, nil
+	case strings.HasPrefix(mimeType, "text/"):
+		return claudeContent{
+			Type:   "document",
+			Source: &claudeSource{Type: "text", MediaType: "text/plain", Data: string(data)},
+		}, nil
+	}
+
+	return claudeContent{}, fmt.Errorf("unsupported attachment type %s: %s", mimeType, filepath.Base(attachment.Path))
+}
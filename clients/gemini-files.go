@@ -0,0 +1,180 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+	"google.golang.org/genai"
+)
+
+// Attachments larger than this are uploaded with the Files API instead of being sent inline:
+// Gemini rejects requests over 20MB, and inline attachments are sent again with every message
+const geminiInlineLimitBytes = 4 * 1024 * 1024
+
+const (
+	fileProcessingPollInterval = 2 * time.Second
+	// Uploaded files are removed by Gemini after 48 hours, files close to expiration are uploaded again
+	fileExpirationMargin = time.Hour
+	filesCleanupTimeout  = 10 * time.Second
+)
+
+// Handles of uploaded attachments by session id and sha256 of the attachment content.
+// Files are deleted when the session is deleted or the app exits
+var uploadedFiles = struct {
+	mu       sync.Mutex
+	sessions map[int]map[string]*genai.File
+}{sessions: map[int]map[string]*genai.File{}}
+
+// Vertex AI has no Files API, attachments are always sent inline there
+func getAttachmentPart(ctx context.Context, client *genai.Client, attachment util.Attachment, data []byte) (*genai.Part, error) {
+	mimeType := getMimeType(attachment.Path, data)
+	if len(data) <= geminiInlineLimitBytes || client.ClientConfig().Backend == genai.BackendVertexAI {
+		return genai.NewPartFromBytes(data, mimeType), nil
+	}
+
+	file, err := getUploadedFile(ctx, client, attachment.Path, mimeType, data)
+	if err != nil {
+		util.Slog.Error("Gemini: failed to upload attachment", "item", attachment.Path, "error", err.Error())
+		return nil, fmt.Errorf("could not upload attachment %s: %w", filepath.Base(attachment.Path), err)
+	}
+
+	return genai.NewPartFromURI(file.URI, file.MIMEType), nil
+}
+
+// The whole history is sent with every request, so a file is uploaded once per session and reused after
+func getUploadedFile(ctx context.Context, client *genai.Client, path string, mimeType string, data []byte) (*genai.File, error) {
+	sessionId, _ := util.SessionIdFromContext(ctx)
+	hash := sha256.Sum256(data)
+	key := hex.EncodeToString(hash[:])
+
+	uploadedFiles.mu.Lock()
+	file, ok := uploadedFiles.sessions[sessionId][key]
+	uploadedFiles.mu.Unlock()
+
+	if ok && time.Until(file.ExpirationTime) > fileExpirationMargin {
+		return file, nil
+	}
+
+	util.Slog.Debug("Gemini: uploading attachment", "item", path, "size", len(data))
+	file, err := client.Files.Upload(ctx, bytes.NewReader(data), &genai.UploadFileConfig{
+		DisplayName: filepath.Base(path),
+		MIMEType:    mimeType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	file, err = waitForFileProcessing(ctx, client, file)
+	if err != nil {
+		return nil, err
+	}
+
+	uploadedFiles.mu.Lock()
+	if uploadedFiles.sessions[sessionId] == nil {
+		uploadedFiles.sessions[sessionId] = map[string]*genai.File{}
+	}
+	uploadedFiles.sessions[sessionId][key] = file
+	uploadedFiles.mu.Unlock()
+
+	return file, nil
+}
+
+// Videos and large documents are processed after upload and can't be used until they are active
+func waitForFileProcessing(ctx context.Context, client *genai.Client, file *genai.File) (*genai.File, error) {
+	var err error
+	for file.State == genai.FileStateProcessing {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(fileProcessingPollInterval):
+		}
+
+		file, err = client.Files.Get(ctx, file.Name, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if file.State == genai.FileStateFailed {
+		return nil, errors.New("file processing failed")
+	}
+	return file, nil
+}
+
+func getMimeType(path string, data []byte) string {
+	if mimeType := mime.TypeByExtension(filepath.Ext(path)); mimeType != "" {
+		return mimeType
+	}
+	return http.DetectContentType(data)
+}
+
+// Deletes files uploaded for the session, does nothing if there are none
+func ReleaseSessionFiles(sessionId int) tea.Cmd {
+	uploadedFiles.mu.Lock()
+	files := []*genai.File{}
+	for _, file := range uploadedFiles.sessions[sessionId] {
+		files = append(files, file)
+	}
+	delete(uploadedFiles.sessions, sessionId)
+	uploadedFiles.mu.Unlock()
+
+	if len(files) == 0 {
+		return nil
+	}
+
+	return func() tea.Msg {
+		deleteUploadedFiles(files)
+		return nil
+	}
+}
+
+// Deletes files uploaded during the app run. Called on exit
+func ReleaseUploadedFiles() {
+	uploadedFiles.mu.Lock()
+	files := []*genai.File{}
+	for _, sessionFiles := range uploadedFiles.sessions {
+		for _, file := range sessionFiles {
+			files = append(files, file)
+		}
+	}
+	uploadedFiles.sessions = map[int]map[string]*genai.File{}
+	uploadedFiles.mu.Unlock()
+
+	if len(files) > 0 {
+		deleteUploadedFiles(files)
+	}
+}
+
+// Failures are only logged, files that were not deleted expire on their own
+func deleteUploadedFiles(files []*genai.File) {
+	ctx, cancel := context.WithTimeout(context.Background(), filesCleanupTimeout)
+	defer cancel()
+
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:  os.Getenv("GEMINI_API_KEY"),
+		Backend: genai.BackendGeminiAPI,
+	})
+	if err != nil {
+		util.Slog.Error("Gemini: failed to delete uploaded files", "error", err.Error())
+		return
+	}
+
+	for _, file := range files {
+		if _, err := client.Files.Delete(ctx, file.Name, nil); err != nil {
+			util.Slog.Error("Gemini: failed to delete uploaded file", "file", file.Name, "error", err.Error())
+		}
+	}
+	util.Slog.Debug("Gemini: uploaded files deleted", "count", len(files))
+}
@@ -6,20 +6,16 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"slices"
 	"strings"
 
 	"github.com/BalanceBalls/nekot/config"
 	"github.com/BalanceBalls/nekot/util"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/googleapi"
-	"google.golang.org/api/iterator"
-	"google.golang.org/api/option"
+	"google.golang.org/genai"
 )
 
-const modelNamePrefix = "models/"
+const geminiApiUrl = "https://generativelanguage.googleapis.com"
 
 type processedChunk struct {
 	chunk      util.CompletionChunk
@@ -57,6 +53,26 @@ var webSearchTool = &genai.Tool{
 	},
 }
 
+var safetyCategories = map[string]genai.HarmCategory{
+	util.HarassmentCategory:       genai.HarmCategoryHarassment,
+	util.HateSpeechCategory:       genai.HarmCategoryHateSpeech,
+	util.SexuallyExplicitCategory: genai.HarmCategorySexuallyExplicit,
+	util.DangerousContentCategory: genai.HarmCategoryDangerousContent,
+}
+
+var safetyThresholds = map[string]genai.HarmBlockThreshold{
+	util.BlockNoneThreshold:           genai.HarmBlockThresholdBlockNone,
+	util.BlockOnlyHighThreshold:       genai.HarmBlockThresholdBlockOnlyHigh,
+	util.BlockMediumAndAboveThreshold: genai.HarmBlockThresholdBlockMediumAndAbove,
+	util.BlockLowAndAboveThreshold:    genai.HarmBlockThresholdBlockLowAndAbove,
+}
+
+// Built-in tools, they are run on Google servers and the results are sent with the response
+var (
+	codeExecutionTool = &genai.Tool{CodeExecution: &genai.ToolCodeExecution{}}
+	googleSearchTool  = &genai.Tool{GoogleSearch: &genai.GoogleSearch{}}
+)
+
 func (c GeminiClient) RequestCompletion(
 	ctx context.Context,
 	chatMsgs []util.LocalStoreMessage,
@@ -71,210 +87,202 @@ func (c GeminiClient) RequestCompletion(
 			panic("No config found in context")
 		}
 
-		client, err := genai.NewClient(ctx, option.WithAPIKey(os.Getenv("GEMINI_API_KEY")))
+		stream := util.NewResponseWriter(ctx, resultChan)
+		client, err := newGenaiClient(ctx, *config)
 		if err != nil {
-			util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{ID: util.ChunkIndexStart, Err: err, Final: true})
+			stream.Fail(err)
 			return nil
 		}
-		defer client.Close()
 
 		util.Slog.Debug("constructing message", "model", modelSettings.Model)
 
-		model := client.GenerativeModel(modelNamePrefix + modelSettings.Model)
-
-		if modelSettings.WebSearchEnabled {
-			model.Tools = []*genai.Tool{webSearchTool}
-		}
-
-		util.Slog.Debug("added tools", "tools", model.Tools)
-
-		setParams(model, *config, modelSettings)
+		generateConfig := getGenerateConfig(*config, modelSettings)
+		util.Slog.Debug("added tools", "tools", generateConfig.Tools)
 
-		cs := model.StartChat()
-		cs.History, err = buildChatHistory(chatMsgs, *config.IncludeReasoningTokensInContext)
+		history, err := buildChatHistory(ctx, client, chatMsgs, *config.IncludeReasoningTokensInContext)
 		if err != nil {
 			return util.MakeErrorMsg(err.Error())
 		}
 
-		iter := cs.SendMessageStream(ctx)
-		processResultID := util.GetNextProcessResultId(chatMsgs)
+		responses := client.Models.GenerateContentStream(ctx, modelSettings.Model, history, generateConfig)
 
 		var citations []string
-		for {
-			resp, err := iter.Next()
-			if err == iterator.Done {
-				util.Slog.Debug(
-					"Gemini: Iterator done. processResultID: ",
-					"result id",
-					processResultID,
-				)
-				sendCompensationChunk(ctx, resultChan, processResultID)
-				return nil
-			}
-
+		for resp, err := range responses {
 			if err != nil {
-				var apiErr *googleapi.Error
+				var apiErr genai.APIError
 				if errors.As(err, &apiErr) {
 					util.Slog.Error(
 						"Gemini: Encountered error while receiving response",
 						"error",
-						apiErr.Body,
+						apiErr.Message,
 					)
-					util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{ID: processResultID, Err: apiErr})
+					wrappedErr := &util.ApiError{
+						Provider:   util.GeminiProviderType,
+						StatusCode: apiErr.Code,
+						Body:       apiErr.Message,
+						Err:        apiErr,
+					}
+					stream.Fail(wrappedErr)
 				} else {
-					util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{ID: processResultID, Err: err})
+					stream.Fail(err)
 				}
-				break
+				return nil
 			}
 
-			result, err := processResponseChunk(resp, processResultID)
+			result, err := processResponseChunk(resp)
 			if err != nil {
 				util.Slog.Error("Gemini: Encountered error during chunks processing", "error", err)
-				util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{ID: processResultID, Err: err})
-				break
+				stream.Fail(err)
+				return nil
 			}
 
 			citations = append(citations, result.citations...)
-			util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{
-				ID:     processResultID,
-				Result: result.chunk,
-				Err:    nil,
-			})
+			stream.Send(result.chunk)
 
-			processResultID++
 			if result.isToolCall {
-				break
+				stream.Finish()
+				return nil
 			}
 
 			if result.isFinal {
-				if len(citations) > 0 {
-					sendCitationsChunk(ctx, resultChan, processResultID, citations)
-					processResultID++
-				}
-
-				sendCompensationChunk(ctx, resultChan, processResultID)
 				break
 			}
 		}
 
+		if len(citations) > 0 {
+			sendCitationsChunk(stream, citations)
+		}
+		stream.Finish()
 		return nil
 	}
 }
 
 func (c GeminiClient) RequestModelsList(ctx context.Context) util.ProcessModelsResponse {
-	client, err := genai.NewClient(ctx, option.WithAPIKey(os.Getenv("GEMINI_API_KEY")))
-	if err != nil {
-		return util.ProcessModelsResponse{Err: err}
+	cfg := config.Config{}
+	if configFromCtx, ok := config.FromContext(ctx); ok {
+		cfg = *configFromCtx
 	}
-	defer client.Close()
 
-	modelsIter := client.ListModels(ctx)
+	if err := util.CheckHostAllowed(getGeminiUrl(cfg)); err != nil {
+		return util.ProcessModelsResponse{Err: err}
+	}
 
-	if ctx.Err() == context.DeadlineExceeded {
-		return util.ProcessModelsResponse{Err: errors.New("timed out during fetching models")}
+	client, err := newGenaiClient(ctx, cfg)
+	if err != nil {
+		return util.ProcessModelsResponse{Err: err}
 	}
 
 	var modelsList []util.ModelDescription
-	for {
-		model, err := modelsIter.Next()
-		if err == iterator.Done {
-			return util.ProcessModelsResponse{
-				Result: util.ModelsListResponse{
-					Data: modelsList,
-				},
-				Err: nil,
-			}
+	for model, err := range client.Models.All(ctx) {
+		if ctx.Err() == context.DeadlineExceeded {
+			return util.ProcessModelsResponse{Err: errors.New("timed out during fetching models")}
 		}
 
 		if err != nil {
 			return util.ProcessModelsResponse{Err: err}
 		}
 
-		formattedName := strings.TrimPrefix(model.Name, modelNamePrefix)
+		// models/gemini-2.5-pro for Gemini API, publishers/google/models/gemini-2.5-pro for Vertex AI
+		formattedName := model.Name[strings.LastIndex(model.Name, "/")+1:]
 		modelsList = append(modelsList, util.ModelDescription{Id: formattedName})
 	}
+
+	return util.ProcessModelsResponse{
+		Result: util.ModelsListResponse{
+			Data: modelsList,
+		},
+		Err: nil,
+	}
+}
+
+// Gemini API is used with GEMINI_API_KEY. Vertex AI uses a service account key file
+// from vertexCredentialsFile or application default credentials (gcloud auth application-default login)
+func newGenaiClient(ctx context.Context, cfg config.Config) (*genai.Client, error) {
+	if cfg.GeminiBackend != util.VertexBackend {
+		return genai.NewClient(ctx, &genai.ClientConfig{
+			APIKey:  os.Getenv("GEMINI_API_KEY"),
+			Backend: genai.BackendGeminiAPI,
+		})
+	}
+
+	creds, err := loadVertexCredentials(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return genai.NewClient(ctx, &genai.ClientConfig{
+		Backend:     genai.BackendVertexAI,
+		Project:     cfg.VertexProject,
+		Location:    getVertexLocation(cfg),
+		Credentials: creds,
+	})
+}
+
+func getGeminiUrl(cfg config.Config) string {
+	if cfg.GeminiBackend != util.VertexBackend {
+		return geminiApiUrl
+	}
+	return getVertexUrl(cfg)
 }
 
-// Gemini may include actual sources with the response chunks which is pretty neat
+// Gemini and Perplexity may include actual sources with the response chunks which is pretty neat
 // The citations are collected from each chunk and sent together as the last chunk
 // because displaying citations all around the response is ugly
-func sendCitationsChunk(
-	ctx context.Context,
-	resultChan chan util.ProcessApiCompletionResponse,
-	id int,
-	citations []string,
-) {
+func sendCitationsChunk(stream *util.ResponseWriter, citations []string) {
 	var chunk util.CompletionChunk
-	chunk.ID = fmt.Sprint(id)
-
 	citations = util.RemoveDuplicates(citations)
 	citationsString := strings.Join(citations, "\n")
 	content := "\n\n`Sources`\n" + citationsString
 
 	choice := util.Choice{
-		Index: id,
 		Delta: map[string]any{
 			"content": content,
 		},
 	}
 
 	chunk.Choices = []util.Choice{choice}
-	util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{
-		ID:     id,
-		Result: chunk,
-		Final:  false,
-	})
+	stream.Send(chunk)
 }
 
-// Since orchestrator is built for openai apis, need to mimic open ai response structure
-// Gemeni sends finish reason with the last response, and openai apis send finish reason with an empty response
-func sendCompensationChunk(ctx context.Context, resultChan chan util.ProcessApiCompletionResponse, id int) {
-	util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{
-		ID: id,
-		Result: util.CompletionChunk{
-			ID: fmt.Sprint(id),
-			Choices: []util.Choice{
-				{
-					Index: id,
-					Delta: map[string]any{
-						"content": "",
-					},
-					FinishReason: "stop",
-				},
-			},
-		},
-		Final: true,
-	})
+func getTools(cfg config.Config, settings util.Settings) []*genai.Tool {
+	tools := []*genai.Tool{}
+	if settings.WebSearchEnabled {
+		tools = append(tools, webSearchTool)
+	}
 
-	nextId := id + 1
-	util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{
-		ID: nextId,
-		Result: util.CompletionChunk{
-			ID: fmt.Sprint(nextId),
-			Choices: []util.Choice{
-				{
-					Index: nextId,
-					Delta: map[string]any{
-						"content": "",
-					},
-					FinishReason: "",
-				},
-			},
-		},
-		Final: true,
-	})
-	util.Slog.Debug("Gemini: compensation chunks sent")
+	if cfg.GeminiCodeExecution {
+		tools = append(tools, codeExecutionTool)
+	}
+
+	if cfg.GeminiGoogleSearch {
+		tools = append(tools, googleSearchTool)
+	}
+
+	return tools
 }
 
-func setParams(model *genai.GenerativeModel, cfg config.Config, settings util.Settings) {
-	model.SetMaxOutputTokens(int32(settings.MaxTokens))
+func getGenerateConfig(cfg config.Config, settings util.Settings) *genai.GenerateContentConfig {
+	generateConfig := &genai.GenerateContentConfig{
+		MaxOutputTokens: int32(settings.MaxTokens),
+		TopP:            settings.TopP,
+		Temperature:     settings.Temperature,
+		Tools:           getTools(cfg, settings),
+	}
 
-	if settings.TopP != nil {
-		model.SetTopP(*settings.TopP)
+	for category, threshold := range cfg.GeminiSafetySettings {
+		generateConfig.SafetySettings = append(generateConfig.SafetySettings, &genai.SafetySetting{
+			Category:  safetyCategories[category],
+			Threshold: safetyThresholds[threshold],
+		})
 	}
 
-	if settings.Temperature != nil {
-		model.SetTemperature(*settings.Temperature)
+	// Thought summaries are requested only from thinking models, others reject the thinking config
+	if util.GetModelCapabilities(util.Gemini, settings.Model).Reasoning {
+		generateConfig.ThinkingConfig = &genai.ThinkingConfig{IncludeThoughts: true}
+		if settings.ReasoningBudget != nil {
+			budget := int32(util.GetReasoningTokens(*settings.ReasoningBudget))
+			generateConfig.ThinkingConfig.ThinkingBudget = &budget
+		}
 	}
 
 	if cfg.SystemMessage != "" || (settings.SystemPrompt != nil && *settings.SystemPrompt != "") {
@@ -282,17 +290,27 @@ func setParams(model *genai.GenerativeModel, cfg config.Config, settings util.Se
 		if settings.SystemPrompt != nil && *settings.SystemPrompt != "" {
 			systemMsg = *settings.SystemPrompt
 		}
-		model.SystemInstruction = genai.NewUserContent(genai.Text(systemMsg))
+		generateConfig.SystemInstruction = genai.NewContentFromText(systemMsg, genai.RoleUser)
 	}
+
+	return generateConfig
 }
 
 // Maps gemini response model to the openai response model
-func processResponseChunk(response *genai.GenerateContentResponse, id int) (processedChunk, error) {
+func processResponseChunk(response *genai.GenerateContentResponse) (processedChunk, error) {
 	var chunk util.CompletionChunk
-	chunk.ID = fmt.Sprint(id)
 
 	result := processedChunk{}
+	if feedback := response.PromptFeedback; feedback != nil && feedback.BlockReason != "" &&
+		feedback.BlockReason != genai.BlockedReasonUnspecified {
+		return result, describePromptBlock(feedback)
+	}
+
 	for _, candidate := range response.Candidates {
+		if isSafetyFinishReason(candidate.FinishReason) {
+			return result, describeResponseBlock(candidate)
+		}
+
 		if candidate.Content == nil {
 			break
 		}
@@ -308,26 +326,19 @@ func processResponseChunk(response *genai.GenerateContentResponse, id int) (proc
 			FinishReason: finishReason,
 		}
 
-		if len(candidate.Content.Parts) > 0 {
-			if candidate.CitationMetadata != nil &&
-				len(candidate.CitationMetadata.CitationSources) > 0 {
-				for _, source := range candidate.CitationMetadata.CitationSources {
-					if source.URI != nil {
-						sourceString := fmt.Sprintf("\t> [](%s)", *source.URI)
-						result.citations = append(result.citations, sourceString)
-					}
-				}
-			}
+		result.citations = append(result.citations, getCitations(candidate)...)
 
+		if len(candidate.Content.Parts) > 0 {
 			hasResponseContent := hasResponseContent(candidate.Content.Parts)
-			toolCalls := candidate.FunctionCalls()
+			toolCalls := getFunctionCallParts(candidate.Content.Parts)
 
 			if len(toolCalls) > 0 && !hasResponseContent {
 				responseToolCalls := []util.ToolCall{}
 				util.Slog.Debug("decided to include tool call request")
-				for _, tc := range toolCalls {
+				for _, part := range toolCalls {
+					tc := part.FunctionCall
 					if tc.Name == webSearchTool.FunctionDeclarations[0].Name {
-						query := tc.Args["query"].(string)
+						query, _ := tc.Args["query"].(string)
 						responseToolCalls = append(responseToolCalls, util.ToolCall{
 							Id:   "gemini_func",
 							Type: "function",
@@ -337,6 +348,7 @@ func processResponseChunk(response *genai.GenerateContentResponse, id int) (proc
 								},
 								Name: tc.Name,
 							},
+							Signature: part.ThoughtSignature,
 						})
 					}
 				}
@@ -346,8 +358,20 @@ func processResponseChunk(response *genai.GenerateContentResponse, id int) (proc
 			}
 
 			if len(toolCalls) == 0 {
+				content, reasoning := "", ""
+				for _, part := range candidate.Content.Parts {
+					if part.Thought {
+						reasoning += part.Text
+						continue
+					}
+					content += formatResponsePart(part)
+				}
+
 				choice.Delta = map[string]any{
-					"content": formatResponsePart(candidate.Content.Parts[0]),
+					"content": content,
+				}
+				if reasoning != "" {
+					choice.Delta["reasoning"] = reasoning
 				}
 			}
 		} else {
@@ -360,9 +384,11 @@ func processResponseChunk(response *genai.GenerateContentResponse, id int) (proc
 
 			util.Slog.Debug("gemini finish reason", "data", finishReason)
 			choice.FinishReason = ""
-			chunk.Usage = &util.TokenUsage{
-				Prompt:     int(response.UsageMetadata.PromptTokenCount),
-				Completion: int(response.UsageMetadata.CandidatesTokenCount),
+			if response.UsageMetadata != nil {
+				chunk.Usage = &util.TokenUsage{
+					Prompt:     int(response.UsageMetadata.PromptTokenCount),
+					Completion: int(response.UsageMetadata.CandidatesTokenCount + response.UsageMetadata.ThoughtsTokenCount),
+				}
 			}
 
 			result.isFinal = true
@@ -375,30 +401,132 @@ func processResponseChunk(response *genai.GenerateContentResponse, id int) (proc
 	return result, nil
 }
 
-func hasResponseContent(parts []genai.Part) bool {
-	return slices.ContainsFunc(parts, func(p genai.Part) bool {
-		switch p.(type) {
-		case genai.Text:
-			return true
-		default:
-			return false
+// Citation sources of recited content and web pages the response was grounded with by Google Search
+func getCitations(candidate *genai.Candidate) []string {
+	citations := []string{}
+	if candidate.CitationMetadata != nil {
+		for _, source := range candidate.CitationMetadata.Citations {
+			if source.URI != "" {
+				citations = append(citations, fmt.Sprintf("\t> [](%s)", source.URI))
+			}
+		}
+	}
+
+	if candidate.GroundingMetadata != nil {
+		for _, groundingChunk := range candidate.GroundingMetadata.GroundingChunks {
+			if groundingChunk.Web != nil && groundingChunk.Web.URI != "" {
+				citations = append(citations, fmt.Sprintf("\t> [%s](%s)", groundingChunk.Web.Title, groundingChunk.Web.URI))
+			}
 		}
+	}
+
+	return citations
+}
+
+func getFunctionCallParts(parts []*genai.Part) []*genai.Part {
+	calls := []*genai.Part{}
+	for _, part := range parts {
+		if part.FunctionCall != nil {
+			calls = append(calls, part)
+		}
+	}
+	return calls
+}
+
+// Thoughts are not a response, they may come in the same chunk with a tool call
+func hasResponseContent(parts []*genai.Part) bool {
+	return slices.ContainsFunc(parts, func(p *genai.Part) bool {
+		return (p.Text != "" && !p.Thought) || p.ExecutableCode != nil || p.CodeExecutionResult != nil
 	})
+}
 
+// Code execution parts are rendered as labeled code blocks, so they stand out from the text of the response
+func formatResponsePart(part *genai.Part) string {
+	switch {
+	case part.ExecutableCode != nil:
+		return fmt.Sprintf("\n\n`Code execution`\n```python\n%s\n```\n", strings.TrimSpace(part.ExecutableCode.Code))
+	case part.CodeExecutionResult != nil:
+		label := "`Result`"
+		if part.CodeExecutionResult.Outcome != genai.OutcomeOK {
+			label = fmt.Sprintf("`Result: %s`", formatCodeExecutionOutcome(part.CodeExecutionResult.Outcome))
+		}
+		return fmt.Sprintf("\n%s\n```\n%s\n```\n\n", label, strings.TrimSpace(part.CodeExecutionResult.Output))
+	case part.Text != "":
+		return part.Text
+	case part.InlineData != nil || part.FileData != nil:
+		util.Slog.Warn("Gemini: unsupported response part is skipped")
+	}
+	return ""
 }
 
-func formatResponsePart(part genai.Part) string {
-	switch v := part.(type) {
-	case genai.Text:
-		response := string(v)
-		return response
+func formatCodeExecutionOutcome(outcome genai.Outcome) string {
+	switch outcome {
+	case genai.OutcomeFailed:
+		return "failed"
+	case genai.OutcomeDeadlineExceeded:
+		return "timed out"
 	default:
-		panic("Only text type is supported")
+		return "unknown"
+	}
+}
+
+func isSafetyFinishReason(reason genai.FinishReason) bool {
+	switch reason {
+	case genai.FinishReasonSafety,
+		genai.FinishReasonBlocklist,
+		genai.FinishReasonProhibitedContent,
+		genai.FinishReasonSPII:
+		return true
+	}
+	return false
+}
+
+func describePromptBlock(feedback *genai.GenerateContentResponsePromptFeedback) error {
+	return fmt.Errorf(
+		"Gemini blocked the prompt (%s)%s",
+		formatEnumName(string(feedback.BlockReason)),
+		formatBlockedCategories(feedback.SafetyRatings))
+}
+
+func describeResponseBlock(candidate *genai.Candidate) error {
+	return fmt.Errorf(
+		"Gemini blocked the response (%s)%s",
+		formatEnumName(string(candidate.FinishReason)),
+		formatBlockedCategories(candidate.SafetyRatings))
+}
+
+// Blocked categories are named as in geminiSafetySettings, so the user knows what to adjust
+func formatBlockedCategories(ratings []*genai.SafetyRating) string {
+	blocked := []string{}
+	for _, rating := range ratings {
+		if rating == nil || !rating.Blocked {
+			continue
+		}
+
+		category := formatEnumName(strings.TrimPrefix(string(rating.Category), "HARM_CATEGORY_"))
+		for name, value := range safetyCategories {
+			if value == rating.Category {
+				category = name
+			}
+		}
+
+		probability := formatEnumName(string(rating.Probability))
+		blocked = append(blocked, fmt.Sprintf("%s (%s probability)", category, probability))
+	}
+
+	if len(blocked) == 0 {
+		return ""
 	}
+	return ": " + strings.Join(blocked, ", ") + ". Adjust geminiSafetySettings in the config to allow it"
+}
+
+func formatEnumName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", " ")
 }
 
 func handleFinishReason(reason genai.FinishReason) (string, error) {
 	switch reason {
+	case "":
 	case genai.FinishReasonStop:
 		return "stop", nil
 	case genai.FinishReasonMaxTokens:
@@ -409,7 +537,8 @@ func handleFinishReason(reason genai.FinishReason) (string, error) {
 		return "", errors.New(
 			"LLM stopped responding due to response containing copyright material",
 		)
-	case genai.FinishReasonSafety:
+	case genai.FinishReasonMalformedFunctionCall:
+		return "", errors.New("LLM stopped responding due to a malformed tool call")
 	default:
 		util.Slog.Error("unexpected genai.FinishReason", "finish reason", reason)
 		return "", errors.New("GeminiAPI: unsupported finish reason")
@@ -418,19 +547,20 @@ func handleFinishReason(reason genai.FinishReason) (string, error) {
 	return "", nil
 }
 
-func buildChatHistory(msgs []util.LocalStoreMessage, includeReasoning bool) ([]*genai.Content, error) {
+func buildChatHistory(
+	ctx context.Context,
+	client *genai.Client,
+	msgs []util.LocalStoreMessage,
+	includeReasoning bool,
+) ([]*genai.Content, error) {
 	chat := []*genai.Content{}
 
 	util.Slog.Debug("building messages history:", "data", msgs)
 
 	for _, singleMessage := range msgs {
-		role := "user"
+		role := string(genai.RoleUser)
 		if singleMessage.Role == "assistant" {
-			role = "model"
-		}
-
-		if singleMessage.Role == "tool" {
-			role = "function"
+			role = string(genai.RoleModel)
 		}
 
 		messageContent := ""
@@ -443,12 +573,12 @@ func buildChatHistory(msgs []util.LocalStoreMessage, includeReasoning bool) ([]*
 		}
 
 		message := genai.Content{
-			Parts: []genai.Part{},
+			Parts: []*genai.Part{},
 			Role:  role,
 		}
 
 		if messageContent != "" {
-			message.Parts = append(message.Parts, genai.Text(messageContent))
+			message.Parts = append(message.Parts, genai.NewPartFromText(messageContent))
 		}
 
 		if len(singleMessage.Attachments) != 0 {
@@ -460,31 +590,28 @@ func buildChatHistory(msgs []util.LocalStoreMessage, includeReasoning bool) ([]*
 					return nil, errors.New("could not prepare attachments for request")
 				}
 
-				extension := filepath.Ext(item.Path)
-				extension = strings.TrimPrefix(extension, ".")
-				part := genai.ImageData(extension, decodedBytes)
+				part, err := getAttachmentPart(ctx, client, item, decodedBytes)
+				if err != nil {
+					return nil, err
+				}
 				message.Parts = append(message.Parts, part)
 			}
 		}
 
 		if len(singleMessage.ToolCalls) != 0 {
 			for _, tc := range singleMessage.ToolCalls {
-				var part genai.Part
+				var part *genai.Part
 
-				if role == "function" {
+				if singleMessage.Role == "tool" {
 					util.Slog.Debug("appending tool call result", "data", tc)
-					part = genai.FunctionResponse{
-						Name: tc.Function.Name,
-						Response: map[string]any{
-							"query":  tc.Function.Args["query"],
-							"result": *tc.Result,
-						}}
+					part = genai.NewPartFromFunctionResponse(tc.Function.Name, map[string]any{
+						"query":  tc.Function.Args["query"],
+						"result": *tc.Result,
+					})
 				} else {
 					util.Slog.Debug("appending tool call request", "data", tc)
-					part = genai.FunctionCall{
-						Name: tc.Function.Name,
-						Args: map[string]any{"query": tc.Function.Args["query"]},
-					}
+					part = genai.NewPartFromFunctionCall(tc.Function.Name, map[string]any{"query": tc.Function.Args["query"]})
+					part.ThoughtSignature = tc.Signature
 				}
 
 				message.Parts = append(message.Parts, part)
@@ -0,0 +1,259 @@
+package clients
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const healthCheckTimeout = 30 * time.Second
+
+type HealthCheckFailure string
+
+const (
+	NoFailure         HealthCheckFailure = ""
+	AuthFailure       HealthCheckFailure = "auth"
+	DnsFailure        HealthCheckFailure = "dns"
+	TlsFailure        HealthCheckFailure = "tls"
+	QuotaFailure      HealthCheckFailure = "quota"
+	ModelFailure      HealthCheckFailure = "model"
+	TimeoutFailure    HealthCheckFailure = "timeout"
+	ConnectionFailure HealthCheckFailure = "connection"
+	UnknownFailure    HealthCheckFailure = "unknown"
+)
+
+type HealthCheckResult struct {
+	Provider string
+	BaseUrl  string
+	Model    string
+	Latency  time.Duration
+	Failure  HealthCheckFailure
+	Err      error
+}
+
+func (r HealthCheckResult) Ok() bool {
+	return r.Failure == NoFailure
+}
+
+func (r HealthCheckResult) String() string {
+	if r.Ok() && r.Model == "" {
+		return "ok: connected to " + r.BaseUrl
+	}
+	if r.Ok() {
+		return fmt.Sprintf("ok: %s responded in %dms", r.Model, r.Latency.Milliseconds())
+	}
+	return fmt.Sprintf("%s failure: %s", r.Failure, r.Err)
+}
+
+// Validates the API key, the base url and the model with a tiny completion request.
+// Empty model skips the completion request
+func CheckConnection(ctx context.Context, cfg config.Config, model string) HealthCheckResult {
+	result := HealthCheckResult{
+		Provider: cfg.Provider,
+		BaseUrl:  GetProviderUrl(cfg),
+		Model:    model,
+	}
+
+	ctx, cancel := context.WithTimeout(config.WithConfig(ctx, &cfg), healthCheckTimeout)
+	defer cancel()
+
+	if err := checkApiKey(cfg); err != nil {
+		return result.withError(AuthFailure, err)
+	}
+
+	host, err := getProviderHost(result.BaseUrl)
+	if err != nil {
+		return result.withError(ConnectionFailure, err)
+	}
+
+	if _, err := net.DefaultResolver.LookupHost(ctx, host); err != nil {
+		return result.withError(classifyConnectionError(err), err)
+	}
+
+	llmClient := ResolveLlmClient(cfg.Provider, cfg.ProviderBaseUrl, "")
+	modelsResponse := llmClient.RequestModelsList(ctx)
+	if modelsResponse.Err != nil {
+		return result.withError(classifyConnectionError(modelsResponse.Err), modelsResponse.Err)
+	}
+
+	if model == "" {
+		return result
+	}
+
+	availableModels := modelsResponse.Result.GetModelNamesFromResponse()
+	if len(availableModels) > 0 && !slices.Contains(availableModels, model) {
+		return result.withError(ModelFailure, fmt.Errorf("model %s is not available", model))
+	}
+
+	start := time.Now()
+	err = requestTinyCompletion(ctx, llmClient, model)
+	result.Latency = time.Since(start)
+	if err != nil {
+		return result.withError(classifyConnectionError(err), err)
+	}
+
+	return result
+}
+
+func (r HealthCheckResult) withError(failure HealthCheckFailure, err error) HealthCheckResult {
+	r.Failure = failure
+	r.Err = err
+	return r
+}
+
+// Sends a single short message and waits for the first chunk of the response
+func requestTinyCompletion(ctx context.Context, llmClient util.LlmClient, model string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	resultChan := make(chan util.ProcessApiCompletionResponse)
+	cmdResult := make(chan tea.Msg, 1)
+	msgs := []util.LocalStoreMessage{{Role: "user", Content: "ping"}}
+	settings := util.Settings{Model: model, MaxTokens: 16}
+
+	go func() {
+		cmdResult <- llmClient.RequestCompletion(ctx, msgs, settings, resultChan)()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case chunk := <-resultChan:
+			return chunk.Err
+
+		case msg := <-cmdResult:
+			// Some clients report request errors as an error command instead of a chunk
+			if errCmd, ok := msg.(tea.Cmd); ok && errCmd != nil {
+				msg = errCmd()
+			}
+			if errMsg, ok := msg.(util.ErrorEvent); ok {
+				return errors.New(errMsg.Message)
+			}
+			cmdResult = nil
+		}
+	}
+}
+
+func checkApiKey(cfg config.Config) error {
+	keyName := ""
+	switch cfg.Provider {
+	case util.OpenAiProviderType:
+		if util.IsLocalProvider(cfg.ProviderBaseUrl) || cfg.ProviderAuth[cfg.Provider].Scheme == config.NoAuth {
+			return nil
+		}
+		keyName = "OPENAI_API_KEY"
+	case util.GeminiProviderType:
+		if cfg.GeminiBackend == util.VertexBackend {
+			return nil
+		}
+		keyName = "GEMINI_API_KEY"
+	case util.OpenrouterProviderType:
+		keyName = "OPENROUTER_API_KEY"
+	case util.BedrockProviderType:
+		_, err := loadAwsCredentials(cfg)
+		return err
+	}
+
+	if keyName != "" && os.Getenv(keyName) == "" {
+		return fmt.Errorf("%s is not set", keyName)
+	}
+	return nil
+}
+
+func GetProviderUrl(cfg config.Config) string {
+	switch cfg.Provider {
+	case util.GeminiProviderType:
+		return getGeminiUrl(cfg)
+	case util.OpenrouterProviderType:
+		return openrouterApiUrl
+	case util.VertexProviderType:
+		return getVertexUrl(cfg)
+	case util.BedrockProviderType:
+		return getBedrockRuntimeUrl(cfg)
+	}
+	return getBaseUrl(cfg.ProviderBaseUrl)
+}
+
+func getProviderHost(providerUrl string) (string, error) {
+	parsedUrl, err := url.Parse(providerUrl)
+	if err != nil {
+		return "", err
+	}
+	if parsedUrl.Hostname() == "" {
+		return "", fmt.Errorf("invalid provider url: %s", providerUrl)
+	}
+	return parsedUrl.Hostname(), nil
+}
+
+var (
+	authErrorKeywords  = []string{"401", "403", "unauthorized", "invalid_api_key", "incorrect api key", "api key not valid", "permission_denied"}
+	quotaErrorKeywords = []string{"429", "quota", "rate limit", "resource_exhausted", "insufficient"}
+	modelErrorKeywords = []string{"404", "model_not_found", "not found", "does not exist", "no endpoints"}
+)
+
+func classifyConnectionError(err error) HealthCheckFailure {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return DnsFailure
+	}
+
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var recordHeaderErr tls.RecordHeaderError
+	if errors.As(err, &certErr) ||
+		errors.As(err, &unknownAuthorityErr) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &recordHeaderErr) {
+		return TlsFailure
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return TimeoutFailure
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return TimeoutFailure
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ConnectionFailure
+	}
+
+	message := strings.ToLower(err.Error())
+	switch {
+	case containsKeyword(message, authErrorKeywords):
+		return AuthFailure
+	case containsKeyword(message, quotaErrorKeywords):
+		return QuotaFailure
+	case containsKeyword(message, modelErrorKeywords):
+		return ModelFailure
+	}
+
+	return UnknownFailure
+}
+
+func containsKeyword(value string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.Contains(value, keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -131,8 +131,10 @@ func (c OpenAiClient) RequestCompletion(
 ) tea.Cmd {
 	apiKey := os.Getenv("OPENAI_API_KEY")
 	path := "v1/chat/completions"
-	processResultID := util.GetNextProcessResultId(chatMsgs)
-
+	// Perplexity serves the API without the version prefix
+	if c.provider == util.Perplexity {
+		path = "chat/completions"
+	}
 	return func() tea.Msg {
 		config, ok := config.FromContext(ctx)
 		if !ok {
@@ -150,12 +152,24 @@ func (c OpenAiClient) RequestCompletion(
 			return util.MakeErrorMsg(err.Error())
 		}
 
-		c.processCompletionResponse(ctx, resp, resultChan, &processResultID)
+		c.processCompletionResponse(ctx, resp, util.NewResponseWriter(ctx, resultChan))
 		return nil
 	}
 }
 
 func (c OpenAiClient) RequestModelsList(ctx context.Context) util.ProcessModelsResponse {
+	if err := util.CheckHostAllowed(c.apiUrl); err != nil {
+		return util.ProcessModelsResponse{Err: err}
+	}
+
+	if models, ok := util.GetStaticModelList(c.provider); ok {
+		modelsList := []util.ModelDescription{}
+		for _, model := range models {
+			modelsList = append(modelsList, util.ModelDescription{Id: model})
+		}
+		return util.ProcessModelsResponse{Result: util.ModelsListResponse{Data: modelsList}}
+	}
+
 	apiKey := os.Getenv("OPENAI_API_KEY")
 	path := "v1/models"
 
@@ -249,15 +263,16 @@ func (c OpenAiClient) constructCompletionRequestPayload(
 ) ([]byte, error) {
 	messages := []OpenAIConversationTurn{}
 
-	if util.IsSystemMessageSupported(c.provider, settings.Model) {
-		if cfg.SystemMessage != "" || settings.SystemPrompt != nil {
-			systemMsg := cfg.SystemMessage
-			if settings.SystemPrompt != nil && *settings.SystemPrompt != "" {
-				systemMsg = *settings.SystemPrompt
-			}
-
+	// models that mishandle the system role get the system prompt in the first user message
+	userPrefix := ""
+	systemMsg := getSystemMessage(cfg, settings)
+	switch util.GetSystemMessageMode(c.provider, settings.Model, cfg.SystemMessageModes) {
+	case util.SystemMessageAsSystem:
+		if systemMsg != "" {
 			messages = append(messages, constructSystemMessage(systemMsg))
 		}
+	case util.SystemMessageAsUser:
+		userPrefix = systemMsg
 	}
 
 	for _, singleMessage := range chatMsgs {
@@ -270,6 +285,11 @@ func (c OpenAiClient) constructCompletionRequestPayload(
 			messageContent += singleMessage.Content
 		}
 
+		if singleMessage.Role == "user" && userPrefix != "" {
+			messageContent = userPrefix + "\n\n" + messageContent
+			userPrefix = ""
+		}
+
 		if messageContent != "" {
 			singleMessage.Content = messageContent
 		}
@@ -303,6 +323,12 @@ func (c OpenAiClient) constructCompletionRequestPayload(
 		reqParams["tools"] = []any{openAIwebSearchTool}
 	}
 
+	// OpenAI rejects reasoning_effort for non reasoning models, local servers ignore unknown params
+	isReasoningModel := util.GetModelCapabilities(c.provider, settings.Model).Reasoning
+	if settings.ReasoningBudget != nil && (c.provider == util.Local || c.provider == util.OpenAi && isReasoningModel) {
+		reqParams["reasoning_effort"] = util.GetReasoningEffort(*settings.ReasoningBudget)
+	}
+
 	util.TransformRequestHeaders(c.provider, reqParams)
 
 	body, err := json.Marshal(reqParams)
@@ -339,7 +365,7 @@ func (c OpenAiClient) getOpenAiAPI(
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+	setRequestHeaders(ctx, req, apiKey)
 
 	client := &http.Client{}
 	return client.Do(req)
@@ -359,12 +385,41 @@ func (c OpenAiClient) postOpenAiAPI(
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+	setRequestHeaders(ctx, req, apiKey)
 
 	client := &http.Client{}
 	return client.Do(req)
 }
 
+// Sends the api key according to the auth scheme of the provider, then organization,
+// project and custom headers from the config. Self-hosted gateways often expect x-api-key or basic auth
+func setRequestHeaders(ctx context.Context, req *http.Request, apiKey string) {
+	cfg, ok := config.FromContext(ctx)
+	if !ok {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+		return
+	}
+
+	auth := cfg.ProviderAuth[util.OpenAiProviderType]
+	switch auth.Scheme {
+	case config.HeaderAuth:
+		header := auth.Header
+		if header == "" {
+			header = config.DefaultAuthHeader
+		}
+		req.Header.Set(header, apiKey)
+	case config.BasicAuth:
+		req.SetBasicAuth(auth.Username, apiKey)
+	case config.NoAuth:
+	default:
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+	}
+
+	for name, value := range cfg.GetProviderHeaders(util.OpenAiProviderType) {
+		req.Header.Set(name, value)
+	}
+}
+
 func processModelsListResponse(resp *http.Response) util.ProcessModelsResponse {
 	defer resp.Body.Close()
 
@@ -395,18 +450,32 @@ func processModelsListResponse(resp *http.Response) util.ProcessModelsResponse {
 func (c OpenAiClient) processCompletionResponse(
 	ctx context.Context,
 	resp *http.Response,
-	resultChan chan util.ProcessApiCompletionResponse,
-	processResultID *int,
+	stream *util.ResponseWriter,
 ) {
 	defer resp.Body.Close()
 
+	// OpenAI compatible APIs have no cancellation endpoint for chat completions,
+	// dropping the connection is what makes the server abort generation.
+	// The body is closed right away on cancel instead of waiting for the next read to fail
+	stopAbortWatch := context.AfterFunc(ctx, func() {
+		resp.Body.Close()
+	})
+	defer stopAbortWatch()
+
 	if resp.StatusCode >= 400 {
 		bodyBytes, err := io.ReadAll(resp.Body)
 		if err != nil {
-			util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{ID: *processResultID, Err: err})
+			stream.Fail(err)
 			return
 		}
-		util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{ID: *processResultID, Err: fmt.Errorf("%s", string(bodyBytes))})
+		apiErr := &util.ApiError{
+			Provider:   util.OpenAiProviderType,
+			StatusCode: resp.StatusCode,
+			RequestId:  getRequestId(resp.Header),
+			Body:       string(bodyBytes),
+			Err:        fmt.Errorf("%s", string(bodyBytes)),
+		}
+		stream.Fail(apiErr)
 		return
 	}
 
@@ -414,15 +483,28 @@ func (c OpenAiClient) processCompletionResponse(
 		Chunks: []OpenAiToolCallsDelta{},
 	}
 
+	lenientStream := false
+	if cfg, ok := config.FromContext(ctx); ok {
+		lenientStream = cfg.ProviderProfile != ""
+	}
+	hasFinishReason := false
+	sources := []string{}
+
 	util.Slog.Debug("starting response processing loop")
 
 	scanner := bufio.NewReader(resp.Body)
 	for {
 		line, err := scanner.ReadString('\n')
 		if err != nil {
+			if err == io.EOF && lenientStream {
+				util.Slog.Info("OpenAI: stream ended without [DONE]")
+				stream.Finish()
+				return
+			}
+
 			if err == io.EOF {
 				util.Slog.Warn("OpenAI: scanner returned EOF", "error", err.Error())
-				util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{ID: *processResultID, Err: io.ErrUnexpectedEOF, Final: true})
+				stream.Fail(io.ErrUnexpectedEOF)
 				break
 			}
 
@@ -431,19 +513,37 @@ func (c OpenAiClient) processCompletionResponse(
 				"error",
 				err.Error(),
 			)
-			util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{ID: *processResultID, Err: err, Final: true})
+			stream.Fail(err)
 			return
 		}
 
 		if line == "data: [DONE]\n" {
 			util.Slog.Info("OpenAI: Received [DONE]")
-			util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{ID: *processResultID, Err: nil, Final: true})
+			if !hasFinishReason {
+				sendSourcesChunk(stream, sources)
+			}
+			stream.Finish()
 			return
 		}
 
 		if after, ok := strings.CutPrefix(line, "data:"); ok {
 			jsonStr := after
-			chunk := processChunk(jsonStr, *processResultID)
+			chunk := processChunk(jsonStr)
+			if lenientStream {
+				chunk = normalizeFinishReason(chunk)
+			}
+
+			if chunkSources := getChunkSources(chunk.Result); len(chunkSources) > 0 {
+				sources = chunkSources
+			}
+
+			if len(chunk.Result.Choices) > 0 && chunk.Result.Choices[0].FinishReason != "" {
+				hasFinishReason = true
+				// Sources go right before the finish reason, so they end up in the stored message
+				sendSourcesChunk(stream, sources)
+				sources = nil
+			}
+
 			if isToolCall(chunk, toolCallsBuffer) {
 				toolCallChunk, isReady := toolCallsBuffer.handleToolCallChunk(chunk)
 				if !isReady {
@@ -451,18 +551,53 @@ func (c OpenAiClient) processCompletionResponse(
 				}
 
 				util.Slog.Info("OpenAI: Tool call interruption sent")
-				util.WriteToResponseChannel(ctx, resultChan, toolCallChunk)
-				*processResultID++
-				util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{ID: *processResultID, Err: nil, Final: true})
+				stream.Write(toolCallChunk)
+				stream.Finish()
 				break
 			}
 
-			util.WriteToResponseChannel(ctx, resultChan, chunk)
-			*processResultID++
+			stream.Write(chunk)
 		}
 	}
 }
 
+func sendSourcesChunk(stream *util.ResponseWriter, sources []string) {
+	if len(sources) == 0 {
+		return
+	}
+	sendCitationsChunk(stream, sources)
+}
+
+// Perplexity sends the sources of a response with every chunk.
+// Search results have page titles, older models send urls only
+func getChunkSources(chunk util.CompletionChunk) []string {
+	sources := []string{}
+	for _, result := range chunk.SearchResults {
+		sources = append(sources, fmt.Sprintf("\t> [%s](%s)", result.Title, result.Url))
+	}
+
+	if len(sources) > 0 {
+		return sources
+	}
+
+	for _, citation := range chunk.Citations {
+		sources = append(sources, fmt.Sprintf("\t> [](%s)", citation))
+	}
+	return sources
+}
+
+// llama.cpp and LM Studio may report non standard finish reasons, e.g. eos
+func normalizeFinishReason(chunk util.ProcessApiCompletionResponse) util.ProcessApiCompletionResponse {
+	for i, choice := range chunk.Result.Choices {
+		switch choice.FinishReason {
+		case "", "stop", "length", "tool_calls":
+		default:
+			chunk.Result.Choices[i].FinishReason = "stop"
+		}
+	}
+	return chunk
+}
+
 func isToolCall(chunk util.ProcessApiCompletionResponse, buffer OpenAiToolCallsBuffer) bool {
 	if len(buffer.Chunks) > 0 {
 		return true
@@ -490,7 +625,7 @@ func (b *OpenAiToolCallsBuffer) handleToolCallChunk(chunk util.ProcessApiComplet
 
 		toolCalls, err := b.mergeBuffer(chunk)
 		if err != nil {
-			return util.ProcessApiCompletionResponse{ID: chunk.ID, Result: util.CompletionChunk{}, Err: err}, true
+			return util.ProcessApiCompletionResponse{Err: err}, true
 		}
 
 		chunk.Result.Choices[0].ToolCalls = toolCalls
@@ -505,7 +640,7 @@ func (b *OpenAiToolCallsBuffer) handleToolCallChunk(chunk util.ProcessApiComplet
 	err = json.Unmarshal(toolCallsJson, &toolCallsDeltaArr)
 	if err != nil {
 		util.Slog.Error("error unmarshalling tool_calls delta:", "delta", string(toolCallsJson), "error", err.Error())
-		return util.ProcessApiCompletionResponse{ID: chunk.ID, Result: util.CompletionChunk{}, Err: err}, false
+		return util.ProcessApiCompletionResponse{Err: err}, false
 	}
 
 	toolCallsDelta := toolCallsDeltaArr[0]
@@ -605,15 +740,15 @@ func (b *OpenAiToolCallsBuffer) mergeBuffer(chunk util.ProcessApiCompletionRespo
 	return result, nil
 }
 
-func processChunk(chunkData string, id int) util.ProcessApiCompletionResponse {
+func processChunk(chunkData string) util.ProcessApiCompletionResponse {
 	var chunk util.CompletionChunk
 	err := json.Unmarshal([]byte(chunkData), &chunk)
 	if err != nil {
 		util.Slog.Error("error unmarshalling:", "chunk data", chunkData, "error", err.Error())
-		return util.ProcessApiCompletionResponse{ID: id, Result: util.CompletionChunk{}, Err: err}
+		return util.ProcessApiCompletionResponse{Err: err}
 	}
 
-	return util.ProcessApiCompletionResponse{ID: id, Result: chunk, Err: nil}
+	return util.ProcessApiCompletionResponse{Result: chunk}
 }
 
 func toOpenAiToolCall(tc util.ToolCall) OpenAiToolCall {
@@ -641,3 +776,10 @@ func fromOpenAiToolCall(tc OpenAiToolCall) util.ToolCall {
 		},
 	}
 }
+
+func getRequestId(header http.Header) string {
+	if id := header.Get("x-request-id"); id != "" {
+		return id
+	}
+	return header.Get("request-id")
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sync"
 
 	"github.com/BalanceBalls/nekot/config"
 	"github.com/BalanceBalls/nekot/util"
@@ -14,6 +15,8 @@ import (
 	"github.com/revrost/go-openrouter"
 )
 
+const openrouterApiUrl = "https://openrouter.ai"
+
 var openRouterwebSearchTool = openrouter.Tool{
 	Type: openrouter.ToolTypeFunction,
 	Function: &openrouter.FunctionDefinition{
@@ -66,16 +69,22 @@ func (c OpenrouterClient) RequestCompletion(
 		setRequestParams(&request, modelSettings)
 		setRequestContext(&request, *config, modelSettings, chatMsgs)
 
+		writer := util.NewResponseWriter(ctx, resultChan)
 		stream, err := client.CreateChatCompletionStream(ctx, request)
 		if err != nil {
-			util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{ID: util.ChunkIndexStart, Err: err, Final: true})
+			writer.Fail(wrapOpenrouterError(err))
 			return nil
 		}
-		defer stream.Close()
+		// Closing the stream drops the connection, which aborts generation on the OpenRouter side.
+		// Stream can not be closed twice, hence the once wrapper
+		closeStream := sync.OnceFunc(stream.Close)
+		defer closeStream()
+
+		stopAbortWatch := context.AfterFunc(ctx, closeStream)
+		defer stopAbortWatch()
 
 		util.Slog.Debug("constructing message", "model", modelSettings.Model)
 
-		processResultID := util.GetNextProcessResultId(chatMsgs)
 		toolCallsBuffer := OpenRouterToolCallsBuffer{
 			Chunks: []openrouter.ToolCall{},
 		}
@@ -89,14 +98,13 @@ func (c OpenrouterClient) RequestCompletion(
 					"error",
 					err.Error(),
 				)
-				util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{ID: processResultID, Err: err, Final: true})
+				writer.Fail(wrapOpenrouterError(err))
 				break
 			}
 
-			processResultID++
 			if errors.Is(err, io.EOF) {
 				util.Slog.Info("Openrouter: Received [DONE]")
-				util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{ID: processResultID, Err: nil, Final: true})
+				writer.Finish()
 				break
 			}
 
@@ -108,30 +116,18 @@ func (c OpenrouterClient) RequestCompletion(
 				}
 
 				util.Slog.Info("OpenRouter: Tool call interruption sent")
-				util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{
-					ID:     processResultID,
-					Result: toolCallChunk,
-					Err:    nil,
-					Final:  false,
-				})
-
-				processResultID++
-				util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{ID: processResultID, Err: nil, Final: true})
+				writer.Send(toolCallChunk)
+				writer.Finish()
 				break
 			}
 
 			result, err := processCompletionChunk(response)
 			if err != nil {
-				util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{ID: processResultID, Err: err})
+				writer.Fail(err)
 				break
 			}
 
-			util.WriteToResponseChannel(ctx, resultChan, util.ProcessApiCompletionResponse{
-				ID:     processResultID,
-				Result: result,
-				Err:    nil,
-				Final:  false,
-			})
+			writer.Send(result)
 		}
 
 		return nil
@@ -139,6 +135,10 @@ func (c OpenrouterClient) RequestCompletion(
 }
 
 func (c OpenrouterClient) RequestModelsList(ctx context.Context) util.ProcessModelsResponse {
+	if err := util.CheckHostAllowed(openrouterApiUrl); err != nil {
+		return util.ProcessModelsResponse{Err: err}
+	}
+
 	client := openrouter.NewClient(os.Getenv("OPENROUTER_API_KEY"))
 
 	client.ListUserModels(ctx)
@@ -307,6 +307,12 @@ func setRequestParams(
 	if settings.WebSearchEnabled {
 		r.Tools = []openrouter.Tool{openRouterwebSearchTool}
 	}
+
+	// OpenRouter maps max_tokens to the budget_tokens of Anthropic models and to the effort of OpenAI models
+	if settings.ReasoningBudget != nil {
+		tokens := util.GetReasoningTokens(*settings.ReasoningBudget)
+		r.Reasoning = &openrouter.ChatCompletionReasoning{MaxTokens: &tokens}
+	}
 }
 
 func processCompletionChunk(chunk openrouter.ChatCompletionStreamResponse) (util.CompletionChunk, error) {
@@ -509,3 +515,29 @@ func fromOpenRouterToolCall(tc openrouter.ToolCall) util.ToolCall {
 		},
 	}
 }
+
+// Extracts request details from OpenRouter errors for the error inspector
+func wrapOpenrouterError(err error) error {
+	var requestErr *openrouter.RequestError
+	if errors.As(err, &requestErr) {
+		return &util.ApiError{
+			Provider:   util.OpenrouterProviderType,
+			StatusCode: requestErr.HTTPStatusCode,
+			Body:       string(requestErr.Body),
+			Err:        err,
+		}
+	}
+
+	var apiErr *openrouter.APIError
+	if errors.As(err, &apiErr) {
+		body, _ := json.Marshal(apiErr)
+		return &util.ApiError{
+			Provider:   util.OpenrouterProviderType,
+			StatusCode: apiErr.HTTPStatusCode,
+			Body:       string(body),
+			Err:        err,
+		}
+	}
+
+	return err
+}
@@ -10,6 +10,10 @@ func ResolveLlmClient(apiType string, apiUrl string, systemMessage string) util.
 		return NewGeminiClient(systemMessage)
 	case util.OpenrouterProviderType:
 		return NewOpenrouterClient(systemMessage)
+	case util.VertexProviderType:
+		return NewVertexClient(systemMessage)
+	case util.BedrockProviderType:
+		return NewBedrockClient(systemMessage)
 	default:
 		panic("Api type not supported: " + apiType)
 	}
@@ -0,0 +1,265 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/auth"
+	"cloud.google.com/go/auth/credentials"
+	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	defaultVertexRegion = "us-central1"
+	cloudPlatformScope  = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// Versions of Claude models on Vertex AI are release dates, e.g. claude-sonnet-4@20250514
+var claudeVersionRegex = regexp.MustCompile(`^\d{8}$`)
+
+// Detected credentials by credentials file, empty key for application default credentials.
+// Credentials cache and refresh access tokens on their own, so they are detected once
+var vertexCredentials = struct {
+	mu    sync.Mutex
+	files map[string]*auth.Credentials
+}{files: map[string]*auth.Credentials{}}
+
+// Runs models of Vertex AI with Google Cloud credentials: Gemini models are handled by the Gemini client
+// with the vertex backend, Claude models are called through the Anthropic Messages API of Vertex AI
+type VertexClient struct {
+	systemMessage string
+	gemini        *GeminiClient
+	client        http.Client
+}
+
+type vertexPublisherModelsResponse struct {
+	PublisherModels []struct {
+		Name      string `json:"name"`
+		VersionId string `json:"versionId"`
+	} `json:"publisherModels"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+func NewVertexClient(systemMessage string) *VertexClient {
+	return &VertexClient{
+		systemMessage: systemMessage,
+		gemini:        NewGeminiClient(systemMessage),
+		client:        http.Client{},
+	}
+}
+
+func (c VertexClient) RequestCompletion(
+	ctx context.Context,
+	chatMsgs []util.LocalStoreMessage,
+	modelSettings util.Settings,
+	resultChan chan util.ProcessApiCompletionResponse,
+) tea.Cmd {
+	if !util.IsAnthropicModel(modelSettings.Model) {
+		return c.gemini.RequestCompletion(withVertexBackend(ctx), chatMsgs, modelSettings, resultChan)
+	}
+
+	return func() tea.Msg {
+		config, ok := config.FromContext(ctx)
+		if !ok {
+			util.Slog.Error("No config found in a context")
+			panic("No config found in context")
+		}
+
+		body, err := constructClaudeRequestPayload(chatMsgs, *config, modelSettings)
+		if err != nil {
+			return util.MakeErrorMsg(err.Error())
+		}
+
+		path := fmt.Sprintf(
+			"v1/projects/%s/locations/%s/publishers/anthropic/models/%s:streamRawPredict",
+			config.VertexProject,
+			getVertexLocation(*config),
+			modelSettings.Model)
+
+		resp, err := c.sendVertexRequest(ctx, *config, http.MethodPost, path, body)
+		if err != nil {
+			return util.MakeErrorMsg(err.Error())
+		}
+
+		processClaudeResponse(ctx, resp, util.NewResponseWriter(ctx, resultChan))
+		return nil
+	}
+}
+
+// Gemini models are listed with the genai SDK, Claude models from the Model Garden.
+// Claude models are optional: the project may have no access to them
+func (c VertexClient) RequestModelsList(ctx context.Context) util.ProcessModelsResponse {
+	cfg := config.Config{}
+	if configFromCtx, ok := config.FromContext(ctx); ok {
+		cfg = *configFromCtx
+	}
+
+	modelsResponse := c.gemini.RequestModelsList(withVertexBackend(ctx))
+	if modelsResponse.Err != nil {
+		return modelsResponse
+	}
+
+	claudeModels, err := c.getClaudeModels(ctx, cfg)
+	if err != nil {
+		util.Slog.Warn("Vertex: failed to fetch a list of claude models", "error", err.Error())
+	}
+
+	modelsResponse.Result.Data = append(modelsResponse.Result.Data, claudeModels...)
+	return modelsResponse
+}
+
+func (c VertexClient) getClaudeModels(ctx context.Context, cfg config.Config) ([]util.ModelDescription, error) {
+	models := []util.ModelDescription{}
+	pageToken := ""
+
+	for {
+		path := "v1beta1/publishers/anthropic/models?pageSize=100"
+		if pageToken != "" {
+			path += "&pageToken=" + url.QueryEscape(pageToken)
+		}
+
+		resp, err := c.sendVertexRequest(ctx, cfg, http.MethodGet, path, nil)
+		if err != nil {
+			return models, err
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return models, err
+		}
+
+		if resp.StatusCode >= 400 {
+			return models, fmt.Errorf("%s", string(body))
+		}
+
+		var page vertexPublisherModelsResponse
+		if err := json.Unmarshal(body, &page); err != nil {
+			return models, err
+		}
+
+		for _, model := range page.PublisherModels {
+			id := model.Name[strings.LastIndex(model.Name, "/")+1:]
+			if claudeVersionRegex.MatchString(model.VersionId) {
+				id += "@" + model.VersionId
+			}
+			models = append(models, util.ModelDescription{Id: id, OwnedBy: "anthropic"})
+		}
+
+		if page.NextPageToken == "" {
+			return models, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+func (c VertexClient) sendVertexRequest(
+	ctx context.Context,
+	cfg config.Config,
+	method, path string,
+	body []byte,
+) (*http.Response, error) {
+	token, err := getVertexToken(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	requestUrl := fmt.Sprintf("%s/%s", getVertexUrl(cfg), path)
+	req, err := http.NewRequestWithContext(ctx, method, requestUrl, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	// Bills the requests to the configured project, user credentials have no project of their own
+	req.Header.Set("x-goog-user-project", cfg.VertexProject)
+
+	return c.client.Do(req)
+}
+
+// The config is copied, so the gemini backend is switched for the request only
+func withVertexBackend(ctx context.Context) context.Context {
+	cfg := config.Config{}
+	if configFromCtx, ok := config.FromContext(ctx); ok {
+		cfg = *configFromCtx
+	}
+
+	cfg.GeminiBackend = util.VertexBackend
+	return config.WithConfig(ctx, &cfg)
+}
+
+// Uses a service account key file from vertexCredentialsFile
+// or application default credentials (gcloud auth application-default login)
+func loadVertexCredentials(cfg config.Config) (*auth.Credentials, error) {
+	vertexCredentials.mu.Lock()
+	defer vertexCredentials.mu.Unlock()
+
+	if creds, ok := vertexCredentials.files[cfg.VertexCredentialsFile]; ok {
+		return creds, nil
+	}
+
+	options := &credentials.DetectOptions{Scopes: []string{cloudPlatformScope}}
+	if cfg.VertexCredentialsFile != "" {
+		content, err := os.ReadFile(cfg.VertexCredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read vertex credentials file: %w", err)
+		}
+		options.CredentialsJSON = content
+	}
+
+	creds, err := credentials.DetectDefault(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vertex credentials: %w", err)
+	}
+
+	vertexCredentials.files[cfg.VertexCredentialsFile] = creds
+	return creds, nil
+}
+
+func getVertexToken(ctx context.Context, cfg config.Config) (string, error) {
+	creds, err := loadVertexCredentials(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := creds.Token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get vertex access token: %w", err)
+	}
+	return token.Value, nil
+}
+
+func getVertexLocation(cfg config.Config) string {
+	if cfg.VertexLocation == "" {
+		return defaultVertexRegion
+	}
+	return cfg.VertexLocation
+}
+
+func getVertexUrl(cfg config.Config) string {
+	location := getVertexLocation(cfg)
+	if location == "global" {
+		return "https://aiplatform.googleapis.com"
+	}
+	return fmt.Sprintf("https://%s-aiplatform.googleapis.com", location)
+}
+
+// The system prompt of the preset takes precedence over the one from the config
+func getSystemMessage(cfg config.Config, settings util.Settings) string {
+	if settings.SystemPrompt != nil && *settings.SystemPrompt != "" {
+		return *settings.SystemPrompt
+	}
+	return cfg.SystemMessage
+}
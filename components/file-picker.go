@@ -3,13 +3,40 @@ package components
 import (
 	"errors"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/BalanceBalls/nekot/i18n"
 	"github.com/BalanceBalls/nekot/util"
 	"github.com/charmbracelet/bubbles/filepicker"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
+type filePickerKeyMap struct {
+	places   key.Binding
+	favorite key.Binding
+	up       key.Binding
+	down     key.Binding
+	choose   key.Binding
+}
+
+var defaultFilePickerKeyMap = filePickerKeyMap{
+	places:   key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "favorites and recent files")),
+	favorite: key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "add/remove directory to favorites")),
+	up:       key.NewBinding(key.WithKeys(tea.KeyUp.String(), "k")),
+	down:     key.NewBinding(key.WithKeys(tea.KeyDown.String(), "j")),
+	choose:   key.NewBinding(key.WithKeys(tea.KeyEnter.String())),
+}
+
+// Favorite directories are opened in the picker, recent files are attached right away
+type filePlace struct {
+	path  string
+	isDir bool
+}
+
 type FilePicker struct {
 	SelectedFile  string
 	PrevView      util.ViewMode
@@ -17,6 +44,15 @@ type FilePicker struct {
 	filepicker    filepicker.Model
 	quitting      bool
 	err           error
+
+	keys            filePickerKeyMap
+	colors          util.SchemeColors
+	favorites       []filePlace
+	recent          []filePlace
+	placesCursor    int
+	isPlacesFocused bool
+	width           int
+	height          int
 }
 
 func NewFilePicker(
@@ -47,10 +83,48 @@ func NewFilePicker(
 		filepicker:    fp,
 		PrevView:      prevView,
 		PrevInputData: prevInput,
+		keys:          defaultFilePickerKeyMap,
+		colors:        colors,
 	}
 	return filePicker
 }
 
+// Paths that no longer exist are left out
+func (m *FilePicker) SetPlaces(favoriteDirs []string, recentFiles []string) {
+	m.favorites = []filePlace{}
+	for _, path := range favoriteDirs {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			m.favorites = append(m.favorites, filePlace{path: path, isDir: true})
+		}
+	}
+
+	m.recent = []filePlace{}
+	for _, path := range recentFiles {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			m.recent = append(m.recent, filePlace{path: path})
+		}
+	}
+
+	m.placesCursor = max(0, min(m.placesCursor, len(m.getPlaces())-1))
+	if len(m.getPlaces()) == 0 {
+		m.isPlacesFocused = false
+	}
+	m.resize()
+}
+
+func (m FilePicker) CurrentDirectory() string {
+	return m.filepicker.CurrentDirectory
+}
+
+func (m FilePicker) getPlaces() []filePlace {
+	return append(append([]filePlace{}, m.favorites...), m.recent...)
+}
+
+// The directory open in the picker is added to favorites or removed from them by the owner of the picker
+type FavoriteDirToggled struct {
+	Path string
+}
+
 type clearErrorMsg struct{}
 
 func clearErrorAfter(t time.Duration) tea.Cmd {
@@ -72,6 +146,20 @@ func (m FilePicker) Update(msg tea.Msg) (FilePicker, tea.Cmd) {
 			return m, util.SendViewModeChangedMsg(m.PrevView)
 		}
 
+		if key.Matches(msg, m.keys.places) && len(m.getPlaces()) != 0 {
+			m.isPlacesFocused = !m.isPlacesFocused
+			return m, nil
+		}
+
+		if m.isPlacesFocused {
+			return m.handlePlacesKeys(msg)
+		}
+
+		if key.Matches(msg, m.keys.favorite) {
+			path := m.filepicker.CurrentDirectory
+			return m, func() tea.Msg { return FavoriteDirToggled{Path: path} }
+		}
+
 	case clearErrorMsg:
 		m.err = nil
 	}
@@ -92,15 +180,103 @@ func (m FilePicker) Update(msg tea.Msg) (FilePicker, tea.Cmd) {
 	return m, cmd
 }
 
+func (m FilePicker) handlePlacesKeys(msg tea.KeyMsg) (FilePicker, tea.Cmd) {
+	places := m.getPlaces()
+	switch {
+	case key.Matches(msg, m.keys.up):
+		m.placesCursor = max(m.placesCursor-1, 0)
+
+	case key.Matches(msg, m.keys.down):
+		m.placesCursor = min(m.placesCursor+1, len(places)-1)
+
+	case key.Matches(msg, m.keys.choose):
+		place := places[m.placesCursor]
+		if !place.isDir {
+			m.SelectedFile = place.path
+			return m, nil
+		}
+
+		m.isPlacesFocused = false
+		m.filepicker.CurrentDirectory = place.path
+		return m, m.filepicker.Init()
+	}
+
+	return m, nil
+}
+
 func (m FilePicker) View() string {
 	if m.quitting {
 		return ""
 	}
-	return m.filepicker.View()
+
+	rows := []string{}
+	headingStyle := lipgloss.NewStyle().Foreground(m.colors.HighlightColor)
+	placeStyle := lipgloss.NewStyle().Foreground(m.colors.NormalTabBorderColor)
+	activeStyle := lipgloss.NewStyle().Foreground(m.colors.ActiveTabBorderColor).Bold(true)
+
+	index := 0
+	renderPlaces := func(title string, places []filePlace) {
+		if len(places) == 0 {
+			return
+		}
+
+		names := []string{}
+		for _, place := range places {
+			name := getPlaceName(place)
+			if m.isPlacesFocused && index == m.placesCursor {
+				name = activeStyle.Render("[" + name + "]")
+			} else {
+				name = placeStyle.Render(name)
+			}
+			names = append(names, name)
+			index++
+		}
+		row := headingStyle.Render(util.ListHeadingDot+" "+title+": ") + strings.Join(names, " ")
+		rows = append(rows, lipgloss.NewStyle().MaxWidth(m.width).Render(row))
+	}
+	renderPlaces(i18n.T("filePicker.favorites"), m.favorites)
+	renderPlaces(i18n.T("filePicker.recent"), m.recent)
+
+	hints := []key.Binding{m.keys.favorite}
+	if len(m.getPlaces()) != 0 {
+		hints = append([]key.Binding{m.keys.places}, hints...)
+	}
+	rows = append(rows, util.HelpStyle.Render(util.RenderKeyHints(hints)), m.filepicker.View())
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// Directories are shown by their path, so nested directories with the same name can be told apart
+func getPlaceName(place filePlace) string {
+	if !place.isDir {
+		return filepath.Base(place.path)
+	}
+
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(place.path, home) {
+		return "~" + strings.TrimPrefix(place.path, home)
+	}
+	return place.path
 }
 
 func (m *FilePicker) SetSize(w, h int) {
 	if w > 2 && h > 2 {
-		m.filepicker.SetHeight(h)
+		m.width = w
+		m.height = h
+		m.resize()
+	}
+}
+
+// Places and hints take rows of the picker
+func (m *FilePicker) resize() {
+	if m.height == 0 {
+		return
+	}
+
+	placesHeight := 1
+	if len(m.favorites) != 0 {
+		placesHeight++
+	}
+	if len(m.recent) != 0 {
+		placesHeight++
 	}
+	m.filepicker.SetHeight(max(m.height-placesHeight, 1))
 }
@@ -0,0 +1,219 @@
+package components
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/BalanceBalls/nekot/settings"
+	"github.com/BalanceBalls/nekot/util"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+type PersonasList struct {
+	list               list.Model
+	service            *settings.PersonasService
+	confirmationActive bool
+}
+
+type PersonasListItem struct {
+	Id         string
+	PersonaId  int
+	Text       string
+	Model      string
+	IsAssigned bool
+}
+
+func (i PersonasListItem) FilterValue() string { return zone.Mark(i.Id, i.Text) }
+
+type personasItemDelegate struct{}
+
+func (d personasItemDelegate) Height() int                             { return 1 }
+func (d personasItemDelegate) Spacing() int                            { return 0 }
+func (d personasItemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+func (d personasItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	i, ok := listItem.(PersonasListItem)
+	if !ok {
+		return
+	}
+
+	str := fmt.Sprintf("%d. %s", index+1, i.Text)
+	if i.Model != "" {
+		str += " (" + i.Model + ")"
+	}
+	if i.IsAssigned {
+		str += " [session]"
+	}
+	str = util.TrimListItem(str, m.Width())
+	str = zone.Mark(i.Id, str)
+
+	fn := listItemSpan.Render
+	if index == m.Index() {
+		fn = func(s ...string) string {
+			row := "> " + strings.Join(s, " ")
+			return listItemSpanSelected.Render(row)
+		}
+	}
+
+	fmt.Fprint(w, fn(str))
+}
+
+func (l *PersonasList) View() string {
+	if l.list.FilterState() == list.Filtering {
+		l.list.SetShowStatusBar(false)
+	} else {
+		l.list.SetShowStatusBar(true)
+	}
+	view := l.list.View()
+	if l.confirmationActive {
+		view += "\n Remove persona? y/n"
+	} else {
+		view += util.HelpStyle.Render(
+			"\n enter session" + util.TipsSeparator +
+				"c detach" + util.TipsSeparator +
+				"n save current" + util.TipsSeparator +
+				"d delete")
+	}
+	return view
+}
+
+func (l *PersonasList) GetSelectedItem() (PersonasListItem, bool) {
+	item, ok := l.list.SelectedItem().(PersonasListItem)
+	return item, ok
+}
+
+func (l PersonasList) VisibleItems() []list.Item {
+	return l.list.VisibleItems()
+}
+
+func (l PersonasList) IsFiltering() bool {
+	return l.list.SettingFilter()
+}
+
+func (l PersonasList) IsConfirming() bool {
+	return l.confirmationActive
+}
+
+func (l PersonasList) IsFirstPage() bool {
+	return l.list.Paginator.Page == 0
+}
+
+func (l PersonasList) Contains(personaId int) bool {
+	for _, item := range l.list.Items() {
+		if item.(PersonasListItem).PersonaId == personaId {
+			return true
+		}
+	}
+	return false
+}
+
+func (l PersonasList) getCurrentPersona() (PersonasListItem, int, bool) {
+	personas := l.list.Items()
+	currentIdx := l.list.Index()
+	if currentIdx < 0 || currentIdx >= len(personas) {
+		return PersonasListItem{}, currentIdx, false
+	}
+	persona := personas[currentIdx].(PersonasListItem)
+	return persona, currentIdx, true
+}
+
+func (l *PersonasList) removePersona() {
+	persona, idx, ok := l.getCurrentPersona()
+	if !ok {
+		return
+	}
+
+	err := l.service.RemovePersona(persona.PersonaId)
+	if err != nil {
+		util.Slog.Error("failed to remove a persona", "error", err.Error())
+		return
+	}
+	l.list.RemoveItem(idx)
+}
+
+func (l PersonasList) Update(msg tea.Msg) (PersonasList, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+
+	case tea.MouseMsg:
+		if msg.Button == tea.MouseButtonWheelUp {
+			l.list.CursorUp()
+			return l, nil
+		}
+
+		if msg.Button == tea.MouseButtonWheelDown {
+			l.list.CursorDown()
+			return l, nil
+		}
+
+	case tea.KeyMsg:
+		if l.list.SettingFilter() {
+			break
+		}
+
+		key := msg.String()
+		switch key {
+		case "d":
+			if _, _, ok := l.getCurrentPersona(); ok {
+				l.confirmationActive = true
+			}
+			return l, cmd
+		case "y":
+			if !l.confirmationActive {
+				break
+			}
+			l.removePersona()
+			l.confirmationActive = false
+			return l, cmd
+		case "n":
+			if !l.confirmationActive {
+				break
+			}
+			l.confirmationActive = false
+			return l, cmd
+		default:
+			if l.confirmationActive {
+				return l, cmd
+			}
+		}
+	}
+	l.list, cmd = l.list.Update(msg)
+	return l, cmd
+}
+
+func NewPersonasList(
+	items []list.Item,
+	w, h int,
+	colors util.SchemeColors,
+	service *settings.PersonasService,
+) PersonasList {
+	l := list.New(items, personasItemDelegate{}, w, h-1)
+
+	l.SetStatusBarItemName("persona", "personas")
+	l.SetShowTitle(false)
+	l.SetShowHelp(false)
+	l.SetFilteringEnabled(true)
+	l.DisableQuitKeybindings()
+
+	l.Paginator.ActiveDot = lipgloss.NewStyle().
+		Foreground(colors.HighlightColor).
+		Render(util.ActiveDot)
+	l.Paginator.InactiveDot = lipgloss.NewStyle().
+		Foreground(colors.DefaultTextColor).
+		Render(util.InactiveDot)
+	listItemSpan = listItemSpan.Foreground(colors.DefaultTextColor)
+	listItemSpanSelected = listItemSpanSelected.Foreground(colors.AccentColor)
+	l.FilterInput.PromptStyle = l.FilterInput.PromptStyle.Foreground(colors.ActiveTabBorderColor).
+		PaddingBottom(0).
+		Margin(0)
+	l.FilterInput.Cursor.Style = l.FilterInput.Cursor.Style.Foreground(colors.NormalTabBorderColor)
+
+	return PersonasList{
+		list:    l,
+		service: service,
+	}
+}
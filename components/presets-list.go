@@ -92,6 +92,10 @@ func (l PresetsList) IsFirstPage() bool {
 	return l.list.Paginator.Page == 0
 }
 
+func (l PresetsList) IsLastPage() bool {
+	return l.list.Paginator.OnLastPage()
+}
+
 func (l PresetsList) getCurrentPreset() (PresetsListItem, int) {
 	presets := l.list.Items()
 	currentIdx := l.list.Index()
@@ -20,11 +20,33 @@ var (
 	activeItemStyle = itemStyle
 )
 
+// Marks sessions a prompt is broadcast to
+const BroadcastTargetMark = "»"
+
+func GetBroadcastTargetMark() string {
+	if util.IsAsciiMode() {
+		return ">>"
+	}
+	return BroadcastTargetMark
+}
+
+// Marks read-only sessions
+const ReadOnlyMark = "🔒"
+
+func GetReadOnlyMark() string {
+	if util.IsAsciiMode() {
+		return "[ro]"
+	}
+	return ReadOnlyMark
+}
+
 type SessionListItem struct {
-	Id        string
-	SessionId int
-	Text      string
-	IsActive  bool
+	Id                string
+	SessionId         int
+	Text              string
+	IsActive          bool
+	IsBroadcastTarget bool
+	IsReadOnly        bool
 }
 
 type SessionsList struct {
@@ -51,6 +73,12 @@ func (d sessionItemDelegate) Render(w io.Writer, m list.Model, index int, listIt
 	}
 
 	str := fmt.Sprintf("%s", i.Text)
+	if i.IsReadOnly {
+		str = GetReadOnlyMark() + " " + str
+	}
+	if i.IsBroadcastTarget {
+		str = GetBroadcastTargetMark() + " " + str
+	}
 	str = util.TrimListItem(str, m.Width())
 	str = zone.Mark(i.Id, str)
 
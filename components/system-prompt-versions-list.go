@@ -0,0 +1,118 @@
+package components
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/BalanceBalls/nekot/util"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+// Replaced system prompts of the current preset, the latest first
+type SystemPromptVersionsList struct {
+	list list.Model
+}
+
+type SystemPromptVersionsListItem struct {
+	Id        string
+	VersionId int
+	Content   string
+	CreatedAt string
+}
+
+func (i SystemPromptVersionsListItem) FilterValue() string { return zone.Mark(i.Id, i.Content) }
+
+type systemPromptVersionsItemDelegate struct{}
+
+func (d systemPromptVersionsItemDelegate) Height() int                             { return 1 }
+func (d systemPromptVersionsItemDelegate) Spacing() int                            { return 0 }
+func (d systemPromptVersionsItemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+func (d systemPromptVersionsItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	i, ok := listItem.(SystemPromptVersionsListItem)
+	if !ok {
+		return
+	}
+
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(i.Content), "\n")
+	str := fmt.Sprintf("%d. %s %s", index+1, i.CreatedAt, firstLine)
+	str = util.TrimListItem(str, m.Width())
+	str = zone.Mark(i.Id, str)
+
+	fn := listItemSpan.Render
+	if index == m.Index() {
+		fn = func(s ...string) string {
+			row := "> " + strings.Join(s, " ")
+			return listItemSpanSelected.Render(row)
+		}
+	}
+
+	fmt.Fprint(w, fn(str))
+}
+
+func (l *SystemPromptVersionsList) View() string {
+	view := l.list.View()
+	view += util.HelpStyle.Render(
+		"\n enter restore" + util.TipsSeparator +
+			"d diff with current" + util.TipsSeparator +
+			"esc back")
+	return view
+}
+
+func (l *SystemPromptVersionsList) GetSelectedItem() (SystemPromptVersionsListItem, bool) {
+	item, ok := l.list.SelectedItem().(SystemPromptVersionsListItem)
+	return item, ok
+}
+
+func (l SystemPromptVersionsList) VisibleItems() []list.Item {
+	return l.list.VisibleItems()
+}
+
+func (l SystemPromptVersionsList) IsFirstPage() bool {
+	return l.list.Paginator.Page == 0
+}
+
+func (l SystemPromptVersionsList) Update(msg tea.Msg) (SystemPromptVersionsList, tea.Cmd) {
+	var cmd tea.Cmd
+
+	if msg, ok := msg.(tea.MouseMsg); ok {
+		if msg.Button == tea.MouseButtonWheelUp {
+			l.list.CursorUp()
+			return l, nil
+		}
+
+		if msg.Button == tea.MouseButtonWheelDown {
+			l.list.CursorDown()
+			return l, nil
+		}
+	}
+
+	l.list, cmd = l.list.Update(msg)
+	return l, cmd
+}
+
+func NewSystemPromptVersionsList(items []list.Item, w, h int, colors util.SchemeColors) SystemPromptVersionsList {
+	l := list.New(items, systemPromptVersionsItemDelegate{}, w, h-1)
+
+	l.SetStatusBarItemName("version", "versions")
+	l.SetShowTitle(false)
+	l.SetShowHelp(false)
+	l.SetFilteringEnabled(false)
+	l.DisableQuitKeybindings()
+
+	l.Paginator.ActiveDot = lipgloss.NewStyle().
+		Foreground(colors.HighlightColor).
+		Render(util.ActiveDot)
+	l.Paginator.InactiveDot = lipgloss.NewStyle().
+		Foreground(colors.DefaultTextColor).
+		Render(util.InactiveDot)
+	listItemSpan = listItemSpan.Foreground(colors.DefaultTextColor)
+	listItemSpanSelected = listItemSpanSelected.Foreground(colors.AccentColor)
+
+	return SystemPromptVersionsList{
+		list: l,
+	}
+}
@@ -0,0 +1,211 @@
+package components
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/BalanceBalls/nekot/settings"
+	"github.com/BalanceBalls/nekot/util"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+type SystemPromptsList struct {
+	list               list.Model
+	service            *settings.SystemPromptsService
+	confirmationActive bool
+}
+
+type SystemPromptsListItem struct {
+	Id         string
+	PromptId   int
+	Text       string
+	AssignedTo string
+}
+
+func (i SystemPromptsListItem) FilterValue() string { return zone.Mark(i.Id, i.Text) }
+
+type systemPromptsItemDelegate struct{}
+
+func (d systemPromptsItemDelegate) Height() int                             { return 1 }
+func (d systemPromptsItemDelegate) Spacing() int                            { return 0 }
+func (d systemPromptsItemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+func (d systemPromptsItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	i, ok := listItem.(SystemPromptsListItem)
+	if !ok {
+		return
+	}
+
+	str := fmt.Sprintf("%d. %s", index+1, i.Text)
+	if i.AssignedTo != "" {
+		str += " [" + i.AssignedTo + "]"
+	}
+	str = util.TrimListItem(str, m.Width())
+	str = zone.Mark(i.Id, str)
+
+	fn := listItemSpan.Render
+	if index == m.Index() {
+		fn = func(s ...string) string {
+			row := "> " + strings.Join(s, " ")
+			return listItemSpanSelected.Render(row)
+		}
+	}
+
+	fmt.Fprint(w, fn(str))
+}
+
+func (l *SystemPromptsList) View() string {
+	if l.list.FilterState() == list.Filtering {
+		l.list.SetShowStatusBar(false)
+	} else {
+		l.list.SetShowStatusBar(true)
+	}
+	view := l.list.View()
+	if l.confirmationActive {
+		view += "\n Remove system prompt? y/n"
+	} else {
+		view += util.HelpStyle.Render(
+			"\n enter preset" + util.TipsSeparator +
+				"a session" + util.TipsSeparator +
+				"c detach" + util.TipsSeparator +
+				"n save current" + util.TipsSeparator +
+				"d delete")
+	}
+	return view
+}
+
+func (l *SystemPromptsList) GetSelectedItem() (SystemPromptsListItem, bool) {
+	item, ok := l.list.SelectedItem().(SystemPromptsListItem)
+	return item, ok
+}
+
+func (l SystemPromptsList) VisibleItems() []list.Item {
+	return l.list.VisibleItems()
+}
+
+func (l SystemPromptsList) IsFiltering() bool {
+	return l.list.SettingFilter()
+}
+
+func (l SystemPromptsList) IsConfirming() bool {
+	return l.confirmationActive
+}
+
+func (l SystemPromptsList) IsFirstPage() bool {
+	return l.list.Paginator.Page == 0
+}
+
+func (l SystemPromptsList) IsLastPage() bool {
+	return l.list.Paginator.OnLastPage()
+}
+
+func (l SystemPromptsList) getCurrentPrompt() (SystemPromptsListItem, int, bool) {
+	prompts := l.list.Items()
+	currentIdx := l.list.Index()
+	if currentIdx < 0 || currentIdx >= len(prompts) {
+		return SystemPromptsListItem{}, currentIdx, false
+	}
+	prompt := prompts[currentIdx].(SystemPromptsListItem)
+	return prompt, currentIdx, true
+}
+
+func (l *SystemPromptsList) removePrompt() {
+	prompt, idx, ok := l.getCurrentPrompt()
+	if !ok {
+		return
+	}
+
+	err := l.service.RemoveSystemPrompt(prompt.PromptId)
+	if err != nil {
+		util.Slog.Error("failed to remove a system prompt", "error", err.Error())
+		return
+	}
+	l.list.RemoveItem(idx)
+}
+
+func (l SystemPromptsList) Update(msg tea.Msg) (SystemPromptsList, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+
+	case tea.MouseMsg:
+		if msg.Button == tea.MouseButtonWheelUp {
+			l.list.CursorUp()
+			return l, nil
+		}
+
+		if msg.Button == tea.MouseButtonWheelDown {
+			l.list.CursorDown()
+			return l, nil
+		}
+
+	case tea.KeyMsg:
+		if l.list.SettingFilter() {
+			break
+		}
+
+		key := msg.String()
+		switch key {
+		case "d":
+			if _, _, ok := l.getCurrentPrompt(); ok {
+				l.confirmationActive = true
+			}
+			return l, cmd
+		case "y":
+			if !l.confirmationActive {
+				break
+			}
+			l.removePrompt()
+			l.confirmationActive = false
+			return l, cmd
+		case "n":
+			if !l.confirmationActive {
+				break
+			}
+			l.confirmationActive = false
+			return l, cmd
+		default:
+			if l.confirmationActive {
+				return l, cmd
+			}
+		}
+	}
+	l.list, cmd = l.list.Update(msg)
+	return l, cmd
+}
+
+func NewSystemPromptsList(
+	items []list.Item,
+	w, h int,
+	colors util.SchemeColors,
+	service *settings.SystemPromptsService,
+) SystemPromptsList {
+	l := list.New(items, systemPromptsItemDelegate{}, w, h-1)
+
+	l.SetStatusBarItemName("prompt", "prompts")
+	l.SetShowTitle(false)
+	l.SetShowHelp(false)
+	l.SetFilteringEnabled(true)
+	l.DisableQuitKeybindings()
+
+	l.Paginator.ActiveDot = lipgloss.NewStyle().
+		Foreground(colors.HighlightColor).
+		Render(util.ActiveDot)
+	l.Paginator.InactiveDot = lipgloss.NewStyle().
+		Foreground(colors.DefaultTextColor).
+		Render(util.InactiveDot)
+	listItemSpan = listItemSpan.Foreground(colors.DefaultTextColor)
+	listItemSpanSelected = listItemSpanSelected.Foreground(colors.AccentColor)
+	l.FilterInput.PromptStyle = l.FilterInput.PromptStyle.Foreground(colors.ActiveTabBorderColor).
+		PaddingBottom(0).
+		Margin(0)
+	l.FilterInput.Cursor.Style = l.FilterInput.Cursor.Style.Foreground(colors.NormalTabBorderColor)
+
+	return SystemPromptsList{
+		list:    l,
+		service: service,
+	}
+}
@@ -0,0 +1,177 @@
+package components
+
+import (
+	"slices"
+	"unicode"
+
+	"github.com/BalanceBalls/nekot/util"
+)
+
+type charClass int
+
+const (
+	spaceClass charClass = iota
+	wordClass
+	punctuationClass
+)
+
+// Same classes as vim words: letters, digits and underscores, other non blank characters, blanks
+func getCharClass(r rune) charClass {
+	switch {
+	case unicode.IsSpace(r):
+		return spaceClass
+	case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		return wordClass
+	}
+	return punctuationClass
+}
+
+func (s TextSelector) charLineRunes() []rune {
+	if s.CharSelection.line < 0 || s.CharSelection.line >= len(s.lines) {
+		return nil
+	}
+	return []rune(util.StripAnsiCodes(s.lines[s.CharSelection.line]))
+}
+
+// Char selection of the cursor line, starting at its first non blank character
+func (s TextSelector) toggleCharSelection() TextSelector {
+	s.Selection.Active = false
+	s.mouseSelecting = false
+	s.mouseSelectingChar = false
+	if s.CharSelection.Active {
+		s.CharSelection.Active = false
+		return s
+	}
+
+	s.CharSelection = charSelection{Active: true, line: s.cursor.line}
+	col := slices.IndexFunc([]rune(util.StripAnsiCodes(s.lines[s.cursor.line])), func(r rune) bool {
+		return !unicode.IsSpace(r)
+	})
+	s.CharSelection.anchorCol = max(col, 0)
+	s.CharSelection.cursorCol = s.CharSelection.anchorCol
+	return s
+}
+
+// Moves the end of the char selection, the anchor stays in place
+func (s TextSelector) moveCharCursor(motion string) TextSelector {
+	runes := s.charLineRunes()
+	if len(runes) == 0 {
+		return s
+	}
+
+	col := min(s.CharSelection.cursorCol, len(runes)-1)
+	switch motion {
+	case "w":
+		col = nextWordStart(runes, col)
+	case "b":
+		col = prevWordStart(runes, col)
+	case "e":
+		col = nextWordEnd(runes, col)
+	case "0":
+		col = 0
+	case "$":
+		col = len(runes) - 1
+	}
+
+	s.CharSelection.cursorCol = col
+	return s
+}
+
+// Inner text objects: iw selects the word or the blanks under the cursor,
+// quotes and backticks select the text between the pair around the cursor
+func (s TextSelector) selectTextObject(object string) TextSelector {
+	runes := s.charLineRunes()
+	if len(runes) == 0 {
+		return s
+	}
+
+	col := min(s.CharSelection.cursorCol, len(runes)-1)
+	start, end, ok := 0, 0, false
+	switch object {
+	case "w":
+		start, end, ok = innerWord(runes, col)
+	case "\"", "`", "'":
+		start, end, ok = innerQuotes(runes, col, []rune(object)[0])
+	}
+
+	if ok {
+		s.CharSelection.anchorCol = start
+		s.CharSelection.cursorCol = end
+	}
+	return s
+}
+
+func nextWordStart(runes []rune, col int) int {
+	last := len(runes) - 1
+	class := getCharClass(runes[col])
+	for col < last && class != spaceClass && getCharClass(runes[col]) == class {
+		col++
+	}
+	for col < last && getCharClass(runes[col]) == spaceClass {
+		col++
+	}
+	return col
+}
+
+func nextWordEnd(runes []rune, col int) int {
+	last := len(runes) - 1
+	if col < last {
+		col++
+	}
+	for col < last && getCharClass(runes[col]) == spaceClass {
+		col++
+	}
+	class := getCharClass(runes[col])
+	for col < last && getCharClass(runes[col+1]) == class {
+		col++
+	}
+	return col
+}
+
+func prevWordStart(runes []rune, col int) int {
+	if col > 0 {
+		col--
+	}
+	for col > 0 && getCharClass(runes[col]) == spaceClass {
+		col--
+	}
+	class := getCharClass(runes[col])
+	for col > 0 && getCharClass(runes[col-1]) == class {
+		col--
+	}
+	return col
+}
+
+func innerWord(runes []rune, col int) (int, int, bool) {
+	class := getCharClass(runes[col])
+	start, end := col, col
+	for start > 0 && getCharClass(runes[start-1]) == class {
+		start--
+	}
+	for end < len(runes)-1 && getCharClass(runes[end+1]) == class {
+		end++
+	}
+	return start, end, true
+}
+
+// Quotes of the line are paired from the start, like vim does
+func innerQuotes(runes []rune, col int, quote rune) (int, int, bool) {
+	quotes := []int{}
+	for i, r := range runes {
+		if r == quote {
+			quotes = append(quotes, i)
+		}
+	}
+
+	for i := 0; i+1 < len(quotes); i += 2 {
+		start, end := quotes[i], quotes[i+1]
+		if col < start || col > end {
+			continue
+		}
+		if end-start < 2 {
+			return 0, 0, false
+		}
+		return start + 1, end - 1, true
+	}
+	return 0, 0, false
+}
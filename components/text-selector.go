@@ -6,7 +6,6 @@ import (
 	"unicode"
 
 	"github.com/BalanceBalls/nekot/util"
-	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -27,8 +26,13 @@ type keyMap struct {
 	pageDown       key.Binding
 	copy           key.Binding
 	copyRaw        key.Binding
+	openLink       key.Binding
 	bottom         key.Binding
 	top            key.Binding
+	charMode       key.Binding
+	charMotion     key.Binding
+	innerObject    key.Binding
+	quote          key.Binding
 }
 
 var defaultKeyMap = keyMap{
@@ -46,10 +50,24 @@ var defaultKeyMap = keyMap{
 		key.WithKeys("ctrl+d", "d"),
 		key.WithHelp("ctrl+d", "move down a page"),
 	),
-	copy:    key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy selection")),
-	copyRaw: key.NewBinding(key.WithKeys("c", "r"), key.WithHelp("c/r", "raw copy selection")),
-	bottom:  key.NewBinding(key.WithKeys("G"), key.WithHelp("G", "go to bottom")),
-	top:     key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "go to top")),
+	copy:     key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy selection")),
+	copyRaw:  key.NewBinding(key.WithKeys("c", "r"), key.WithHelp("c/r", "raw copy selection")),
+	openLink: key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "open link from selection or cursor line")),
+	quote:    key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "quote selection in the prompt")),
+	bottom:   key.NewBinding(key.WithKeys("G"), key.WithHelp("G", "go to bottom")),
+	top:      key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "go to top")),
+	charMode: key.NewBinding(
+		key.WithKeys("ctrl+v"),
+		key.WithHelp("ctrl+v", "character selection mode"),
+	),
+	charMotion: key.NewBinding(
+		key.WithKeys("w", "b", "e", "0", "$"),
+		key.WithHelp("w/b/e/0/$", "move selection end by words or to line start/end"),
+	),
+	innerObject: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("iw/i\"/i`", "select inner word, quotes or backticks"),
+	),
 }
 
 type cursor struct {
@@ -84,7 +102,8 @@ type TextSelector struct {
 	mouseTopOffset     int
 	mouseLeftOffset    int
 
-	numberLines int
+	numberLines       int
+	pendingTextObject bool
 }
 
 func (s TextSelector) Init() tea.Cmd {
@@ -107,6 +126,17 @@ func (s TextSelector) Update(msg tea.Msg) (TextSelector, tea.Cmd) {
 	case tea.KeyMsg:
 
 		keypress := msg.String()
+		if s.pendingTextObject {
+			s.pendingTextObject = false
+			return s.selectTextObject(keypress), nil
+		}
+
+		// 0 is a motion unless it continues a count, e.g. 10j
+		isLineStart := keypress == "0" && s.numberLines == 0
+		if s.CharSelection.Active && (isLineStart || keypress != "0" && key.Matches(msg, s.keys.charMotion)) {
+			return s.moveCharCursor(keypress), nil
+		}
+
 		if number, err := strconv.Atoi(keypress); err == nil {
 			return s.handleLineJumps(keypress, number), nil
 		}
@@ -147,6 +177,12 @@ func (s TextSelector) Update(msg tea.Msg) (TextSelector, tea.Cmd) {
 			s.CharSelection.Active = false
 			s.mouseSelectingChar = false
 
+		case key.Matches(msg, s.keys.charMode):
+			s = s.toggleCharSelection()
+
+		case key.Matches(msg, s.keys.innerObject):
+			s.pendingTextObject = s.CharSelection.Active
+
 		case key.Matches(msg, s.keys.copy):
 			if s.IsSelecting() {
 				if s.CharSelection.Active {
@@ -161,6 +197,16 @@ func (s TextSelector) Update(msg tea.Msg) (TextSelector, tea.Cmd) {
 				cmds = append(cmds, util.SendNotificationMsg(util.CopiedNotification))
 			}
 
+		case key.Matches(msg, s.keys.openLink):
+			cmds = append(cmds, util.SendOpenLinksMsg(util.ExtractUrls(s.getTextUnderCursor())))
+
+		case key.Matches(msg, s.keys.quote):
+			text := s.getTextUnderCursor()
+			if strings.TrimSpace(text) != "" {
+				s.Reset()
+				cmds = append(cmds, util.SendQuotePromptMsg(util.QuoteText(text)))
+			}
+
 		case key.Matches(msg, s.keys.copyRaw):
 			if s.IsSelecting() {
 				if s.CharSelection.Active {
@@ -498,7 +544,7 @@ func (s TextSelector) copySelectedLinesToClipboard(isRawCopy bool) {
 		joinSeparator = " "
 	}
 
-	clipboard.WriteAll(strings.Join(linesToCopy, joinSeparator))
+	util.CopyToClipboard(strings.Join(linesToCopy, joinSeparator))
 }
 
 func (s TextSelector) copySelectedCharsToClipboard(isRawCopy bool) {
@@ -513,7 +559,7 @@ func (s TextSelector) copySelectedCharsToClipboard(isRawCopy bool) {
 		selectedText = strings.TrimRight(selectedText, " ")
 	}
 
-	clipboard.WriteAll(selectedText)
+	util.CopyToClipboard(selectedText)
 }
 
 func (s TextSelector) GetSelectedLines() []string {
@@ -551,6 +597,22 @@ func (s TextSelector) GetSelectedChars() string {
 	return filterLine(selected)
 }
 
+// Selected text or the cursor line if nothing is selected
+func (s TextSelector) getTextUnderCursor() string {
+	if s.CharSelection.Active {
+		return s.GetSelectedChars()
+	}
+
+	if s.Selection.Active {
+		return util.StripAnsiCodes(strings.Join(s.GetSelectedLines(), "\n"))
+	}
+
+	if s.cursor.line < 0 || s.cursor.line >= len(s.lines) {
+		return ""
+	}
+	return util.StripAnsiCodes(s.lines[s.cursor.line])
+}
+
 func (s TextSelector) charSelectionRange(lineLength int) (int, int) {
 	if lineLength <= 0 {
 		return 0, 0
@@ -571,9 +633,7 @@ func (s TextSelector) charSelectionRange(lineLength int) (int, int) {
 }
 
 func filterLine(line string) string {
-	line = strings.ReplaceAll(line, "🤖", "")
-	line = strings.ReplaceAll(line, "💁", "")
-	return line
+	return util.StripSpeakerIcon(util.StripAnsiCodes(line))
 }
 
 func (s *TextSelector) Reset() {
@@ -591,6 +651,10 @@ func (s TextSelector) IsCharSelecting() bool {
 	return s.CharSelection.Active
 }
 
+func (s TextSelector) CursorLine() int {
+	return s.cursor.line
+}
+
 func (s TextSelector) LinesSelected() int {
 	if s.CharSelection.Active {
 		return 1
@@ -606,6 +670,35 @@ func (s TextSelector) SelectedCharCount() int {
 	return len([]rune(s.GetSelectedChars()))
 }
 
+func (s TextSelector) ShortHelp() []key.Binding {
+	return []key.Binding{s.keys.visualLineMode, s.keys.copy, s.keys.copyRaw}
+}
+
+func (s TextSelector) FullHelp() []key.Binding {
+	return SelectionHelp()
+}
+
+// Selection mode keybindings, available before a selector is created
+func SelectionHelp() []key.Binding {
+	keys := defaultKeyMap
+	return []key.Binding{
+		keys.visualLineMode,
+		keys.up,
+		keys.down,
+		keys.pageUp,
+		keys.pageDown,
+		keys.top,
+		keys.bottom,
+		keys.copy,
+		keys.copyRaw,
+		keys.openLink,
+		keys.quote,
+		keys.charMode,
+		keys.charMotion,
+		keys.innerObject,
+	}
+}
+
 func NewTextSelector(
 	w, h int,
 	scrollPos int,
@@ -0,0 +1,779 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/util"
+)
+
+type ConfigOption struct {
+	Key             string
+	Description     string
+	RequiresRestart bool
+
+	get func(c Config) string
+	set func(c *Config, value string) (any, error)
+}
+
+func (o ConfigOption) Value(c Config) string {
+	return o.get(c)
+}
+
+// Options that can be edited from the app.
+// Options marked with `RequiresRestart` are persisted right away, but applied on the next start
+var EditableOptions = []ConfigOption{
+	{
+		Key:         "systemMessage",
+		Description: "Default system prompt. Preset and library prompts take precedence",
+		get:         func(c Config) string { return c.SystemMessage },
+		set: func(c *Config, value string) (any, error) {
+			c.SystemMessage = value
+			return value, nil
+		},
+	},
+	{
+		Key:         "sessionExportDir",
+		Description: "Directory for session exports. Must be an absolute path. Empty value exports to current directory",
+		get:         func(c Config) string { return c.SessionExportDir },
+		set: func(c *Config, value string) (any, error) {
+			if value != "" && !filepath.IsAbs(value) {
+				return nil, errors.New("sessionExportDir must be an absolute path")
+			}
+			c.SessionExportDir = value
+			return value, nil
+		},
+	},
+	{
+		Key:         "maxAttachmentSizeMb",
+		Description: "Maximum allowed attachment size in megabytes",
+		get:         func(c Config) string { return fmt.Sprint(c.MaxAttachmentSizeMb) },
+		set: func(c *Config, value string) (any, error) {
+			size, err := strconv.Atoi(value)
+			if err != nil || size <= 0 {
+				return nil, errors.New("maxAttachmentSizeMb must be a positive integer")
+			}
+			c.MaxAttachmentSizeMb = size
+			return size, nil
+		},
+	},
+	{
+		Key:         "includeReasoningTokensInContext",
+		Description: "Whether to include reasoning tokens in the next request or not (true/false)",
+		get: func(c Config) string {
+			if c.IncludeReasoningTokensInContext == nil {
+				return "true"
+			}
+			return fmt.Sprint(*c.IncludeReasoningTokensInContext)
+		},
+		set: func(c *Config, value string) (any, error) {
+			include, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("includeReasoningTokensInContext must be true or false")
+			}
+			c.IncludeReasoningTokensInContext = &include
+			return include, nil
+		},
+	},
+	{
+		Key:         "zenModeMaxWidth",
+		Description: "Max width of the chat in zen mode, the chat is centered. 0 disables the limit",
+		get:         func(c Config) string { return fmt.Sprint(c.ZenModeMaxWidth) },
+		set: func(c *Config, value string) (any, error) {
+			width, err := strconv.Atoi(value)
+			if err != nil || width < 0 {
+				return nil, errors.New("zenModeMaxWidth must be a non-negative integer")
+			}
+			c.ZenModeMaxWidth = width
+			return width, nil
+		},
+	},
+	{
+		Key:         "chatPaneWidthRatio",
+		Description: "Chat pane share of the terminal width, from 0.4 to 0.8. Can also be changed with ctrl+left/ctrl+right",
+		get: func(c Config) string {
+			return strconv.FormatFloat(c.GetChatPaneWidthRatio(), 'f', 2, 64)
+		},
+		set: func(c *Config, value string) (any, error) {
+			ratio, err := strconv.ParseFloat(value, 64)
+			if err != nil || ratio < util.MinChatPaneWidthRatio || ratio > util.MaxChatPaneWidthRatio {
+				return nil, errors.New("chatPaneWidthRatio must be a number from 0.4 to 0.8")
+			}
+			c.ChatPaneWidthRatio = ratio
+			return ratio, nil
+		},
+	},
+	{
+		Key:         "notificationDurationSec",
+		Description: "How long notifications stay on the screen, in seconds. Past notifications can be viewed with ctrl+y",
+		get: func(c Config) string {
+			return fmt.Sprint(int(c.GetNotificationDuration().Seconds()))
+		},
+		set: func(c *Config, value string) (any, error) {
+			duration, err := strconv.Atoi(value)
+			if err != nil || duration <= 0 {
+				return nil, errors.New("notificationDurationSec must be a positive integer")
+			}
+			c.NotificationDurationSec = duration
+			return duration, nil
+		},
+	},
+	{
+		Key:             "checkForUpdates",
+		Description:     "Check GitHub releases for a newer version on startup (true/false). Install it with nekot --self-update",
+		RequiresRestart: true,
+		get:             func(c Config) string { return fmt.Sprint(c.CheckForUpdates) },
+		set: func(c *Config, value string) (any, error) {
+			check, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("checkForUpdates must be true or false")
+			}
+			return check, nil
+		},
+	},
+	{
+		Key:         "shareService",
+		Description: "Where shared sessions are uploaded: 0x0 (0x0.st or compatible paste service) or gist (requires GITHUB_TOKEN)",
+		get: func(c Config) string {
+			if c.ShareService == "" {
+				return util.PasteShareService
+			}
+			return c.ShareService
+		},
+		set: func(c *Config, value string) (any, error) {
+			switch value {
+			case util.PasteShareService, util.GistShareService:
+			default:
+				return nil, errors.New("shareService must be one of: 0x0, gist")
+			}
+			c.ShareService = value
+			return value, nil
+		},
+	},
+	{
+		Key:         "shareEndpoint",
+		Description: "Url of a 0x0.st compatible paste service. Empty value uses https://0x0.st",
+		get:         func(c Config) string { return c.ShareEndpoint },
+		set: func(c *Config, value string) (any, error) {
+			match, _ := regexp.MatchString(`^https?://`, value)
+			if value != "" && !match {
+				return nil, errors.New("shareEndpoint must be a valid URL")
+			}
+			c.ShareEndpoint = value
+			return value, nil
+		},
+	},
+	{
+		Key:         "notesDir",
+		Description: "Notes vault directory (e.g. an Obsidian vault) for responses and chats saved as notes. Must be an absolute path",
+		get:         func(c Config) string { return c.NotesDir },
+		set: func(c *Config, value string) (any, error) {
+			if value != "" && !filepath.IsAbs(value) {
+				return nil, errors.New("notesDir must be an absolute path")
+			}
+			c.NotesDir = value
+			return value, nil
+		},
+	},
+	{
+		Key:         "notesTags",
+		Description: "Comma separated tags added to the frontmatter of saved notes, along with the nekot tag",
+		get:         func(c Config) string { return strings.Join(c.NotesTags, ",") },
+		set: func(c *Config, value string) (any, error) {
+			tags := []string{}
+			for _, tag := range strings.Split(value, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					tags = append(tags, tag)
+				}
+			}
+			c.NotesTags = tags
+			return tags, nil
+		},
+	},
+	{
+		Key:         "translationLanguage",
+		Description: "Target language of the translation mode, toggled per session in the sessions pane. Empty value translates to English",
+		get:         func(c Config) string { return c.TranslationLanguage },
+		set: func(c *Config, value string) (any, error) {
+			c.TranslationLanguage = value
+			return value, nil
+		},
+	},
+	{
+		Key:         "translationTone",
+		Description: "Tone of translations, e.g. formal or casual. Empty value keeps the tone of the original",
+		get:         func(c Config) string { return c.TranslationTone },
+		set: func(c *Config, value string) (any, error) {
+			c.TranslationTone = value
+			return value, nil
+		},
+	},
+	{
+		Key:         "redactPrompts",
+		Description: "Mask API keys, emails, AWS keys and redactionPatterns matches in prompts before they are sent (true/false)",
+		get:         func(c Config) string { return fmt.Sprint(c.RedactPrompts) },
+		set: func(c *Config, value string) (any, error) {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("redactPrompts must be true or false")
+			}
+			c.RedactPrompts = enabled
+			return enabled, nil
+		},
+	},
+	{
+		Key:         "openAiOrganization",
+		Description: "OpenAI organization id, sent as OpenAI-Organization header by the openai provider. Empty value omits the header",
+		get:         func(c Config) string { return c.OpenAiOrganization },
+		set: func(c *Config, value string) (any, error) {
+			c.OpenAiOrganization = value
+			return value, nil
+		},
+	},
+	{
+		Key:         "openAiProject",
+		Description: "OpenAI project id, sent as OpenAI-Project header by the openai provider. Empty value omits the header",
+		get:         func(c Config) string { return c.OpenAiProject },
+		set: func(c *Config, value string) (any, error) {
+			c.OpenAiProject = value
+			return value, nil
+		},
+	},
+	{
+		Key:         "providerProfile",
+		Description: "Streaming quirks of the openai provider server: " + strings.Join(util.ProviderProfiles, ", ") + ". Empty value expects standard OpenAI streaming",
+		get:         func(c Config) string { return c.ProviderProfile },
+		set: func(c *Config, value string) (any, error) {
+			if value != "" && !slices.Contains(util.ProviderProfiles, value) {
+				return nil, fmt.Errorf("providerProfile must be one of: %s", strings.Join(util.ProviderProfiles, ", "))
+			}
+			c.ProviderProfile = value
+			return value, nil
+		},
+	},
+	{
+		Key:         "geminiCodeExecution",
+		Description: "Let Gemini models write and run python code on Google servers while answering (true/false)",
+		get:         func(c Config) string { return fmt.Sprint(c.GeminiCodeExecution) },
+		set: func(c *Config, value string) (any, error) {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("geminiCodeExecution must be true or false")
+			}
+			c.GeminiCodeExecution = enabled
+			return enabled, nil
+		},
+	},
+	{
+		Key:         "geminiGoogleSearch",
+		Description: "Ground Gemini responses with Google Search results, sources are listed after the response (true/false)",
+		get:         func(c Config) string { return fmt.Sprint(c.GeminiGoogleSearch) },
+		set: func(c *Config, value string) (any, error) {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("geminiGoogleSearch must be true or false")
+			}
+			c.GeminiGoogleSearch = enabled
+			return enabled, nil
+		},
+	},
+	{
+		Key:         "offlineMode",
+		Description: "Local-only mode: block requests to hosts other than localhost and offlineAllowlist, web search is disabled (true/false). Also enabled with --offline",
+		get:         func(c Config) string { return fmt.Sprint(c.OfflineMode) },
+		set: func(c *Config, value string) (any, error) {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("offlineMode must be true or false")
+			}
+			c.OfflineMode = enabled
+			return enabled, nil
+		},
+	},
+	{
+		Key:         "clipboardWatch",
+		Description: "Watch the system clipboard and offer to insert newly copied text into the prompt as a code block (true/false)",
+		get:         func(c Config) string { return fmt.Sprint(c.ClipboardWatch) },
+		set: func(c *Config, value string) (any, error) {
+			watch, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("clipboardWatch must be true or false")
+			}
+			c.ClipboardWatch = watch
+			return watch, nil
+		},
+	},
+	{
+		Key:         "windowTitle",
+		Description: "Show the current session name and a busy indicator in the terminal window or tmux pane title (true/false)",
+		get:         func(c Config) string { return fmt.Sprint(c.WindowTitle) },
+		set: func(c *Config, value string) (any, error) {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("windowTitle must be true or false")
+			}
+			c.WindowTitle = enabled
+			return enabled, nil
+		},
+	},
+	{
+		Key:         "hyperlinks",
+		Description: "Render sources of responses as clickable links (OSC 8). Disable if the terminal does not support them, urls are listed in full then (true/false)",
+		get: func(c Config) string {
+			if c.Hyperlinks == nil {
+				return "true"
+			}
+			return fmt.Sprint(*c.Hyperlinks)
+		},
+		set: func(c *Config, value string) (any, error) {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("hyperlinks must be true or false")
+			}
+			c.Hyperlinks = &enabled
+			return enabled, nil
+		},
+	},
+	{
+		Key:         "contentMaxWidth",
+		Description: "Max width of rendered messages, narrower panes use their own width. 0 disables the limit, otherwise at least 40",
+		get:         func(c Config) string { return fmt.Sprint(c.ContentMaxWidth) },
+		set: func(c *Config, value string) (any, error) {
+			width, err := strconv.Atoi(value)
+			if err != nil || width < 0 || (width != 0 && width < util.MinContentMaxWidth) {
+				return nil, errors.New("contentMaxWidth must be 0 or an integer of at least 40")
+			}
+			c.ContentMaxWidth = width
+			return width, nil
+		},
+	},
+	{
+		Key:         "wrapCodeBlocks",
+		Description: "Wrap long lines of code blocks and squeeze tables to the pane width. When disabled, they keep their layout and wide lines are scrolled horizontally with h/l (true/false)",
+		get: func(c Config) string {
+			if c.WrapCodeBlocks == nil {
+				return "true"
+			}
+			return fmt.Sprint(*c.WrapCodeBlocks)
+		},
+		set: func(c *Config, value string) (any, error) {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("wrapCodeBlocks must be true or false")
+			}
+			c.WrapCodeBlocks = &enabled
+			return enabled, nil
+		},
+	},
+	{
+		Key:         "mermaidCommand",
+		Description: "Command that renders mermaid diagrams, NEKOT_DIAGRAM_INPUT and NEKOT_DIAGRAM_OUTPUT hold the paths. Empty uses mmdc if installed",
+		get:         func(c Config) string { return c.MermaidCommand },
+		set: func(c *Config, value string) (any, error) {
+			c.MermaidCommand = value
+			return value, nil
+		},
+	},
+	{
+		Key:         "mermaidFormat",
+		Description: "Image format of rendered mermaid diagrams: svg or png",
+		get: func(c Config) string {
+			if c.MermaidFormat == "" {
+				return util.SvgDiagramFormat
+			}
+			return c.MermaidFormat
+		},
+		set: func(c *Config, value string) (any, error) {
+			switch value {
+			case util.SvgDiagramFormat, util.PngDiagramFormat:
+			default:
+				return nil, errors.New("mermaidFormat must be svg or png")
+			}
+			c.MermaidFormat = value
+			return value, nil
+		},
+	},
+	{
+		Key:         "spellCheckLanguage",
+		Description: "Hunspell dictionaries to check the spelling of prompts with, e.g. en_US or en_US,de_DE. Empty disables the check",
+		get:         func(c Config) string { return c.SpellCheckLanguage },
+		set: func(c *Config, value string) (any, error) {
+			c.SpellCheckLanguage = strings.ReplaceAll(value, " ", "")
+			return c.SpellCheckLanguage, nil
+		},
+	},
+	{
+		Key:         "userIcon",
+		Description: "Icon of your messages, e.g. > for terminals without emoji. Empty restores the default, none hides the icon",
+		get:         func(c Config) string { return c.UserIcon },
+		set: func(c *Config, value string) (any, error) {
+			c.UserIcon = value
+			return value, nil
+		},
+	},
+	{
+		Key:         "assistantIcon",
+		Description: "Icon of responses, e.g. * for terminals without emoji. Empty restores the default, none hides the icon",
+		get:         func(c Config) string { return c.AssistantIcon },
+		set: func(c *Config, value string) (any, error) {
+			c.AssistantIcon = value
+			return value, nil
+		},
+	},
+	{
+		Key:         "userLabel",
+		Description: "Label of your messages. Empty restores the default",
+		get:         func(c Config) string { return c.UserLabel },
+		set: func(c *Config, value string) (any, error) {
+			c.UserLabel = value
+			return value, nil
+		},
+	},
+	{
+		Key:         "autosaveIntervalSec",
+		Description: "How often a streamed response is saved to the session, in seconds. 0 saves it only once it is complete",
+		get:         func(c Config) string { return fmt.Sprint(c.AutosaveIntervalSec) },
+		set: func(c *Config, value string) (any, error) {
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 0 {
+				return nil, errors.New("autosaveIntervalSec must be a non-negative integer")
+			}
+			c.AutosaveIntervalSec = interval
+			return interval, nil
+		},
+	},
+	{
+		Key:         "requestTimeoutSec",
+		Description: "Timeout of loading the models list and the settings, in seconds",
+		get: func(c Config) string {
+			return fmt.Sprint(int(c.GetRequestTimeout().Seconds()))
+		},
+		set: func(c *Config, value string) (any, error) {
+			timeout, err := strconv.Atoi(value)
+			if err != nil || timeout <= 0 {
+				return nil, errors.New("requestTimeoutSec must be a positive integer")
+			}
+			c.RequestTimeoutSec = timeout
+			return timeout, nil
+		},
+	},
+	{
+		Key:         "webSearchTimeoutSec",
+		Description: "Timeout of a web search, including loading of the found pages, in seconds",
+		get: func(c Config) string {
+			return fmt.Sprint(int(c.GetWebSearchTimeout().Seconds()))
+		},
+		set: func(c *Config, value string) (any, error) {
+			timeout, err := strconv.Atoi(value)
+			if err != nil || timeout <= 0 {
+				return nil, errors.New("webSearchTimeoutSec must be a positive integer")
+			}
+			c.WebSearchTimeoutSec = timeout
+			return timeout, nil
+		},
+	},
+	{
+		Key:         "webSearchPages",
+		Description: "Maximum number of found pages loaded by a web search",
+		get:         func(c Config) string { return fmt.Sprint(c.GetWebSearchPages()) },
+		set: func(c *Config, value string) (any, error) {
+			pages, err := strconv.Atoi(value)
+			if err != nil || pages <= 0 {
+				return nil, errors.New("webSearchPages must be a positive integer")
+			}
+			c.WebSearchPages = pages
+			return pages, nil
+		},
+	},
+	{
+		Key:         "webSearchResults",
+		Description: "Number of page excerpts a web search adds to the context",
+		get:         func(c Config) string { return fmt.Sprint(c.GetWebSearchResults()) },
+		set: func(c *Config, value string) (any, error) {
+			results, err := strconv.Atoi(value)
+			if err != nil || results <= 0 {
+				return nil, errors.New("webSearchResults must be a positive integer")
+			}
+			c.WebSearchResults = results
+			return results, nil
+		},
+	},
+	{
+		Key:         "exitZenModeOnError",
+		Description: "Leave zen mode when an error occurs, so the info and sidebar panes are visible (true/false)",
+		get:         func(c Config) string { return fmt.Sprint(c.ExitZenModeOnError) },
+		set: func(c *Config, value string) (any, error) {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("exitZenModeOnError must be true or false")
+			}
+			c.ExitZenModeOnError = enabled
+			return enabled, nil
+		},
+	},
+	{
+		Key:         "returnFromEditorOnSend",
+		Description: "Return to the normal mode after a prompt is sent from the editor mode (true/false)",
+		get: func(c Config) string {
+			if c.ReturnFromEditorOnSend == nil {
+				return "true"
+			}
+			return fmt.Sprint(*c.ReturnFromEditorOnSend)
+		},
+		set: func(c *Config, value string) (any, error) {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("returnFromEditorOnSend must be true or false")
+			}
+			c.ReturnFromEditorOnSend = &enabled
+			return enabled, nil
+		},
+	},
+	{
+		Key:         "clearConfirmLength",
+		Description: "Prompts longer than this number of characters are cleared only after a confirmation. 0 clears without asking",
+		get:         func(c Config) string { return fmt.Sprint(c.ClearConfirmLength) },
+		set: func(c *Config, value string) (any, error) {
+			length, err := strconv.Atoi(value)
+			if err != nil || length < 0 {
+				return nil, errors.New("clearConfirmLength must be a non-negative integer")
+			}
+			c.ClearConfirmLength = length
+			return length, nil
+		},
+	},
+	{
+		Key:         "followUpSuggestions",
+		Description: "Follow-up questions under each response: heuristic (from the response headings), model (asks the model, an extra request) or empty to disable",
+		get:         func(c Config) string { return c.FollowUpSuggestions },
+		set: func(c *Config, value string) (any, error) {
+			switch value {
+			case "", util.HeuristicFollowUps, util.ModelFollowUps:
+			default:
+				return nil, errors.New("followUpSuggestions must be one of: heuristic, model or empty")
+			}
+			c.FollowUpSuggestions = value
+			return value, nil
+		},
+	},
+	{
+		Key:         "colorScheme",
+		Description: "Color scheme: pink, blue, groove",
+		get:         func(c Config) string { return string(c.ColorScheme) },
+		set: func(c *Config, value string) (any, error) {
+			scheme := util.ColorScheme(strings.ToLower(value))
+			switch scheme {
+			case util.OriginalPink, util.SmoothBlue, util.Groovebox:
+			default:
+				return nil, errors.New("colorScheme must be one of: pink, blue, groove")
+			}
+			c.ColorScheme = scheme
+			return string(scheme), nil
+		},
+	},
+	{
+		Key:             "demoMode",
+		Description:     "Presentation mode: masks API keys in errors, hides session names and saved prompts (true/false). Also enabled with --demo",
+		RequiresRestart: true,
+		get:             func(c Config) string { return fmt.Sprint(c.DemoMode) },
+		set: func(c *Config, value string) (any, error) {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("demoMode must be true or false")
+			}
+			return enabled, nil
+		},
+	},
+	{
+		Key:             "language",
+		Description:     "Language of the interface: " + strings.Join(i18n.SupportedLanguages, ", "),
+		RequiresRestart: true,
+		get: func(c Config) string {
+			if c.Language == "" {
+				return i18n.DefaultLanguage
+			}
+			return c.Language
+		},
+		set: func(c *Config, value string) (any, error) {
+			lang := strings.ToLower(value)
+			if !i18n.IsSupported(lang) {
+				return nil, errors.New("language must be one of: " + strings.Join(i18n.SupportedLanguages, ", "))
+			}
+			return lang, nil
+		},
+	},
+	{
+		Key:             "asciiMode",
+		Description:     "Plain ASCII icons, list dots and borders for terminals and fonts without emoji and box drawing glyphs (true/false)",
+		RequiresRestart: true,
+		get:             func(c Config) string { return fmt.Sprint(c.AsciiMode) },
+		set: func(c *Config, value string) (any, error) {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("asciiMode must be true or false")
+			}
+			return enabled, nil
+		},
+	},
+	{
+		Key:             "screenReaderMode",
+		Description:     "Plain text state lines and a message log view for screen readers, implies asciiMode and reducedMotion (true/false)",
+		RequiresRestart: true,
+		get:             func(c Config) string { return fmt.Sprint(c.ScreenReaderMode) },
+		set: func(c *Config, value string) (any, error) {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("screenReaderMode must be true or false")
+			}
+			return enabled, nil
+		},
+	},
+	{
+		Key:             "reducedMotion",
+		Description:     "Static text instead of spinners, no cursor blinking and no constant redraws (true/false)",
+		RequiresRestart: true,
+		get:             func(c Config) string { return fmt.Sprint(c.ReducedMotion) },
+		set: func(c *Config, value string) (any, error) {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("reducedMotion must be true or false")
+			}
+			return enabled, nil
+		},
+	},
+	{
+		Key:             "startupDashboard",
+		Description:     "Show recent sessions, sessions with pinned messages and quick actions on startup (true/false)",
+		RequiresRestart: true,
+		get:             func(c Config) string { return fmt.Sprint(c.StartupDashboard) },
+		set: func(c *Config, value string) (any, error) {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("startupDashboard must be true or false")
+			}
+			return enabled, nil
+		},
+	},
+	{
+		Key:             "defaultModel",
+		Description:     "Model to use on startup. Better to set it from the settings pane",
+		RequiresRestart: true,
+		get:             func(c Config) string { return c.DefaultModel },
+		set: func(c *Config, value string) (any, error) {
+			return value, nil
+		},
+	},
+	{
+		Key:             "provider",
+		Description:     "LLM provider: openai, gemini, openrouter, vertex, bedrock",
+		RequiresRestart: true,
+		get:             func(c Config) string { return c.Provider },
+		set: func(c *Config, value string) (any, error) {
+			switch value {
+			case util.OpenAiProviderType, util.GeminiProviderType, util.OpenrouterProviderType,
+				util.VertexProviderType, util.BedrockProviderType:
+			default:
+				return nil, errors.New("provider must be one of: openai, gemini, openrouter, vertex, bedrock")
+			}
+			return value, nil
+		},
+	},
+	{
+		Key:             "providerBaseUrl",
+		Description:     "Base url of an OpenAI compatible API. Used by the openai provider only",
+		RequiresRestart: true,
+		get:             func(c Config) string { return c.ProviderBaseUrl },
+		set: func(c *Config, value string) (any, error) {
+			match, _ := regexp.MatchString(`^https?://`, value)
+			if !match {
+				return nil, errors.New("providerBaseUrl must be a valid URL")
+			}
+			return value, nil
+		},
+	},
+}
+
+// Validates the value, applies it to the config and persists it to the config file.
+// Options that require restart are persisted only
+func (c *Config) UpdateOption(option ConfigOption, value string) error {
+	updated := *c
+	persistedValue, err := option.set(&updated, strings.TrimSpace(value))
+	if err != nil {
+		return err
+	}
+
+	err = persistConfigValue(option.Key, persistedValue)
+	if err != nil {
+		return err
+	}
+
+	if !option.RequiresRestart {
+		*c = updated
+	}
+
+	return nil
+}
+
+// Persists rows added to the prompt pane in the view mode. The rows are changed
+// with keys or the mouse, so they are not listed among the editable options
+func (c *Config) SetPromptPaneRows(mode string, rows int) error {
+	updated := maps.Clone(c.PromptPaneRows)
+	if updated == nil {
+		updated = map[string]int{}
+	}
+	updated[mode] = rows
+
+	err := persistConfigValue("promptPaneRows", updated)
+	if err != nil {
+		return err
+	}
+
+	c.PromptPaneRows = updated
+	return nil
+}
+
+// Looks up an editable option by its key
+func FindOption(key string) (ConfigOption, bool) {
+	for _, option := range EditableOptions {
+		if option.Key == key {
+			return option, true
+		}
+	}
+	return ConfigOption{}, false
+}
+
+// Updates a single key in the config file, keeping the rest of the file intact
+func persistConfigValue(key string, value any) error {
+	configFilePath, err := createConfig()
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(configFilePath)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]any{}
+	err = json.Unmarshal(content, &values)
+	if err != nil {
+		return err
+	}
+
+	values[key] = value
+	updatedContent, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(configFilePath, updatedContent, 0644)
+}
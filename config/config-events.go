@@ -0,0 +1,13 @@
+package config
+
+import tea "github.com/charmbracelet/bubbletea"
+
+type ConfigUpdated struct {
+	Config Config
+}
+
+func SendConfigUpdatedMsg(config Config) tea.Cmd {
+	return func() tea.Msg {
+		return ConfigUpdated{Config: config}
+	}
+}
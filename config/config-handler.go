@@ -9,8 +9,11 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/BalanceBalls/nekot/i18n"
 	"github.com/BalanceBalls/nekot/util"
 )
 
@@ -44,15 +47,149 @@ func FromContext(ctx context.Context) (*Config, bool) {
 }
 
 type Config struct {
-	ChatGPTApiUrl                   string           `json:"chatGPTAPiUrl"`
-	ProviderBaseUrl                 string           `json:"providerBaseUrl"`
-	SystemMessage                   string           `json:"systemMessage"`
-	DefaultModel                    string           `json:"defaultModel"`
-	Provider                        string           `json:"provider"`
-	ColorScheme                     util.ColorScheme `json:"colorScheme"`
-	MaxAttachmentSizeMb             int              `json:"maxAttachmentSizeMb"`
-	IncludeReasoningTokensInContext *bool            `json:"includeReasoningTokensInContext"`
-	SessionExportDir                string           `json:"sessionExportDir"`
+	ChatGPTApiUrl                   string              `json:"chatGPTAPiUrl"`
+	ProviderBaseUrl                 string              `json:"providerBaseUrl"`
+	SystemMessage                   string              `json:"systemMessage"`
+	DefaultModel                    string              `json:"defaultModel"`
+	Provider                        string              `json:"provider"`
+	ColorScheme                     util.ColorScheme    `json:"colorScheme"`
+	MaxAttachmentSizeMb             int                 `json:"maxAttachmentSizeMb"`
+	IncludeReasoningTokensInContext *bool               `json:"includeReasoningTokensInContext"`
+	SessionExportDir                string              `json:"sessionExportDir"`
+	ZenModeMaxWidth                 int                 `json:"zenModeMaxWidth"`
+	ChatPaneWidthRatio              float64             `json:"chatPaneWidthRatio"`
+	NotificationDurationSec         int                 `json:"notificationDurationSec"`
+	Language                        string              `json:"language"`
+	CheckForUpdates                 bool                `json:"checkForUpdates"`
+	KeyBindings                     map[string][]string `json:"keyBindings"`
+	DemoMode                        bool                `json:"demoMode"`
+	ShareService                    string              `json:"shareService"`
+	ShareEndpoint                   string              `json:"shareEndpoint"`
+	ClipboardWatch                  bool                `json:"clipboardWatch"`
+	WindowTitle                     bool                `json:"windowTitle"`
+	Hyperlinks                      *bool               `json:"hyperlinks"`
+	FollowUpSuggestions             string              `json:"followUpSuggestions"`
+	Hooks                           Hooks               `json:"hooks"`
+	NotesDir                        string              `json:"notesDir"`
+	NotesTags                       []string            `json:"notesTags"`
+	TranslationLanguage             string              `json:"translationLanguage"`
+	TranslationTone                 string              `json:"translationTone"`
+	Middleware                      []Middleware        `json:"middleware"`
+	RedactPrompts                   bool                `json:"redactPrompts"`
+	RedactionPatterns               []string            `json:"redactionPatterns"`
+	OfflineMode                     bool                `json:"offlineMode"`
+	OfflineAllowlist                []string            `json:"offlineAllowlist"`
+	OpenAiOrganization              string              `json:"openAiOrganization"`
+	OpenAiProject                   string              `json:"openAiProject"`
+	ProviderHeaders                 map[string]Headers  `json:"providerHeaders"`
+	ProviderAuth                    map[string]Auth     `json:"providerAuth"`
+	ProviderProfile                 string              `json:"providerProfile"`
+	GeminiCodeExecution             bool                `json:"geminiCodeExecution"`
+	GeminiGoogleSearch              bool                `json:"geminiGoogleSearch"`
+	GeminiSafetySettings            map[string]string   `json:"geminiSafetySettings"`
+	GeminiBackend                   string              `json:"geminiBackend"`
+	VertexProject                   string              `json:"vertexProject"`
+	VertexLocation                  string              `json:"vertexLocation"`
+	VertexCredentialsFile           string              `json:"vertexCredentialsFile"`
+	BedrockRegion                   string              `json:"bedrockRegion"`
+	BedrockProfile                  string              `json:"bedrockProfile"`
+	ContentMaxWidth                 int                 `json:"contentMaxWidth"`
+	WrapCodeBlocks                  *bool               `json:"wrapCodeBlocks"`
+	MermaidCommand                  string              `json:"mermaidCommand"`
+	MermaidFormat                   string              `json:"mermaidFormat"`
+	SpellCheckLanguage              string              `json:"spellCheckLanguage"`
+	ModelPrices                     map[string]float64  `json:"modelPrices"`
+	UserIcon                        string              `json:"userIcon"`
+	AssistantIcon                   string              `json:"assistantIcon"`
+	UserLabel                       string              `json:"userLabel"`
+	AsciiMode                       bool                `json:"asciiMode"`
+	ScreenReaderMode                bool                `json:"screenReaderMode"`
+	ReducedMotion                   bool                `json:"reducedMotion"`
+	AutosaveIntervalSec             int                 `json:"autosaveIntervalSec"`
+	ExitZenModeOnError              bool                `json:"exitZenModeOnError"`
+	ReturnFromEditorOnSend          *bool               `json:"returnFromEditorOnSend"`
+	StartupDashboard                bool                `json:"startupDashboard"`
+	ClearConfirmLength              int                 `json:"clearConfirmLength"`
+	SystemMessageModes              map[string]string   `json:"systemMessageModes"`
+	ParameterSchedule               []ParameterStep     `json:"parameterSchedule"`
+	FineTuningToolCalls             string              `json:"fineTuningToolCalls"`
+	PromptPaneRows                  map[string]int      `json:"promptPaneRows"`
+	RequestTimeoutSec               int                 `json:"requestTimeoutSec"`
+	WebSearchTimeoutSec             int                 `json:"webSearchTimeoutSec"`
+	WebSearchPages                  int                 `json:"webSearchPages"`
+	WebSearchResults                int                 `json:"webSearchResults"`
+}
+
+const (
+	AppendInstructionMiddleware = "appendInstruction"
+	RedactSecretsMiddleware     = "redactSecrets"
+	DateTimeMiddleware          = "dateTime"
+	VariablesMiddleware         = "variables"
+	StripBoilerplateMiddleware  = "stripBoilerplate"
+)
+
+// A step of the middleware chain. Prompt middleware transforms outgoing messages,
+// response middleware (stripBoilerplate) transforms received responses. Steps run in the config order
+type Middleware struct {
+	Type     string            `json:"type"`
+	Text     string            `json:"text"`
+	Values   map[string]string `json:"values"`
+	Patterns []string          `json:"patterns"`
+}
+
+// Request parameters used once the conversation has the given number of answered prompts.
+// Later steps override the earlier ones, parameters a step does not set are kept
+type ParameterStep struct {
+	AfterTurns  int      `json:"afterTurns"`
+	Temperature *float32 `json:"temperature"`
+	TopP        *float32 `json:"topP"`
+	MaxTokens   *int     `json:"maxTokens"`
+}
+
+// Shell commands run on app events, see the hooks package for the data they receive
+type Hooks struct {
+	OnResponseComplete string `json:"on_response_complete"`
+	OnSessionCreated   string `json:"on_session_created"`
+	OnExport           string `json:"on_export"`
+}
+
+const (
+	BearerAuth = "bearer"
+	HeaderAuth = "header"
+	BasicAuth  = "basic"
+	NoAuth     = "none"
+)
+
+const DefaultAuthHeader = "x-api-key"
+
+// How the API key is sent to the provider. Bearer token is used by default,
+// `header` sends the key as is in Header (x-api-key by default), `basic` uses the key as the password
+type Auth struct {
+	Scheme   string `json:"scheme"`
+	Header   string `json:"header"`
+	Username string `json:"username"`
+}
+
+// Extra http headers sent with every request to a provider, header name to value
+type Headers map[string]string
+
+// Returns headers for requests to the provider: OpenAI organization and project for the openai provider,
+// then custom headers from providerHeaders. Custom headers take precedence
+func (c Config) GetProviderHeaders(provider string) Headers {
+	headers := Headers{}
+	if provider == util.OpenAiProviderType {
+		if c.OpenAiOrganization != "" {
+			headers["OpenAI-Organization"] = c.OpenAiOrganization
+		}
+		if c.OpenAiProject != "" {
+			headers["OpenAI-Project"] = c.OpenAiProject
+		}
+	}
+
+	for name, value := range c.ProviderHeaders[provider] {
+		headers[name] = value
+	}
+	return headers
 }
 
 type StartupFlags struct {
@@ -62,15 +199,17 @@ type StartupFlags struct {
 	ProviderUrl     string
 	StartNewSession bool
 	InitialPrompt   string
+	DemoMode        bool
+	OfflineMode     bool
 }
 
 //go:embed config.json
 var configEmbed embed.FS
 
 func createConfig() (string, error) {
-	appPath, err := util.GetAppDataPath()
+	appPath, err := util.GetAppConfigPath()
 	if err != nil {
-		util.Slog.Error("failed to get app path", "error", err.Error())
+		util.Slog.Error("failed to get app config path", "error", err.Error())
 		panic(err)
 	}
 
@@ -116,11 +255,145 @@ func validateConfig(config Config) bool {
 		}
 	}
 
+	if config.ContentMaxWidth != 0 && config.ContentMaxWidth < util.MinContentMaxWidth {
+		fmt.Printf("ContentMaxWidth must be 0 or at least %d\n", util.MinContentMaxWidth)
+		return false
+	}
+
+	if config.NotesDir != "" && !filepath.IsAbs(config.NotesDir) {
+		fmt.Println("NotesDir must be an absolute path")
+		return false
+	}
+
+	for _, pattern := range config.RedactionPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			fmt.Printf("Invalid redaction pattern %q: %s\n", pattern, err.Error())
+			return false
+		}
+	}
+
+	if config.ProviderProfile != "" && !slices.Contains(util.ProviderProfiles, config.ProviderProfile) {
+		fmt.Printf("Unsupported provider profile. Supported values: %s\n", strings.Join(util.ProviderProfiles, ", "))
+		return false
+	}
+
+	switch config.GeminiBackend {
+	case "", util.GeminiApiBackend:
+	case util.VertexBackend:
+		if config.VertexProject == "" {
+			fmt.Println("vertexProject must be set to use the vertex gemini backend")
+			return false
+		}
+	default:
+		fmt.Printf("Unsupported gemini backend. Supported values: %s, %s\n", util.GeminiApiBackend, util.VertexBackend)
+		return false
+	}
+
+	if config.VertexCredentialsFile != "" && !filepath.IsAbs(config.VertexCredentialsFile) {
+		fmt.Println("VertexCredentialsFile must be an absolute path")
+		return false
+	}
+
+	for category, threshold := range config.GeminiSafetySettings {
+		if !slices.Contains(util.SafetyCategories, category) {
+			fmt.Printf("Unsupported gemini safety category %q. Supported values: %s\n", category, strings.Join(util.SafetyCategories, ", "))
+			return false
+		}
+		if !slices.Contains(util.SafetyThresholds, threshold) {
+			fmt.Printf("Unsupported gemini safety threshold %q. Supported values: %s\n", threshold, strings.Join(util.SafetyThresholds, ", "))
+			return false
+		}
+	}
+
+	for provider, auth := range config.ProviderAuth {
+		err := auth.validate()
+		if err != nil {
+			fmt.Printf("Invalid auth of %s provider: %s\n", provider, err.Error())
+			return false
+		}
+	}
+
+	for _, middleware := range config.Middleware {
+		err := middleware.validate()
+		if err != nil {
+			fmt.Println(err.Error())
+			return false
+		}
+	}
+
+	switch config.FollowUpSuggestions {
+	case "", util.HeuristicFollowUps, util.ModelFollowUps:
+	default:
+		fmt.Printf("Unsupported follow-up suggestions. Supported values: %s, %s\n", util.HeuristicFollowUps, util.ModelFollowUps)
+		return false
+	}
+
+	switch config.FineTuningToolCalls {
+	case "", util.ExcludeToolCalls, util.FlattenToolCalls:
+	default:
+		fmt.Printf("Unsupported fine-tuning tool calls. Supported values: %s, %s\n", util.ExcludeToolCalls, util.FlattenToolCalls)
+		return false
+	}
+
+	for mode, rows := range config.PromptPaneRows {
+		if _, ok := util.PromptPaneModes[mode]; !ok {
+			fmt.Printf("Unsupported view mode %q in promptPaneRows. Supported values: normal, zen, editor\n", mode)
+			return false
+		}
+
+		if rows < 0 {
+			fmt.Printf("Invalid prompt pane rows of %s mode: must not be negative\n", mode)
+			return false
+		}
+	}
+
+	for model, mode := range config.SystemMessageModes {
+		if !slices.Contains(util.SystemMessageModes, mode) {
+			fmt.Printf("Unsupported system message mode %q of %s model. Supported values: %s\n", mode, model, strings.Join(util.SystemMessageModes, ", "))
+			return false
+		}
+	}
+
+	for _, step := range config.ParameterSchedule {
+		err := step.validate()
+		if err != nil {
+			fmt.Println(err.Error())
+			return false
+		}
+	}
+
+	for model, price := range config.ModelPrices {
+		if price < 0 {
+			fmt.Printf("Invalid price of %s model: must not be negative\n", model)
+			return false
+		}
+	}
+
+	switch config.MermaidFormat {
+	case "", util.SvgDiagramFormat, util.PngDiagramFormat:
+	default:
+		fmt.Printf("Unsupported mermaid format. Supported values: %s, %s\n", util.SvgDiagramFormat, util.PngDiagramFormat)
+		return false
+	}
+
+	if config.Language != "" && !i18n.IsSupported(config.Language) {
+		fmt.Printf("Unsupported language. Supported values: %s\n", strings.Join(i18n.SupportedLanguages, ", "))
+		return false
+	}
+
 	switch config.Provider {
 	case util.OpenrouterProviderType:
 		return true
 	case util.GeminiProviderType:
 		return true
+	case util.VertexProviderType:
+		if config.VertexProject == "" {
+			fmt.Println("vertexProject must be set to use the vertex provider")
+			return false
+		}
+		return true
+	case util.BedrockProviderType:
+		return true
 	case util.OpenAiProviderType:
 		// Validate provider base url format
 		match, _ := regexp.MatchString(`^https?://`, config.ProviderBaseUrl)
@@ -131,7 +404,7 @@ func validateConfig(config Config) bool {
 		// Add any other validation logic here
 		return true
 	default:
-		fmt.Println("Incorrect provider type. Supported values: 'openai', 'gemini', 'openrouter'")
+		fmt.Println("Incorrect provider type. Supported values: 'openai', 'gemini', 'openrouter', 'vertex', 'bedrock'")
 		return false
 	}
 }
@@ -185,7 +458,7 @@ func (c Config) checkApiKeys() {
 		}
 	case util.GeminiProviderType:
 		apiKey := os.Getenv("GEMINI_API_KEY")
-		if apiKey == "" {
+		if apiKey == "" && c.GeminiBackend != util.VertexBackend {
 			fmt.Println("GEMINI_API_KEY not set; set it in your profile")
 			fmt.Printf(
 				"export GEMINI_API_KEY=your_key in the config for :%v \n",
@@ -195,7 +468,7 @@ func (c Config) checkApiKeys() {
 			os.Exit(1)
 		}
 	case util.OpenAiProviderType:
-		if util.IsLocalProvider(c.ProviderBaseUrl) {
+		if util.IsLocalProvider(c.ProviderBaseUrl) || c.ProviderAuth[c.Provider].Scheme == NoAuth {
 			return
 		}
 
@@ -229,6 +502,138 @@ func (c *Config) setDefaults() {
 	if c.IncludeReasoningTokensInContext == nil {
 		c.IncludeReasoningTokensInContext = &TRUE
 	}
+
+	if c.Hyperlinks == nil {
+		c.Hyperlinks = &TRUE
+	}
+
+	if c.WrapCodeBlocks == nil {
+		c.WrapCodeBlocks = &TRUE
+	}
+
+	if c.ReturnFromEditorOnSend == nil {
+		c.ReturnFromEditorOnSend = &TRUE
+	}
+}
+
+func (a Auth) validate() error {
+	switch a.Scheme {
+	case "", BearerAuth, HeaderAuth, NoAuth:
+		return nil
+	case BasicAuth:
+		if a.Username == "" {
+			return fmt.Errorf("%s auth requires username", a.Scheme)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown auth scheme %q, supported: %s, %s, %s, %s", a.Scheme, BearerAuth, HeaderAuth, BasicAuth, NoAuth)
+}
+
+func (m Middleware) validate() error {
+	switch m.Type {
+	case AppendInstructionMiddleware:
+		if m.Text == "" {
+			return fmt.Errorf("%s middleware requires text", m.Type)
+		}
+	case VariablesMiddleware:
+		if len(m.Values) == 0 {
+			return fmt.Errorf("%s middleware requires values", m.Type)
+		}
+	case StripBoilerplateMiddleware:
+		for _, pattern := range m.Patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("invalid %s pattern %q: %s", m.Type, pattern, err.Error())
+			}
+		}
+	case RedactSecretsMiddleware, DateTimeMiddleware:
+	default:
+		return fmt.Errorf(
+			"Unknown middleware type %q. Supported values: %s",
+			m.Type,
+			strings.Join([]string{
+				AppendInstructionMiddleware,
+				RedactSecretsMiddleware,
+				DateTimeMiddleware,
+				VariablesMiddleware,
+				StripBoilerplateMiddleware,
+			}, ", "))
+	}
+	return nil
+}
+
+func (s ParameterStep) validate() error {
+	if s.AfterTurns < 0 {
+		return fmt.Errorf("Invalid parameter schedule step: afterTurns must not be negative")
+	}
+
+	if s.Temperature != nil && util.TemperatureValidator(fmt.Sprint(*s.Temperature)) != nil {
+		return fmt.Errorf("Invalid temperature of the parameter schedule step after %d turns, must be in %s", s.AfterTurns, util.TemperatureRange)
+	}
+
+	if s.TopP != nil && util.TopPValidator(fmt.Sprint(*s.TopP)) != nil {
+		return fmt.Errorf("Invalid topP of the parameter schedule step after %d turns, must be in %s", s.AfterTurns, util.TopPRange)
+	}
+
+	if s.MaxTokens != nil && *s.MaxTokens <= 0 {
+		return fmt.Errorf("Invalid maxTokens of the parameter schedule step after %d turns, must be positive", s.AfterTurns)
+	}
+	return nil
+}
+
+func (c Config) GetChatPaneWidthRatio() float64 {
+	if c.ChatPaneWidthRatio == 0 {
+		return util.DefaultChatPaneWidthRatio
+	}
+	return c.ChatPaneWidthRatio
+}
+
+func (c Config) GetNotificationDuration() time.Duration {
+	if c.NotificationDurationSec <= 0 {
+		return util.DefaultNotificationDurationSec * time.Second
+	}
+	return time.Duration(c.NotificationDurationSec) * time.Second
+}
+
+func (c Config) ShouldReturnFromEditorOnSend() bool {
+	return c.ReturnFromEditorOnSend == nil || *c.ReturnFromEditorOnSend
+}
+
+// Zero means the response is saved only once it is complete
+func (c Config) GetAutosaveInterval() time.Duration {
+	if c.AutosaveIntervalSec <= 0 {
+		return 0
+	}
+	return time.Duration(c.AutosaveIntervalSec) * time.Second
+}
+
+// Timeout of loading settings and the models list
+func (c Config) GetRequestTimeout() time.Duration {
+	if c.RequestTimeoutSec <= 0 {
+		return util.DefaultRequestTimeOutSec * time.Second
+	}
+	return time.Duration(c.RequestTimeoutSec) * time.Second
+}
+
+// Timeout of a whole web search, including loading of the found pages
+func (c Config) GetWebSearchTimeout() time.Duration {
+	if c.WebSearchTimeoutSec <= 0 {
+		return util.DefaultWebSearchTimeoutSec * time.Second
+	}
+	return time.Duration(c.WebSearchTimeoutSec) * time.Second
+}
+
+func (c Config) GetWebSearchPages() int {
+	if c.WebSearchPages <= 0 {
+		return util.DefaultWebSearchPages
+	}
+	return c.WebSearchPages
+}
+
+func (c Config) GetWebSearchResults() int {
+	if c.WebSearchResults <= 0 {
+		return util.DefaultWebSearchResults
+	}
+	return c.WebSearchResults
 }
 
 func (c *Config) applyFlags(flags StartupFlags) {
@@ -247,4 +652,12 @@ func (c *Config) applyFlags(flags StartupFlags) {
 	if flags.Model != "" {
 		c.DefaultModel = flags.Model
 	}
+
+	if flags.DemoMode {
+		c.DemoMode = true
+	}
+
+	if flags.OfflineMode {
+		c.OfflineMode = true
+	}
 }
@@ -0,0 +1,178 @@
+package flows
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	RunCommand = "/run"
+	flowsDir   = "flows"
+	inputVar   = "input"
+)
+
+var variableRegex = regexp.MustCompile(`{{\s*(\w+)\s*}}`)
+var argRegex = regexp.MustCompile(`^(\w+)=(.*)$`)
+
+// A single prompt of a flow. WebSearch overrides the web search setting of the preset for this step
+type Step struct {
+	Prompt    string `yaml:"prompt"`
+	WebSearch *bool  `yaml:"webSearch"`
+}
+
+// A sequence of prompts sent one after another, each one after the previous response is complete.
+// Flows are stored as yaml files in the flows directory of the config, e.g. ~/.config/nekot/flows/review.yaml
+type Flow struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Variables   map[string]string `yaml:"variables"`
+	Steps       []Step            `yaml:"steps"`
+}
+
+// A flow being run in the current session
+type Run struct {
+	Flow      Flow
+	Variables map[string]string
+	Step      int
+}
+
+type RunFlowRequested struct {
+	Command string
+}
+
+func RequestRun(command string) tea.Cmd {
+	return func() tea.Msg {
+		return RunFlowRequested{Command: command}
+	}
+}
+
+func IsRunCommand(prompt string) bool {
+	prompt = strings.TrimSpace(prompt)
+	return prompt == RunCommand || strings.HasPrefix(prompt, RunCommand+" ")
+}
+
+// Parses `/run flow-name key=value ... input text`, loads the flow and checks that
+// every variable used by its steps has a value. The text after the arguments is available as {{input}}
+func NewRun(command string) (Run, error) {
+	fields := strings.Fields(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(command), RunCommand)))
+	if len(fields) == 0 {
+		return Run{}, errors.New("flow name is missing, usage: /run flow-name [key=value ...] [input]")
+	}
+
+	flow, err := LoadFlow(fields[0])
+	if err != nil {
+		return Run{}, err
+	}
+
+	variables := map[string]string{}
+	for name, value := range flow.Variables {
+		variables[name] = value
+	}
+
+	inputStart := 1
+	for _, field := range fields[1:] {
+		match := argRegex.FindStringSubmatch(field)
+		if match == nil {
+			break
+		}
+		variables[match[1]] = match[2]
+		inputStart++
+	}
+
+	if input := strings.Join(fields[inputStart:], " "); input != "" {
+		variables[inputVar] = input
+	}
+
+	missing := flow.missingVariables(variables)
+	if len(missing) > 0 {
+		return Run{}, fmt.Errorf("flow %s is missing variables: %s", flow.Name, strings.Join(missing, ", "))
+	}
+
+	return Run{Flow: flow, Variables: variables}, nil
+}
+
+// Returns the next step with variables substituted, false once all steps are done
+func (r *Run) Next() (Step, bool) {
+	if r.Step >= len(r.Flow.Steps) {
+		return Step{}, false
+	}
+
+	step := r.Flow.Steps[r.Step]
+	step.Prompt = variableRegex.ReplaceAllStringFunc(step.Prompt, func(match string) string {
+		return r.Variables[variableRegex.FindStringSubmatch(match)[1]]
+	})
+
+	r.Step++
+	return step, true
+}
+
+func LoadFlow(name string) (Flow, error) {
+	dir, err := GetFlowsDir()
+	if err != nil {
+		return Flow{}, err
+	}
+
+	var content []byte
+	for _, ext := range []string{".yaml", ".yml"} {
+		content, err = os.ReadFile(filepath.Join(dir, name+ext))
+		if err == nil {
+			break
+		}
+	}
+
+	if err != nil {
+		return Flow{}, fmt.Errorf("flow %s not found in %s", name, dir)
+	}
+
+	var flow Flow
+	err = yaml.Unmarshal(content, &flow)
+	if err != nil {
+		return Flow{}, fmt.Errorf("failed to parse flow %s: %w", name, err)
+	}
+
+	if flow.Name == "" {
+		flow.Name = name
+	}
+
+	if len(flow.Steps) == 0 {
+		return Flow{}, fmt.Errorf("flow %s has no steps", name)
+	}
+
+	for i, step := range flow.Steps {
+		if strings.TrimSpace(step.Prompt) == "" {
+			return Flow{}, fmt.Errorf("step %d of flow %s has an empty prompt", i+1, name)
+		}
+	}
+
+	return flow, nil
+}
+
+func GetFlowsDir() (string, error) {
+	configPath, err := util.GetAppConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configPath, flowsDir), nil
+}
+
+func (f Flow) missingVariables(variables map[string]string) []string {
+	missing := []string{}
+	for _, step := range f.Steps {
+		for _, match := range variableRegex.FindAllStringSubmatch(step.Prompt, -1) {
+			name := match[1]
+			if _, ok := variables[name]; !ok && !slices.Contains(missing, name) {
+				missing = append(missing, name)
+			}
+		}
+	}
+	return missing
+}
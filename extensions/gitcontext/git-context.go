@@ -0,0 +1,125 @@
+package gitcontext
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const gitTimeout = 10 * time.Second
+const maxOutputSize = 60 * 1024
+
+// Prompt references, expanded into git output before the prompt is sent:
+// @git:status, @git:diff (unstaged changes), @git:staged (staged changes), @git:blame:<path>.
+// Punctuation after the path is left in the prompt, e.g. in `see @git:blame:main.go, please`
+var referenceRegex = regexp.MustCompile(`@git:(status|diff|staged|blame:(\S*[^\s.,;:)]))`)
+
+type gitCommand struct {
+	title    string
+	args     []string
+	language string
+}
+
+func HasReferences(prompt string) bool {
+	return referenceRegex.MatchString(prompt)
+}
+
+// Removes git references from the prompt and appends output of the referenced commands.
+// The repository is detected from the current working directory
+func ExpandReferences(ctx context.Context, prompt string) (string, error) {
+	matches := referenceRegex.FindAllStringSubmatch(prompt, -1)
+	if len(matches) == 0 {
+		return prompt, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, gitTimeout)
+	defer cancel()
+
+	_, err := runGit(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", errors.New("git context is not available: current directory is not a git repository")
+	}
+
+	seen := map[string]bool{}
+	blocks := []string{}
+	for _, match := range matches {
+		if seen[match[0]] {
+			continue
+		}
+		seen[match[0]] = true
+
+		command := getCommand(match[1], match[2])
+		output, err := runGit(ctx, command.args...)
+		if err != nil {
+			return "", fmt.Errorf("%s failed: %w", command.title, err)
+		}
+
+		blocks = append(blocks, formatBlock(command, output))
+	}
+
+	expanded := strings.TrimSpace(referenceRegex.ReplaceAllString(prompt, ""))
+	return expanded + "\n\n" + strings.Join(blocks, "\n\n"), nil
+}
+
+func getCommand(reference string, path string) gitCommand {
+	switch {
+	case path != "":
+		return gitCommand{title: "git blame " + path, args: []string{"blame", "--", path}}
+	case reference == "diff":
+		return gitCommand{title: "git diff", args: []string{"diff"}, language: "diff"}
+	case reference == "staged":
+		return gitCommand{title: "git diff --staged", args: []string{"diff", "--staged"}, language: "diff"}
+	default:
+		return gitCommand{title: "git status", args: []string{"status"}}
+	}
+}
+
+func formatBlock(command gitCommand, output string) string {
+	output = strings.TrimRight(output, "\n")
+	if output == "" {
+		return fmt.Sprintf("`%s`: no output, nothing to show", command.title)
+	}
+
+	if len(output) > maxOutputSize {
+		output = truncateOutput(output) + "\n... (truncated)"
+	}
+
+	return fmt.Sprintf("`%s`:\n```%s\n%s\n```", command.title, command.language, output)
+}
+
+// Output is cut at the last complete line, a single long line is cut at a rune boundary
+func truncateOutput(output string) string {
+	cut := output[:maxOutputSize]
+	if end := strings.LastIndexByte(cut, '\n'); end > 0 {
+		return cut[:end]
+	}
+
+	end := maxOutputSize
+	for end > 0 && !utf8.RuneStart(output[end]) {
+		end--
+	}
+	return output[:end]
+}
+
+func runGit(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", errors.New(message)
+		}
+		return "", err
+	}
+
+	return stdout.String(), nil
+}
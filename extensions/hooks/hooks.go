@@ -0,0 +1,82 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const hookTimeout = 30 * time.Second
+
+const (
+	ResponseCompleteHook = "on_response_complete"
+	SessionCreatedHook   = "on_session_created"
+	ExportHook           = "on_export"
+)
+
+// Data passed to a hook: Input is piped to stdin, Env is added to the environment
+// together with NEKOT_HOOK set to the hook name
+type Event struct {
+	Hook  string
+	Input string
+	Env   map[string]string
+}
+
+// Runs the user command for the event in the background. Does nothing if the command is empty.
+// A failed hook doesn't affect the app or the request that may be running, its error is shown as a toast
+func Run(command string, event Event) tea.Cmd {
+	if strings.TrimSpace(command) == "" {
+		return nil
+	}
+
+	return func() tea.Msg {
+		err := runCommand(command, event)
+		if err != nil {
+			util.Slog.Error("hook failed", "hook", event.Hook, "error", err.Error())
+			return util.SendToastMsg(i18n.Tf("notification.hookFailed", event.Hook, err.Error()), util.ErrorSeverity)()
+		}
+
+		util.Slog.Debug("hook finished", "hook", event.Hook)
+		return nil
+	}
+}
+
+func runCommand(command string, event Event) error {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := shellCommand(ctx, command)
+	cmd.Stdin = strings.NewReader(event.Input)
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), "NEKOT_HOOK="+event.Hook)
+	for name, value := range event.Env {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+
+	err := cmd.Run()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("%w: %s", err, message)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,132 @@
+package mermaid
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const renderTimeout = 60 * time.Second
+
+// mermaid-cli is used when no command is configured and it is installed
+const defaultRenderer = "mmdc"
+
+var diagramRegex = regexp.MustCompile("(?s)```mermaid[ \\t]*\\n(.*?)```")
+
+// Export settings from the config. Command is run by the shell with
+// NEKOT_DIAGRAM_INPUT and NEKOT_DIAGRAM_OUTPUT set to the diagram and the image paths
+type Options struct {
+	Dir     string
+	Name    string
+	Command string
+	Format  string
+}
+
+// Sources of the mermaid code blocks of a text
+func FindDiagrams(content string) []string {
+	diagrams := []string{}
+	for _, match := range diagramRegex.FindAllStringSubmatch(content, -1) {
+		if diagram := strings.TrimSpace(match[1]); diagram != "" {
+			diagrams = append(diagrams, diagram)
+		}
+	}
+	return diagrams
+}
+
+// Saves the diagrams to .mmd files. If a renderer is available, the diagrams are rendered
+// to images and the first image is opened. Without a renderer only the sources are saved
+func Export(diagrams []string, options Options) tea.Cmd {
+	return func() tea.Msg {
+		dir := options.Dir
+		if dir == "" {
+			var err error
+			dir, err = os.Getwd()
+			if err != nil {
+				return util.MakeRecoverableErrorMsg(err.Error())()
+			}
+		}
+
+		format := options.Format
+		if format == "" {
+			format = util.SvgDiagramFormat
+		}
+
+		canRender := options.Command != ""
+		if !canRender {
+			_, err := exec.LookPath(defaultRenderer)
+			canRender = err == nil
+		}
+
+		prefix := fmt.Sprintf("%s_diagram_%d", options.Name, time.Now().Unix())
+		images := []string{}
+		for i, diagram := range diagrams {
+			input := filepath.Join(dir, fmt.Sprintf("%s_%d.mmd", prefix, i+1))
+			if err := os.WriteFile(input, []byte(diagram+"\n"), 0644); err != nil {
+				return util.MakeRecoverableErrorMsg(err.Error())()
+			}
+
+			if !canRender {
+				continue
+			}
+
+			output := strings.TrimSuffix(input, ".mmd") + "." + format
+			if err := render(options.Command, input, output); err != nil {
+				return util.MakeRecoverableErrorMsg(fmt.Sprintf("failed to render a diagram: %s", err.Error()))()
+			}
+			images = append(images, output)
+		}
+
+		if len(images) == 0 {
+			return util.ToastMsg{
+				Text:     i18n.Tf("notification.diagramsSaved", len(diagrams), dir),
+				Severity: util.SuccessSeverity,
+			}
+		}
+
+		if err := util.OpenPath(images[0]); err != nil {
+			util.Slog.Warn("failed to open a diagram", "path", images[0], "error", err.Error())
+		}
+		return util.ToastMsg{
+			Text:     i18n.Tf("notification.diagramsRendered", len(images), dir),
+			Severity: util.SuccessSeverity,
+		}
+	}
+}
+
+func render(command string, input string, output string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), renderTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	switch {
+	case command == "":
+		cmd = exec.CommandContext(ctx, defaultRenderer, "-i", input, "-o", output)
+	case runtime.GOOS == "windows":
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	default:
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), "NEKOT_DIAGRAM_INPUT="+input, "NEKOT_DIAGRAM_OUTPUT="+output)
+
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("%w: %s", err, message)
+		}
+		return err
+	}
+	return nil
+}
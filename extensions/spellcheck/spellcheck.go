@@ -0,0 +1,198 @@
+package spellcheck
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/BalanceBalls/nekot/util"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const checkTimeout = 5 * time.Second
+
+const maxSuggestions = 5
+
+// Code, links and file references are not prose, their words are not checked
+var skippedTextRegex = regexp.MustCompile("(?s)```.*?(?:```|$)|`[^`\\n]*`|https?://\\S+|\\[(?:img|file)=[^\\]]*\\]")
+
+// Misspelled words of the text with their suggestions. Words are checked by hunspell
+// in the pipe mode with the dictionaries of the language, e.g. en_US or en_US,ru_RU
+func Check(ctx context.Context, language string, text string) (map[string][]string, error) {
+	words := getWords(skippedTextRegex.ReplaceAllString(text, " "))
+	misspelled := map[string][]string{}
+	if len(words) == 0 {
+		return misspelled, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	input := strings.Builder{}
+	for _, word := range words {
+		// the caret keeps words from being read as hunspell commands
+		input.WriteString("^" + word + "\n")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "hunspell", "-a", "-d", language)
+	cmd.Stdin = strings.NewReader(input.String())
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%w: %s", err, message)
+		}
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		word, suggestions, ok := parseResult(scanner.Text())
+		if ok {
+			misspelled[word] = suggestions
+		}
+	}
+	return misspelled, scanner.Err()
+}
+
+// Underlines the misspelled words in the rendered prompt. The word under the cursor
+// is being typed, it is left as is
+func Highlight(view string, misspelled map[string][]string, typedWord string, style lipgloss.Style) string {
+	if len(misspelled) == 0 {
+		return view
+	}
+
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		runes := []rune(util.StripAnsiCodes(line))
+		// words are replaced from the end, so the columns of the previous ones don't move
+		spans := getWordSpans(runes)
+		for j := len(spans) - 1; j >= 0; j-- {
+			start, end := spans[j][0], spans[j][1]
+			word := string(runes[start:end])
+			if _, ok := misspelled[word]; !ok || word == typedWord {
+				continue
+			}
+
+			startCol := ansi.StringWidth(string(runes[:start]))
+			endCol := startCol + ansi.StringWidth(word)
+			line = ansi.Cut(line, 0, startCol) + style.Render(word) + ansi.Cut(line, endCol, ansi.StringWidth(line))
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Word at the column of the line: the cursor is inside of it or right after it
+func WordAt(line string, col int) string {
+	runes := []rune(line)
+	for _, span := range getWordSpans(runes) {
+		if col >= span[0] && col <= span[1] {
+			return string(runes[span[0]:span[1]])
+		}
+	}
+	return ""
+}
+
+// Span of the misspelled word closest before the cursor, or the first one after it.
+// Offsets are in runes of the text
+func FindMisspelled(text string, misspelled map[string][]string, cursor int) (int, int, bool) {
+	runes := []rune(text)
+	found := false
+	start, end := 0, 0
+	for _, span := range getWordSpans(runes) {
+		if _, ok := misspelled[string(runes[span[0]:span[1]])]; !ok {
+			continue
+		}
+		if found && span[0] > cursor {
+			break
+		}
+
+		start, end, found = span[0], span[1], true
+		if span[0] > cursor {
+			break
+		}
+	}
+	return start, end, found
+}
+
+// Result lines of the ispell protocol: "& word count offset: suggestions" or "# word offset"
+// for misspelled words, other lines are for correct words or empty
+func parseResult(line string) (string, []string, bool) {
+	switch {
+	case strings.HasPrefix(line, "& "):
+		header, list, _ := strings.Cut(line, ": ")
+		fields := strings.Fields(header)
+		if len(fields) < 2 {
+			return "", nil, false
+		}
+
+		suggestions := []string{}
+		for suggestion := range strings.SplitSeq(list, ", ") {
+			if suggestion = strings.TrimSpace(suggestion); suggestion != "" {
+				suggestions = append(suggestions, suggestion)
+			}
+		}
+		return fields[1], suggestions[:min(len(suggestions), maxSuggestions)], true
+
+	case strings.HasPrefix(line, "# "):
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return "", nil, false
+		}
+		return fields[1], []string{}, true
+	}
+	return "", nil, false
+}
+
+func getWords(text string) []string {
+	runes := []rune(text)
+	words := []string{}
+	for _, span := range getWordSpans(runes) {
+		if span[1]-span[0] > 1 {
+			words = append(words, string(runes[span[0]:span[1]]))
+		}
+	}
+	return util.RemoveDuplicates(words)
+}
+
+// Words are letters with apostrophes inside, e.g. don't. Words with digits are identifiers, not prose
+func getWordSpans(runes []rune) [][2]int {
+	spans := [][2]int{}
+	isWordRune := func(i int) bool {
+		r := runes[i]
+		if unicode.IsLetter(r) {
+			return true
+		}
+		return r == '\'' && i > 0 && i+1 < len(runes) && unicode.IsLetter(runes[i-1]) && unicode.IsLetter(runes[i+1])
+	}
+
+	for i := 0; i < len(runes); {
+		if !isWordRune(i) {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(runes) && isWordRune(i) {
+			i++
+		}
+
+		// parts of identifiers like user_id or v2 are skipped
+		if start > 0 && (unicode.IsDigit(runes[start-1]) || runes[start-1] == '_') ||
+			i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '_') {
+			continue
+		}
+		spans = append(spans, [2]int{start, i})
+	}
+	return spans
+}
@@ -14,8 +14,6 @@ import (
 	"github.com/tmc/langchaingo/textsplitter"
 )
 
-const pagesMax = 10
-const chunksToInclude = 2
 const maxBodySize = 3 * 1024 * 1024 // 3MB limit
 
 type WebSearchResult struct {
@@ -24,6 +22,12 @@ type WebSearchResult struct {
 	Score float64 `json:"score"`
 }
 
+// Pages limits the found pages that are loaded, Results limits the page chunks added to the context
+type Options struct {
+	Pages   int
+	Results int
+}
+
 type WebPageDataExport struct {
 	engines.SearchEngineData
 	ContentChunks []string
@@ -35,8 +39,8 @@ type PageChunk struct {
 	Content string
 }
 
-func PrepareContextFromWebSearch(ctx context.Context, query string) ([]WebSearchResult, error) {
-	corpus, err := getDataChunksFromQuery(ctx, query)
+func PrepareContextFromWebSearch(ctx context.Context, query string, options Options) ([]WebSearchResult, error) {
+	corpus, err := getDataChunksFromQuery(ctx, query, options.Pages)
 	if err != nil {
 		return []WebSearchResult{}, err
 	}
@@ -50,8 +54,8 @@ func PrepareContextFromWebSearch(ctx context.Context, query string) ([]WebSearch
 	}
 
 	topRankedChunks := rankedChunks
-	if len(rankedChunks) > chunksToInclude {
-		topRankedChunks = rankedChunks[:chunksToInclude]
+	if len(rankedChunks) > options.Results {
+		topRankedChunks = rankedChunks[:options.Results]
 	}
 
 	results := []WebSearchResult{}
@@ -69,7 +73,7 @@ func PrepareContextFromWebSearch(ctx context.Context, query string) ([]WebSearch
 	return results, nil
 }
 
-func getDataChunksFromQuery(ctx context.Context, query string) ([]PageChunk, error) {
+func getDataChunksFromQuery(ctx context.Context, query string, pagesMax int) ([]PageChunk, error) {
 	var (
 		ddgResponse   []engines.SearchEngineData
 		braveResponse []engines.SearchEngineData
@@ -0,0 +1,101 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+)
+
+const DefaultLanguage = "en"
+
+// Languages with a message catalog and a manual
+var SupportedLanguages = []string{"en", "ru", "es"}
+
+//go:embed locales/*.json
+var localesFS embed.FS
+
+//go:embed manuals/*.md
+var manualsFS embed.FS
+
+var (
+	language = DefaultLanguage
+	fallback = mustLoadCatalog(DefaultLanguage)
+	catalog  = fallback
+)
+
+func IsSupported(lang string) bool {
+	return slices.Contains(SupportedLanguages, lang)
+}
+
+// Switches UI strings to the given language. Empty value switches to the default language
+func SetLanguage(lang string) error {
+	if lang == "" {
+		lang = DefaultLanguage
+	}
+
+	if !IsSupported(lang) {
+		return fmt.Errorf("unsupported language: %s. Supported languages: %v", lang, SupportedLanguages)
+	}
+
+	loaded, err := loadCatalog(lang)
+	if err != nil {
+		return err
+	}
+
+	language = lang
+	catalog = loaded
+	return nil
+}
+
+func GetLanguage() string {
+	return language
+}
+
+// Translates a message by its key. Missing translations fall back to english,
+// unknown keys are returned as is
+func T(key string) string {
+	if message, ok := catalog[key]; ok {
+		return message
+	}
+	if message, ok := fallback[key]; ok {
+		return message
+	}
+	return key
+}
+
+// Same as T, with fmt.Sprintf formatting
+func Tf(key string, args ...any) string {
+	return fmt.Sprintf(T(key), args...)
+}
+
+func Manual() string {
+	content, err := manualsFS.ReadFile(path.Join("manuals", language+".md"))
+	if err != nil {
+		content, _ = manualsFS.ReadFile(path.Join("manuals", DefaultLanguage+".md"))
+	}
+	return string(content)
+}
+
+func loadCatalog(lang string) (map[string]string, error) {
+	content, err := localesFS.ReadFile(path.Join("locales", lang+".json"))
+	if err != nil {
+		return nil, err
+	}
+
+	messages := map[string]string{}
+	err = json.Unmarshal(content, &messages)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s message catalog: %w", lang, err)
+	}
+	return messages, nil
+}
+
+func mustLoadCatalog(lang string) map[string]string {
+	messages, err := loadCatalog(lang)
+	if err != nil {
+		panic(err)
+	}
+	return messages
+}
@@ -7,10 +7,15 @@ import (
 	"io"
 	"log"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 
+	"github.com/BalanceBalls/nekot/clients"
 	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/i18n"
 	"github.com/BalanceBalls/nekot/migrations"
+	"github.com/BalanceBalls/nekot/updater"
 	"github.com/BalanceBalls/nekot/util"
 	"github.com/BalanceBalls/nekot/views"
 	tea "github.com/charmbracelet/bubbletea"
@@ -24,23 +29,50 @@ var baseUrl string
 var theme string
 var model string
 var newSession bool
+var dataDir string
+var profile string
+var checkConnection bool
+var selfUpdate bool
+var printVersion bool
+var demoMode bool
+var offlineMode bool
+
+// Set by goreleaser with ldflags
+var version = "dev"
 
 func init() {
 	flag.BoolVar(&purgeCache, "purge-cache", false, "Invalidate models cache")
 	flag.BoolVar(&newSession, "n", false, "Create a new session on startup")
+	flag.BoolVar(&checkConnection, "check", false, "Check connection to the LLM provider and exit")
+	flag.BoolVar(&selfUpdate, "self-update", false, "Download the latest release, replace the current binary and exit")
+	flag.BoolVar(&printVersion, "version", false, "Print the version and exit")
+	flag.BoolVar(&demoMode, "demo", false, "Demo mode: hides API keys, session names and saved prompts for screenshots")
+	flag.BoolVar(&offlineMode, "offline", false, "Local-only mode: blocks requests to hosts that are not localhost or in offlineAllowlist")
 	flag.StringVar(
 		&provider,
 		"p",
 		"",
-		"Overrides LLM provider configuration. Available: openai, gemini",
+		"Overrides LLM provider configuration. Available: openai, gemini, openrouter, vertex, bedrock",
 	)
 	flag.StringVar(&baseUrl, "u", "", "Overrides LLM provider base url configuration")
 	flag.StringVar(&theme, "t", "", "Overrides theme configuration")
 	flag.StringVar(&model, "m", "", "Model name")
+	flag.StringVar(&dataDir, "data-dir", "", "Overrides the data directory (chat.db, logs)")
+	flag.StringVar(&profile, "profile", "", "Profile to use. Each profile has its own database and config")
 }
 
 func main() {
 	flag.Parse()
+	updater.SetCurrentVersion(version)
+
+	if printVersion {
+		fmt.Println(updater.GetCurrentVersion())
+		os.Exit(0)
+	}
+
+	if selfUpdate {
+		runSelfUpdate()
+	}
 
 	var pipedContent string
 	stat, _ := os.Stdin.Stat()
@@ -58,6 +90,8 @@ func main() {
 		ProviderUrl:     baseUrl,
 		StartNewSession: newSession,
 		InitialPrompt:   pipedContent,
+		DemoMode:        demoMode,
+		OfflineMode:     offlineMode,
 	}
 
 	env := os.Getenv("NEKOT_ENV")
@@ -72,7 +106,41 @@ func main() {
 	godotenv.Load(".env." + env)
 	godotenv.Load() // The Original .env
 
+	if dataDir != "" {
+		absDataDir, err := filepath.Abs(dataDir)
+		if err != nil {
+			fmt.Println("fatal:", err)
+			os.Exit(1)
+		}
+		util.SetDataDirOverride(absDataDir)
+	}
+
+	if profile != "" {
+		err := util.ValidateProfileName(profile)
+		if err != nil {
+			fmt.Println("fatal:", err)
+			os.Exit(1)
+		}
+		util.SetProfile(profile)
+	}
+
+	err := util.MigrateLegacyAppDir()
+	if err != nil {
+		fmt.Println("fatal: failed to migrate app files:", err)
+		os.Exit(1)
+	}
+
+	err = util.InitLogger()
+	if err != nil {
+		fmt.Println("fatal:", err)
+		os.Exit(1)
+	}
+
 	appPath, err := util.GetAppDataPath()
+	if err != nil {
+		fmt.Println("fatal:", err)
+		os.Exit(1)
+	}
 	f, err := tea.LogToFile(filepath.Join(appPath, "debug.log"), "debug")
 	if err != nil {
 		fmt.Println("fatal:", err)
@@ -84,6 +152,19 @@ func main() {
 	util.DeleteFilesIfDevMode()
 	// validate config
 	configToUse := config.CreateAndValidateConfig(flags)
+	err = i18n.SetLanguage(configToUse.Language)
+	if err != nil {
+		fmt.Println("fatal:", err)
+		os.Exit(1)
+	}
+	util.SetDemoMode(configToUse.DemoMode)
+	util.SetHyperlinks(*configToUse.Hyperlinks)
+	util.SetContentLayout(configToUse.ContentMaxWidth, *configToUse.WrapCodeBlocks)
+	util.SetScreenReaderMode(configToUse.ScreenReaderMode)
+	util.SetReducedMotion(configToUse.ReducedMotion || configToUse.ScreenReaderMode)
+	util.SetAsciiMode(configToUse.AsciiMode || configToUse.ScreenReaderMode)
+	util.SetSpeakerLabels(configToUse.UserIcon, configToUse.AssistantIcon, configToUse.UserLabel)
+	util.SetOfflineMode(configToUse.OfflineMode, configToUse.OfflineAllowlist)
 
 	// run migrations for our database
 	db := util.InitDb()
@@ -103,6 +184,11 @@ func main() {
 		}
 	}
 
+	if checkConnection {
+		db.Close()
+		runConnectionCheck(configToUse)
+	}
+
 	ctx := context.Background()
 	ctxWithConfig := config.WithConfig(ctx, &configToUse)
 	appCtx := config.WithFlags(ctxWithConfig, &flags)
@@ -114,7 +200,7 @@ func main() {
 		tea.WithMouseCellMotion(),
 	)
 
-	_, err = p.Run()
+	finalModel, err := p.Run()
 	if err != nil {
 		if err == tea.ErrProgramPanic {
 			fmt.Fprintf(os.Stderr, "Program panicked: %v\n", err)
@@ -122,4 +208,94 @@ func main() {
 		}
 		log.Fatal(err)
 	}
+
+	clients.ReleaseUploadedFiles()
+
+	mainView, ok := finalModel.(views.MainView)
+	if ok && mainView.HasWindowTitle() {
+		// The title set by the app would otherwise stay in the terminal after exit
+		fmt.Print("\x1b]0;\x07")
+	}
+
+	if ok && mainView.ProfileToSwitch() != "" {
+		db.Close()
+		restartWithProfile(mainView.ProfileToSwitch())
+	}
+}
+
+// Checks the provider connection with the current model, prints the result and exits
+func runConnectionCheck(cfg config.Config) {
+	checkModel := model
+	if checkModel == "" {
+		checkModel = cfg.DefaultModel
+	}
+
+	result := clients.CheckConnection(context.Background(), cfg, checkModel)
+	fmt.Printf("%s (%s)\n", result.Provider, result.BaseUrl)
+	fmt.Println(result.String())
+
+	if !result.Ok() {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// Replaces the binary with the latest release, prints the result and exits
+func runSelfUpdate() {
+	fmt.Println("current version:", updater.GetCurrentVersion())
+
+	installed, err := updater.SelfUpdate(context.Background())
+	if err != nil {
+		fmt.Println("update failed:", err)
+		os.Exit(1)
+	}
+
+	if installed == "" {
+		fmt.Println("already up to date")
+	} else {
+		fmt.Println("updated to", installed)
+	}
+	os.Exit(0)
+}
+
+// Starts the app again with the given profile and exits with its exit code
+func restartWithProfile(profileName string) {
+	args := []string{}
+	skipNext := false
+	for _, arg := range os.Args[1:] {
+		if skipNext {
+			skipNext = false
+			continue
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		if name == "profile" {
+			skipNext = true
+			continue
+		}
+		if strings.HasPrefix(name, "profile=") {
+			continue
+		}
+
+		args = append(args, arg)
+	}
+	args = append(args, "--profile", profileName)
+
+	executable, err := os.Executable()
+	if err != nil {
+		executable = os.Args[0]
+	}
+
+	cmd := exec.Command(executable, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err = cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		os.Exit(exitErr.ExitCode())
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE system_prompts (
+  system_prompt_id INTEGER PRIMARY KEY,
+  system_prompt_name VARCHAR(255) NOT NULL,
+  system_prompt_content TEXT NOT NULL
+);
+
+INSERT INTO system_prompts
+(system_prompt_name, system_prompt_content)
+VALUES
+('coder', 'You are an experienced software engineer. Give concise, correct answers. Prefer code over prose and explain trade-offs briefly when they matter.'),
+('translator', 'You are a professional translator. Translate the user input to English, or to the language the user asks for. Preserve meaning, tone and formatting. Output only the translation.'),
+('summarizer', 'You summarize text. Produce a short summary of the user input as a bulleted list of the key points, followed by a one sentence conclusion.');
+
+ALTER TABLE settings ADD COLUMN system_prompt_id INTEGER;
+ALTER TABLE sessions ADD COLUMN system_prompt_id INTEGER;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN system_prompt_id;
+ALTER TABLE settings DROP COLUMN system_prompt_id;
+DROP TABLE system_prompts;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE provider_profiles (
+  provider INTEGER PRIMARY KEY,
+  max_tokens INTEGER NOT NULL,
+  frequency REAL,
+  top_p REAL,
+  temperature REAL
+);
+
+ALTER TABLE settings ADD COLUMN provider INTEGER;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE settings DROP COLUMN provider;
+DROP TABLE provider_profiles;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE personas (
+  persona_id INTEGER PRIMARY KEY,
+  persona_name VARCHAR(255) NOT NULL,
+  persona_color VARCHAR(32) NOT NULL DEFAULT '',
+  persona_model VARCHAR(255) NOT NULL DEFAULT '',
+  persona_system_prompt TEXT NOT NULL DEFAULT ''
+);
+
+ALTER TABLE sessions ADD COLUMN persona_id INTEGER;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN persona_id;
+DROP TABLE personas;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE file_places (
+  place_path TEXT NOT NULL,
+  place_kind VARCHAR(16) NOT NULL,
+  place_used_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (place_path, place_kind)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE file_places;
+-- +goose StatementEnd
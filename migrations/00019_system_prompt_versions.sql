@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE system_prompt_versions (
+  version_id INTEGER PRIMARY KEY,
+  settings_id INTEGER NOT NULL,
+  version_content TEXT NOT NULL,
+  version_created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE system_prompt_versions;
+-- +goose StatementEnd
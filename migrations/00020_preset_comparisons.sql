@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE preset_comparisons (
+  comparison_id INTEGER PRIMARY KEY,
+  comparison_prompt TEXT NOT NULL,
+  preset_a TEXT NOT NULL,
+  model_a TEXT NOT NULL,
+  answer_a TEXT NOT NULL,
+  preset_b TEXT NOT NULL,
+  model_b TEXT NOT NULL,
+  answer_b TEXT NOT NULL,
+  -- 'a' or 'b' once one of the answers is marked as better
+  comparison_winner TEXT,
+  comparison_created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE preset_comparisons;
+-- +goose StatementEnd
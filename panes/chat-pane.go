@@ -3,12 +3,15 @@ package panes
 import (
 	"context"
 	"fmt"
+	"maps"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/BalanceBalls/nekot/components"
 	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/i18n"
 	"github.com/BalanceBalls/nekot/sessions"
 	"github.com/BalanceBalls/nekot/settings"
 	"github.com/BalanceBalls/nekot/util"
@@ -19,11 +22,31 @@ import (
 	zone "github.com/lrstanley/bubblezone"
 )
 
+// Columns scrolled by h/l when code blocks or tables are wider than the pane
+const horizontalScrollStep = 4
+
 type displayMode int
 
 const (
 	normalMode displayMode = iota
 	selectionMode
+	linkPickerMode
+	quickActionsMode
+	pinnedMode
+	hintMode
+	rawMarkdownMode
+	messageLogMode
+	promptDiffMode
+	comparisonMode
+	manualMode
+)
+
+type markCommand int
+
+const (
+	noMarkCommand markCommand = iota
+	setMarkCommand
+	jumpToMarkCommand
 )
 
 type chatPaneKeyMap struct {
@@ -31,38 +54,151 @@ type chatPaneKeyMap struct {
 	exit          key.Binding
 	copyLast      key.Binding
 	copyAll       key.Binding
+	saveLast      key.Binding
+	saveAll       key.Binding
 	goUp          key.Binding
 	goDown        key.Binding
+	openConfig    key.Binding
+	openLink      key.Binding
+	quickActions  key.Binding
+	pin           key.Binding
+	pinnedList    key.Binding
+	rateUp        key.Binding
+	rateDown      key.Binding
+	ratingNote    key.Binding
+	setMark       key.Binding
+	hints         key.Binding
+	rawMarkdown   key.Binding
+	messageLog    key.Binding
+	manual        key.Binding
+	diagrams      key.Binding
+	hintCopy      key.Binding
+	hintOpen      key.Binding
+	hintSave      key.Binding
+	jumpToMark    key.Binding
+	pickerUp      key.Binding
+	pickerDown    key.Binding
+	pickerChoose  key.Binding
+	followUp      key.Binding
+	betterA       key.Binding
+	betterB       key.Binding
 }
 
 var defaultChatPaneKeyMap = chatPaneKeyMap{
 	exit: key.NewBinding(
 		key.WithKeys(tea.KeyEsc.String()),
-		key.WithHelp("esc", "exit insert mode or editor mode"),
+		key.WithHelp("esc", "keys.exitInsertMode"),
 	),
 	copyLast: key.NewBinding(
 		key.WithKeys("y"),
-		key.WithHelp("y", "copy last message from chat to clipboard"),
+		key.WithHelp("y", "keys.copyLastMessage"),
 	),
 	copyAll: key.NewBinding(
 		key.WithKeys("Y"),
-		key.WithHelp("Y", "copy all chat to clipboard"),
+		key.WithHelp("Y", "keys.copyChat"),
+	),
+	saveLast: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "keys.saveMessageToNotes"),
+	),
+	saveAll: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("N", "keys.saveChatToNotes"),
 	),
 	selectionMode: key.NewBinding(
 		key.WithKeys(tea.KeySpace.String(), "v", "V"),
-		key.WithHelp("<space>, v, V", "enter selection mode"),
+		key.WithHelp("<space>, v, V", "keys.selectionMode"),
 	),
 	goUp: key.NewBinding(
 		key.WithKeys("g"),
-		key.WithHelp("g", "scroll to top"),
+		key.WithHelp("g", "keys.scrollToTop"),
 	),
 	goDown: key.NewBinding(
 		key.WithKeys("G"),
-		key.WithHelp("G", "scroll to bottom"),
+		key.WithHelp("G", "keys.scrollToBottom"),
+	),
+	openConfig: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "keys.openConfig"),
+	),
+	openLink: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "keys.openLink"),
+	),
+	quickActions: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "keys.quickActions"),
+	),
+	pin: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "keys.pinMessage"),
+	),
+	pinnedList: key.NewBinding(
+		key.WithKeys("P"),
+		key.WithHelp("P", "keys.jumpToPinned"),
+	),
+	rateUp: key.NewBinding(
+		key.WithKeys("+"),
+		key.WithHelp("+", "keys.rateUp"),
+	),
+	rateDown: key.NewBinding(
+		key.WithKeys("-"),
+		key.WithHelp("-", "keys.rateDown"),
+	),
+	ratingNote: key.NewBinding(
+		key.WithKeys("R"),
+		key.WithHelp("R", "keys.rateNote"),
+	),
+	setMark: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m{a-z}", "keys.setMark"),
+	),
+	jumpToMark: key.NewBinding(
+		key.WithKeys("'"),
+		key.WithHelp("'{a-z}", "keys.jumpToMark"),
+	),
+	hints: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "keys.showHints"),
+	),
+	rawMarkdown: key.NewBinding(
+		key.WithKeys("M"),
+		key.WithHelp("M", "keys.showRawMarkdown"),
+	),
+	messageLog: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "keys.showMessageLog"),
+	),
+	manual: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "keys.showManual"),
+	),
+	diagrams: key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("d", "keys.saveDiagrams"),
+	),
+	hintCopy:     key.NewBinding(key.WithKeys("c", "y"), key.WithHelp("c/y", "keys.copy")),
+	hintOpen:     key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "keys.openHintLink")),
+	hintSave:     key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "keys.saveToNotes")),
+	pickerUp:     key.NewBinding(key.WithKeys(tea.KeyUp.String(), "k"), key.WithHelp("↑/k", "keys.previousItem")),
+	pickerDown:   key.NewBinding(key.WithKeys(tea.KeyDown.String(), "j"), key.WithHelp("↓/j", "keys.nextItem")),
+	pickerChoose: key.NewBinding(key.WithKeys(tea.KeyEnter.String()), key.WithHelp("enter", "keys.choose")),
+	followUp: key.NewBinding(
+		key.WithKeys("1", "2", "3", "alt+1", "alt+2", "alt+3"),
+		key.WithHelp("1-3, alt+1-3", "keys.useFollowUp"),
+	),
+	betterA: key.NewBinding(
+		key.WithKeys("1"),
+		key.WithHelp("1", "keys.markAnswerA"),
+	),
+	betterB: key.NewBinding(
+		key.WithKeys("2"),
+		key.WithHelp("2", "keys.markAnswerB"),
 	),
 }
 
 const pulsarIntervalMs = 100
+const chunksWindowMs = 40
 
 type renderContentMsg int
 
@@ -86,6 +222,28 @@ type ChatPane struct {
 	idleCyclesCount        int
 	processingState        util.ProcessingState
 	currentSettings        util.Settings
+	inlineError            string
+	pickerItems            []string
+	pickerCursor           int
+	pinnedIndexes          []int
+	messageOffsets         []int
+	selectionOffsets       []int
+	marks                  map[string]sessions.Mark
+	pendingMarkCommand     markCommand
+	hints                  []util.Hint
+	typedHint              string
+	chosenHint             *util.Hint
+	rawMessageIndex        int
+	rawReturnOffset        int
+	logReturnOffset        int
+	promptDiff             util.SystemPromptDiffMsg
+	diffReturnOffset       int
+	comparison             util.PresetComparison
+	comparisonReturnOffset int
+	manualReturnOffset     int
+	sessionId              int
+	persona                *util.Persona
+	followUps              []string
 	mu                     *sync.RWMutex
 
 	terminalWidth  int
@@ -103,7 +261,7 @@ type ChatPane struct {
 }
 
 var chatContainerStyle = lipgloss.NewStyle().
-	Border(lipgloss.ThickBorder()).
+	Border(util.ThickBorder()).
 	MarginRight(util.ChatPaneMarginRight)
 
 var infoBarStyle = lipgloss.NewStyle().
@@ -112,6 +270,7 @@ var infoBarStyle = lipgloss.NewStyle().
 
 func NewChatPane(ctx context.Context, w, h int) ChatPane {
 	chatView := viewport.New(w, h)
+	chatView.SetHorizontalStep(horizontalScrollStep)
 	msgChan := make(chan util.ProcessApiCompletionResponse)
 
 	config, ok := config.FromContext(ctx)
@@ -124,6 +283,7 @@ func NewChatPane(ctx context.Context, w, h int) ChatPane {
 	defaultChatContent := util.GetManual(w, colors)
 	chatView.SetContent(defaultChatContent)
 	chatContainerStyle = chatContainerStyle.
+		BorderStyle(util.ThickBorder()).
 		Width(w).
 		Height(h).
 		BorderForeground(colors.NormalTabBorderColor)
@@ -153,14 +313,47 @@ func NewChatPane(ctx context.Context, w, h int) ChatPane {
 	}
 }
 
+// Switches the pane to the colors of another scheme. The chat content
+// is rendered with the new colors on the next resize
+func (p ChatPane) SetColors(colors util.SchemeColors) ChatPane {
+	p.colors = colors
+	infoBarStyle = infoBarStyle.
+		BorderForeground(colors.MainColor).
+		Foreground(colors.HighlightColor)
+	return p
+}
+
+// Fast providers would flood the update loop with a message per chunk.
+// Chunks arriving within the window after the first one are sent together.
 func waitForActivity(ctx context.Context, sub chan util.ProcessApiCompletionResponse) tea.Cmd {
 	return func() tea.Msg {
+		batch := util.CompletionChunksBatch{}
 		select {
-		case someMessage := <-sub:
-			return someMessage
+		case chunk := <-sub:
+			batch.Chunks = append(batch.Chunks, chunk)
+			if chunk.Final || chunk.Err != nil {
+				return batch
+			}
 		case <-ctx.Done():
 			return nil
 		}
+
+		window := time.NewTimer(time.Millisecond * chunksWindowMs)
+		defer window.Stop()
+
+		for {
+			select {
+			case chunk := <-sub:
+				batch.Chunks = append(batch.Chunks, chunk)
+				if chunk.Final || chunk.Err != nil {
+					return batch
+				}
+			case <-window.C:
+				return batch
+			case <-ctx.Done():
+				return batch
+			}
+		}
 	}
 }
 
@@ -192,7 +385,23 @@ func (p ChatPane) Update(msg tea.Msg) (ChatPane, tea.Cmd) {
 
 	case util.FocusEvent:
 		p.isChatContainerFocused = msg.IsFocused
+		if p.displayMode == rawMarkdownMode {
+			p = p.closeRawMarkdown()
+		}
+		if p.displayMode == messageLogMode {
+			p = p.closeMessageLog()
+		}
+		if p.displayMode == promptDiffMode {
+			p = p.closePromptDiff()
+		}
+		if p.displayMode == comparisonMode {
+			p = p.closeComparison()
+		}
+		if p.displayMode == manualMode {
+			p = p.closeManual()
+		}
 		p.displayMode = normalMode
+		p.pendingMarkCommand = noMarkCommand
 
 		return p, nil
 
@@ -207,11 +416,76 @@ func (p ChatPane) Update(msg tea.Msg) (ChatPane, tea.Cmd) {
 			p.chunksBuffer = []string{}
 			cmds = append(cmds, renderingPulsar)
 		case util.ProcessingChunks:
+			p.inlineError = ""
+			p.followUps = nil
+			if p.displayMode == rawMarkdownMode {
+				p.displayMode = normalMode
+			}
 			cmds = append(cmds, renderingPulsar)
 		case util.Finalized:
 			cmds = append(cmds, renderingPulsar)
 		}
 
+	case sessions.FollowUpsReady:
+		if msg.SessionId != p.sessionId || msg.MessagesCount != len(p.sessionContent) {
+			break
+		}
+
+		p.followUps = msg.Suggestions
+		w, _ := util.CalcChatPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
+		p = p.displaySession(p.sessionContent, w, true)
+
+	case sessions.PinnedMessagesChanged:
+		if msg.SessionId != p.sessionId {
+			break
+		}
+
+		w, _ := util.CalcChatPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
+		p = p.displaySession(msg.Messages, w, false)
+
+	case sessions.RatedMessagesChanged:
+		if msg.SessionId != p.sessionId {
+			break
+		}
+
+		w, _ := util.CalcChatPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
+		p = p.displaySession(msg.Messages, w, false)
+
+	case util.OpenLinksMsg:
+		switch len(msg.Urls) {
+		case 0:
+			return p, util.SendToastMsg(i18n.T("notification.noLinks"), util.WarningSeverity)
+		case 1:
+			return p, util.OpenUrl(msg.Urls[0])
+		}
+
+		p.selectionView.Reset()
+		p.openPicker(linkPickerMode, msg.Urls)
+		return p, nil
+
+	case util.ErrorEvent:
+		if !msg.IsRecoverable() || !msg.IsRequestFailure {
+			break
+		}
+
+		messages := p.sessionContent
+		if len(messages) > 0 && messages[len(messages)-1].Role == "user" {
+			messages = messages[:len(messages)-1]
+		}
+
+		w, _ := util.CalcChatPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
+		p.inlineError = msg.Message
+		p = p.displaySession(messages, w, true)
+
+	case util.SystemPromptDiffMsg:
+		return p.openPromptDiff(msg), nil
+
+	case util.PresetComparisonMsg:
+		return p.openComparison(msg), nil
+
+	case util.ManualMsg:
+		return p.openManual(), nil
+
 	case sessions.LoadDataFromDB:
 		// util.Slog.Debug("case LoadDataFromDB: ", "message", msg)
 		return p.initializePane(msg.Session)
@@ -251,7 +525,13 @@ func (p ChatPane) Update(msg tea.Msg) (ChatPane, tea.Cmd) {
 		diff := getStringsDiff(p.responseBuffer, newContent)
 		p.responseBuffer += diff
 
-		renderWindow := p.responseBuffer
+		// the log is not redrawn on every chunk, it is updated once the response is complete
+		if p.displayMode == messageLogMode || p.displayMode == promptDiffMode || p.displayMode == comparisonMode ||
+			p.displayMode == manualMode {
+			return p, renderingPulsar
+		}
+
+		reasoning, renderWindow := util.SplitReasoning(p.responseBuffer)
 
 		chatHeightDelta := p.chatView.Height + 20 // arbitrary , just my emperical guess
 		bufferLines := strings.Split(renderWindow, "\n")
@@ -266,10 +546,12 @@ func (p ChatPane) Update(msg tea.Msg) (ChatPane, tea.Cmd) {
 		}
 
 		if diff != "" {
-			p.renderedResponseBuffer = util.RenderBotMessage(util.LocalStoreMessage{
-				Content: renderWindow,
-				Role:    "assistant",
-			}, paneWidth, p.colors, false, p.currentSettings)
+			p.renderedResponseBuffer = util.RenderStreamingResponse(
+				reasoning,
+				renderWindow,
+				paneWidth,
+				p.colors,
+				p.currentSettings)
 		}
 
 		result := p.renderedResponseBuffer
@@ -285,7 +567,7 @@ func (p ChatPane) Update(msg tea.Msg) (ChatPane, tea.Cmd) {
 	case sessions.ResponseChunkProcessed:
 		if len(p.sessionContent) != len(msg.PreviousMsgArray) {
 			paneWidth := p.chatContainer.GetWidth()
-			p.renderedHistory = util.GetMessagesAsPrettyString(
+			p.renderedHistory, p.messageOffsets = util.GetMessagesAsPrettyString(
 				msg.PreviousMsgArray,
 				paneWidth,
 				p.colors,
@@ -313,13 +595,27 @@ func (p ChatPane) Update(msg tea.Msg) (ChatPane, tea.Cmd) {
 			}
 		}
 
-		if msg.Button == tea.MouseButtonWheelUp && p.isChatContainerFocused {
-			p.chatView.ScrollUp(3)
-			return p, nil
-		}
+		// Wheel scrolls the pane under the cursor, regardless of focus
+		if msg.Button == tea.MouseButtonWheelUp || msg.Button == tea.MouseButtonWheelDown {
+			if !zone.Get("chat_pane").InBounds(msg) {
+				return p, nil
+			}
+
+			// labels are placed for the visible lines only
+			if p.displayMode == hintMode {
+				p.displayMode = normalMode
+			}
 
-		if msg.Button == tea.MouseButtonWheelDown && p.isChatContainerFocused {
-			p.chatView.ScrollDown(3)
+			switch {
+			case msg.Shift && msg.Button == tea.MouseButtonWheelUp:
+				p.chatView.ScrollLeft(horizontalScrollStep)
+			case msg.Shift:
+				p.chatView.ScrollRight(horizontalScrollStep)
+			case msg.Button == tea.MouseButtonWheelUp:
+				p.chatView.ScrollUp(3)
+			default:
+				p.chatView.ScrollDown(3)
+			}
 			return p, nil
 		}
 
@@ -328,7 +624,7 @@ func (p ChatPane) Update(msg tea.Msg) (ChatPane, tea.Cmd) {
 		}
 
 		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
-			if !p.IsSelectionMode() && len(p.sessionContent) > 0 {
+			if p.displayMode == normalMode && len(p.sessionContent) > 0 {
 				p.enterSelectionMode()
 				enableUpdateOfViewport = false
 				p.selectionView, cmd = p.selectionView.Update(msg)
@@ -338,7 +634,7 @@ func (p ChatPane) Update(msg tea.Msg) (ChatPane, tea.Cmd) {
 		}
 
 		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonRight {
-			if !p.IsSelectionMode() && len(p.sessionContent) > 0 {
+			if p.displayMode == normalMode && len(p.sessionContent) > 0 {
 				p.enterSelectionMode()
 				enableUpdateOfViewport = false
 				p.selectionView, cmd = p.selectionView.Update(msg)
@@ -352,12 +648,87 @@ func (p ChatPane) Update(msg tea.Msg) (ChatPane, tea.Cmd) {
 			enableUpdateOfViewport = false
 		}
 
+		if p.isPickerOpen() {
+			return p.handlePickerKeys(msg)
+		}
+
+		if p.pendingMarkCommand != noMarkCommand {
+			return p.handleMarkKey(msg)
+		}
+
+		if p.displayMode == hintMode {
+			return p.handleHintKeys(msg)
+		}
+
+		// the raw message is scrolled as usual, other keys are ignored
+		if p.displayMode == rawMarkdownMode {
+			if key.Matches(msg, p.keyMap.exit, p.keyMap.rawMarkdown) {
+				return p.closeRawMarkdown(), nil
+			}
+			break
+		}
+
+		if p.displayMode == messageLogMode {
+			if key.Matches(msg, p.keyMap.exit, p.keyMap.messageLog) {
+				return p.closeMessageLog(), nil
+			}
+			break
+		}
+
+		if p.displayMode == promptDiffMode {
+			if key.Matches(msg, p.keyMap.exit) {
+				return p.closePromptDiff(), nil
+			}
+			break
+		}
+
+		if p.displayMode == manualMode {
+			if key.Matches(msg, p.keyMap.exit, p.keyMap.manual) {
+				return p.closeManual(), nil
+			}
+			break
+		}
+
+		if p.displayMode == comparisonMode {
+			switch {
+			case key.Matches(msg, p.keyMap.exit):
+				return p.closeComparison(), nil
+			case key.Matches(msg, p.keyMap.betterA):
+				return p.markComparisonWinner(0)
+			case key.Matches(msg, p.keyMap.betterB):
+				return p.markComparisonWinner(1)
+			}
+			break
+		}
+
+		if key.Matches(msg, p.keyMap.followUp) && p.HasFollowUps() &&
+			(msg.Alt || p.isChatContainerFocused && !p.IsSelectionMode()) {
+			return p.useFollowUp(msg)
+		}
+
 		if p.IsSelectionMode() {
 			switch {
 			case key.Matches(msg, p.keyMap.exit):
 				p.displayMode = normalMode
 				p.chatContainer.BorderForeground(p.colors.ActiveTabBorderColor)
 				p.selectionView.Reset()
+
+			case key.Matches(msg, p.keyMap.pin):
+				line := p.selectionView.CursorLine()
+				return p, sessions.SendTogglePinnedMessageMsg(util.GetMessageIndexAtLine(p.selectionOffsets, line))
+
+			case key.Matches(msg, p.keyMap.rateUp, p.keyMap.rateDown, p.keyMap.ratingNote):
+				line := p.selectionView.CursorLine()
+				return p, p.rateMessage(msg, util.GetMessageIndexAtLine(p.selectionOffsets, line))
+
+			case key.Matches(msg, p.keyMap.rawMarkdown):
+				index := util.GetMessageIndexAtLine(p.selectionOffsets, p.selectionView.CursorLine())
+				returnOffset := p.chatView.YOffset
+				if index >= 0 && index < len(p.messageOffsets) {
+					returnOffset = p.messageOffsets[index]
+				}
+				p.selectionView.Reset()
+				return p.openRawMarkdown(index, returnOffset)
 			}
 		}
 
@@ -376,6 +747,11 @@ func (p ChatPane) Update(msg tea.Msg) (ChatPane, tea.Cmd) {
 				p.chatView.GotoBottom()
 			}
 
+		case key.Matches(msg, p.keyMap.openConfig):
+			if p.isChatContainerFocused && len(p.sessionContent) == 0 && !p.quickChatActive {
+				cmds = append(cmds, util.ToggleConfigEditor(true))
+			}
+
 		case key.Matches(msg, p.keyMap.selectionMode):
 			if !p.isChatContainerFocused || len(p.sessionContent) == 0 {
 				break
@@ -398,6 +774,82 @@ func (p ChatPane) Update(msg tea.Msg) (ChatPane, tea.Cmd) {
 				}
 				cmds = append(cmds, copyAll)
 			}
+
+		case key.Matches(msg, p.keyMap.diagrams):
+			if p.isChatContainerFocused && len(p.sessionContent) > 0 {
+				cmds = append(cmds, util.SendExportDiagramsMsg)
+			}
+
+		case key.Matches(msg, p.keyMap.saveLast):
+			if p.isChatContainerFocused && len(p.sessionContent) > 0 {
+				cmds = append(cmds, util.SendSaveToNotesMsg(false))
+			}
+
+		case key.Matches(msg, p.keyMap.saveAll):
+			if p.isChatContainerFocused && len(p.sessionContent) > 0 {
+				cmds = append(cmds, util.SendSaveToNotesMsg(true))
+			}
+
+		case key.Matches(msg, p.keyMap.openLink):
+			if p.isChatContainerFocused && len(p.sessionContent) > 0 {
+				cmds = append(cmds, util.SendOpenLinksMsg(util.GetLastResponseUrls(p.sessionContent)))
+			}
+
+		case key.Matches(msg, p.keyMap.quickActions):
+			if p.isChatContainerFocused && len(p.sessionContent) > 0 {
+				titles := []string{}
+				for _, action := range util.QuickActions {
+					titles = append(titles, util.GetQuickActionTitle(action))
+				}
+				p.openPicker(quickActionsMode, titles)
+			}
+
+		case key.Matches(msg, p.keyMap.pin):
+			if p.isChatContainerFocused && len(p.sessionContent) > 0 {
+				cmds = append(cmds, sessions.SendTogglePinnedMessageMsg(util.GetLastPinnableIndex(p.sessionContent)))
+			}
+
+		case key.Matches(msg, p.keyMap.pinnedList):
+			if p.isChatContainerFocused && len(p.sessionContent) > 0 {
+				cmds = append(cmds, p.openPinnedList())
+			}
+
+		case key.Matches(msg, p.keyMap.rateUp, p.keyMap.rateDown, p.keyMap.ratingNote):
+			if p.isChatContainerFocused && len(p.sessionContent) > 0 {
+				cmds = append(cmds, p.rateMessage(msg, util.GetLastRateableIndex(p.sessionContent)))
+			}
+
+		case key.Matches(msg, p.keyMap.rawMarkdown):
+			if p.isChatContainerFocused && len(p.sessionContent) > 0 {
+				return p.openRawMarkdown(util.GetLastPinnableIndex(p.sessionContent), p.chatView.YOffset)
+			}
+
+		case key.Matches(msg, p.keyMap.messageLog):
+			if p.isChatContainerFocused && len(p.sessionContent) > 0 {
+				return p.openMessageLog(), nil
+			}
+
+		case key.Matches(msg, p.keyMap.manual):
+			if p.isChatContainerFocused {
+				return p.openManual(), nil
+			}
+
+		case key.Matches(msg, p.keyMap.hints):
+			if p.isChatContainerFocused && len(p.sessionContent) > 0 {
+				return p, p.enterHintMode()
+			}
+
+		case key.Matches(msg, p.keyMap.setMark):
+			if p.isChatContainerFocused && len(p.sessionContent) > 0 {
+				p.pendingMarkCommand = setMarkCommand
+				return p, nil
+			}
+
+		case key.Matches(msg, p.keyMap.jumpToMark):
+			if p.isChatContainerFocused && len(p.sessionContent) > 0 {
+				p.pendingMarkCommand = jumpToMarkCommand
+				return p, nil
+			}
 		}
 	}
 
@@ -419,6 +871,68 @@ func getStringsDiff(oldStr, newStr string) string {
 	return newStr[i:]
 }
 
+func (p ChatPane) ShortHelp() []key.Binding {
+	if p.IsSelectionMode() {
+		return p.selectionView.ShortHelp()
+	}
+	return []key.Binding{p.keyMap.selectionMode, p.keyMap.copyLast, p.keyMap.copyAll, p.keyMap.goUp, p.keyMap.goDown}
+}
+
+func (p ChatPane) FullHelp() []key.Binding {
+	scroll := viewport.DefaultKeyMap()
+	return []key.Binding{
+		scroll.Up,
+		scroll.Down,
+		scroll.HalfPageUp,
+		scroll.HalfPageDown,
+		p.keyMap.goUp,
+		p.keyMap.goDown,
+		p.keyMap.copyLast,
+		p.keyMap.copyAll,
+		p.keyMap.saveLast,
+		p.keyMap.saveAll,
+		p.keyMap.openLink,
+		p.keyMap.quickActions,
+		p.keyMap.pin,
+		p.keyMap.pinnedList,
+		p.keyMap.rateUp,
+		p.keyMap.rateDown,
+		p.keyMap.ratingNote,
+		p.keyMap.setMark,
+		p.keyMap.jumpToMark,
+		p.keyMap.hints,
+		p.keyMap.rawMarkdown,
+		p.keyMap.messageLog,
+		p.keyMap.manual,
+		p.keyMap.followUp,
+		p.keyMap.selectionMode,
+		p.keyMap.openConfig,
+	}
+}
+
+func (p ChatPane) PaletteCommands() []util.PaletteCommand {
+	return []util.PaletteCommand{
+		{Binding: p.keyMap.copyLast, Target: util.ChatPane, RequiresFocus: true},
+		{Binding: p.keyMap.copyAll, Target: util.ChatPane, RequiresFocus: true},
+		{Binding: p.keyMap.saveLast, Target: util.ChatPane, RequiresFocus: true},
+		{Binding: p.keyMap.saveAll, Target: util.ChatPane, RequiresFocus: true},
+		{Binding: p.keyMap.openLink, Target: util.ChatPane, RequiresFocus: true},
+		{Binding: p.keyMap.quickActions, Target: util.ChatPane, RequiresFocus: true},
+		{Binding: p.keyMap.pin, Target: util.ChatPane, RequiresFocus: true},
+		{Binding: p.keyMap.pinnedList, Target: util.ChatPane, RequiresFocus: true},
+		{Binding: p.keyMap.rateUp, Target: util.ChatPane, RequiresFocus: true},
+		{Binding: p.keyMap.rateDown, Target: util.ChatPane, RequiresFocus: true},
+		{Binding: p.keyMap.ratingNote, Target: util.ChatPane, RequiresFocus: true},
+		{Binding: p.keyMap.hints, Target: util.ChatPane, RequiresFocus: true},
+		{Binding: p.keyMap.rawMarkdown, Target: util.ChatPane, RequiresFocus: true},
+		{Binding: p.keyMap.messageLog, Target: util.ChatPane, RequiresFocus: true},
+		{Binding: p.keyMap.manual, Target: util.ChatPane, RequiresFocus: true},
+		{Binding: p.keyMap.selectionMode, Target: util.ChatPane, RequiresFocus: true},
+		{Binding: p.keyMap.goUp, Target: util.ChatPane, RequiresFocus: true},
+		{Binding: p.keyMap.goDown, Target: util.ChatPane, RequiresFocus: true},
+	}
+}
+
 func (p ChatPane) IsSelectionMode() bool {
 	return p.displayMode == selectionMode
 }
@@ -430,11 +944,12 @@ func (p *ChatPane) enterSelectionMode() {
 
 	p.displayMode = selectionMode
 	p.chatContainer = p.chatContainer.BorderForeground(p.colors.AccentColor)
-	renderedContent := util.GetVisualModeView(
+	renderedContent, offsets := util.GetVisualModeView(
 		p.sessionContent,
 		p.chatView.Width,
 		p.colors,
 		p.currentSettings)
+	p.selectionOffsets = offsets
 	mouseTopOffset := p.chatContainer.GetMarginTop() + p.chatContainer.GetBorderTopSize() + p.chatContainer.GetPaddingTop()
 	mouseLeftOffset := p.chatContainer.GetMarginLeft() + p.chatContainer.GetBorderLeftSize() + p.chatContainer.GetPaddingLeft()
 	p.selectionView = components.NewTextSelector(
@@ -452,7 +967,149 @@ func (p ChatPane) AllowFocusChange(isMouseEvent bool) bool {
 	if isMouseEvent {
 		return true
 	}
-	return !p.selectionView.IsSelecting()
+	// the key after m or ' names the mark
+	return !p.selectionView.IsSelecting() && !p.isPickerOpen() && p.displayMode != hintMode &&
+		p.pendingMarkCommand == noMarkCommand
+}
+
+// Suggestions are numbered, the last character of the key is the number
+func (p ChatPane) useFollowUp(msg tea.KeyMsg) (ChatPane, tea.Cmd) {
+	keypress := msg.String()
+	number, err := strconv.Atoi(keypress[len(keypress)-1:])
+	if err != nil || number > len(p.followUps) {
+		return p, nil
+	}
+
+	suggestion := p.followUps[number-1]
+	p.followUps = nil
+	w, _ := util.CalcChatPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
+	p = p.displaySession(p.sessionContent, w, true)
+	return p, util.SendInsertPromptMsg(suggestion)
+}
+
+func (p ChatPane) HasFollowUps() bool {
+	return len(p.followUps) > 0
+}
+
+// The note is typed in the prompt pane, the current note is offered for editing
+func (p ChatPane) rateMessage(msg tea.KeyMsg, index int) tea.Cmd {
+	switch {
+	case key.Matches(msg, p.keyMap.rateUp):
+		return sessions.SendRateMessageMsg(index, util.RatingUp)
+	case key.Matches(msg, p.keyMap.rateDown):
+		return sessions.SendRateMessageMsg(index, util.RatingDown)
+	}
+
+	if index < 0 || index >= len(p.sessionContent) || !util.IsRateable(p.sessionContent[index]) {
+		return util.SendToastMsg(i18n.T("notification.nothingToRate"), util.WarningSeverity)
+	}
+	return sessions.RequestRatingNote(index, p.sessionContent[index].RatingNote)
+}
+
+func (p ChatPane) IsComparing() bool {
+	return p.displayMode == comparisonMode
+}
+
+func (p *ChatPane) openPicker(mode displayMode, items []string) {
+	p.selectionView.Reset()
+	p.displayMode = mode
+	p.pickerItems = items
+	p.pickerCursor = 0
+}
+
+func (p ChatPane) isPickerOpen() bool {
+	return p.displayMode == linkPickerMode || p.displayMode == quickActionsMode || p.displayMode == pinnedMode
+}
+
+func (p *ChatPane) openPinnedList() tea.Cmd {
+	items := []string{}
+	p.pinnedIndexes = []int{}
+	for i, message := range p.sessionContent {
+		if message.Pinned {
+			items = append(items, util.GetPinnedPreview(message))
+			p.pinnedIndexes = append(p.pinnedIndexes, i)
+		}
+	}
+
+	if len(items) == 0 {
+		return util.SendToastMsg(i18n.T("notification.noPinned"), util.WarningSeverity)
+	}
+
+	p.openPicker(pinnedMode, items)
+	return nil
+}
+
+// Messages of the streamed response are not in the offsets until the history is rendered again
+func (p *ChatPane) jumpToMessage(index int, line int) {
+	if index >= len(p.messageOffsets) {
+		p.chatView.GotoBottom()
+		return
+	}
+	p.chatView.SetYOffset(p.messageOffsets[index] + line)
+}
+
+// Marks are named with lowercase letters, any other key cancels the command
+func (p ChatPane) handleMarkKey(msg tea.KeyMsg) (ChatPane, tea.Cmd) {
+	command := p.pendingMarkCommand
+	p.pendingMarkCommand = noMarkCommand
+
+	name := msg.String()
+	if len(name) != 1 || name[0] < 'a' || name[0] > 'z' {
+		return p, nil
+	}
+
+	if command == jumpToMarkCommand {
+		mark, ok := p.marks[name]
+		if !ok {
+			return p, util.SendToastMsg(i18n.Tf("notification.markNotSet", name), util.WarningSeverity)
+		}
+		p.jumpToMessage(mark.Message, mark.Line)
+		return p, nil
+	}
+
+	mark := sessions.Mark{}
+	if index := util.GetMessageIndexAtLine(p.messageOffsets, p.chatView.YOffset); index >= 0 {
+		mark = sessions.Mark{Message: index, Line: p.chatView.YOffset - p.messageOffsets[index]}
+	}
+
+	marks := maps.Clone(p.marks)
+	if marks == nil {
+		marks = map[string]sessions.Mark{}
+	}
+	marks[name] = mark
+	p.marks = marks
+
+	return p, tea.Batch(
+		sessions.SendSessionMarksChangedMsg(p.sessionId, marks),
+		util.SendToastMsg(i18n.Tf("notification.markSet", name), util.SuccessSeverity),
+	)
+}
+
+func (p ChatPane) handlePickerKeys(msg tea.KeyMsg) (ChatPane, tea.Cmd) {
+	switch {
+	case key.Matches(msg, p.keyMap.exit):
+		p.displayMode = normalMode
+
+	case key.Matches(msg, p.keyMap.pickerUp):
+		p.pickerCursor = max(p.pickerCursor-1, 0)
+
+	case key.Matches(msg, p.keyMap.pickerDown):
+		p.pickerCursor = min(p.pickerCursor+1, len(p.pickerItems)-1)
+
+	case key.Matches(msg, p.keyMap.pickerChoose):
+		mode := p.displayMode
+		p.displayMode = normalMode
+		switch mode {
+		case quickActionsMode:
+			return p, util.SendQuickActionMsg(util.QuickActions[p.pickerCursor])
+		case pinnedMode:
+			p.jumpToMessage(p.pinnedIndexes[p.pickerCursor], 0)
+			return p, nil
+		}
+		return p, util.OpenUrl(p.pickerItems[p.pickerCursor])
+	}
+
+	return p, nil
 }
 
 func (p *ChatPane) DisplayCompletion(
@@ -492,6 +1149,17 @@ func (p *ChatPane) ResumeCompletion(
 }
 
 func (p ChatPane) View() string {
+	if p.isPickerOpen() {
+		return zone.Mark("chat_pane", p.chatContainer.BorderForeground(p.colors.AccentColor).Render(p.renderPicker()))
+	}
+
+	if p.displayMode == hintMode {
+		lines := strings.Split(p.chatView.View(), "\n")
+		viewportContent := util.RenderHints(lines, p.hints, p.typedHint, p.colors)
+		content := lipgloss.JoinVertical(lipgloss.Left, viewportContent, p.renderHintsInfoRow())
+		return zone.Mark("chat_pane", p.chatContainer.BorderForeground(p.colors.AccentColor).Render(content))
+	}
+
 	if p.IsSelectionMode() {
 		infoRow := p.renderSelectionViewInfoRow()
 		selectionView := p.selectionView.View()
@@ -537,18 +1205,22 @@ func (p ChatPane) GetWidth() int {
 func (p ChatPane) renderInfoRow() string {
 	percent := p.chatView.ScrollPercent()
 
-	info := fmt.Sprintf("▐ [%.f%%]", percent*100)
+	info := fmt.Sprintf("%s [%.f%%]", util.InfoBarMarker, percent*100)
 	if percent == 0 {
-		info = "▐ [Top]"
+		info = util.InfoBarMarker + " [Top]"
 	}
 	if percent == 1 {
-		info = "▐ [Bottom]"
+		info = util.InfoBarMarker + " [Bottom]"
 	}
 
 	if p.quickChatActive {
 		info += " | [Quick chat]"
 	}
 
+	if p.persona != nil {
+		info += " | [" + p.persona.Name + "]"
+	}
+
 	if p.currentSettings.WebSearchEnabled {
 		info += " | [Web search]"
 	}
@@ -557,10 +1229,92 @@ func (p ChatPane) renderInfoRow() string {
 		info += " | [Reasoning hidden]"
 	}
 
+	if p.displayMode == rawMarkdownMode {
+		info += " | [Raw markdown]"
+	}
+
+	if p.displayMode == messageLogMode {
+		info += " | [Message log]"
+	}
+
+	if p.displayMode == promptDiffMode {
+		info += " | [Sys prompt diff]"
+	}
+
+	if p.displayMode == comparisonMode {
+		info += " | [Preset comparison]"
+	}
+
+	if p.displayMode == manualMode {
+		info += " | [Manual]"
+	}
+
+	if horizontal := p.chatView.HorizontalScrollPercent(); horizontal > 0 {
+		info += fmt.Sprintf(" | [→ %.f%%]", horizontal*100)
+	}
+
 	infoBar := infoBarStyle.Width(p.chatView.Width).Render(info)
 	return infoBar
 }
 
+func (p ChatPane) renderPicker() string {
+	titleStyle := lipgloss.NewStyle().Foreground(p.colors.DefaultTextColor)
+	activeTitleStyle := lipgloss.NewStyle().Foreground(p.colors.AccentColor).Bold(true)
+	rowStyle := lipgloss.NewStyle().MaxWidth(p.chatView.Width - util.DefaultElementsPadding)
+
+	title := i18n.T("links.title")
+	switch p.displayMode {
+	case quickActionsMode:
+		title = i18n.T("quickActions.title")
+	case pinnedMode:
+		title = i18n.T("pinned.title")
+	}
+
+	header := []string{activeHeader.Render(title), ""}
+	tips := util.HelpStyle.Render(util.RenderKeyHints([]key.Binding{
+		p.keyMap.pickerUp,
+		p.keyMap.pickerDown,
+		p.keyMap.pickerChoose,
+		p.keyMap.exit,
+	}))
+
+	listHeight := max(p.chatView.Height-len(header)-lipgloss.Height(tips), 1)
+	offset := max(0, p.pickerCursor-listHeight+1)
+
+	rows := []string{}
+	for i := offset; i < len(p.pickerItems) && len(rows) < listHeight; i++ {
+		prefix := "  "
+		style := titleStyle
+		if i == p.pickerCursor {
+			prefix = "> "
+			style = activeTitleStyle
+		}
+		rows = append(rows, rowStyle.Render(style.Render(fmt.Sprintf("%s%d. %s", prefix, i+1, p.pickerItems[i]))))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left, append(header, rows...)...)
+	spacerHeight := p.chatView.Height - lipgloss.Height(content) - lipgloss.Height(tips)
+	if spacerHeight > 0 {
+		content += strings.Repeat("\n", spacerHeight)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, content, tips)
+}
+
+func (p ChatPane) renderHintsInfoRow() string {
+	cancel := util.TipsSeparator + "esc " + i18n.T("hints.cancel")
+	info := util.InfoBarMarker + " " + i18n.T("hints.typeLabel") + cancel
+	if p.chosenHint != nil {
+		bindings := []key.Binding{p.keyMap.hintCopy, p.keyMap.hintSave}
+		if p.chosenHint.Kind == util.LinkHint {
+			bindings = []key.Binding{p.keyMap.hintCopy, p.keyMap.hintOpen, p.keyMap.hintSave}
+		}
+		info = util.InfoBarMarker + " [" + p.chosenHint.Label + "] " + util.RenderKeyHints(bindings) + cancel
+	}
+
+	return infoBarStyle.Width(p.chatView.Width).Render(info)
+}
+
 func (p ChatPane) renderSelectionViewInfoRow() string {
 	info := ""
 	if p.selectionView.IsCharSelecting() {
@@ -570,7 +1324,7 @@ func (p ChatPane) renderSelectionViewInfoRow() string {
 			charsWord = "char"
 		}
 
-		info += fmt.Sprintf("▐ Selected [%d %s]", charsSelected, charsWord)
+		info += fmt.Sprintf("%s Selected [%d %s]", util.InfoBarMarker, charsSelected, charsWord)
 		info += "  | `r` to copy raw • `y` to copy with formatting"
 
 	} else if p.selectionView.IsSelecting() {
@@ -580,11 +1334,11 @@ func (p ChatPane) renderSelectionViewInfoRow() string {
 			linesWord = "line"
 		}
 
-		info += fmt.Sprintf("▐ Selected [%d %s]", len(linesSelected), linesWord)
+		info += fmt.Sprintf("%s Selected [%d %s]", util.InfoBarMarker, len(linesSelected), linesWord)
 		info += "  | `r` to copy raw • `y` to copy with formatting"
 
 	} else {
-		info += "▐ Press 'space' to start selecting"
+		info += util.InfoBarMarker + " Press 'space' to start selecting"
 	}
 
 	infoBar := infoBarStyle.Width(p.chatView.Width).Render(info)
@@ -596,11 +1350,20 @@ func (p ChatPane) initializePane(session sessions.Session) (ChatPane, tea.Cmd) {
 	if !p.isChatPaneReady {
 		p.chatView = viewport.New(paneWidth, paneHeight-2)
 		p.chatView.MouseWheelEnabled = false
+		p.chatView.SetHorizontalStep(horizontalScrollStep)
 
 		p.isChatPaneReady = true
 	}
 
 	p.quickChatActive = session.IsTemporary
+	p.inlineError = ""
+	p.sessionId = session.ID
+	p.persona = session.Persona
+	util.SetPersona(session.Persona)
+	p.followUps = nil
+	p.marks = session.Marks
+	p.pendingMarkCommand = noMarkCommand
+	p.chatView.SetXOffset(0)
 	if len(session.Messages) == 0 && !session.IsTemporary {
 		p = p.displayManual()
 	} else {
@@ -624,23 +1387,53 @@ func (p ChatPane) displaySession(
 	paneWidth int,
 	useScroll bool,
 ) ChatPane {
-	oldContent := util.GetMessagesAsPrettyString(
+	oldContent, offsets := util.GetMessagesAsPrettyString(
 		messages,
 		paneWidth-1,
 		p.colors,
 		p.quickChatActive,
 		p.currentSettings)
+	if p.inlineError != "" {
+		oldContent += "\n" + util.RenderInlineErrorMessage(p.inlineError, paneWidth-1, p.colors)
+	}
+	if len(p.followUps) > 0 {
+		oldContent += "\n\n" + util.RenderFollowUps(p.followUps, paneWidth-1, p.colors)
+	}
 	p.chatView.SetContent(oldContent)
 	if useScroll {
 		p.chatView.GotoBottom()
 	}
 	p.sessionContent = messages
 	p.renderedHistory = oldContent
+	p.messageOffsets = offsets
 
 	p.chunksBuffer = []string{}
 
 	p.responseBuffer = ""
 	p.renderedResponseBuffer = ""
+
+	if p.displayMode == rawMarkdownMode {
+		p.displayRawMarkdown()
+	}
+
+	if p.displayMode == messageLogMode {
+		p.displayMessageLog()
+		if useScroll {
+			p.chatView.GotoBottom()
+		}
+	}
+
+	if p.displayMode == promptDiffMode {
+		p.displayPromptDiff()
+	}
+
+	if p.displayMode == comparisonMode {
+		p.displayComparison()
+	}
+
+	if p.displayMode == manualMode {
+		p.chatView.SetContent(util.GetManual(p.terminalWidth, p.colors))
+	}
 	return p
 }
 
@@ -648,6 +1441,10 @@ func (p ChatPane) handleWindowResize(width int, height int) ChatPane {
 	p.terminalWidth = width
 	p.terminalHeight = height
 
+	if p.displayMode == hintMode {
+		p.displayMode = normalMode
+	}
+
 	w, h := util.CalcChatPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
 	p.chatView.Height = h - 2
 	p.chatView.Width = w
@@ -657,9 +1454,271 @@ func (p ChatPane) handleWindowResize(width int, height int) ChatPane {
 		p = p.displaySession(p.sessionContent, w, false)
 	}
 
-	if len(p.sessionContent) == 0 && !p.quickChatActive {
+	if len(p.sessionContent) == 0 && !p.quickChatActive && p.inlineError == "" {
 		p = p.displayManual()
 	}
 
 	return p
 }
+
+// Labels are put on the elements of the messages that are visible in the chat view
+func (p *ChatPane) enterHintMode() tea.Cmd {
+	if util.IsProcessingActive(p.processingState) {
+		return nil
+	}
+
+	first := max(util.GetMessageIndexAtLine(p.messageOffsets, p.chatView.YOffset), 0)
+	last := util.GetMessageIndexAtLine(p.messageOffsets, p.chatView.YOffset+p.chatView.Height-1)
+	if last < 0 || last >= len(p.sessionContent) {
+		return util.SendToastMsg(i18n.T("notification.noHints"), util.WarningSeverity)
+	}
+
+	hints := util.FindHints(p.sessionContent[first:last+1], strings.Split(p.chatView.View(), "\n"))
+	if len(hints) == 0 {
+		return util.SendToastMsg(i18n.T("notification.noHints"), util.WarningSeverity)
+	}
+
+	p.displayMode = hintMode
+	p.hints = hints
+	p.typedHint = ""
+	p.chosenHint = nil
+	return nil
+}
+
+// A label is typed first, then a key of the action to apply to the element
+func (p ChatPane) handleHintKeys(msg tea.KeyMsg) (ChatPane, tea.Cmd) {
+	if key.Matches(msg, p.keyMap.exit) {
+		p.displayMode = normalMode
+		return p, nil
+	}
+
+	if p.chosenHint != nil {
+		return p.applyHintAction(msg)
+	}
+
+	if msg.Type == tea.KeyBackspace {
+		if p.typedHint != "" {
+			p.typedHint = p.typedHint[:len(p.typedHint)-1]
+		}
+		return p, nil
+	}
+
+	if msg.Type != tea.KeyRunes {
+		return p, nil
+	}
+
+	typed := p.typedHint + msg.String()
+	matches := 0
+	for i, hint := range p.hints {
+		if hint.Label == typed {
+			p.chosenHint = &p.hints[i]
+			return p, nil
+		}
+		if strings.HasPrefix(hint.Label, typed) {
+			matches++
+		}
+	}
+
+	if matches > 0 {
+		p.typedHint = typed
+	}
+	return p, nil
+}
+
+func (p ChatPane) applyHintAction(msg tea.KeyMsg) (ChatPane, tea.Cmd) {
+	hint := *p.chosenHint
+
+	switch {
+	case key.Matches(msg, p.keyMap.hintCopy):
+		p.displayMode = normalMode
+		util.CopyToClipboard(hint.Value)
+		return p, util.SendNotificationMsg(util.CopiedNotification)
+
+	case key.Matches(msg, p.keyMap.hintOpen):
+		if hint.Kind != util.LinkHint {
+			return p, nil
+		}
+		p.displayMode = normalMode
+		return p, util.OpenUrl(hint.Value)
+
+	case key.Matches(msg, p.keyMap.hintSave):
+		p.displayMode = normalMode
+		text := hint.Value
+		if hint.Kind == util.CodeBlockHint {
+			text = "```\n" + text + "\n```"
+		}
+		return p, util.SendSaveTextToNotesMsg(text)
+	}
+
+	return p, nil
+}
+
+// Shows the message as the model wrote it, without the markdown rendering
+// Closing it returns the chat to the offset, e.g. where the message is
+func (p ChatPane) openRawMarkdown(index int, returnOffset int) (ChatPane, tea.Cmd) {
+	if index < 0 || index >= len(p.sessionContent) || !util.IsPinnable(p.sessionContent[index]) {
+		return p, util.SendToastMsg(i18n.T("notification.noRawMessage"), util.WarningSeverity)
+	}
+
+	p.displayMode = rawMarkdownMode
+	p.rawMessageIndex = index
+	p.rawReturnOffset = returnOffset
+	p.displayRawMarkdown()
+	p.chatView.GotoTop()
+	return p, nil
+}
+
+func (p *ChatPane) displayRawMarkdown() {
+	message := p.sessionContent[p.rawMessageIndex]
+	header := util.HelpStyle.Render(i18n.T("chat.rawMarkdown"))
+	content := lipgloss.NewStyle().Width(p.chatView.Width - util.DefaultElementsPadding).Render(message.Content)
+	p.chatView.SetContent(header + "\n\n" + content)
+}
+
+func (p ChatPane) closeRawMarkdown() ChatPane {
+	p.displayMode = normalMode
+	w, _ := util.CalcChatPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
+	p = p.displaySession(p.sessionContent, w, false)
+	p.chatView.SetYOffset(p.rawReturnOffset)
+	return p
+}
+
+// Linear plain text log of the chat without the markdown rendering and decorations,
+// so screen readers read the messages in order. Closing it returns the chat to the offset
+func (p ChatPane) openMessageLog() ChatPane {
+	p.displayMode = messageLogMode
+	p.logReturnOffset = p.chatView.YOffset
+	p.displayMessageLog()
+	p.chatView.GotoBottom()
+	return p
+}
+
+func (p *ChatPane) displayMessageLog() {
+	header := i18n.T("chat.messageLog")
+	content := lipgloss.NewStyle().
+		Width(p.chatView.Width - util.DefaultElementsPadding).
+		Render(util.FormatMessageLog(p.sessionContent))
+	p.chatView.SetContent(header + "\n\n" + content)
+}
+
+func (p ChatPane) closeMessageLog() ChatPane {
+	p.displayMode = normalMode
+	w, _ := util.CalcChatPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
+	p = p.displaySession(p.sessionContent, w, false)
+	p.chatView.SetYOffset(p.logReturnOffset)
+	return p
+}
+
+// Diff of a system prompt version opened from the settings pane, it is shown in place of the chat
+func (p ChatPane) openPromptDiff(msg util.SystemPromptDiffMsg) ChatPane {
+	if p.displayMode != promptDiffMode {
+		p.diffReturnOffset = p.chatView.YOffset
+	}
+	p.displayMode = promptDiffMode
+	p.promptDiff = msg
+	p.displayPromptDiff()
+	p.chatView.GotoTop()
+	return p
+}
+
+func (p *ChatPane) displayPromptDiff() {
+	width := p.chatView.Width - util.DefaultElementsPadding
+	styles := map[util.DiffKind]lipgloss.Style{
+		util.DiffEqual:   lipgloss.NewStyle().Width(width).Foreground(p.colors.DefaultTextColor),
+		util.DiffAdded:   lipgloss.NewStyle().Width(width).Foreground(p.colors.AccentColor),
+		util.DiffRemoved: lipgloss.NewStyle().Width(width).Foreground(p.colors.ErrorColor),
+	}
+
+	lines := []string{
+		util.HelpStyle.Render(i18n.T("chat.promptDiff")),
+		"",
+		lipgloss.NewStyle().Bold(true).Width(width).Render(p.promptDiff.Title),
+		"",
+	}
+	for _, line := range p.promptDiff.Lines {
+		lines = append(lines, styles[line.Kind].Render(line.String()))
+	}
+	p.chatView.SetContent(strings.Join(lines, "\n"))
+}
+
+func (p ChatPane) closePromptDiff() ChatPane {
+	p.displayMode = normalMode
+	w, _ := util.CalcChatPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
+	p = p.displaySession(p.sessionContent, w, false)
+	p.chatView.SetYOffset(p.diffReturnOffset)
+	return p
+}
+
+// Answers of the `/compare` command, shown in place of the chat until one of them is marked as better
+func (p ChatPane) openComparison(msg util.PresetComparisonMsg) ChatPane {
+	if p.displayMode != comparisonMode {
+		p.comparisonReturnOffset = p.chatView.YOffset
+	}
+	p.displayMode = comparisonMode
+	p.comparison = msg.Comparison
+	p.displayComparison()
+	p.chatView.GotoTop()
+	return p
+}
+
+func (p *ChatPane) displayComparison() {
+	width := p.chatView.Width - util.DefaultElementsPadding
+	parts := []string{
+		util.HelpStyle.Render(i18n.T("chat.comparison")),
+		util.RenderUserMessage(
+			util.LocalStoreMessage{Role: "user", Content: p.comparison.Prompt},
+			width,
+			p.colors,
+			false),
+	}
+
+	for i, answer := range p.comparison.Answers {
+		label := fmt.Sprintf("%c: %s (%s)", 'A'+i, answer.Preset, answer.Model)
+		if i == p.comparison.Winner {
+			label += " - " + i18n.T("chat.comparisonWinner")
+		}
+
+		message := util.LocalStoreMessage{Role: "assistant", Model: label, Content: answer.Content}
+		parts = append(parts, util.RenderBotMessage(message, width, p.colors, false, p.currentSettings))
+	}
+	p.chatView.SetContent(strings.Join(parts, "\n\n"))
+}
+
+func (p ChatPane) markComparisonWinner(winner int) (ChatPane, tea.Cmd) {
+	p.comparison.Winner = winner
+	offset := p.chatView.YOffset
+	p.displayComparison()
+	p.chatView.SetYOffset(offset)
+	return p, util.SendComparisonWinnerMarkedMsg(p.comparison.ID, winner)
+}
+
+func (p ChatPane) closeComparison() ChatPane {
+	p.displayMode = normalMode
+	w, _ := util.CalcChatPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
+	p = p.displaySession(p.sessionContent, w, false)
+	p.chatView.SetYOffset(p.comparisonReturnOffset)
+	return p
+}
+
+// The manual is shown in place of the chat on demand, e.g. when the info pane is clicked
+func (p ChatPane) openManual() ChatPane {
+	if p.displayMode != manualMode {
+		p.manualReturnOffset = p.chatView.YOffset
+	}
+	p.displayMode = manualMode
+	p.chatView.SetContent(util.GetManual(p.terminalWidth, p.colors))
+	p.chatView.GotoTop()
+	return p
+}
+
+func (p ChatPane) closeManual() ChatPane {
+	p.displayMode = normalMode
+	if len(p.sessionContent) == 0 && !p.quickChatActive {
+		return p.displayManual()
+	}
+
+	w, _ := util.CalcChatPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
+	p = p.displaySession(p.sessionContent, w, false)
+	p.chatView.SetYOffset(p.manualReturnOffset)
+	return p
+}
@@ -0,0 +1,238 @@
+package panes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/util"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+	"github.com/sahilm/fuzzy"
+)
+
+type commandPaletteKeyMap struct {
+	up      key.Binding
+	down    key.Binding
+	execute key.Binding
+	close   key.Binding
+}
+
+var defaultCommandPaletteKeyMap = commandPaletteKeyMap{
+	up:      key.NewBinding(key.WithKeys(tea.KeyUp.String(), "ctrl+p"), key.WithHelp("↑", "keys.previousCommand")),
+	down:    key.NewBinding(key.WithKeys(tea.KeyDown.String(), "ctrl+n"), key.WithHelp("↓", "keys.nextCommand")),
+	execute: key.NewBinding(key.WithKeys(tea.KeyEnter.String()), key.WithHelp("enter", "keys.runCommand")),
+	close:   key.NewBinding(key.WithKeys(tea.KeyEsc.String(), "ctrl+k"), key.WithHelp("esc", "keys.closePalette")),
+}
+
+// Lists every action of the app with fuzzy search by title
+type CommandPalette struct {
+	commands  []util.PaletteCommand
+	matches   fuzzy.Matches
+	cursor    int
+	input     textinput.Model
+	keyMap    commandPaletteKeyMap
+	colors    util.SchemeColors
+	container lipgloss.Style
+	viewMode  util.ViewMode
+
+	terminalWidth  int
+	terminalHeight int
+}
+
+func NewCommandPalette(ctx context.Context) CommandPalette {
+	cfg, ok := config.FromContext(ctx)
+	if !ok {
+		util.Slog.Error("failed to extract config from context")
+		panic("No config found in context")
+	}
+
+	colors := cfg.ColorScheme.GetColors()
+	container := lipgloss.NewStyle().
+		Border(util.ThickBorder()).
+		BorderForeground(colors.ActiveTabBorderColor).
+		MarginRight(util.ChatPaneMarginRight)
+
+	input := textinput.New()
+	input.PromptStyle = lipgloss.NewStyle().Foreground(colors.ActiveTabBorderColor)
+	input.Placeholder = i18n.T("palette.placeholder")
+
+	return CommandPalette{
+		input:          input,
+		keyMap:         defaultCommandPaletteKeyMap,
+		colors:         colors,
+		container:      container,
+		viewMode:       util.NormalMode,
+		terminalWidth:  util.DefaultTerminalWidth,
+		terminalHeight: util.DefaultTerminalHeight,
+	}
+}
+
+func (p CommandPalette) SetColors(colors util.SchemeColors) CommandPalette {
+	p.colors = colors
+	p.container = p.container.BorderForeground(colors.ActiveTabBorderColor)
+	p.input.PromptStyle = p.input.PromptStyle.Foreground(colors.ActiveTabBorderColor)
+	return p
+}
+
+// Resets the search and shows the given commands
+func (p CommandPalette) Open(commands []util.PaletteCommand) (CommandPalette, tea.Cmd) {
+	p.commands = commands
+	p.input.Reset()
+	p.filter()
+	return p, p.input.Focus()
+}
+
+func (p CommandPalette) Update(msg tea.Msg) (CommandPalette, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.terminalWidth = msg.Width
+		p.terminalHeight = msg.Height
+
+	case util.ViewModeChanged:
+		p.viewMode = msg.Mode
+
+	case tea.MouseMsg:
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			p.moveCursor(-1)
+		case tea.MouseButtonWheelDown:
+			p.moveCursor(1)
+		}
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, p.keyMap.close):
+			return p, util.ToggleCommandPalette(false)
+
+		case key.Matches(msg, p.keyMap.up):
+			p.moveCursor(-1)
+
+		case key.Matches(msg, p.keyMap.down):
+			p.moveCursor(1)
+
+		case key.Matches(msg, p.keyMap.execute):
+			if len(p.matches) == 0 {
+				return p, nil
+			}
+			return p, util.SendPaletteCommandChosenMsg(p.commands[p.matches[p.cursor].Index])
+
+		default:
+			query := p.input.Value()
+			p.input, cmd = p.input.Update(msg)
+			if p.input.Value() != query {
+				p.filter()
+			}
+		}
+	}
+
+	return p, cmd
+}
+
+func (p *CommandPalette) moveCursor(delta int) {
+	p.cursor = max(0, min(p.cursor+delta, len(p.matches)-1))
+}
+
+// Empty query lists all commands in their original order
+func (p *CommandPalette) filter() {
+	p.cursor = 0
+	query := strings.TrimSpace(p.input.Value())
+
+	if query == "" {
+		p.matches = make(fuzzy.Matches, len(p.commands))
+		for i, command := range p.commands {
+			p.matches[i] = fuzzy.Match{Str: command.GetTitle(), Index: i}
+		}
+		return
+	}
+
+	titles := make([]string, len(p.commands))
+	for i, command := range p.commands {
+		titles[i] = command.GetTitle()
+	}
+	p.matches = fuzzy.Find(query, titles)
+}
+
+func (p CommandPalette) View() string {
+	w, h := util.CalcChatPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
+
+	titleStyle := lipgloss.NewStyle().Foreground(p.colors.DefaultTextColor)
+	activeTitleStyle := lipgloss.NewStyle().Foreground(p.colors.AccentColor).Bold(true)
+	matchStyle := lipgloss.NewStyle().Foreground(p.colors.HighlightColor).Underline(true)
+	keyStyle := lipgloss.NewStyle().Foreground(util.SubduedColor)
+	rowStyle := lipgloss.NewStyle().MaxWidth(w - util.DefaultElementsPadding)
+
+	p.input.Width = w - util.InputContainerDelta
+	header := []string{activeHeader.Render(i18n.T("palette.title")), p.input.View(), ""}
+
+	tips := util.HelpStyle.Render(util.RenderKeyHints([]key.Binding{
+		p.keyMap.up,
+		p.keyMap.down,
+		p.keyMap.execute,
+		p.keyMap.close,
+	}))
+
+	listHeight := max(h-len(header)-lipgloss.Height(tips), 1)
+	offset := max(0, p.cursor-listHeight+1)
+
+	rows := []string{}
+	if len(p.matches) == 0 {
+		rows = append(rows, titleStyle.Render("  "+i18n.T("palette.noMatches")))
+	}
+
+	for i := offset; i < len(p.matches) && len(rows) < listHeight; i++ {
+		match := p.matches[i]
+		command := p.commands[match.Index]
+
+		prefix := "  "
+		style := titleStyle
+		if i == p.cursor {
+			prefix = "> "
+			style = activeTitleStyle
+		}
+
+		title := highlightMatches(match.Str, match.MatchedIndexes, style, matchStyle)
+		if keys := command.Binding.Help().Key; keys != "" && command.Cmd == nil {
+			title += " " + keyStyle.Render(keys)
+		}
+		rows = append(rows, rowStyle.Render(prefix+title))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left, append(header, rows...)...)
+	spacerHeight := h - lipgloss.Height(content) - lipgloss.Height(tips)
+	if spacerHeight > 0 {
+		content += strings.Repeat("\n", spacerHeight)
+	}
+
+	return zone.Mark("chat_pane", p.container.Width(w).Height(h).Render(
+		lipgloss.JoinVertical(lipgloss.Left, content, tips),
+	))
+}
+
+// Matched indexes are byte offsets into the title
+func highlightMatches(title string, matched []int, style, matchStyle lipgloss.Style) string {
+	if len(matched) == 0 {
+		return style.Render(title)
+	}
+
+	isMatched := map[int]bool{}
+	for _, idx := range matched {
+		isMatched[idx] = true
+	}
+
+	result := strings.Builder{}
+	for idx, char := range title {
+		if isMatched[idx] {
+			result.WriteString(matchStyle.Render(string(char)))
+			continue
+		}
+		result.WriteString(style.Render(string(char)))
+	}
+	return result.String()
+}
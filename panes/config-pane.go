@@ -0,0 +1,321 @@
+package panes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/util"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+type configPaneKeyMap struct {
+	up      key.Binding
+	down    key.Binding
+	edit    key.Binding
+	profile key.Binding
+	close   key.Binding
+}
+
+var defaultConfigPaneKeyMap = configPaneKeyMap{
+	up:      key.NewBinding(key.WithKeys("k", tea.KeyUp.String()), key.WithHelp("k", "keys.previousOption")),
+	down:    key.NewBinding(key.WithKeys("j", tea.KeyDown.String()), key.WithHelp("j", "keys.nextOption")),
+	edit:    key.NewBinding(key.WithKeys(tea.KeyEnter.String()), key.WithHelp("enter", "keys.editOption")),
+	profile: key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "keys.switchProfile")),
+	close:   key.NewBinding(key.WithKeys(tea.KeyEsc.String()), key.WithHelp("esc", "keys.closeConfig")),
+}
+
+type ConfigPane struct {
+	config           *config.Config
+	options          []config.ConfigOption
+	pending          map[string]string
+	cursor           int
+	isEditing        bool
+	isEditingProfile bool
+	errorText        string
+	textInput        textinput.Model
+	keyMap           configPaneKeyMap
+	colors           util.SchemeColors
+	container        lipgloss.Style
+	viewMode         util.ViewMode
+
+	terminalWidth  int
+	terminalHeight int
+}
+
+func NewConfigPane(ctx context.Context) ConfigPane {
+	cfg, ok := config.FromContext(ctx)
+	if !ok {
+		util.Slog.Error("failed to extract config from context")
+		panic("No config found in context")
+	}
+
+	colors := cfg.ColorScheme.GetColors()
+	container := lipgloss.NewStyle().
+		Border(util.ThickBorder()).
+		BorderForeground(colors.ActiveTabBorderColor).
+		MarginRight(util.ChatPaneMarginRight)
+
+	return ConfigPane{
+		config:         cfg,
+		options:        config.EditableOptions,
+		pending:        map[string]string{},
+		keyMap:         defaultConfigPaneKeyMap,
+		colors:         colors,
+		container:      container,
+		viewMode:       util.NormalMode,
+		terminalWidth:  util.DefaultTerminalWidth,
+		terminalHeight: util.DefaultTerminalHeight,
+	}
+}
+
+func (p ConfigPane) SetColors(colors util.SchemeColors) ConfigPane {
+	p.colors = colors
+	p.container = p.container.BorderForeground(colors.ActiveTabBorderColor)
+	return p
+}
+
+func (p ConfigPane) Update(msg tea.Msg) (ConfigPane, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.terminalWidth = msg.Width
+		p.terminalHeight = msg.Height
+
+	case util.ViewModeChanged:
+		p.viewMode = msg.Mode
+
+	case tea.MouseMsg:
+		if msg.Action != tea.MouseActionRelease || msg.Button != tea.MouseButtonLeft || p.isEditing {
+			break
+		}
+
+		for i := range p.options {
+			if zone.Get(configOptionZone(i)).InBounds(msg) {
+				p.cursor = i
+				return p, p.startEditing()
+			}
+		}
+
+	case tea.KeyMsg:
+		if p.isEditing {
+			return p.handleEditing(msg)
+		}
+
+		switch {
+		case key.Matches(msg, p.keyMap.close):
+			p.errorText = ""
+			return p, util.ToggleConfigEditor(false)
+
+		case key.Matches(msg, p.keyMap.up):
+			if p.cursor > 0 {
+				p.cursor--
+			}
+
+		case key.Matches(msg, p.keyMap.down):
+			if p.cursor < len(p.options)-1 {
+				p.cursor++
+			}
+
+		case key.Matches(msg, p.keyMap.edit):
+			cmd = p.startEditing()
+
+		case key.Matches(msg, p.keyMap.profile):
+			cmd = p.startProfileEditing()
+		}
+	}
+
+	return p, cmd
+}
+
+// Moves the cursor to the option and starts editing it
+func (p ConfigPane) SelectOption(optionKey string) (ConfigPane, tea.Cmd) {
+	for i, option := range p.options {
+		if option.Key == optionKey {
+			p.cursor = i
+			return p, p.startEditing()
+		}
+	}
+	return p, nil
+}
+
+func (p *ConfigPane) startEditing() tea.Cmd {
+	option := p.options[p.cursor]
+
+	ti := textinput.New()
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(p.colors.ActiveTabBorderColor)
+	ti.Placeholder = i18n.Tf("config.enterValue", option.Key)
+	ti.Width = p.container.GetWidth() - util.InputContainerDelta
+	ti.SetValue(p.getOptionValue(option))
+	ti.Focus()
+
+	p.textInput = ti
+	p.isEditing = true
+	p.isEditingProfile = false
+	p.errorText = ""
+	return p.textInput.Cursor.BlinkCmd()
+}
+
+func (p *ConfigPane) startProfileEditing() tea.Cmd {
+	ti := textinput.New()
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(p.colors.ActiveTabBorderColor)
+	ti.Placeholder = i18n.T("config.enterProfile")
+	ti.Width = p.container.GetWidth() - util.InputContainerDelta
+	ti.Focus()
+
+	p.textInput = ti
+	p.isEditing = true
+	p.isEditingProfile = true
+	p.errorText = ""
+	return p.textInput.Cursor.BlinkCmd()
+}
+
+func (p ConfigPane) switchProfile(name string) (ConfigPane, tea.Cmd) {
+	name = strings.TrimSpace(name)
+	err := util.ValidateProfileName(name)
+	if err != nil {
+		p.errorText = err.Error()
+		return p, nil
+	}
+
+	p.isEditing = false
+	p.isEditingProfile = false
+	p.errorText = ""
+
+	if name == util.GetProfile() {
+		return p, nil
+	}
+
+	return p, util.SendProfileSwitchRequestedMsg(name)
+}
+
+func (p ConfigPane) handleEditing(msg tea.KeyMsg) (ConfigPane, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		p.isEditing = false
+		p.isEditingProfile = false
+		p.errorText = ""
+		return p, nil
+
+	case tea.KeyEnter:
+		if p.isEditingProfile {
+			return p.switchProfile(p.textInput.Value())
+		}
+
+		option := p.options[p.cursor]
+		value := p.textInput.Value()
+
+		err := p.config.UpdateOption(option, value)
+		if err != nil {
+			p.errorText = err.Error()
+			return p, nil
+		}
+
+		if option.RequiresRestart {
+			p.pending[option.Key] = strings.TrimSpace(value)
+		}
+
+		p.isEditing = false
+		p.errorText = ""
+		return p, tea.Batch(
+			config.SendConfigUpdatedMsg(*p.config),
+			util.SendNotificationMsg(util.ConfigSavedNotification),
+		)
+	}
+
+	p.textInput, cmd = p.textInput.Update(msg)
+	return p, cmd
+}
+
+func (p ConfigPane) getOptionValue(option config.ConfigOption) string {
+	if pendingValue, ok := p.pending[option.Key]; ok {
+		return pendingValue
+	}
+	return option.Value(*p.config)
+}
+
+func (p ConfigPane) View() string {
+	w, h := util.CalcChatPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
+
+	keyStyle := lipgloss.NewStyle().Foreground(p.colors.MainColor)
+	activeKeyStyle := lipgloss.NewStyle().Foreground(p.colors.AccentColor).Bold(true)
+	valueStyle := lipgloss.NewStyle().Foreground(p.colors.DefaultTextColor)
+	descriptionStyle := lipgloss.NewStyle().
+		Foreground(p.colors.HighlightColor).
+		PaddingLeft(util.ListItemPaddingLeft).
+		Width(w - util.DefaultElementsPadding)
+	errorStyle := lipgloss.NewStyle().
+		Foreground(p.colors.ErrorColor).
+		PaddingLeft(util.ListItemPaddingLeft)
+	titleStyle := activeHeader
+
+	profileLine := keyStyle.Render(i18n.T("config.profile")) + " " + valueStyle.Render(util.GetProfile())
+	rows := []string{titleStyle.Render(i18n.T("config.title")), profileLine, ""}
+	for i, option := range p.options {
+		prefix := "  "
+		style := keyStyle
+		if i == p.cursor {
+			prefix = "> "
+			style = activeKeyStyle
+		}
+
+		value := p.getOptionValue(option)
+		if value == "" {
+			value = i18n.T("config.notSet")
+		}
+		if _, ok := p.pending[option.Key]; ok {
+			value += i18n.T("config.restartRequired")
+		}
+
+		row := prefix + style.Render(option.Key+":") + " " + valueStyle.Render(value)
+		row = lipgloss.NewStyle().MaxWidth(w - util.DefaultElementsPadding).Render(row)
+		rows = append(rows, zone.Mark(configOptionZone(i), row))
+	}
+
+	selected := p.options[p.cursor]
+	description := selected.Description
+	if selected.RequiresRestart {
+		description += i18n.T("config.appliedAfterRestart")
+	}
+	if p.isEditingProfile {
+		description = i18n.T("config.profileDescription")
+	}
+
+	rows = append(rows, "", descriptionStyle.Render(description))
+
+	if p.isEditing {
+		rows = append(rows, "", p.textInput.View())
+	}
+
+	if p.errorText != "" {
+		rows = append(rows, errorStyle.Render(p.errorText))
+	}
+
+	tips := util.HelpStyle.Render(
+		i18n.T("tips.navigate") + util.TipsSeparator +
+			i18n.T("tips.editSave") + util.TipsSeparator +
+			i18n.T("tips.profile") + util.TipsSeparator +
+			i18n.T("tips.closeCancel"))
+	content := lipgloss.JoinVertical(lipgloss.Left, rows...)
+	spacerHeight := h - lipgloss.Height(content) - lipgloss.Height(tips)
+	if spacerHeight > 0 {
+		content += strings.Repeat("\n", spacerHeight)
+	}
+
+	return zone.Mark("chat_pane", p.container.Width(w).Height(h).Render(
+		lipgloss.JoinVertical(lipgloss.Left, content, tips),
+	))
+}
+
+func configOptionZone(idx int) string {
+	return fmt.Sprintf("config_option_%d", idx)
+}
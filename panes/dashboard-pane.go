@@ -0,0 +1,282 @@
+package panes
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/sessions"
+	"github.com/BalanceBalls/nekot/util"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+// Max sessions listed in a section of the dashboard
+const dashboardSessionsLimit = 5
+
+type dashboardKeyMap struct {
+	up        key.Binding
+	down      key.Binding
+	choose    key.Binding
+	newChat   key.Binding
+	quickChat key.Binding
+	close     key.Binding
+}
+
+var defaultDashboardKeyMap = dashboardKeyMap{
+	up:        key.NewBinding(key.WithKeys(tea.KeyUp.String(), "k"), key.WithHelp("↑/k", "keys.previousItem")),
+	down:      key.NewBinding(key.WithKeys(tea.KeyDown.String(), "j"), key.WithHelp("↓/j", "keys.nextItem")),
+	choose:    key.NewBinding(key.WithKeys(tea.KeyEnter.String()), key.WithHelp("enter", "keys.open")),
+	newChat:   key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "keys.newChat")),
+	quickChat: key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "keys.quickChat")),
+	close:     key.NewBinding(key.WithKeys(tea.KeyEsc.String()), key.WithHelp("esc", "keys.resumeSession")),
+}
+
+type dashboardItem struct {
+	title     string
+	action    util.DashboardAction
+	sessionId int
+}
+
+type dashboardSection struct {
+	title string
+	items []dashboardItem
+}
+
+// Startup screen with quick actions, recent sessions and sessions with pinned messages.
+// Closing it resumes the session the app was opened with
+type DashboardPane struct {
+	sections       []dashboardSection
+	cursor         int
+	keyMap         dashboardKeyMap
+	colors         util.SchemeColors
+	container      lipgloss.Style
+	viewMode       util.ViewMode
+	sessionService *sessions.SessionService
+
+	terminalWidth  int
+	terminalHeight int
+}
+
+func NewDashboardPane(db *sql.DB, ctx context.Context) DashboardPane {
+	cfg, ok := config.FromContext(ctx)
+	if !ok {
+		util.Slog.Error("failed to extract config from context")
+		panic("No config found in context")
+	}
+
+	colors := cfg.ColorScheme.GetColors()
+	container := lipgloss.NewStyle().
+		Border(util.ThickBorder()).
+		BorderForeground(colors.ActiveTabBorderColor).
+		MarginRight(util.ChatPaneMarginRight)
+
+	return DashboardPane{
+		keyMap:         defaultDashboardKeyMap,
+		colors:         colors,
+		container:      container,
+		viewMode:       util.NormalMode,
+		sessionService: sessions.NewSessionService(db),
+		terminalWidth:  util.DefaultTerminalWidth,
+		terminalHeight: util.DefaultTerminalHeight,
+	}
+}
+
+func (p DashboardPane) SetColors(colors util.SchemeColors) DashboardPane {
+	p.colors = colors
+	p.container = p.container.BorderForeground(colors.ActiveTabBorderColor)
+	return p
+}
+
+// Loads the sessions, the current session is the one resumed on close
+func (p DashboardPane) Open(currentSessionId int, currentSessionName string) DashboardPane {
+	p.cursor = 0
+	p.sections = []dashboardSection{{
+		title: i18n.T("dashboard.actions"),
+		items: []dashboardItem{
+			{
+				title:  i18n.Tf("dashboard.resume", util.GetSessionName(currentSessionId, currentSessionName)),
+				action: util.ResumeSessionAction,
+			},
+			{title: i18n.T("dashboard.newChat"), action: util.NewChatAction},
+			{title: i18n.T("dashboard.quickChat"), action: util.QuickChatAction},
+		},
+	}}
+
+	allSessions, err := p.sessionService.GetAllSessions()
+	if err != nil {
+		util.Slog.Error("failed to load sessions for the dashboard", "error", err.Error())
+	}
+
+	recent := []dashboardItem{}
+	for _, session := range allSessions {
+		if len(recent) == dashboardSessionsLimit {
+			break
+		}
+		if session.ID != currentSessionId {
+			recent = append(recent, newSessionItem(session))
+		}
+	}
+	if len(recent) > 0 {
+		p.sections = append(p.sections, dashboardSection{title: i18n.T("dashboard.recent"), items: recent})
+	}
+
+	pinnedSessions, err := p.sessionService.GetPinnedSessions(dashboardSessionsLimit)
+	if err != nil {
+		util.Slog.Error("failed to load pinned sessions for the dashboard", "error", err.Error())
+	}
+
+	pinned := []dashboardItem{}
+	for _, session := range pinnedSessions {
+		pinned = append(pinned, newSessionItem(session))
+	}
+	if len(pinned) > 0 {
+		p.sections = append(p.sections, dashboardSection{title: i18n.T("dashboard.pinned"), items: pinned})
+	}
+
+	return p
+}
+
+func newSessionItem(session sessions.Session) dashboardItem {
+	return dashboardItem{
+		title:     util.GetSessionName(session.ID, session.SessionName),
+		action:    util.OpenSessionAction,
+		sessionId: session.ID,
+	}
+}
+
+func (p DashboardPane) Update(msg tea.Msg) (DashboardPane, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.terminalWidth = msg.Width
+		p.terminalHeight = msg.Height
+
+	case util.ViewModeChanged:
+		p.viewMode = msg.Mode
+
+	case tea.MouseMsg:
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			p.moveCursor(-1)
+		case tea.MouseButtonWheelDown:
+			p.moveCursor(1)
+		}
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, p.keyMap.close):
+			return p, util.SendDashboardActionChosenMsg(util.ResumeSessionAction, 0)
+
+		case key.Matches(msg, p.keyMap.newChat):
+			return p, util.SendDashboardActionChosenMsg(util.NewChatAction, 0)
+
+		case key.Matches(msg, p.keyMap.quickChat):
+			return p, util.SendDashboardActionChosenMsg(util.QuickChatAction, 0)
+
+		case key.Matches(msg, p.keyMap.up):
+			p.moveCursor(-1)
+
+		case key.Matches(msg, p.keyMap.down):
+			p.moveCursor(1)
+
+		case key.Matches(msg, p.keyMap.choose):
+			item, ok := p.getItem(p.cursor)
+			if !ok {
+				return p, nil
+			}
+			return p, util.SendDashboardActionChosenMsg(item.action, item.sessionId)
+		}
+	}
+
+	return p, nil
+}
+
+func (p *DashboardPane) moveCursor(delta int) {
+	p.cursor = max(0, min(p.cursor+delta, p.countItems()-1))
+}
+
+func (p DashboardPane) countItems() int {
+	count := 0
+	for _, section := range p.sections {
+		count += len(section.items)
+	}
+	return count
+}
+
+// Item at the position in the list of all sections
+func (p DashboardPane) getItem(index int) (dashboardItem, bool) {
+	for _, section := range p.sections {
+		if index < len(section.items) {
+			return section.items[index], true
+		}
+		index -= len(section.items)
+	}
+	return dashboardItem{}, false
+}
+
+func (p DashboardPane) View() string {
+	w, h := util.CalcChatPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
+
+	sectionStyle := lipgloss.NewStyle().Foreground(p.colors.HighlightColor)
+	titleStyle := lipgloss.NewStyle().Foreground(p.colors.DefaultTextColor)
+	activeTitleStyle := lipgloss.NewStyle().Foreground(p.colors.AccentColor).Bold(true)
+	rowStyle := lipgloss.NewStyle().MaxWidth(w - util.DefaultElementsPadding)
+
+	rows := []string{activeHeader.Render(i18n.T("dashboard.title"))}
+	index := 0
+	for _, section := range p.sections {
+		rows = append(rows, "", rowStyle.Render(sectionStyle.Render(util.ListHeadingDot+" "+section.title)))
+		for _, item := range section.items {
+			prefix := "  "
+			style := titleStyle
+			if index == p.cursor {
+				prefix = "> "
+				style = activeTitleStyle
+			}
+			rows = append(rows, rowStyle.Render(prefix+style.Render(item.title)))
+			index++
+		}
+	}
+
+	tips := util.HelpStyle.Render(util.RenderKeyHints([]key.Binding{
+		p.keyMap.down,
+		p.keyMap.choose,
+		p.keyMap.newChat,
+		p.keyMap.quickChat,
+		p.keyMap.close,
+	}))
+
+	// rows that don't fit are cut from the top, so the cursor stays visible
+	listHeight := max(h-lipgloss.Height(tips), 1)
+	offset := max(0, p.getCursorRow()-listHeight+1)
+	rows = rows[offset:min(len(rows), offset+listHeight)]
+
+	content := lipgloss.JoinVertical(lipgloss.Left, rows...)
+	spacerHeight := h - lipgloss.Height(content) - lipgloss.Height(tips)
+	if spacerHeight > 0 {
+		content += strings.Repeat("\n", spacerHeight)
+	}
+
+	return zone.Mark("chat_pane", p.container.Width(w).Height(h).Render(
+		lipgloss.JoinVertical(lipgloss.Left, content, tips),
+	))
+}
+
+// Row of the item under the cursor: the header, then a blank line and a heading before every section
+func (p DashboardPane) getCursorRow() int {
+	row := 1
+	index := 0
+	for _, section := range p.sections {
+		row += 2
+		if p.cursor < index+len(section.items) {
+			return row + p.cursor - index
+		}
+		row += len(section.items)
+		index += len(section.items)
+	}
+	return 0
+}
@@ -0,0 +1,241 @@
+package panes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/util"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+type errorPaneKeyMap struct {
+	up    key.Binding
+	down  key.Binding
+	copy  key.Binding
+	close key.Binding
+}
+
+var defaultErrorPaneKeyMap = errorPaneKeyMap{
+	up:    key.NewBinding(key.WithKeys("k", tea.KeyUp.String()), key.WithHelp("k", "keys.scrollUp")),
+	down:  key.NewBinding(key.WithKeys("j", tea.KeyDown.String()), key.WithHelp("j", "keys.scrollDown")),
+	copy:  key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "keys.copyErrorReport")),
+	close: key.NewBinding(key.WithKeys(tea.KeyEsc.String(), "ctrl+f"), key.WithHelp("esc", "keys.closeError")),
+}
+
+// Shows details of the last error: request details for provider errors, message for the rest
+type ErrorPane struct {
+	event      util.ErrorEvent
+	occurredAt time.Time
+	offset     int
+	keyMap     errorPaneKeyMap
+	colors     util.SchemeColors
+	container  lipgloss.Style
+	viewMode   util.ViewMode
+
+	terminalWidth  int
+	terminalHeight int
+}
+
+func NewErrorPane(ctx context.Context) ErrorPane {
+	cfg, ok := config.FromContext(ctx)
+	if !ok {
+		util.Slog.Error("failed to extract config from context")
+		panic("No config found in context")
+	}
+
+	colors := cfg.ColorScheme.GetColors()
+	container := lipgloss.NewStyle().
+		Border(util.ThickBorder()).
+		BorderForeground(colors.ErrorColor).
+		MarginRight(util.ChatPaneMarginRight)
+
+	return ErrorPane{
+		keyMap:         defaultErrorPaneKeyMap,
+		colors:         colors,
+		container:      container,
+		viewMode:       util.NormalMode,
+		terminalWidth:  util.DefaultTerminalWidth,
+		terminalHeight: util.DefaultTerminalHeight,
+	}
+}
+
+func (p ErrorPane) SetColors(colors util.SchemeColors) ErrorPane {
+	p.colors = colors
+	p.container = p.container.BorderForeground(colors.ErrorColor)
+	return p
+}
+
+func (p ErrorPane) SetError(event util.ErrorEvent, occurredAt time.Time) ErrorPane {
+	p.event = event
+	p.occurredAt = occurredAt
+	p.offset = 0
+	return p
+}
+
+func (p ErrorPane) HasError() bool {
+	return p.event.Message != ""
+}
+
+func (p ErrorPane) Update(msg tea.Msg) (ErrorPane, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.terminalWidth = msg.Width
+		p.terminalHeight = msg.Height
+
+	case util.ViewModeChanged:
+		p.viewMode = msg.Mode
+
+	case tea.MouseMsg:
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			p.scroll(-1)
+		case tea.MouseButtonWheelDown:
+			p.scroll(1)
+		}
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, p.keyMap.close):
+			return p, util.ToggleErrorInspector(false)
+
+		case key.Matches(msg, p.keyMap.copy):
+			err := util.CopyToClipboard(p.errorReport())
+			if err != nil {
+				util.Slog.Error("failed to copy error report", "error", err.Error())
+				return p, util.SendToastMsg(i18n.T("notification.copyReportFailed"), util.ErrorSeverity)
+			}
+			return p, util.SendNotificationMsg(util.CopiedNotification)
+
+		case key.Matches(msg, p.keyMap.up):
+			p.scroll(-1)
+
+		case key.Matches(msg, p.keyMap.down):
+			p.scroll(1)
+		}
+	}
+
+	return p, nil
+}
+
+func (p *ErrorPane) scroll(delta int) {
+	p.offset = max(0, p.offset+delta)
+}
+
+func (p ErrorPane) getFields() [][2]string {
+	fields := [][2]string{
+		{"time", p.occurredAt.Format(time.DateTime)},
+	}
+
+	details := p.event.Details
+	if details != nil {
+		status := ""
+		if details.StatusCode != 0 {
+			status = fmt.Sprintf("%d %s", details.StatusCode, http.StatusText(details.StatusCode))
+		}
+
+		fields = append(fields,
+			[2]string{"provider", details.Provider},
+			[2]string{"model", details.Model},
+			[2]string{"status", status},
+			[2]string{"request id", details.RequestId},
+		)
+	}
+
+	return append(fields, [2]string{"message", p.event.Message})
+}
+
+func (p ErrorPane) getResponseBody() string {
+	if p.event.Details == nil || p.event.Details.Body == "" {
+		return ""
+	}
+
+	body := p.event.Details.Body
+	var formatted bytes.Buffer
+	if json.Indent(&formatted, []byte(body), "", "  ") == nil {
+		body = formatted.String()
+	}
+	return body
+}
+
+// Plain text report, meant to be pasted into a bug report
+func (p ErrorPane) errorReport() string {
+	report := strings.Builder{}
+	report.WriteString("nekot error report\n")
+	report.WriteString(fmt.Sprintf("os: %s/%s\n", runtime.GOOS, runtime.GOARCH))
+
+	for _, field := range p.getFields() {
+		value := field[1]
+		if value == "" {
+			value = "n/a"
+		}
+		report.WriteString(field[0] + ": " + value + "\n")
+	}
+
+	if body := p.getResponseBody(); body != "" {
+		report.WriteString("response body:\n" + body + "\n")
+	}
+
+	return report.String()
+}
+
+func (p ErrorPane) View() string {
+	w, h := util.CalcChatPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
+
+	keyStyle := lipgloss.NewStyle().Foreground(p.colors.MainColor)
+	valueStyle := lipgloss.NewStyle().Foreground(p.colors.DefaultTextColor)
+	bodyStyle := lipgloss.NewStyle().
+		Foreground(p.colors.HighlightColor).
+		PaddingLeft(util.ListItemPaddingLeft).
+		Width(w - util.DefaultElementsPadding)
+
+	lines := []string{}
+	for _, field := range p.getFields() {
+		value := field[1]
+		if value == "" {
+			value = "n/a"
+		}
+
+		row := "  " + keyStyle.Render(field[0]+":") + " " + valueStyle.Render(value)
+		row = lipgloss.NewStyle().Width(w - util.DefaultElementsPadding).Render(row)
+		lines = append(lines, strings.Split(row, "\n")...)
+	}
+
+	if body := p.getResponseBody(); body != "" {
+		lines = append(lines, "", "  "+keyStyle.Render("response body:"))
+		lines = append(lines, strings.Split(bodyStyle.Render(body), "\n")...)
+	}
+
+	tips := util.HelpStyle.Render(
+		i18n.T("tips.scroll") + util.TipsSeparator +
+			i18n.T("tips.copyReport") + util.TipsSeparator +
+			i18n.T("tips.close"))
+
+	header := []string{activeHeader.Render(i18n.T("errorDetails.title")), ""}
+	contentHeight := max(h-lipgloss.Height(tips)-len(header), 0)
+	offset := min(p.offset, max(len(lines)-contentHeight, 0))
+	lines = lines[offset:]
+	if len(lines) > contentHeight {
+		lines = lines[:contentHeight]
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left, append(header, lines...)...)
+	spacerHeight := h - lipgloss.Height(content) - lipgloss.Height(tips)
+	if spacerHeight > 0 {
+		content += strings.Repeat("\n", spacerHeight)
+	}
+
+	return zone.Mark("chat_pane", p.container.Width(w).Height(h).Render(
+		lipgloss.JoinVertical(lipgloss.Left, content, tips),
+	))
+}
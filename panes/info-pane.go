@@ -4,35 +4,29 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/i18n"
 	"github.com/BalanceBalls/nekot/sessions"
 	"github.com/BalanceBalls/nekot/settings"
 	"github.com/BalanceBalls/nekot/util"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
 )
 
-const notificationDisplayDurationSec = 2
-
-const (
-	copiedLabelText           = "Copied to clipboard"
-	cancelledLabelText        = "Inference interrupted"
-	sysPromptChangedLabelText = "System prompt updated"
-	presetSavedLabelText      = "Preset saved"
-	sessionSavedLableText     = "Session saved"
-	sessionExportedLabelText  = "Session exported"
-	idleLabelText             = "IDLE"
-	processingLabelText       = "Processing"
-)
+// Info pane has two rows, newer toasts push older ones down
+const maxVisibleToasts = 2
 
 var infoSpinnerStyle = lipgloss.NewStyle()
 var defaultLabelStyle = lipgloss.NewStyle().
 	BorderLeft(true).
-	BorderStyle(lipgloss.InnerHalfBlockBorder()).
+	BorderStyle(util.HalfBlockBorder()).
 	Bold(true).
 	MarginRight(1).
 	PaddingRight(1).
@@ -52,14 +46,25 @@ type InfoPane struct {
 	notificationLabel     lipgloss.Style
 	quickChatLabel        lipgloss.Style
 	webSearchLabel        lipgloss.Style
-
-	mu               *sync.RWMutex
-	showNotification bool
-	notification     util.Notification
-	isProcessing     bool
-	processingState  util.ProcessingState
-	terminalWidth    int
-	terminalHeight   int
+	updateLabel           lipgloss.Style
+	translationLabel      lipgloss.Style
+	readOnlyLabel         lipgloss.Style
+	macroLabel            lipgloss.Style
+	statusBar             lipgloss.Style
+	statusBarAccent       lipgloss.Style
+
+	provider             string
+	availableUpdate      string
+	mu                   *sync.RWMutex
+	toasts               []util.Toast
+	history              []util.Toast
+	nextToastId          int
+	notificationDuration time.Duration
+	isProcessing         bool
+	isRecordingMacro     bool
+	processingState      util.ProcessingState
+	terminalWidth        int
+	terminalHeight       int
 }
 
 func NewInfoPane(db *sql.DB, ctx context.Context) InfoPane {
@@ -73,59 +78,86 @@ func NewInfoPane(db *sql.DB, ctx context.Context) InfoPane {
 	colors := config.ColorScheme.GetColors()
 	spinner := initInfoSpinner()
 
+	pane := InfoPane{
+		provider:             config.Provider,
+		notificationDuration: config.GetNotificationDuration(),
+		spinner:              spinner,
+		sessionService:       ss,
+		terminalWidth:        util.DefaultTerminalWidth,
+		terminalHeight:       util.DefaultTerminalHeight,
+
+		mu: &sync.RWMutex{},
+	}
+	return pane.SetColors(colors)
+}
+
+// Rebuilds the labels with the colors of a new color scheme
+func (p InfoPane) SetColors(colors util.SchemeColors) InfoPane {
 	infoSpinnerStyle = infoSpinnerStyle.Foreground(colors.HighlightColor)
-	processingIdleLabel := defaultLabelStyle.
+	p.spinner.Style = infoSpinnerStyle
+	// package styles are created before the ASCII mode is set
+	defaultLabelStyle = defaultLabelStyle.BorderStyle(util.HalfBlockBorder())
+	p.processingIdleLabel = defaultLabelStyle.
 		BorderLeftForeground(colors.HighlightColor).
 		Foreground(colors.DefaultTextColor)
-	processingActiveLabel := defaultLabelStyle.
+	p.processingActiveLabel = defaultLabelStyle.
 		BorderLeftForeground(colors.AccentColor).
 		Foreground(colors.DefaultTextColor)
-	promptTokensLablel := defaultLabelStyle.
+	p.promptTokensLablel = defaultLabelStyle.
 		BorderLeftForeground(colors.ActiveTabBorderColor).
 		Foreground(colors.DefaultTextColor)
-	completionTokensLabel := defaultLabelStyle.
+	p.completionTokensLabel = defaultLabelStyle.
 		BorderLeftForeground(colors.ActiveTabBorderColor).
 		Foreground(colors.DefaultTextColor)
-	notificationLabel := defaultLabelStyle.
+	p.notificationLabel = defaultLabelStyle.
 		Background(colors.NormalTabBorderColor).
 		BorderLeftForeground(colors.HighlightColor).
 		Align(lipgloss.Left).
 		Foreground(lipgloss.Color(colors.DefaultTextColor.Dark))
-	quickChatLabel := defaultLabelStyle.
+	p.quickChatLabel = defaultLabelStyle.
 		Background(colors.HighlightColor).
 		Foreground(lipgloss.Color(colors.DefaultTextColor.Dark))
-	webSearchLabel := defaultLabelStyle.
+	p.webSearchLabel = defaultLabelStyle.
 		Background(colors.ErrorColor).
 		Foreground(lipgloss.Color(colors.DefaultTextColor.Dark))
+	p.updateLabel = defaultLabelStyle.
+		Background(colors.AccentColor).
+		Foreground(lipgloss.Color(colors.DefaultTextColor.Dark))
+	p.translationLabel = defaultLabelStyle.
+		Background(colors.NormalTabBorderColor).
+		Foreground(lipgloss.Color(colors.DefaultTextColor.Dark))
+	p.readOnlyLabel = defaultLabelStyle.
+		Background(colors.ActiveTabBorderColor).
+		Foreground(lipgloss.Color(colors.DefaultTextColor.Dark))
+	p.macroLabel = defaultLabelStyle.
+		Background(colors.AccentColor).
+		Foreground(lipgloss.Color(colors.DefaultTextColor.Dark))
 
-	return InfoPane{
-		processingIdleLabel:   processingIdleLabel,
-		processingActiveLabel: processingActiveLabel,
-		promptTokensLablel:    promptTokensLablel,
-		completionTokensLabel: completionTokensLabel,
-		notificationLabel:     notificationLabel,
-		quickChatLabel:        quickChatLabel,
-		webSearchLabel:        webSearchLabel,
-
-		spinner:        spinner,
-		colors:         colors,
-		sessionService: ss,
-		terminalWidth:  util.DefaultTerminalWidth,
-		terminalHeight: util.DefaultTerminalHeight,
-
-		mu: &sync.RWMutex{},
-	}
+	p.statusBar = lipgloss.NewStyle().
+		Foreground(colors.DefaultTextColor).
+		PaddingLeft(1)
+	p.statusBarAccent = lipgloss.NewStyle().
+		Foreground(colors.AccentColor).
+		Bold(true)
+	p.colors = colors
+	return p
 }
 
 func initInfoSpinner() spinner.Model {
 	s := spinner.New()
 	s.Spinner = spinner.Ellipsis
+	if util.IsReducedMotion() {
+		// the spinner is not ticked, so it stays on this frame
+		s.Spinner = spinner.Spinner{Frames: []string{"..."}}
+	}
 	s.Style = infoSpinnerStyle
 
 	return s
 }
 
-type tickMsg struct{}
+type toastExpiredMsg struct {
+	id int
+}
 
 func (p InfoPane) Init() tea.Cmd {
 	return nil
@@ -148,17 +180,50 @@ func (p InfoPane) Update(msg tea.Msg) (InfoPane, tea.Cmd) {
 	case sessions.UpdateCurrentSession:
 		p.currentSession = msg.Session
 
+	case sessions.SessionTranslationToggled:
+		if msg.ID == p.currentSession.ID {
+			p.currentSession.TranslationEnabled = msg.Enabled
+		}
+
+	case sessions.SessionReadOnlyToggled:
+		if msg.ID == p.currentSession.ID {
+			p.currentSession.ReadOnly = msg.Enabled
+		}
+
 	case spinner.TickMsg:
 		p.spinner, cmd = p.spinner.Update(msg)
 		cmds = append(cmds, cmd)
 
 	case util.NotificationMsg:
-		p.notification = msg.Notification
-		p.showNotification = true
-		cmds = append(cmds, tickAfter(notificationDisplayDurationSec))
+		text, severity := getNotificationToast(msg.Notification)
+		if text != "" {
+			cmds = append(cmds, p.pushToast(text, severity))
+		}
 
-	case tickMsg:
-		p.showNotification = false
+	case util.ToastMsg:
+		cmds = append(cmds, p.pushToast(msg.Text, msg.Severity))
+
+	case util.InferenceCancelled:
+		text, severity := getNotificationToast(util.CancelledNotification)
+		if msg.Usage.Completion > 0 {
+			text += i18n.Tf("notification.cancelledTokens", msg.Usage.Completion)
+		}
+		cmds = append(cmds, p.pushToast(text, severity))
+
+	case util.ErrorEvent:
+		cmds = append(cmds, p.pushToast(msg.Message, util.ErrorSeverity))
+
+	case util.UpdateAvailable:
+		p.availableUpdate = msg.Version
+		cmds = append(cmds, p.pushToast(i18n.Tf("notification.updateAvailable", msg.Version), util.InfoSeverity))
+
+	case toastExpiredMsg:
+		p.toasts = slices.DeleteFunc(p.toasts, func(t util.Toast) bool {
+			return t.Id == msg.id
+		})
+
+	case config.ConfigUpdated:
+		p.notificationDuration = msg.Config.GetNotificationDuration()
 
 	case util.ProcessingStateChanged:
 		p.mu.Lock()
@@ -172,13 +237,16 @@ func (p InfoPane) Update(msg tea.Msg) (InfoPane, tea.Cmd) {
 				util.MakeErrorMsg(err.Error())
 			}
 			p.currentSession = session
-		} else {
+		} else if !util.IsReducedMotion() {
 			cmds = append(cmds, p.spinner.Tick)
 		}
 
 	case settings.UpdateSettingsEvent:
 		p.currentSettings = msg.Settings
 
+	case util.MacroRecordingChanged:
+		p.isRecordingMacro = msg.IsRecording
+
 	}
 
 	return p, tea.Batch(cmds...)
@@ -186,18 +254,31 @@ func (p InfoPane) Update(msg tea.Msg) (InfoPane, tea.Cmd) {
 
 func (p InfoPane) View() string {
 	paneWidth, _ := util.CalcSettingsPaneSize(p.terminalWidth, p.terminalHeight)
+	if util.IsScreenReaderMode() {
+		return p.renderPlainView(paneWidth)
+	}
+
 	var processingLabel string
 	if p.isProcessing {
 		processingLabel = p.processingActiveLabel.Render(p.getProcessingStateText() + p.spinner.View())
 	} else {
-		processingLabel = p.processingIdleLabel.Render(idleLabelText)
+		processingLabel = p.processingIdleLabel.Render(i18n.T("state.idleLabel"))
+	}
+
+	// Stats estimated with the local tokenizer are marked, as they may differ from the provider count
+	estimateMarker := ""
+	if p.currentSession.UsageEstimated {
+		estimateMarker = "≈"
+		if util.IsAsciiMode() {
+			estimateMarker = "~"
+		}
 	}
 
 	promptTokensLablel := p.promptTokensLablel.Render(
-		fmt.Sprintf("IN: %d", p.currentSession.PromptTokens),
+		fmt.Sprintf("IN: %s%d", estimateMarker, p.currentSession.PromptTokens),
 	)
 	completionTokensLabel := p.completionTokensLabel.Render(
-		fmt.Sprintf("OUT: %d", p.currentSession.CompletionTokens),
+		fmt.Sprintf("OUT: %s%d", estimateMarker, p.currentSession.CompletionTokens),
 	)
 
 	quickChatLabel := ""
@@ -207,7 +288,27 @@ func (p InfoPane) View() string {
 
 	webSearchLabel := ""
 	if p.currentSettings.WebSearchEnabled {
-		webSearchLabel = p.webSearchLabel.Render("W")
+		webSearchLabel = zone.Mark("info_web_search", p.webSearchLabel.Render("W"))
+	}
+
+	translationLabel := ""
+	if p.currentSession.TranslationEnabled {
+		translationLabel = p.translationLabel.Render("T")
+	}
+
+	readOnlyLabel := ""
+	if p.currentSession.ReadOnly {
+		readOnlyLabel = p.readOnlyLabel.Render("R")
+	}
+
+	macroLabel := ""
+	if p.isRecordingMacro {
+		macroLabel = p.macroLabel.Render("M")
+	}
+
+	updateLabel := ""
+	if p.availableUpdate != "" {
+		updateLabel = p.updateLabel.Render("U")
 	}
 
 	firstRow := processingLabel
@@ -217,55 +318,32 @@ func (p InfoPane) View() string {
 		completionTokensLabel,
 		quickChatLabel,
 		webSearchLabel,
+		translationLabel,
+		readOnlyLabel,
+		macroLabel,
+		updateLabel,
 	)
 
-	if p.showNotification {
-		notificationLabel := lipgloss.NewStyle()
-		notificationText := ""
-
-		switch p.notification {
-		case util.SessionSavedNotification:
-			notificationText = sessionSavedLableText
-			notificationLabel = p.notificationLabel.
-				Background(p.colors.AccentColor).
-				Width(paneWidth - 1)
-		case util.SessionExportedNotification:
-			notificationText = sessionExportedLabelText
-			notificationLabel = p.notificationLabel.
-				Background(p.colors.AccentColor).
-				Width(paneWidth - 1)
-		case util.PresetSavedNotification:
-			notificationText = presetSavedLabelText
-			notificationLabel = p.notificationLabel.
-				Background(p.colors.AccentColor).
-				Width(paneWidth - 1)
-		case util.SysPromptChangedNotification:
-			notificationText = sysPromptChangedLabelText
-			notificationLabel = p.notificationLabel.
-				Background(p.colors.AccentColor).
-				Width(paneWidth - 1)
-		case util.CopiedNotification:
-			notificationText = copiedLabelText
-			notificationLabel = p.notificationLabel.
-				Background(p.colors.NormalTabBorderColor).
-				Width(paneWidth - 1)
-		case util.CancelledNotification:
-			notificationText = cancelledLabelText
-			notificationLabel = p.notificationLabel.
-				Background(p.colors.ErrorColor).
-				Width(paneWidth - 1)
+	if len(p.toasts) > 0 {
+		rows := []string{}
+		for i := len(p.toasts) - 1; i >= 0 && len(rows) < maxVisibleToasts; i-- {
+			toast := p.toasts[i]
+			rows = append(rows, zone.Mark("info_notifications", p.notificationLabel.
+				Background(p.getSeverityColor(toast.Severity)).
+				Width(paneWidth-1).
+				MaxHeight(1).
+				Render(toast.Text)))
 		}
 
-		firstRow = lipgloss.JoinHorizontal(
-			lipgloss.Left,
-			notificationLabel.Render(notificationText),
-		)
-
+		firstRow = rows[0]
 		secondRow = ""
+		if len(rows) > 1 {
+			secondRow = rows[1]
+		}
 	}
 
-	return lipgloss.NewStyle().
-		BorderStyle(lipgloss.ThickBorder()).
+	return zone.Mark("info_pane", lipgloss.NewStyle().
+		BorderStyle(util.ThickBorder()).
 		BorderForeground(p.colors.NormalTabBorderColor).
 		Width(paneWidth).
 		Render(
@@ -274,29 +352,216 @@ func (p InfoPane) View() string {
 				firstRow,
 				secondRow,
 			),
-		)
+		))
 }
 
-func tickAfter(seconds int) tea.Cmd {
-	return tea.Tick(time.Second*time.Duration(seconds), func(t time.Time) tea.Msg {
-		return tickMsg{}
+// Screen reader mode: the spinner and the colored letters are replaced with words,
+// the latest toast is prefixed with its severity, so every change is read as a plain line
+func (p InfoPane) renderPlainView(paneWidth int) string {
+	firstRow := i18n.Tf("screenReader.status", i18n.T("state.idle"))
+	if p.isProcessing {
+		firstRow = i18n.Tf("screenReader.status", p.getProcessingStateText())
+	}
+
+	if len(p.toasts) > 0 {
+		latest := p.toasts[len(p.toasts)-1]
+		firstRow = i18n.T(severityNames[latest.Severity]) + ": " + latest.Text
+	}
+
+	stats := []string{
+		fmt.Sprintf("IN: %d", p.currentSession.PromptTokens),
+		fmt.Sprintf("OUT: %d", p.currentSession.CompletionTokens),
+	}
+	if p.currentSession.UsageEstimated {
+		stats = append(stats, i18n.T("screenReader.estimated"))
+	}
+	if p.currentSession.IsTemporary {
+		stats = append(stats, i18n.T("screenReader.quickChat"))
+	}
+	if p.currentSettings.WebSearchEnabled {
+		stats = append(stats, i18n.T("screenReader.webSearch"))
+	}
+	if p.currentSession.TranslationEnabled {
+		stats = append(stats, i18n.T("screenReader.translation"))
+	}
+	if p.currentSession.ReadOnly {
+		stats = append(stats, i18n.T("screenReader.readOnly"))
+	}
+	if p.isRecordingMacro {
+		stats = append(stats, i18n.T("screenReader.macro"))
+	}
+	if p.availableUpdate != "" {
+		stats = append(stats, i18n.T("screenReader.update"))
+	}
+
+	row := lipgloss.NewStyle().Width(paneWidth - 1).MaxHeight(1)
+	return zone.Mark("info_pane", lipgloss.NewStyle().
+		BorderStyle(util.ThickBorder()).
+		BorderForeground(p.colors.NormalTabBorderColor).
+		Width(paneWidth).
+		Render(
+			lipgloss.JoinVertical(
+				lipgloss.Left,
+				row.Render(firstRow),
+				row.Render(strings.Join(stats, ", ")),
+			),
+		))
+}
+
+var viewModeNames = map[util.ViewMode]string{
+	util.NormalMode:     "status.mode.normal",
+	util.ZenMode:        "status.mode.zen",
+	util.TextEditMode:   "status.mode.editor",
+	util.FilePickerMode: "status.mode.filePicker",
+}
+
+var paneNames = map[util.Pane]string{
+	util.PromptPane:   "pane.prompt",
+	util.ChatPane:     "pane.chat",
+	util.SettingsPane: "pane.settings",
+	util.SessionsPane: "pane.sessions",
+}
+
+// A single line that is always visible, even when the info pane is hidden.
+// Hints of the focused pane are shown last, so they are the first to be cut in narrow terminals
+func (p InfoPane) StatusBarView(viewMode util.ViewMode, focused util.Pane, hints string) string {
+	webSearch := i18n.T("status.off")
+	if p.currentSettings.WebSearchEnabled && !util.IsOfflineMode() {
+		webSearch = i18n.T("status.on")
+	}
+
+	processing := p.getProcessingStateText()
+	if p.isProcessing {
+		processing += p.spinner.View()
+	}
+
+	mode := i18n.T(viewModeNames[viewMode])
+	if util.IsDemoMode() {
+		mode += " " + i18n.T("status.demo")
+	}
+
+	if util.IsOfflineMode() {
+		mode += " " + i18n.T("status.offline")
+	}
+
+	if p.currentSession.TranslationEnabled {
+		mode += " " + i18n.T("status.translation")
+	}
+
+	if p.currentSession.ReadOnly {
+		mode += " " + i18n.T("status.readOnly")
+	}
+
+	// clickable items are marked, see MainView.handleIndicatorClick
+	items := []string{
+		zone.Mark("status_help", p.statusBarAccent.Render("?")),
+		p.statusBarAccent.Render(mode),
+		i18n.Tf("status.focus", i18n.T(paneNames[focused])),
+		p.provider + ": " + p.currentSettings.Model,
+		zone.Mark("status_web_search", i18n.Tf("status.webSearch", webSearch)),
+		processing,
+	}
+
+	if p.availableUpdate != "" {
+		items = append(items, p.statusBarAccent.Render(i18n.Tf("status.update", p.availableUpdate)))
+	}
+
+	if len(p.toasts) > 0 {
+		latest := p.toasts[len(p.toasts)-1]
+		items = append(items, zone.Mark("status_notifications", lipgloss.NewStyle().
+			Foreground(p.getSeverityColor(latest.Severity)).
+			Render(latest.Text)))
+	}
+
+	if hints != "" {
+		items = append(items, lipgloss.NewStyle().Foreground(util.SubduedColor).Render(hints))
+	}
+
+	return p.statusBar.
+		MaxWidth(p.terminalWidth).
+		Render(strings.Join(items, util.TipsSeparator))
+}
+
+// Past notifications, oldest first
+func (p InfoPane) NotificationHistory() []util.Toast {
+	return slices.Clone(p.history)
+}
+
+func (p *InfoPane) pushToast(text string, severity util.Severity) tea.Cmd {
+	p.nextToastId++
+	toast := util.Toast{
+		Id:        p.nextToastId,
+		Text:      text,
+		Severity:  severity,
+		CreatedAt: time.Now(),
+	}
+
+	p.toasts = append(p.toasts, toast)
+	p.history = append(p.history, toast)
+	if len(p.history) > util.MaxNotificationHistory {
+		p.history = p.history[len(p.history)-util.MaxNotificationHistory:]
+	}
+
+	return tea.Tick(p.notificationDuration, func(t time.Time) tea.Msg {
+		return toastExpiredMsg{id: toast.Id}
 	})
 }
 
+func (p InfoPane) getSeverityColor(severity util.Severity) lipgloss.AdaptiveColor {
+	return getSeverityColor(p.colors, severity)
+}
+
+func getSeverityColor(colors util.SchemeColors, severity util.Severity) lipgloss.AdaptiveColor {
+	switch severity {
+	case util.SuccessSeverity:
+		return colors.AccentColor
+	case util.WarningSeverity:
+		return colors.HighlightColor
+	case util.ErrorSeverity:
+		return colors.ErrorColor
+	default:
+		return colors.NormalTabBorderColor
+	}
+}
+
+func getNotificationToast(notification util.Notification) (string, util.Severity) {
+	switch notification {
+	case util.SessionSavedNotification:
+		return i18n.T("notification.sessionSaved"), util.SuccessSeverity
+	case util.SessionExportedNotification:
+		return i18n.T("notification.sessionExported"), util.SuccessSeverity
+	case util.NoteSavedNotification:
+		return i18n.T("notification.noteSaved"), util.SuccessSeverity
+	case util.ConfigSavedNotification:
+		return i18n.T("notification.configSaved"), util.SuccessSeverity
+	case util.PresetSavedNotification:
+		return i18n.T("notification.presetSaved"), util.SuccessSeverity
+	case util.SysPromptChangedNotification:
+		return i18n.T("notification.sysPromptChanged"), util.SuccessSeverity
+	case util.CancelledNotification:
+		return i18n.T("notification.cancelled"), util.WarningSeverity
+	case util.CopiedNotification:
+		return i18n.T("notification.copied"), util.InfoSeverity
+	default:
+		util.Slog.Warn("no toast for the notification", "notification", notification)
+		return "", util.InfoSeverity
+	}
+}
+
 func (p InfoPane) getProcessingStateText() string {
 	switch p.processingState {
 	case util.AwaitingFinalization:
-		return "Finishing"
+		return i18n.T("state.finishing")
 	case util.AwaitingToolCallResult:
-		return "Calling tools"
+		return i18n.T("state.callingTools")
 	case util.Error:
-		return "Error"
+		return i18n.T("state.error")
 	case util.Finalized:
-		return "Done"
+		return i18n.T("state.done")
 	case util.Idle:
-		return "Idle"
+		return i18n.T("state.idle")
 	case util.ProcessingChunks:
-		return "Processing"
+		return i18n.T("state.processing")
 	default:
 		panic(fmt.Sprintf("unexpected util.ProcessingState: %#v", p.processingState))
 	}
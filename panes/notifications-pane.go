@@ -0,0 +1,163 @@
+package panes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/util"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+type notificationsPaneKeyMap struct {
+	up    key.Binding
+	down  key.Binding
+	close key.Binding
+}
+
+var defaultNotificationsPaneKeyMap = notificationsPaneKeyMap{
+	up:    key.NewBinding(key.WithKeys("k", tea.KeyUp.String()), key.WithHelp("k", "keys.scrollUp")),
+	down:  key.NewBinding(key.WithKeys("j", tea.KeyDown.String()), key.WithHelp("j", "keys.scrollDown")),
+	close: key.NewBinding(key.WithKeys(tea.KeyEsc.String(), "ctrl+y"), key.WithHelp("esc", "keys.closeNotifications")),
+}
+
+var severityNames = map[util.Severity]string{
+	util.InfoSeverity:    "severity.info",
+	util.SuccessSeverity: "severity.success",
+	util.WarningSeverity: "severity.warning",
+	util.ErrorSeverity:   "severity.error",
+}
+
+type NotificationsPane struct {
+	history   []util.Toast
+	offset    int
+	keyMap    notificationsPaneKeyMap
+	colors    util.SchemeColors
+	container lipgloss.Style
+	viewMode  util.ViewMode
+
+	terminalWidth  int
+	terminalHeight int
+}
+
+func NewNotificationsPane(ctx context.Context) NotificationsPane {
+	cfg, ok := config.FromContext(ctx)
+	if !ok {
+		util.Slog.Error("failed to extract config from context")
+		panic("No config found in context")
+	}
+
+	colors := cfg.ColorScheme.GetColors()
+	container := lipgloss.NewStyle().
+		Border(util.ThickBorder()).
+		BorderForeground(colors.ActiveTabBorderColor).
+		MarginRight(util.ChatPaneMarginRight)
+
+	return NotificationsPane{
+		keyMap:         defaultNotificationsPaneKeyMap,
+		colors:         colors,
+		container:      container,
+		viewMode:       util.NormalMode,
+		terminalWidth:  util.DefaultTerminalWidth,
+		terminalHeight: util.DefaultTerminalHeight,
+	}
+}
+
+func (p NotificationsPane) SetColors(colors util.SchemeColors) NotificationsPane {
+	p.colors = colors
+	p.container = p.container.BorderForeground(colors.ActiveTabBorderColor)
+	return p
+}
+
+// Replaces displayed notifications, newest first
+func (p NotificationsPane) SetHistory(history []util.Toast) NotificationsPane {
+	p.history = make([]util.Toast, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		p.history = append(p.history, history[i])
+	}
+	p.offset = 0
+	return p
+}
+
+func (p NotificationsPane) Update(msg tea.Msg) (NotificationsPane, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.terminalWidth = msg.Width
+		p.terminalHeight = msg.Height
+
+	case util.ViewModeChanged:
+		p.viewMode = msg.Mode
+
+	case tea.MouseMsg:
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			p.scroll(-1)
+		case tea.MouseButtonWheelDown:
+			p.scroll(1)
+		}
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, p.keyMap.close):
+			return p, util.ToggleNotificationHistory(false)
+
+		case key.Matches(msg, p.keyMap.up):
+			p.scroll(-1)
+
+		case key.Matches(msg, p.keyMap.down):
+			p.scroll(1)
+		}
+	}
+
+	return p, nil
+}
+
+func (p *NotificationsPane) scroll(delta int) {
+	p.offset = max(0, min(p.offset+delta, len(p.history)-1))
+}
+
+func (p NotificationsPane) View() string {
+	w, h := util.CalcChatPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
+
+	timeStyle := lipgloss.NewStyle().Foreground(p.colors.HighlightColor)
+	textStyle := lipgloss.NewStyle().Foreground(p.colors.DefaultTextColor)
+	rowStyle := lipgloss.NewStyle().MaxWidth(w - util.DefaultElementsPadding)
+
+	rows := []string{activeHeader.Render(i18n.T("notifications.title")), ""}
+	if len(p.history) == 0 {
+		rows = append(rows, textStyle.Render("  "+i18n.T("notifications.empty")))
+	}
+
+	for _, toast := range p.history[p.offset:] {
+		severityStyle := lipgloss.NewStyle().
+			Foreground(getSeverityColor(p.colors, toast.Severity)).
+			Bold(true)
+		row := "  " + timeStyle.Render(toast.CreatedAt.Format("15:04:05")) + " " +
+			severityStyle.Render(i18n.T(severityNames[toast.Severity])+":") + " " +
+			textStyle.Render(strings.ReplaceAll(toast.Text, "\n", " "))
+		rows = append(rows, rowStyle.Render(row))
+	}
+
+	tips := util.HelpStyle.Render(
+		i18n.T("tips.scroll") + util.TipsSeparator +
+			i18n.T("tips.close"))
+
+	contentHeight := max(h-lipgloss.Height(tips), 0)
+	if len(rows) > contentHeight {
+		rows = rows[:contentHeight]
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left, rows...)
+	spacerHeight := h - lipgloss.Height(content) - lipgloss.Height(tips)
+	if spacerHeight > 0 {
+		content += strings.Repeat("\n", spacerHeight)
+	}
+
+	return zone.Mark("chat_pane", p.container.Width(w).Height(h).Render(
+		lipgloss.JoinVertical(lipgloss.Left, content, tips),
+	))
+}
@@ -0,0 +1,131 @@
+package panes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+// The chips row takes a row of the chat pane, the layout is recalculated when the row appears or disappears.
+// Sizes are checked once a file is attached, so files over the limit are reported before the prompt is sent
+func (p *PromptPane) syncAttachmentChips() tea.Cmd {
+	cmds := []tea.Cmd{}
+	for _, attachment := range p.attachments {
+		if _, ok := p.attachmentSizes[attachment.Path]; ok {
+			continue
+		}
+
+		size := int64(-1)
+		if info, err := os.Stat(attachment.Path); err == nil {
+			size = info.Size()
+		}
+		p.attachmentSizes[attachment.Path] = size
+
+		if p.isOversized(attachment) {
+			toast := i18n.Tf("prompt.attachmentTooLarge", filepath.Base(attachment.Path), p.maxAttachmentSizeMb)
+			cmds = append(cmds, util.SendToastMsg(toast, util.WarningSeverity))
+		}
+	}
+	p.attachmentCursor = max(0, min(p.attachmentCursor, len(p.attachments)-1))
+
+	isShown := len(p.attachments) != 0
+	if isShown != util.AreAttachmentChipsShown() {
+		util.SetAttachmentChipsShown(isShown)
+		width, height := p.terminalWidth, p.terminalHeight
+		cmds = append(cmds, func() tea.Msg {
+			return tea.WindowSizeMsg{Width: width, Height: height}
+		})
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// Same limit as the one applied when attachments are encoded for sending
+func (p PromptPane) isOversized(attachment util.Attachment) bool {
+	return p.attachmentSizes[attachment.Path] > int64(p.maxAttachmentSizeMb)*1024*1024
+}
+
+// Chips are selected with the keyboard outside of insert mode, so the keys don't end up in the prompt
+func (p PromptPane) canSelectAttachments() bool {
+	return p.isFocused &&
+		p.inputMode == util.PromptNormalMode &&
+		p.viewMode != util.FilePickerMode &&
+		len(p.attachments) != 0
+}
+
+func (p *PromptPane) removeAttachment(index int) {
+	if index < 0 || index >= len(p.attachments) {
+		return
+	}
+	p.attachments = slices.Delete(p.attachments, index, index+1)
+}
+
+// Removes the chip under the cursor, reports whether a chip was clicked
+func (p *PromptPane) handleChipClick(msg tea.MouseMsg) bool {
+	for i := range p.attachments {
+		if zone.Get(getChipZoneId(i)).InBounds(msg) {
+			p.removeAttachment(i)
+			return true
+		}
+	}
+	return false
+}
+
+func (p PromptPane) renderAttachmentChips() string {
+	w, _ := util.CalcPromptPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
+	chipStyle := lipgloss.NewStyle().
+		Foreground(p.colors.DefaultTextColor).
+		Background(p.colors.NormalTabBorderColor).
+		Padding(0, 1).
+		MarginRight(1)
+	selectedStyle := chipStyle.Background(p.colors.ActiveTabBorderColor)
+	warningStyle := chipStyle.Background(p.colors.ErrorColor)
+
+	closeMark := "×"
+	if util.IsAsciiMode() {
+		closeMark = "x"
+	}
+
+	isSelectable := p.isFocused && p.inputMode == util.PromptNormalMode
+	chips := []string{}
+	for i, attachment := range p.attachments {
+		label := attachment.Type + " " + filepath.Base(attachment.Path)
+		if size := p.attachmentSizes[attachment.Path]; size >= 0 {
+			label += " " + formatFileSize(size)
+		}
+
+		isSelected := isSelectable && i == p.attachmentCursor
+		style := chipStyle
+		switch {
+		case p.isOversized(attachment):
+			label += fmt.Sprintf(" > %d MB", p.maxAttachmentSizeMb)
+			style = warningStyle.Underline(isSelected)
+		case isSelected:
+			style = selectedStyle
+		}
+		chips = append(chips, zone.Mark(getChipZoneId(i), style.Render(label+" "+closeMark)))
+	}
+
+	return lipgloss.NewStyle().MaxWidth(w).Render(lipgloss.JoinHorizontal(lipgloss.Left, chips...))
+}
+
+func getChipZoneId(index int) string {
+	return fmt.Sprintf("attachment_chip_%d", index)
+}
+
+func formatFileSize(size int64) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	}
+	return fmt.Sprintf("%d B", size)
+}
@@ -0,0 +1,89 @@
+package panes
+
+import (
+	"strings"
+
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/util"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Prompt removed by the last clear, so it can be brought back
+type clearedPrompt struct {
+	prompt      string
+	attachments []util.Attachment
+}
+
+// Prompts longer than the configured length are cleared only after a confirmation
+func (p *PromptPane) keyClear() tea.Cmd {
+	if p.clearConfirmLength == 0 || len([]rune(p.getPromptValue())) <= p.clearConfirmLength {
+		p.clearPrompt()
+		return nil
+	}
+
+	p.spelling = nil
+	p.isConfirmingClear = true
+	if !p.isFocused {
+		return util.SwitchToPane(util.PromptPane)
+	}
+	return nil
+}
+
+func (p *PromptPane) clearPrompt() {
+	prompt := p.getPromptValue()
+	if strings.TrimSpace(prompt) != "" || len(p.attachments) != 0 {
+		p.lastCleared = &clearedPrompt{prompt: prompt, attachments: p.attachments}
+	}
+
+	p.attachments = []util.Attachment{}
+	switch p.viewMode {
+	case util.TextEditMode:
+		p.textEditor.Reset()
+	default:
+		p.input.Reset()
+	}
+}
+
+func (p *PromptPane) handleClearConfirmKeys(msg tea.KeyMsg) {
+	switch {
+	case key.Matches(msg, p.keys.clearConfirm):
+		p.isConfirmingClear = false
+		p.clearPrompt()
+
+	case key.Matches(msg, p.keys.clearCancel):
+		p.isConfirmingClear = false
+	}
+}
+
+// The current prompt takes the place of the restored one, so restoring twice brings it back
+func (p *PromptPane) keyRestoreCleared() tea.Cmd {
+	if !p.isFocused || p.lastCleared == nil || p.viewMode == util.FilePickerMode {
+		return nil
+	}
+
+	restored := p.lastCleared
+	p.lastCleared = nil
+	p.clearPrompt()
+	p.attachments = restored.attachments
+
+	if p.viewMode == util.TextEditMode {
+		p.textEditor.SetValue(restored.prompt)
+		return nil
+	}
+
+	if strings.Contains(restored.prompt, "\n") {
+		return util.SwitchToEditor(restored.prompt, util.NoOperaton, true)
+	}
+	p.input.SetValue(restored.prompt)
+	return nil
+}
+
+func (p PromptPane) renderClearConfirmation() string {
+	tips := util.HelpStyle.Render(util.RenderKeyHints([]key.Binding{
+		p.keys.clearConfirm,
+		p.keys.clearCancel,
+	}))
+	notice := i18n.Tf("prompt.clearConfirm", len([]rune(p.getPromptValue())))
+	return infoLabel.Render(infoPrefix.Render(notice)) + tips
+}
@@ -0,0 +1,93 @@
+package panes
+
+import (
+	"strings"
+
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/util"
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Language of a code block pasted from the clipboard. The block is inserted with the detected
+// language right away, the prompt only offers to change it
+type codeFenceLanguage struct {
+	before string
+	code   string
+	input  textinput.Model
+}
+
+func getCodeBlock(lang, code string) string {
+	return "\n```" + lang + "\n" + code + "\n```\n"
+}
+
+// A language typed on the last line of the editor is used as is,
+// otherwise it is detected from the pasted code
+func (p *PromptPane) insertBufferContentAsCodeBlock() tea.Cmd {
+	buffer, _ := clipboard.ReadAll()
+	currentInput := p.textEditor.Value()
+
+	lines := strings.Split(currentInput, "\n")
+	lang := strings.TrimSpace(lines[len(lines)-1])
+	currentInput = strings.Join(lines[0:len(lines)-1], "\n")
+	bufferContent := strings.Trim(string(buffer), "\n")
+
+	isDetected := lang == ""
+	if isDetected {
+		lang = util.DetectCodeLanguage(bufferContent)
+	}
+
+	p.textEditor.SetValue(currentInput + getCodeBlock(lang, bufferContent))
+	p.textEditor.SetCursor(0)
+
+	if !isDetected || bufferContent == "" {
+		return nil
+	}
+
+	input := textinput.New()
+	input.PromptStyle = p.input.PromptStyle
+	input.CharLimit = 32
+	input.SetValue(lang)
+	if util.IsReducedMotion() {
+		input.Cursor.SetMode(cursor.CursorStatic)
+	}
+
+	p.spelling = nil
+	p.codeFence = &codeFenceLanguage{
+		before: currentInput,
+		code:   bufferContent,
+		input:  input,
+	}
+	return p.codeFence.input.Focus()
+}
+
+func (p *PromptPane) handleCodeFenceKeys(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, p.keys.fenceApply):
+		lang := strings.Join(strings.Fields(p.codeFence.input.Value()), "")
+		p.textEditor.SetValue(p.codeFence.before + getCodeBlock(lang, p.codeFence.code))
+		p.textEditor.SetCursor(0)
+		p.codeFence = nil
+		return nil
+
+	case key.Matches(msg, p.keys.fenceKeep):
+		p.codeFence = nil
+		return nil
+	}
+
+	var cmd tea.Cmd
+	p.codeFence.input, cmd = p.codeFence.input.Update(msg)
+	return cmd
+}
+
+func (p PromptPane) renderCodeFenceLanguage() string {
+	tips := util.HelpStyle.Render(util.RenderKeyHints([]key.Binding{
+		p.keys.fenceApply,
+		p.keys.fenceKeep,
+	}))
+	label := infoLabel.Render(infoPrefix.Render(i18n.T("prompt.codeLanguage")))
+	return label + p.codeFence.input.View() + " " + tips
+}
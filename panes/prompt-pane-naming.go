@@ -0,0 +1,81 @@
+package panes
+
+import (
+	"strings"
+
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/sessions"
+	"github.com/BalanceBalls/nekot/util"
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Name of the quick chat being saved. It is typed in place of the prompt, so the draft is kept
+type quickChatNaming struct {
+	sessionId int
+	input     textinput.Model
+	// A title suggested after the user started typing doesn't replace the typed name
+	isEdited bool
+}
+
+func (p *PromptPane) openQuickChatNaming(msg sessions.QuickChatNaming) tea.Cmd {
+	if p.naming != nil && p.naming.sessionId == msg.SessionId {
+		return nil
+	}
+
+	input := textinput.New()
+	input.PromptStyle = p.input.PromptStyle
+	input.CharLimit = 0
+	input.Width = 20000
+	input.SetValue(msg.Name)
+	if util.IsReducedMotion() {
+		input.Cursor.SetMode(cursor.CursorStatic)
+	}
+
+	p.spelling = nil
+	p.naming = &quickChatNaming{
+		sessionId: msg.SessionId,
+		input:     input,
+	}
+	return p.naming.input.Focus()
+}
+
+func (p *PromptPane) handleTitleSuggested(msg sessions.QuickChatTitleSuggested) {
+	if p.naming == nil || p.naming.isEdited || p.naming.sessionId != msg.SessionId {
+		return
+	}
+
+	p.naming.input.SetValue(msg.Title)
+	p.naming.input.CursorEnd()
+}
+
+func (p *PromptPane) handleNamingKeys(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, p.keys.namingSave):
+		name := strings.TrimSpace(p.naming.input.Value())
+		p.naming = nil
+		return sessions.SendSaveQuickChatMsg(name)
+
+	case key.Matches(msg, p.keys.namingCancel):
+		p.naming = nil
+		return nil
+	}
+
+	value := p.naming.input.Value()
+	var cmd tea.Cmd
+	p.naming.input, cmd = p.naming.input.Update(msg)
+	if p.naming.input.Value() != value {
+		p.naming.isEdited = true
+	}
+	return cmd
+}
+
+func (p PromptPane) renderQuickChatNaming() string {
+	tips := util.HelpStyle.Render(util.RenderKeyHints([]key.Binding{
+		p.keys.namingSave,
+		p.keys.namingCancel,
+	}))
+	return infoLabel.Render(infoPrefix.Render(i18n.T("prompt.quickChatName"))) + tips
+}
@@ -0,0 +1,67 @@
+package panes
+
+import (
+	"strings"
+
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/sessions"
+	"github.com/BalanceBalls/nekot/util"
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Note of a rated answer. It is typed in place of the prompt, so the draft is kept
+type ratingNote struct {
+	index int
+	input textinput.Model
+}
+
+func (p *PromptPane) openRatingNote(msg sessions.RatingNoteRequested) tea.Cmd {
+	if !p.isFocused {
+		return nil
+	}
+
+	input := textinput.New()
+	input.PromptStyle = p.input.PromptStyle
+	input.CharLimit = 0
+	input.Width = 20000
+	input.SetValue(msg.Note)
+	if util.IsReducedMotion() {
+		input.Cursor.SetMode(cursor.CursorStatic)
+	}
+
+	p.spelling = nil
+	p.ratingNote = &ratingNote{
+		index: msg.Index,
+		input: input,
+	}
+	return p.ratingNote.input.Focus()
+}
+
+func (p *PromptPane) handleRatingNoteKeys(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, p.keys.noteSave):
+		note := strings.TrimSpace(p.ratingNote.input.Value())
+		index := p.ratingNote.index
+		p.ratingNote = nil
+		return tea.Sequence(sessions.SendSetRatingNoteMsg(index, note), util.SwitchToPane(util.ChatPane))
+
+	case key.Matches(msg, p.keys.noteCancel):
+		p.ratingNote = nil
+		return nil
+	}
+
+	var cmd tea.Cmd
+	p.ratingNote.input, cmd = p.ratingNote.input.Update(msg)
+	return cmd
+}
+
+func (p PromptPane) renderRatingNote() string {
+	tips := util.HelpStyle.Render(util.RenderKeyHints([]key.Binding{
+		p.keys.noteSave,
+		p.keys.noteCancel,
+	}))
+	return infoLabel.Render(infoPrefix.Render(i18n.T("prompt.ratingNote"))) + tips
+}
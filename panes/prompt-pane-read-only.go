@@ -0,0 +1,50 @@
+package panes
+
+import (
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/sessions"
+	"github.com/BalanceBalls/nekot/util"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Prompt rejected by a read-only session. It is put back into the input, so it is not lost if the copy is declined
+type readOnlyNotice struct {
+	prompt      string
+	attachments []util.Attachment
+}
+
+func (p *PromptPane) openReadOnlyNotice(msg sessions.ReadOnlyPromptRejected) {
+	p.spelling = nil
+	p.restorePrompt(msg.Prompt)
+	p.attachments = msg.Attachments
+	p.readOnly = &readOnlyNotice{
+		prompt:      msg.Prompt,
+		attachments: msg.Attachments,
+	}
+}
+
+func (p *PromptPane) handleReadOnlyKeys(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, p.keys.readOnlyCopy):
+		notice := p.readOnly
+		p.readOnly = nil
+		p.input.SetValue("")
+		p.textEditor.SetValue("")
+		p.attachments = []util.Attachment{}
+		return sessions.SendToSessionCopyMsg(notice.prompt, notice.attachments)
+
+	case key.Matches(msg, p.keys.readOnlyCancel):
+		p.readOnly = nil
+	}
+
+	return nil
+}
+
+func (p PromptPane) renderReadOnlyNotice() string {
+	tips := util.HelpStyle.Render(util.RenderKeyHints([]key.Binding{
+		p.keys.readOnlyCopy,
+		p.keys.readOnlyCancel,
+	}))
+	return infoLabel.Render(infoPrefix.Render(i18n.T("prompt.readOnly"))) + tips
+}
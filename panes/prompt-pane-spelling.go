@@ -0,0 +1,197 @@
+package panes
+
+import (
+	"strings"
+	"time"
+
+	"github.com/BalanceBalls/nekot/extensions/spellcheck"
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/util"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The prompt is checked once typing pauses, not on every key
+const spellCheckDelay = 500 * time.Millisecond
+
+type spellCheckTick struct {
+	seq int
+}
+
+type spellChecked struct {
+	seq        int
+	misspelled map[string][]string
+	err        error
+}
+
+// Suggestions for a misspelled word of the prompt, start and end are rune offsets in the prompt
+type spellingPopup struct {
+	start       int
+	end         int
+	word        string
+	suggestions []string
+	cursor      int
+}
+
+func (p *PromptPane) scheduleSpellCheck() tea.Cmd {
+	if p.spellCheckLanguage == "" {
+		return nil
+	}
+
+	p.spellCheckSeq++
+	seq := p.spellCheckSeq
+	return tea.Tick(spellCheckDelay, func(time.Time) tea.Msg {
+		return spellCheckTick{seq: seq}
+	})
+}
+
+func (p *PromptPane) checkSpelling(msg spellCheckTick) tea.Cmd {
+	if msg.seq != p.spellCheckSeq || p.spellCheckLanguage == "" {
+		return nil
+	}
+
+	ctx := p.mainCtx
+	language := p.spellCheckLanguage
+	text := p.getPromptValue()
+	return func() tea.Msg {
+		misspelled, err := spellcheck.Check(ctx, language, text)
+		return spellChecked{seq: msg.seq, misspelled: misspelled, err: err}
+	}
+}
+
+func (p *PromptPane) handleSpellChecked(msg spellChecked) tea.Cmd {
+	if msg.seq != p.spellCheckSeq {
+		return nil
+	}
+
+	if msg.err != nil {
+		// a missing hunspell or dictionary would fail every check, so the checks stop until the config changes
+		util.Slog.Warn("spell check failed", "error", msg.err.Error())
+		p.spellCheckLanguage = ""
+		p.misspelled = nil
+		return util.SendToastMsg(i18n.Tf("notification.spellCheckFailed", msg.err.Error()), util.WarningSeverity)
+	}
+
+	p.misspelled = msg.misspelled
+	return nil
+}
+
+func (p *PromptPane) openSpellingPopup() {
+	start, end, ok := spellcheck.FindMisspelled(p.getPromptValue(), p.misspelled, p.getCursorOffset())
+	if !ok {
+		return
+	}
+
+	word := string([]rune(p.getPromptValue())[start:end])
+	p.spelling = &spellingPopup{
+		start:       start,
+		end:         end,
+		word:        word,
+		suggestions: p.misspelled[word],
+	}
+}
+
+func (p *PromptPane) handleSpellingKeys(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, p.keys.spellingPrev):
+		p.spelling.cursor = max(p.spelling.cursor-1, 0)
+
+	case key.Matches(msg, p.keys.spellingNext):
+		p.spelling.cursor = min(p.spelling.cursor+1, max(len(p.spelling.suggestions)-1, 0))
+
+	case key.Matches(msg, p.keys.spellingChoose):
+		if len(p.spelling.suggestions) > 0 {
+			p.replaceMisspelled(p.spelling.suggestions[p.spelling.cursor])
+		}
+		p.spelling = nil
+		return p.scheduleSpellCheck()
+
+	case key.Matches(msg, p.keys.spellingClose, p.keys.spelling):
+		p.spelling = nil
+	}
+
+	return nil
+}
+
+func (p *PromptPane) replaceMisspelled(suggestion string) {
+	runes := []rune(p.getPromptValue())
+	if p.spelling.end > len(runes) {
+		return
+	}
+
+	value := string(runes[:p.spelling.start]) + suggestion + string(runes[p.spelling.end:])
+	if p.viewMode == util.TextEditMode {
+		p.textEditor.SetValue(value)
+		return
+	}
+
+	p.input.SetValue(value)
+	p.input.SetCursor(p.spelling.start + len([]rune(suggestion)))
+}
+
+func (p PromptPane) getPromptValue() string {
+	if p.viewMode == util.TextEditMode {
+		return p.textEditor.Value()
+	}
+	return p.input.Value()
+}
+
+// Cursor position in runes of the prompt
+func (p PromptPane) getCursorOffset() int {
+	if p.viewMode != util.TextEditMode {
+		return p.input.Position()
+	}
+
+	lines := strings.Split(p.textEditor.Value(), "\n")
+	row := min(p.textEditor.Line(), len(lines)-1)
+	offset := 0
+	for _, line := range lines[:row] {
+		offset += len([]rune(line)) + 1
+	}
+
+	info := p.textEditor.LineInfo()
+	return offset + info.StartColumn + info.CharOffset
+}
+
+// The word under the cursor is not highlighted while the prompt is edited
+func (p PromptPane) getTypedWord() string {
+	if p.inputMode != util.PromptInsertMode {
+		return ""
+	}
+
+	runes := []rune(p.getPromptValue())
+	offset := min(p.getCursorOffset(), len(runes))
+	lineStart := strings.LastIndex(string(runes[:offset]), "\n") + 1
+	line := string(runes[:offset])[lineStart:]
+	rest, _, _ := strings.Cut(string(runes[offset:]), "\n")
+	return spellcheck.WordAt(line+rest, len([]rune(line)))
+}
+
+func (p PromptPane) highlightMisspelled(content string) string {
+	style := lipgloss.NewStyle().Underline(true).Foreground(p.colors.ErrorColor)
+	return spellcheck.Highlight(content, p.misspelled, p.getTypedWord(), style)
+}
+
+func (p PromptPane) renderSpellingPopup() string {
+	items := []string{infoPrefix.Render(p.spelling.word + " →")}
+	if len(p.spelling.suggestions) == 0 {
+		items = append(items, i18n.T("prompt.noSuggestions"))
+	}
+
+	activeStyle := lipgloss.NewStyle().Bold(true).Foreground(p.colors.AccentColor)
+	for i, suggestion := range p.spelling.suggestions {
+		if i == p.spelling.cursor {
+			items = append(items, activeStyle.Render("["+suggestion+"]"))
+			continue
+		}
+		items = append(items, suggestion)
+	}
+
+	tips := util.HelpStyle.Render(util.RenderKeyHints([]key.Binding{
+		p.keys.spellingNext,
+		p.keys.spellingChoose,
+		p.keys.spellingClose,
+	}))
+	return infoLabel.Render(strings.Join(items, " ")) + tips
+}
@@ -0,0 +1,58 @@
+package panes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/util"
+)
+
+// Larger files are not read for the estimate, they are over any sane attachment limit
+const maxEstimatedFileSize = 5 * 1024 * 1024
+
+var fileReferenceRegex = regexp.MustCompile(`\[file=([^\]]+)\]`)
+
+// Estimate of the prompt with the text files it references. Files are read once per path
+func (p *PromptPane) updateTokenEstimate() {
+	prompt := p.getPromptValue()
+	p.promptTokens = util.CountTokens(fileReferenceRegex.ReplaceAllString(prompt, ""))
+
+	for _, match := range fileReferenceRegex.FindAllStringSubmatch(prompt, -1) {
+		path := strings.ReplaceAll(filepath.Clean(match[1]), `\ `, " ")
+		tokens, ok := p.fileTokens[path]
+		if !ok {
+			tokens = countFileTokens(path)
+			p.fileTokens[path] = tokens
+		}
+		p.promptTokens += tokens
+	}
+}
+
+// Binary files like pdfs are sent as is and have no estimate
+func countFileTokens(path string) int {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || info.Size() > maxEstimatedFileSize {
+		return 0
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil || !utf8.Valid(content) {
+		return 0
+	}
+	return util.CountTokens(string(content))
+}
+
+func (p PromptPane) renderTokenEstimate() string {
+	price, ok := p.modelPrices[p.model]
+	if !ok {
+		return infoLabel.Render(i18n.Tf("prompt.tokens", p.promptTokens))
+	}
+
+	cost := float64(p.promptTokens) * price / 1_000_000
+	return infoLabel.Render(i18n.Tf("prompt.tokensCost", p.promptTokens, fmt.Sprintf("%.4f", cost)))
+}
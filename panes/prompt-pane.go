@@ -2,14 +2,24 @@ package panes
 
 import (
 	"context"
+	"database/sql"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/BalanceBalls/nekot/components"
 	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/extensions/flows"
+	"github.com/BalanceBalls/nekot/extensions/gitcontext"
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/sessions"
+	"github.com/BalanceBalls/nekot/settings"
+	"github.com/BalanceBalls/nekot/user"
 	"github.com/BalanceBalls/nekot/util"
 	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/cursor"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -18,46 +28,108 @@ import (
 	zone "github.com/lrstanley/bubblezone"
 )
 
-const ResponseWaitingMsg = "> Please wait ..."
-const InitializingMsg = "Components initializing ..."
-const PlaceholderMsg = "Press i to type • ctrl+e expand/collapse editor • ctrl+r clear"
-
 type keyMap struct {
-	insert    key.Binding
-	clear     key.Binding
-	exit      key.Binding
-	paste     key.Binding
-	pasteCode key.Binding
-	attach    key.Binding
-	enter     key.Binding
+	insert       key.Binding
+	clear        key.Binding
+	exit         key.Binding
+	paste        key.Binding
+	pasteCode    key.Binding
+	attach       key.Binding
+	enter        key.Binding
+	insertCopied key.Binding
+
+	attachmentPrev   key.Binding
+	attachmentNext   key.Binding
+	removeAttachment key.Binding
+
+	spelling       key.Binding
+	spellingPrev   key.Binding
+	spellingNext   key.Binding
+	spellingChoose key.Binding
+	spellingClose  key.Binding
+
+	namingSave   key.Binding
+	namingCancel key.Binding
+
+	noteSave   key.Binding
+	noteCancel key.Binding
+
+	readOnlyCopy   key.Binding
+	readOnlyCancel key.Binding
+
+	fenceApply key.Binding
+	fenceKeep  key.Binding
+
+	restoreCleared key.Binding
+	clearConfirm   key.Binding
+	clearCancel    key.Binding
 }
 
 var defaultKeyMap = keyMap{
-	insert: key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "enter insert mode")),
+	insert: key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "keys.insertMode")),
 	clear: key.NewBinding(
 		key.WithKeys(tea.KeyCtrlR.String()),
-		key.WithHelp("ctrl+r", "clear prompt"),
+		key.WithHelp("ctrl+r", "keys.clearPrompt"),
 	),
 	exit: key.NewBinding(
 		key.WithKeys(tea.KeyEsc.String()),
-		key.WithHelp("esc", "exit insert mode or editor mode"),
+		key.WithHelp("esc", "keys.exitInsertMode"),
 	),
 	paste: key.NewBinding(
 		key.WithKeys(tea.KeyCtrlV.String()),
-		key.WithHelp("ctrl+v", "insert text from clipboard"),
+		key.WithHelp("ctrl+v", "keys.paste"),
 	),
 	pasteCode: key.NewBinding(
 		key.WithKeys(tea.KeyCtrlS.String()),
-		key.WithHelp("ctrl+s", "insert code block from clipboard"),
+		key.WithHelp("ctrl+s", "keys.pasteCodeBlock"),
 	),
 	attach: key.NewBinding(
 		key.WithKeys(tea.KeyCtrlA.String()),
-		key.WithHelp("ctrl+a", "attach an image"),
+		key.WithHelp("ctrl+a", "keys.attachImage"),
 	),
 	enter: key.NewBinding(
 		key.WithKeys(tea.KeyEnter.String()),
-		key.WithHelp("enter", "send prompt"),
+		key.WithHelp("enter", "keys.sendPrompt"),
+	),
+	insertCopied: key.NewBinding(
+		key.WithKeys(tea.KeyCtrlL.String()),
+		key.WithHelp("ctrl+l", "keys.insertCopied"),
+	),
+	attachmentPrev: key.NewBinding(key.WithKeys(tea.KeyLeft.String()), key.WithHelp("←", "keys.previousAttachment")),
+	attachmentNext: key.NewBinding(key.WithKeys(tea.KeyRight.String()), key.WithHelp("←/→", "keys.selectAttachment")),
+	removeAttachment: key.NewBinding(
+		key.WithKeys("x", tea.KeyDelete.String()),
+		key.WithHelp("x", "keys.removeAttachment"),
+	),
+	spelling: key.NewBinding(
+		key.WithKeys("alt+s"),
+		key.WithHelp("alt+s", "keys.spelling"),
 	),
+	spellingPrev:   key.NewBinding(key.WithKeys(tea.KeyLeft.String()), key.WithHelp("←", "keys.previous")),
+	spellingNext:   key.NewBinding(key.WithKeys(tea.KeyRight.String()), key.WithHelp("←/→", "keys.choose")),
+	spellingChoose: key.NewBinding(key.WithKeys(tea.KeyEnter.String()), key.WithHelp("enter", "keys.replace")),
+	spellingClose:  key.NewBinding(key.WithKeys(tea.KeyEsc.String()), key.WithHelp("esc", "keys.close")),
+	namingSave:     key.NewBinding(key.WithKeys(tea.KeyEnter.String()), key.WithHelp("enter", "keys.save")),
+	namingCancel:   key.NewBinding(key.WithKeys(tea.KeyEsc.String()), key.WithHelp("esc", "keys.cancel")),
+	noteSave:       key.NewBinding(key.WithKeys(tea.KeyEnter.String()), key.WithHelp("enter", "keys.saveNote")),
+	noteCancel:     key.NewBinding(key.WithKeys(tea.KeyEsc.String()), key.WithHelp("esc", "keys.cancel")),
+	readOnlyCopy:   key.NewBinding(key.WithKeys(tea.KeyEnter.String()), key.WithHelp("enter", "keys.sendToCopy")),
+	readOnlyCancel: key.NewBinding(key.WithKeys(tea.KeyEsc.String()), key.WithHelp("esc", "keys.cancel")),
+	fenceApply:     key.NewBinding(key.WithKeys(tea.KeyEnter.String()), key.WithHelp("enter", "keys.setLanguage")),
+	fenceKeep:      key.NewBinding(key.WithKeys(tea.KeyEsc.String()), key.WithHelp("esc", "keys.keep")),
+	restoreCleared: key.NewBinding(key.WithKeys("alt+r"), key.WithHelp("alt+r", "keys.restorePrompt")),
+	clearConfirm: key.NewBinding(
+		key.WithKeys(tea.KeyEnter.String(), tea.KeyCtrlR.String()),
+		key.WithHelp("enter", "keys.clear"),
+	),
+	clearCancel: key.NewBinding(key.WithKeys(tea.KeyEsc.String()), key.WithHelp("esc", "keys.cancel")),
+}
+
+const clipboardPollInterval = time.Second
+
+type clipboardPolled struct {
+	content    string
+	isBaseline bool
 }
 
 var infoBlockStyle = lipgloss.NewStyle()
@@ -65,7 +137,7 @@ var infoPrefix = lipgloss.NewStyle().Bold(true)
 
 var infoLabel = lipgloss.NewStyle().
 	BorderLeft(true).
-	BorderStyle(lipgloss.InnerHalfBlockBorder()).
+	BorderStyle(util.HalfBlockBorder()).
 	Bold(true).
 	MarginRight(1).
 	PaddingRight(1).
@@ -81,6 +153,10 @@ type PromptPane struct {
 	keys           keyMap
 
 	pendingInsert  string
+	copiedText     string
+	lastClipboard  string
+	watchClipboard bool
+	isWatching     bool
 	attachments    []util.Attachment
 	operation      util.Operation
 	viewMode       util.ViewMode
@@ -90,9 +166,41 @@ type PromptPane struct {
 	terminalHeight int
 	ready          bool
 	mainCtx        context.Context
+
+	apiProvider  util.ApiProvider
+	capabilities util.ModelCapabilities
+
+	spellCheckLanguage string
+	spellCheckSeq      int
+	misspelled         map[string][]string
+	spelling           *spellingPopup
+
+	model        string
+	modelPrices  map[string]float64
+	promptTokens int
+	fileTokens   map[string]int
+
+	broadcastTargets int
+	naming           *quickChatNaming
+	ratingNote       *ratingNote
+	readOnly         *readOnlyNotice
+	codeFence        *codeFenceLanguage
+
+	clearConfirmLength int
+	isConfirmingClear  bool
+	lastCleared        *clearedPrompt
+
+	attachmentCursor int
+	// File sizes by path, -1 if the file can't be read
+	attachmentSizes     map[string]int64
+	maxAttachmentSizeMb int
+	placesService       *user.FilePlacesService
+
+	// Editor mode is left once the prompt is sent
+	returnFromEditor bool
 }
 
-func NewPromptPane(ctx context.Context) PromptPane {
+func NewPromptPane(db *sql.DB, ctx context.Context) PromptPane {
 	config, ok := config.FromContext(ctx)
 	if !ok {
 		util.Slog.Error("failed to extract config from context")
@@ -102,13 +210,13 @@ func NewPromptPane(ctx context.Context) PromptPane {
 	colors := config.ColorScheme.GetColors()
 
 	input := textinput.New()
-	input.Placeholder = InitializingMsg
+	input.Placeholder = i18n.T("prompt.initializing")
 	input.PromptStyle = lipgloss.NewStyle().Foreground(colors.ActiveTabBorderColor)
 	input.CharLimit = 0
 	input.Width = 20000
 
 	textEditor := textarea.New()
-	textEditor.Placeholder = PlaceholderMsg
+	textEditor.Placeholder = i18n.T("prompt.placeholder")
 	textEditor.FocusedStyle.Prompt = lipgloss.NewStyle().Foreground(colors.ActiveTabBorderColor)
 	textEditor.FocusedStyle.CursorLine.Background(lipgloss.NoColor{})
 	textEditor.FocusedStyle.EndOfBuffer = lipgloss.NewStyle().
@@ -121,13 +229,19 @@ func NewPromptPane(ctx context.Context) PromptPane {
 	textEditor.MaxHeight = 0
 	textEditor.Blur()
 
+	if util.IsReducedMotion() {
+		input.Cursor.SetMode(cursor.CursorStatic)
+		textEditor.Cursor.SetMode(cursor.CursorStatic)
+	}
+
 	container := lipgloss.NewStyle().
 		AlignVertical(lipgloss.Bottom).
-		BorderStyle(lipgloss.ThickBorder()).
+		BorderStyle(util.ThickBorder()).
 		BorderForeground(colors.ActiveTabBorderColor).
 		MarginTop(util.PromptPaneMarginTop)
 
 	infoLabel = infoLabel.
+		BorderStyle(util.HalfBlockBorder()).
 		BorderLeftForeground(colors.ActiveTabBorderColor).
 		Foreground(colors.NormalTabBorderColor)
 
@@ -148,10 +262,47 @@ func NewPromptPane(ctx context.Context) PromptPane {
 		isFocused:      true,
 		terminalWidth:  util.DefaultTerminalWidth,
 		terminalHeight: util.DefaultTerminalHeight,
+		apiProvider:    util.GetOpenAiInferenceProvider(config.Provider, config.ProviderBaseUrl),
+		capabilities:   util.UnknownModelCapabilities,
+		watchClipboard: config.ClipboardWatch,
+		isWatching:     config.ClipboardWatch,
+
+		spellCheckLanguage: config.SpellCheckLanguage,
+		modelPrices:        config.ModelPrices,
+		returnFromEditor:   config.ShouldReturnFromEditorOnSend(),
+		fileTokens:         map[string]int{},
+		attachmentSizes:    map[string]int64{},
+
+		maxAttachmentSizeMb: config.MaxAttachmentSizeMb,
+		placesService:       user.NewFilePlacesService(db),
+		clearConfirmLength:  config.ClearConfirmLength,
 	}
 }
 
+func (p PromptPane) SetColors(colors util.SchemeColors) PromptPane {
+	p.colors = colors
+	p.textEditor.FocusedStyle.Prompt = p.textEditor.FocusedStyle.Prompt.Foreground(colors.ActiveTabBorderColor)
+	p.textEditor.FocusedStyle.EndOfBuffer = p.textEditor.FocusedStyle.EndOfBuffer.Foreground(colors.ActiveTabBorderColor)
+	p.textEditor.FocusedStyle.LineNumber = p.textEditor.FocusedStyle.LineNumber.Foreground(colors.AccentColor)
+
+	borderColor := colors.NormalTabBorderColor
+	if p.isFocused {
+		borderColor = colors.ActiveTabBorderColor
+	}
+	p.inputContainer = p.inputContainer.BorderForeground(borderColor)
+	p.input.PromptStyle = p.input.PromptStyle.Foreground(borderColor)
+
+	infoLabel = infoLabel.
+		BorderLeftForeground(colors.ActiveTabBorderColor).
+		Foreground(colors.NormalTabBorderColor)
+	infoPrefix = infoPrefix.Foreground(colors.HighlightColor)
+	return p
+}
+
 func (p PromptPane) Init() tea.Cmd {
+	if p.isWatching {
+		return tea.Batch(p.input.Cursor.BlinkCmd(), readClipboard(true))
+	}
 	return p.input.Cursor.BlinkCmd()
 }
 
@@ -161,6 +312,59 @@ func (p PromptPane) Update(msg tea.Msg) (PromptPane, tea.Cmd) {
 		cmds []tea.Cmd
 	)
 
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && p.isFocused {
+		if p.naming != nil {
+			cmd = p.handleNamingKeys(keyMsg)
+			return p, cmd
+		}
+
+		if p.ratingNote != nil {
+			cmd = p.handleRatingNoteKeys(keyMsg)
+			return p, cmd
+		}
+
+		if p.readOnly != nil {
+			cmd = p.handleReadOnlyKeys(keyMsg)
+			return p, tea.Batch(cmd, p.syncAttachmentChips())
+		}
+
+		if p.codeFence != nil {
+			cmd = p.handleCodeFenceKeys(keyMsg)
+			return p, cmd
+		}
+
+		if p.isConfirmingClear {
+			p.handleClearConfirmKeys(keyMsg)
+			return p, p.syncAttachmentChips()
+		}
+
+		if p.spelling != nil {
+			cmd = p.handleSpellingKeys(keyMsg)
+			return p, cmd
+		}
+
+		if key.Matches(keyMsg, p.keys.spelling) && p.spellCheckLanguage != "" {
+			p.openSpellingPopup()
+			return p, nil
+		}
+	}
+
+	if p.naming != nil {
+		p.naming.input, cmd = p.naming.input.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if p.ratingNote != nil {
+		p.ratingNote.input, cmd = p.ratingNote.input.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if p.codeFence != nil {
+		p.codeFence.input, cmd = p.codeFence.input.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	promptValue := p.getPromptValue()
 	cmds = append(cmds, p.processTextInputUpdates(msg))
 	cmds = append(cmds, p.processFilePickerUpdates(msg))
 
@@ -179,6 +383,78 @@ func (p PromptPane) Update(msg tea.Msg) (PromptPane, tea.Cmd) {
 	case util.ProcessingStateChanged:
 		p.isSessionIdle = !util.IsProcessingActive(msg.State)
 
+	case util.RestorePromptMsg:
+		p.restorePrompt(msg.Prompt)
+
+	case util.InsertPromptMsg:
+		if p.viewMode == util.TextEditMode {
+			p.textEditor.SetValue(msg.Prompt)
+		} else {
+			p.input.SetValue(msg.Prompt)
+		}
+		cmds = append(cmds, util.SwitchToPane(util.PromptPane))
+
+	case util.QuotePromptMsg:
+		if p.viewMode != util.FilePickerMode {
+			cmds = append(cmds, p.appendBlock(msg.Text+"\n\n"))
+		}
+
+	case spellCheckTick:
+		cmds = append(cmds, p.checkSpelling(msg))
+
+	case spellChecked:
+		cmds = append(cmds, p.handleSpellChecked(msg))
+
+	case config.ConfigUpdated:
+		if p.spellCheckLanguage != msg.Config.SpellCheckLanguage {
+			p.spellCheckLanguage = msg.Config.SpellCheckLanguage
+			p.misspelled = nil
+			cmds = append(cmds, p.scheduleSpellCheck())
+		}
+		p.modelPrices = msg.Config.ModelPrices
+		p.returnFromEditor = msg.Config.ShouldReturnFromEditorOnSend()
+		p.maxAttachmentSizeMb = msg.Config.MaxAttachmentSizeMb
+		p.clearConfirmLength = msg.Config.ClearConfirmLength
+		p.watchClipboard = msg.Config.ClipboardWatch
+		if p.watchClipboard && !p.isWatching {
+			p.isWatching = true
+			cmds = append(cmds, readClipboard(true))
+		}
+
+	case sessions.QuickChatNaming:
+		cmds = append(cmds, p.openQuickChatNaming(msg))
+
+	case sessions.QuickChatTitleSuggested:
+		p.handleTitleSuggested(msg)
+
+	case sessions.ReadOnlyPromptRejected:
+		p.openReadOnlyNotice(msg)
+
+	case sessions.RatingNoteRequested:
+		cmds = append(cmds, p.openRatingNote(msg))
+
+	case sessions.UpdateCurrentSession:
+		if p.naming != nil && p.naming.sessionId != msg.Session.ID {
+			p.naming = nil
+		}
+		p.readOnly = nil
+		p.ratingNote = nil
+
+	case sessions.BroadcastTargetsChanged:
+		p.broadcastTargets = len(msg.SessionIds)
+
+	case components.FavoriteDirToggled:
+		cmds = append(cmds, p.toggleFavoriteDir(msg.Path))
+
+	case clipboardPolled:
+		cmds = append(cmds, p.handleClipboardPolled(msg))
+
+	case settings.UpdateSettingsEvent:
+		if msg.Err == nil {
+			p.capabilities = util.GetModelCapabilities(p.apiProvider, msg.Settings.Model)
+			p.model = msg.Settings.Model
+		}
+
 	case util.FocusEvent:
 		p.handleFocusEvent(msg)
 
@@ -190,6 +466,9 @@ func (p PromptPane) Update(msg tea.Msg) (PromptPane, tea.Cmd) {
 			break
 		}
 		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			if p.handleChipClick(msg) {
+				break
+			}
 			cmds = append(cmds, p.keyInsert())
 		}
 
@@ -208,6 +487,9 @@ func (p PromptPane) Update(msg tea.Msg) (PromptPane, tea.Cmd) {
 		case key.Matches(msg, p.keys.clear):
 			cmds = append(cmds, p.keyClear())
 
+		case key.Matches(msg, p.keys.restoreCleared):
+			cmds = append(cmds, p.keyRestoreCleared())
+
 		case key.Matches(msg, p.keys.exit):
 			cmds = append(cmds, p.keyExit())
 
@@ -219,13 +501,36 @@ func (p PromptPane) Update(msg tea.Msg) (PromptPane, tea.Cmd) {
 
 		case key.Matches(msg, p.keys.pasteCode):
 			cmds = append(cmds, p.keyPasteCode())
+
+		case key.Matches(msg, p.keys.insertCopied):
+			cmds = append(cmds, p.keyInsertCopied())
+
+		case key.Matches(msg, p.keys.attachmentPrev) && p.canSelectAttachments():
+			p.attachmentCursor = max(p.attachmentCursor-1, 0)
+
+		case key.Matches(msg, p.keys.attachmentNext) && p.canSelectAttachments():
+			p.attachmentCursor = min(p.attachmentCursor+1, len(p.attachments)-1)
+
+		case key.Matches(msg, p.keys.removeAttachment) && p.canSelectAttachments():
+			p.removeAttachment(p.attachmentCursor)
 		}
 	}
 
+	cmds = append(cmds, p.syncAttachmentChips())
+
+	if p.getPromptValue() != promptValue {
+		p.updateTokenEstimate()
+		cmds = append(cmds, p.scheduleSpellCheck())
+	}
+
 	return p, tea.Batch(cmds...)
 }
 
 func (p *PromptPane) keyAttach() tea.Cmd {
+	if !p.capabilities.Vision && p.viewMode != util.FilePickerMode {
+		return nil
+	}
+
 	if p.isFocused && p.operation == util.NoOperaton && p.viewMode != util.FilePickerMode {
 		return util.SendViewModeChangedMsg(util.FilePickerMode)
 	} else {
@@ -249,18 +554,6 @@ func (p *PromptPane) keyInsert() tea.Cmd {
 	}
 }
 
-func (p *PromptPane) keyClear() tea.Cmd {
-	p.attachments = []util.Attachment{}
-	switch p.viewMode {
-	case util.TextEditMode:
-		p.textEditor.Reset()
-	default:
-		p.input.Reset()
-	}
-
-	return nil
-}
-
 func (p *PromptPane) keyExit() tea.Cmd {
 	if !p.isFocused {
 		return nil
@@ -303,6 +596,17 @@ func (p *PromptPane) keyEnter() tea.Cmd {
 	}
 
 	attachments := p.attachments
+	if !p.capabilities.Vision && slices.ContainsFunc(attachments, func(a util.Attachment) bool {
+		return a.Type == "img"
+	}) {
+		return util.MakeRecoverableErrorMsg(i18n.T("prompt.noVision"))
+	}
+
+	if index := slices.IndexFunc(attachments, p.isOversized); index != -1 {
+		return util.MakeRecoverableErrorMsg(
+			i18n.Tf("prompt.attachmentTooLarge", filepath.Base(attachments[index].Path), p.maxAttachmentSizeMb),
+		)
+	}
 
 	switch p.viewMode {
 	case util.TextEditMode:
@@ -329,8 +633,11 @@ func (p *PromptPane) keyEnter() tea.Cmd {
 		}
 
 		p.attachments = []util.Attachment{}
+		if !p.returnFromEditor {
+			return sendPrompt(promptText, attachments)
+		}
 		return tea.Batch(
-			util.SendPromptReadyMsg(promptText, attachments),
+			sendPrompt(promptText, attachments),
 			util.SendViewModeChangedMsg(util.NormalMode))
 
 	default:
@@ -345,12 +652,43 @@ func (p *PromptPane) keyEnter() tea.Cmd {
 		p.inputMode = util.PromptNormalMode
 
 		p.attachments = []util.Attachment{}
-		return util.SendPromptReadyMsg(promptText, attachments)
+		return sendPrompt(promptText, attachments)
 	}
 
 	return nil
 }
 
+// Git references are expanded in the background, so slow git commands don't block the UI.
+// On failure the prompt is restored, so it can be fixed and sent again
+func sendPrompt(promptText string, attachments []util.Attachment) tea.Cmd {
+	if flows.IsRunCommand(promptText) {
+		return flows.RequestRun(promptText)
+	}
+
+	if sessions.IsCompareCommand(promptText) {
+		return sessions.RequestComparison(promptText)
+	}
+
+	if _, _, err := util.ParseParameterOverrides(promptText); err != nil {
+		return tea.Batch(util.MakeRecoverableErrorMsg(err.Error()), util.SendRestorePromptMsg(promptText))
+	}
+
+	if !gitcontext.HasReferences(promptText) {
+		return util.SendPromptReadyMsg(promptText, attachments)
+	}
+
+	return func() tea.Msg {
+		expanded, err := gitcontext.ExpandReferences(context.Background(), promptText)
+		if err != nil {
+			return tea.BatchMsg{
+				util.MakeRecoverableErrorMsg(err.Error()),
+				util.SendRestorePromptMsg(promptText),
+			}
+		}
+		return util.SendPromptReadyMsg(expanded, attachments)()
+	}
+}
+
 func (p *PromptPane) keyPaste() tea.Cmd {
 	var cmd tea.Cmd
 	if p.isFocused {
@@ -362,18 +700,115 @@ func (p *PromptPane) keyPaste() tea.Cmd {
 			p.pendingInsert = ""
 		}
 
-		clipboard.WriteAll(content)
+		util.CopyToClipboard(content)
 	}
 	return cmd
 }
 
+// Bracketed paste delivers the whole text at once, so it is inserted as is instead of being
+// typed key by key. Multiline text opens the editor, the single line input would join the lines
+func (p *PromptPane) insertPastedText(text string) tea.Cmd {
+	if !p.isFocused || !p.isSessionIdle || p.viewMode == util.FilePickerMode {
+		return nil
+	}
+
+	text = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(text)
+	cmds := []tea.Cmd{p.keyInsert()}
+
+	if p.viewMode == util.TextEditMode {
+		p.textEditor.InsertString(text)
+	} else {
+		value := []rune(p.input.Value())
+		position := min(p.input.Position(), len(value))
+		content := string(value[:position]) + text + string(value[position:])
+
+		if strings.Contains(text, "\n") || lipgloss.Width(content) > p.input.Width-4 {
+			cmds = append(cmds, util.SwitchToEditor(content, util.NoOperaton, true))
+		} else {
+			p.input.SetValue(content)
+			p.input.SetCursor(position + len([]rune(text)))
+		}
+	}
+
+	if p.operation == util.NoOperaton {
+		p.parseAttachments()
+	}
+	return tea.Batch(cmds...)
+}
+
 func (p *PromptPane) keyPasteCode() tea.Cmd {
 	if p.isFocused && p.viewMode == util.TextEditMode && p.textEditor.Focused() {
-		p.insertBufferContentAsCodeBlock()
+		return p.insertBufferContentAsCodeBlock()
 	}
 	return nil
 }
 
+// The baseline read only remembers what is already in the clipboard, so it isn't offered on startup
+func readClipboard(isBaseline bool) tea.Cmd {
+	read := func() tea.Msg {
+		content, _ := clipboard.ReadAll()
+		return clipboardPolled{content: content, isBaseline: isBaseline}
+	}
+
+	if isBaseline {
+		return read
+	}
+
+	return tea.Tick(clipboardPollInterval, func(_ time.Time) tea.Msg {
+		return read()
+	})
+}
+
+func (p *PromptPane) handleClipboardPolled(msg clipboardPolled) tea.Cmd {
+	if !p.watchClipboard {
+		p.isWatching = false
+		p.copiedText = ""
+		return nil
+	}
+
+	var cmd tea.Cmd
+	isNew := msg.content != p.lastClipboard && strings.TrimSpace(msg.content) != ""
+	if !msg.isBaseline && isNew && !util.IsCopiedByApp(msg.content) {
+		p.copiedText = msg.content
+		cmd = util.SendToastMsg(
+			i18n.Tf("notification.clipboardCopied", p.keys.insertCopied.Help().Key),
+			util.InfoSeverity)
+	}
+
+	p.lastClipboard = msg.content
+	return tea.Batch(cmd, readClipboard(false))
+}
+
+// Inserts text detected by the clipboard watcher, switching to the editor
+// since a code block spans multiple lines
+func (p *PromptPane) keyInsertCopied() tea.Cmd {
+	if p.copiedText == "" || p.viewMode == util.FilePickerMode {
+		return nil
+	}
+
+	codeBlock := "```\n" + strings.Trim(p.copiedText, "\n") + "\n```\n"
+	p.copiedText = ""
+	return p.appendBlock(codeBlock)
+}
+
+// Multiline blocks go to the editor, the single line input can't hold them
+func (p *PromptPane) appendBlock(block string) tea.Cmd {
+	if p.viewMode == util.TextEditMode {
+		currentInput := p.textEditor.Value()
+		if currentInput != "" && !strings.HasSuffix(currentInput, "\n") {
+			currentInput += "\n"
+		}
+		p.textEditor.SetValue(currentInput + block)
+		return util.SwitchToPane(util.PromptPane)
+	}
+
+	currentInput := strings.TrimSpace(p.input.Value())
+	if currentInput != "" {
+		currentInput += "\n"
+	}
+	return util.SwitchToEditor(currentInput+block, util.NoOperaton, true)
+}
+
 func (p *PromptPane) handlePlaceholder() {
 	if !p.ready {
 		return
@@ -387,7 +822,7 @@ func (p *PromptPane) handlePlaceholder() {
 			p.textEditor.Placeholder = ""
 			break
 		}
-		p.textEditor.Placeholder = PlaceholderMsg
+		p.textEditor.Placeholder = i18n.T("prompt.placeholder")
 
 	case util.FilePickerMode:
 		break
@@ -397,7 +832,7 @@ func (p *PromptPane) handlePlaceholder() {
 			p.input.Placeholder = ""
 			return
 		}
-		p.input.Placeholder = PlaceholderMsg
+		p.input.Placeholder = i18n.T("prompt.placeholder")
 	}
 }
 
@@ -415,6 +850,10 @@ func (p *PromptPane) processFilePickerUpdates(msg tea.Msg) tea.Cmd {
 				Path: attachmentPath,
 			})
 
+			if err := p.placesService.AddRecentFile(attachmentPath); err != nil {
+				util.Slog.Error("failed to save a recent file", "error", err.Error())
+			}
+
 			cmds = append(cmds, util.SendViewModeChangedMsg(p.filePicker.PrevView))
 			p.filePicker.SelectedFile = ""
 		} else {
@@ -431,6 +870,10 @@ func (p *PromptPane) processFilePickerUpdates(msg tea.Msg) tea.Cmd {
 }
 
 func (p *PromptPane) processTextInputUpdates(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.Paste {
+		return p.insertPastedText(string(keyMsg.Runes))
+	}
+
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 	if p.isFocused && p.inputMode == util.PromptInsertMode && p.isSessionIdle {
@@ -461,6 +904,14 @@ func (p *PromptPane) processTextInputUpdates(msg tea.Msg) tea.Cmd {
 
 func (p *PromptPane) handleFocusEvent(msg util.FocusEvent) {
 	p.isFocused = msg.IsFocused
+	p.spelling = nil
+	if !p.isFocused {
+		p.naming = nil
+		p.ratingNote = nil
+		p.readOnly = nil
+		p.codeFence = nil
+		p.isConfirmingClear = false
+	}
 
 	if p.isFocused {
 		p.inputMode = util.PromptNormalMode
@@ -522,6 +973,19 @@ func (p *PromptPane) handleViewModeChange(msg util.ViewModeChanged) tea.Cmd {
 	return cmd
 }
 
+// Multiline prompts go to the text editor, they are not supported by the input field
+func (p *PromptPane) restorePrompt(prompt string) {
+	if p.getCurrentInput() != "" {
+		return
+	}
+
+	if strings.Contains(prompt, "\n") {
+		p.textEditor.SetValue(prompt)
+		return
+	}
+	p.input.SetValue(prompt)
+}
+
 func (p *PromptPane) getCurrentInput() string {
 
 	if p.textEditor.Value() != "" {
@@ -563,9 +1027,38 @@ func (p *PromptPane) openFilePicker(previousViewMode util.ViewMode, currentInput
 	w, h := util.CalcPromptPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
 	p.filePicker = components.NewFilePicker(previousViewMode, currentInput, p.colors)
 	p.filePicker.SetSize(w, h)
+	p.loadFilePlaces()
 	return p.filePicker.Init()
 }
 
+func (p *PromptPane) loadFilePlaces() {
+	favorites, err := p.placesService.GetPlaces(user.FavoriteDirPlace)
+	if err != nil {
+		util.Slog.Error("failed to load favorite directories", "error", err.Error())
+	}
+
+	recent, err := p.placesService.GetPlaces(user.RecentFilePlace)
+	if err != nil {
+		util.Slog.Error("failed to load recent files", "error", err.Error())
+	}
+
+	p.filePicker.SetPlaces(favorites, recent)
+}
+
+func (p *PromptPane) toggleFavoriteDir(path string) tea.Cmd {
+	isFavorite, err := p.placesService.ToggleFavoriteDir(path)
+	if err != nil {
+		return util.MakeRecoverableErrorMsg(err.Error())
+	}
+	p.loadFilePlaces()
+
+	toast := i18n.Tf("notification.favoriteRemoved", path)
+	if isFavorite {
+		toast = i18n.Tf("notification.favoriteAdded", path)
+	}
+	return util.SendToastMsg(toast, util.InfoSeverity)
+}
+
 func (p *PromptPane) openTextEditor(content string, op util.Operation, isFocused bool) tea.Cmd {
 	p.operation = op
 
@@ -637,22 +1130,8 @@ func (p *PromptPane) parseAttachments() []util.Attachment {
 	return attachments
 }
 
-func (p *PromptPane) insertBufferContentAsCodeBlock() {
-	buffer, _ := clipboard.ReadAll()
-	currentInput := p.textEditor.Value()
-
-	lines := strings.Split(currentInput, "\n")
-	lang := lines[len(lines)-1]
-	currentInput = strings.Join(lines[0:len(lines)-1], "\n")
-	bufferContent := strings.Trim(string(buffer), "\n")
-	codeBlock := "\n```" + lang + "\n" + bufferContent + "\n```\n"
-
-	p.textEditor.SetValue(currentInput + codeBlock)
-	p.textEditor.SetCursor(0)
-}
-
 func (p PromptPane) AllowFocusChange(isMouseEvent bool) bool {
-	if p.operation == util.SystemMessageEditing {
+	if p.operation == util.SystemMessageEditing || p.naming != nil || p.ratingNote != nil || p.readOnly != nil || p.codeFence != nil || p.isConfirmingClear {
 		return false
 	}
 
@@ -676,6 +1155,53 @@ func (p PromptPane) Enable() PromptPane {
 	return p
 }
 
+func (p PromptPane) ShortHelp() []key.Binding {
+	return []key.Binding{p.keys.insert, p.keys.enter, p.keys.attach, p.keys.exit}
+}
+
+func (p PromptPane) FullHelp() []key.Binding {
+	return []key.Binding{
+		p.keys.insert,
+		p.keys.enter,
+		p.keys.exit,
+		p.keys.attach,
+		p.keys.paste,
+		p.keys.pasteCode,
+		p.keys.insertCopied,
+		p.keys.attachmentNext,
+		p.keys.removeAttachment,
+		p.keys.spelling,
+		p.keys.clear,
+		p.keys.restoreCleared,
+	}
+}
+
+func (p PromptPane) PaletteCommands() []util.PaletteCommand {
+	return []util.PaletteCommand{
+		{Binding: p.keys.attach, Target: util.PromptPane, RequiresFocus: true},
+		{Binding: p.keys.paste, Target: util.PromptPane, RequiresFocus: true},
+		{Binding: p.keys.pasteCode, Target: util.PromptPane, RequiresFocus: true},
+		{Binding: p.keys.clear, Target: util.PromptPane, RequiresFocus: true},
+		{Binding: p.keys.restoreCleared, Target: util.PromptPane, RequiresFocus: true},
+	}
+}
+
+// Rows added to the prompt pane show the whole prompt wrapped, the input field scrolls horizontally.
+// The end of the prompt is shown if it doesn't fit
+func (p PromptPane) renderPromptPreview(rows int) string {
+	w, _ := util.CalcPromptPaneSize(p.terminalWidth, p.terminalHeight, p.viewMode)
+	wrapped := lipgloss.NewStyle().
+		Width(w - util.DefaultElementsPadding).
+		Foreground(p.colors.NormalTabBorderColor).
+		Render(p.input.Value())
+
+	lines := strings.Split(wrapped, "\n")
+	if len(lines) > rows {
+		lines = lines[len(lines)-rows:]
+	}
+	return lipgloss.NewStyle().Height(rows).Render(strings.Join(lines, "\n"))
+}
+
 func (p PromptPane) View() string {
 	if p.isSessionIdle {
 		content := ""
@@ -684,32 +1210,69 @@ func (p PromptPane) View() string {
 		case util.FilePickerMode:
 			content = p.filePicker.View()
 		case util.TextEditMode:
-			content = p.textEditor.View()
+			content = p.highlightMisspelled(p.textEditor.View())
 		default:
-			content = p.input.View()
+			content = p.highlightMisspelled(p.input.View())
+			if rows := util.GetPromptPaneRows(p.viewMode); rows > 0 {
+				content = lipgloss.JoinVertical(lipgloss.Left, p.renderPromptPreview(rows), content)
+			}
 		}
 
-		infoBlockContent := infoLabel.Render("Use ctrl+a to attach an image")
+		infoBlockContent := infoLabel.Render(i18n.T("prompt.attachHint"))
+		if !p.capabilities.Vision {
+			infoBlockContent = infoLabel.Render(i18n.T("prompt.noVision"))
+		}
 
-		if len(p.attachments) != 0 {
-			imageBlocks := []string{infoPrefix.Render("Attachments: ")}
-			for _, image := range p.attachments {
-				fileName := filepath.Base(image.Path)
-				imageBlocks = append(imageBlocks, infoLabel.Render(fileName))
-			}
+		if len(p.misspelled) != 0 && p.viewMode != util.FilePickerMode {
+			misspelled := infoLabel.Render(i18n.Tf("prompt.misspelled", len(p.misspelled), p.keys.spelling.Help().Key))
+			infoBlockContent = lipgloss.JoinHorizontal(lipgloss.Left, infoBlockContent, misspelled)
+		}
 
-			infoBlockContent = lipgloss.JoinHorizontal(lipgloss.Left, imageBlocks...)
+		if p.promptTokens != 0 && p.viewMode != util.FilePickerMode {
+			infoBlockContent = lipgloss.JoinHorizontal(lipgloss.Left, infoBlockContent, p.renderTokenEstimate())
+		}
+
+		if p.broadcastTargets != 0 {
+			broadcast := infoPrefix.Render(i18n.Tf("prompt.broadcast", p.broadcastTargets))
+			infoBlockContent = lipgloss.JoinHorizontal(lipgloss.Left, infoLabel.Render(broadcast), infoBlockContent)
 		}
 
 		if p.operation == util.SystemMessageEditing {
-			infoBlockContent = infoLabel.Render("Editing system prompt")
+			infoBlockContent = infoLabel.Render(i18n.T("prompt.editingSystemPrompt"))
+		}
+
+		if p.spelling != nil {
+			infoBlockContent = p.renderSpellingPopup()
+		}
+
+		if p.readOnly != nil {
+			infoBlockContent = p.renderReadOnlyNotice()
+		}
+
+		if p.codeFence != nil {
+			infoBlockContent = p.renderCodeFenceLanguage()
+		}
+
+		if p.isConfirmingClear {
+			infoBlockContent = p.renderClearConfirmation()
 		}
 
-		return zone.Mark("prompt_pane", lipgloss.JoinVertical(lipgloss.Left,
-			p.inputContainer.Render(content),
-			infoBlockStyle.Render(infoBlockContent),
-		))
+		if p.naming != nil {
+			content = p.naming.input.View()
+			infoBlockContent = p.renderQuickChatNaming()
+		}
+
+		if p.ratingNote != nil {
+			content = p.ratingNote.input.View()
+			infoBlockContent = p.renderRatingNote()
+		}
+
+		rows := []string{p.inputContainer.Render(content), infoBlockStyle.Render(infoBlockContent)}
+		if len(p.attachments) != 0 {
+			rows = append([]string{p.renderAttachmentChips()}, rows...)
+		}
+		return zone.Mark("prompt_pane", lipgloss.JoinVertical(lipgloss.Left, rows...))
 	}
 
-	return zone.Mark("prompt_pane", p.inputContainer.Render(ResponseWaitingMsg))
+	return zone.Mark("prompt_pane", p.inputContainer.Render(i18n.T("prompt.waiting")))
 }
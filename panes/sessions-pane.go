@@ -4,12 +4,18 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/BalanceBalls/nekot/clients"
 	"github.com/BalanceBalls/nekot/components"
 	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/extensions/hooks"
+	"github.com/BalanceBalls/nekot/i18n"
 	"github.com/BalanceBalls/nekot/sessions"
+	"github.com/BalanceBalls/nekot/settings"
 	"github.com/BalanceBalls/nekot/user"
 	"github.com/BalanceBalls/nekot/util"
 	"github.com/charmbracelet/bubbles/key"
@@ -28,41 +34,70 @@ const (
 	defaultMode operationMode = iota
 	editMode
 	deleteMode
+	shareMode
 )
 
 type sessionsKeyMap struct {
-	addNew key.Binding
-	delete key.Binding
-	rename key.Binding
-	export key.Binding
-	cancel key.Binding
-	apply  key.Binding
+	addNew    key.Binding
+	delete    key.Binding
+	rename    key.Binding
+	export    key.Binding
+	ratings   key.Binding
+	fineTune  key.Binding
+	share     key.Binding
+	translate key.Binding
+	duplicate key.Binding
+	readOnly  key.Binding
+	broadcast key.Binding
+	clearAll  key.Binding
+	cancel    key.Binding
+	apply     key.Binding
 }
 
 var defaultSessionsKeyMap = sessionsKeyMap{
-	delete: key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "d delete")),
-	rename: key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "e edit")),
-	export: key.NewBinding(key.WithKeys("X"), key.WithHelp("X", "shift+x export")),
-	cancel: key.NewBinding(key.WithKeys(tea.KeyEsc.String()), key.WithHelp("esc", "cancel action")),
+	delete: key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "keys.delete")),
+	rename: key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "keys.edit")),
+	export: key.NewBinding(key.WithKeys("X"), key.WithHelp("shift+x", "keys.export")),
+	share:  key.NewBinding(key.WithKeys("S"), key.WithHelp("shift+s", "keys.share")),
+	translate: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "keys.translate"),
+	),
+	duplicate: key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "keys.copy")),
+	readOnly:  key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "keys.lock")),
+	cancel:    key.NewBinding(key.WithKeys(tea.KeyEsc.String()), key.WithHelp("esc", "keys.cancelAction")),
 	apply: key.NewBinding(
 		key.WithKeys(tea.KeyEnter.String()),
-		key.WithHelp("esc", "switch to session/apply renaming"),
+		key.WithHelp("enter", "keys.switchSession"),
 	),
-	addNew: key.NewBinding(key.WithKeys("ctrl+n"), key.WithHelp("ctrl+n", "ctrl+n add new")),
+	addNew:    key.NewBinding(key.WithKeys("ctrl+n"), key.WithHelp("ctrl+n", "keys.addNew")),
+	broadcast: key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "keys.broadcast")),
+	clearAll:  key.NewBinding(key.WithKeys("B"), key.WithHelp("shift+b", "keys.clearBroadcast")),
+	ratings:   key.NewBinding(key.WithKeys("R"), key.WithHelp("shift+r", "keys.exportRatings")),
+	fineTune:  key.NewBinding(key.WithKeys("F"), key.WithHelp("shift+f", "keys.fineTuningExport")),
 }
 
 var tips = []string{
-	strings.Join([]string{
-		defaultSessionsKeyMap.addNew.Help().Desc,
-		util.TipsSeparator,
-		defaultSessionsKeyMap.export.Help().Desc,
-	}, ""),
-	strings.Join([]string{
-		defaultSessionsKeyMap.rename.Help().Desc,
-		util.TipsSeparator,
-		defaultSessionsKeyMap.delete.Help().Desc,
-		util.TipsSeparator,
-		"/ filter"}, ""),
+	util.RenderKeyHints([]key.Binding{
+		defaultSessionsKeyMap.addNew,
+		defaultSessionsKeyMap.export,
+		defaultSessionsKeyMap.fineTune,
+	}),
+	util.RenderKeyHints([]key.Binding{
+		defaultSessionsKeyMap.rename,
+		defaultSessionsKeyMap.delete,
+		defaultSessionsKeyMap.readOnly,
+	}) + util.TipsSeparator + "/ filter",
+	util.RenderKeyHints([]key.Binding{
+		defaultSessionsKeyMap.share,
+		defaultSessionsKeyMap.translate,
+		defaultSessionsKeyMap.duplicate,
+	}),
+	util.RenderKeyHints([]key.Binding{
+		defaultSessionsKeyMap.broadcast,
+		defaultSessionsKeyMap.clearAll,
+		defaultSessionsKeyMap.ratings,
+	}),
 }
 var tipsOffset = len(tips) - 1 // 1 is the input field height
 
@@ -71,12 +106,17 @@ type SessionsPane struct {
 	sessionsList     components.SessionsList
 	textInput        textinput.Model
 	sessionService   *sessions.SessionService
+	promptsService   *settings.SystemPromptsService
 	userService      *user.UserService
 	container        lipgloss.Style
 	colors           util.SchemeColors
 	currentSession   sessions.Session
 	operationMode    operationMode
 	keyMap           sessionsKeyMap
+	// Sessions a prompt is sent to instead of the current one, in the order they were marked
+	broadcastTargets []int
+	// Sessions without a prompt of their own are answered with the prompt of the current preset
+	presetSettings util.Settings
 
 	sessionsListReady  bool
 	currentSessionId   int
@@ -108,17 +148,36 @@ func NewSessionsPane(db *sql.DB, ctx context.Context) SessionsPane {
 		keyMap:            defaultSessionsKeyMap,
 		colors:            colors,
 		sessionService:    ss,
+		promptsService:    settings.NewSystemPromptsService(db),
 		userService:       us,
 		isFocused:         false,
 		terminalWidth:     util.DefaultTerminalWidth,
 		terminalHeight:    util.DefaultTerminalHeight,
 		container: lipgloss.NewStyle().
 			AlignVertical(lipgloss.Top).
-			Border(lipgloss.ThickBorder(), true).
+			Border(util.ThickBorder(), true).
 			BorderForeground(colors.NormalTabBorderColor),
 	}
 }
 
+// The sessions list is created again, since the list styles are set on creation
+func (p SessionsPane) SetColors(colors util.SchemeColors) SessionsPane {
+	p.colors = colors
+	p.container = p.container.BorderForeground(colors.NormalTabBorderColor)
+	if !p.sessionsListReady {
+		return p
+	}
+
+	offset := 0
+	if p.isFocused {
+		offset = tipsOffset
+	}
+	items := constructSessionsListItems(p.sessionsListData, p.currentSessionId, p.broadcastTargets)
+	w, h := util.CalcSessionsListSize(p.terminalWidth, p.terminalHeight, offset)
+	p.sessionsList = components.NewSessionsList(items, w, h, colors)
+	return p
+}
+
 func (p SessionsPane) Init() tea.Cmd {
 	return nil
 }
@@ -131,6 +190,14 @@ func (p SessionsPane) Update(msg tea.Msg) (SessionsPane, tea.Cmd) {
 
 	switch msg := msg.(type) {
 
+	case config.ConfigUpdated:
+		p.config = msg.Config
+
+	case settings.UpdateSettingsEvent:
+		if msg.Err == nil {
+			p.presetSettings = msg.Settings
+		}
+
 	case util.AddNewSessionMsg:
 		cmds = append(cmds, p.addNewSession(msg))
 
@@ -142,7 +209,7 @@ func (p SessionsPane) Update(msg tea.Msg) (SessionsPane, tea.Cmd) {
 		p.currentSession = msg.Session
 		p.sessionsListData = msg.AllSessions
 		p.currentSessionId = msg.CurrentActiveSessionID
-		listItems := constructSessionsListItems(msg.AllSessions, msg.CurrentActiveSessionID)
+		listItems := constructSessionsListItems(msg.AllSessions, msg.CurrentActiveSessionID, p.broadcastTargets)
 		w, h := util.CalcSessionsListSize(p.terminalWidth, p.terminalHeight, 0)
 		p.sessionsList = components.NewSessionsList(listItems, w, h, p.colors)
 		p.operationMode = defaultMode
@@ -185,31 +252,37 @@ func (p SessionsPane) Update(msg tea.Msg) (SessionsPane, tea.Cmd) {
 		}
 
 	case tea.MouseMsg:
+		// Wheel scrolls the pane under the cursor, regardless of focus
+		if msg.Button == tea.MouseButtonWheelUp || msg.Button == tea.MouseButtonWheelDown {
+			if zone.Get("sessions_pane").InBounds(msg) && p.sessionsListReady && p.operationMode == defaultMode {
+				p.sessionsList, cmd = p.sessionsList.Update(msg)
+			}
+			return p, cmd
+		}
+
 		if !zone.Get("sessions_pane").InBounds(msg) || !p.isFocused {
 			break
 		}
 
 		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
-			for idx, listItem := range p.sessionsList.VisibleItems() {
-				v, _ := listItem.(components.SessionListItem)
-				if zone.Get(v.Id).InBounds(msg) {
-
-					selected, ok := p.sessionsList.GetSelectedItem()
-					if ok && selected.SessionId == v.SessionId {
-						session, err := p.sessionService.GetSession(v.SessionId)
-						p.currentSessionId = v.SessionId
-						if err != nil {
-							return p, util.MakeErrorMsg(err.Error())
-						}
-
-						cmds = append(cmds, p.handleUpdateCurrentSession(session))
-						break
-					}
-
-					p.sessionsList.SetSelectedItem(idx)
-					break
+			idx, v, ok := p.getClickedItem(msg)
+			if !ok {
+				break
+			}
+
+			selected, ok := p.sessionsList.GetSelectedItem()
+			if ok && selected.SessionId == v.SessionId {
+				session, err := p.sessionService.GetSession(v.SessionId)
+				p.currentSessionId = v.SessionId
+				if err != nil {
+					return p, util.MakeErrorMsg(err.Error())
 				}
+
+				cmds = append(cmds, p.handleUpdateCurrentSession(session))
+				break
 			}
+
+			p.sessionsList.SetSelectedItem(idx)
 		}
 
 	case tea.KeyMsg:
@@ -224,6 +297,9 @@ func (p SessionsPane) Update(msg tea.Msg) (SessionsPane, tea.Cmd) {
 			case editMode:
 				cmd = p.handleEditMode(msg)
 				cmds = append(cmds, cmd)
+			case shareMode:
+				cmd = p.handleShareMode(msg)
+				cmds = append(cmds, cmd)
 			}
 		}
 	}
@@ -236,6 +312,45 @@ func (p SessionsPane) Update(msg tea.Msg) (SessionsPane, tea.Cmd) {
 	return p, tea.Batch(cmds...)
 }
 
+func (p SessionsPane) ShortHelp() []key.Binding {
+	return []key.Binding{p.keyMap.apply, p.keyMap.addNew, p.keyMap.rename, p.keyMap.delete, p.keyMap.export}
+}
+
+func (p SessionsPane) FullHelp() []key.Binding {
+	return []key.Binding{
+		p.keyMap.apply,
+		p.keyMap.addNew,
+		p.keyMap.rename,
+		p.keyMap.delete,
+		p.keyMap.export,
+		p.keyMap.ratings,
+		p.keyMap.fineTune,
+		p.keyMap.share,
+		p.keyMap.translate,
+		p.keyMap.duplicate,
+		p.keyMap.readOnly,
+		p.keyMap.broadcast,
+		p.keyMap.clearAll,
+		p.keyMap.cancel,
+	}
+}
+
+func (p SessionsPane) PaletteCommands() []util.PaletteCommand {
+	return []util.PaletteCommand{
+		{Binding: p.keyMap.rename, Target: util.SessionsPane, RequiresFocus: true},
+		{Binding: p.keyMap.delete, Target: util.SessionsPane, RequiresFocus: true},
+		{Binding: p.keyMap.export, Target: util.SessionsPane, RequiresFocus: true},
+		{Binding: p.keyMap.ratings, Target: util.SessionsPane, RequiresFocus: true},
+		{Binding: p.keyMap.fineTune, Target: util.SessionsPane, RequiresFocus: true},
+		{Binding: p.keyMap.share, Target: util.SessionsPane, RequiresFocus: true},
+		{Binding: p.keyMap.translate, Target: util.SessionsPane, RequiresFocus: true},
+		{Binding: p.keyMap.duplicate, Target: util.SessionsPane, RequiresFocus: true},
+		{Binding: p.keyMap.readOnly, Target: util.SessionsPane, RequiresFocus: true},
+		{Binding: p.keyMap.broadcast, Target: util.SessionsPane, RequiresFocus: true},
+		{Binding: p.keyMap.clearAll, Target: util.SessionsPane, RequiresFocus: true},
+	}
+}
+
 func (p SessionsPane) View() string {
 	listView := p.normalListView()
 	borderColor := p.colors.NormalTabBorderColor
@@ -294,7 +409,7 @@ func (p *SessionsPane) handleDefaultMode(msg tea.KeyMsg) tea.Cmd {
 		i, ok := p.sessionsList.GetSelectedItem()
 		if ok {
 			p.operationTargetId = i.SessionId
-			p.textInput = p.createInput("New Session Name", 100, util.EmptyValidator)
+			p.textInput = p.createInput(i18n.T("sessions.newName"), 100, util.EmptyValidator)
 		}
 
 		cmd = p.textInput.Focus()
@@ -308,14 +423,65 @@ func (p *SessionsPane) handleDefaultMode(msg tea.KeyMsg) tea.Cmd {
 				break
 			}
 
-			err = sessions.ExportSessionToMarkdown(session, p.config.SessionExportDir)
+			path, err := sessions.ExportSessionToMarkdown(session, p.config.SessionExportDir)
 			if err != nil {
 				cmd = util.MakeErrorMsg(err.Error())
 			} else {
-				cmd = util.SendNotificationMsg(util.SessionExportedNotification)
+				cmd = tea.Batch(
+					util.SendNotificationMsg(util.SessionExportedNotification),
+					runExportHook(p.config.Hooks.OnExport, session, path),
+				)
 			}
 		}
 
+	case key.Matches(msg, p.keyMap.ratings):
+		cmd = p.exportRatings()
+
+	case key.Matches(msg, p.keyMap.fineTune):
+		cmd = p.exportFineTuning()
+
+	case key.Matches(msg, p.keyMap.translate):
+		i, ok := p.sessionsList.GetSelectedItem()
+		if ok {
+			cmd = p.toggleTranslation(i.SessionId)
+		}
+
+	case key.Matches(msg, p.keyMap.duplicate):
+		i, ok := p.sessionsList.GetSelectedItem()
+		if ok {
+			cmd = p.DuplicateSession(i.SessionId)
+		}
+
+	case key.Matches(msg, p.keyMap.readOnly):
+		i, ok := p.sessionsList.GetSelectedItem()
+		if ok {
+			cmd = p.toggleReadOnly(i.SessionId)
+		}
+
+	case key.Matches(msg, p.keyMap.broadcast):
+		i, ok := p.sessionsList.GetSelectedItem()
+		if ok {
+			cmd = p.toggleBroadcastTarget(i.SessionId)
+		}
+
+	case key.Matches(msg, p.keyMap.clearAll):
+		if len(p.broadcastTargets) != 0 {
+			p.broadcastTargets = []int{}
+			p.updateSessionsList()
+			cmd = sessions.SendBroadcastTargetsChangedMsg(p.BroadcastTargets())
+		}
+
+	case key.Matches(msg, p.keyMap.share):
+		i, ok := p.sessionsList.GetSelectedItem()
+		if !ok {
+			break
+		}
+
+		p.operationMode = shareMode
+		p.operationTargetId = i.SessionId
+		p.textInput = p.createInput(i18n.T("sessions.shareConfirm"), 1, util.DeleteSessionValidator)
+		cmd = p.textInput.Focus()
+
 	case key.Matches(msg, p.keyMap.delete):
 		i, ok := p.sessionsList.GetSelectedItem()
 		if p.currentSession.ID == i.SessionId {
@@ -325,7 +491,7 @@ func (p *SessionsPane) handleDefaultMode(msg tea.KeyMsg) tea.Cmd {
 		p.operationMode = deleteMode
 		if ok {
 			p.operationTargetId = i.SessionId
-			p.textInput = p.createInput("Delete session? y/n", 1, util.DeleteSessionValidator)
+			p.textInput = p.createInput(i18n.T("sessions.deleteConfirm"), 1, util.DeleteSessionValidator)
 		}
 
 		cmd = p.textInput.Focus()
@@ -346,9 +512,128 @@ func (p *SessionsPane) addNewSession(msg util.AddNewSessionMsg) tea.Cmd {
 
 	cmd := p.handleUpdateCurrentSession(newSession)
 	p.updateSessionsList()
+
+	if !msg.IsTemporary {
+		cmd = tea.Batch(cmd, hooks.Run(p.config.Hooks.OnSessionCreated, hooks.Event{
+			Hook:  hooks.SessionCreatedHook,
+			Input: newSession.SessionName,
+			Env:   sessionHookEnv(newSession),
+		}))
+	}
 	return cmd
 }
 
+// The copy becomes the current session, the original conversation stays as it was
+func (p *SessionsPane) DuplicateSession(id int) tea.Cmd {
+	session, err := p.sessionService.GetSession(id)
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+
+	copied, err := p.sessionService.DuplicateSession(id, i18n.Tf("sessions.copyName", session.SessionName))
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+
+	cmd := p.handleUpdateCurrentSession(copied)
+	p.updateSessionsList()
+
+	return tea.Batch(
+		cmd,
+		util.SendToastMsg(i18n.Tf("notification.sessionDuplicated", util.GetSessionName(session.ID, session.SessionName)), util.SuccessSeverity),
+		hooks.Run(p.config.Hooks.OnSessionCreated, hooks.Event{
+			Hook:  hooks.SessionCreatedHook,
+			Input: copied.SessionName,
+			Env:   sessionHookEnv(copied),
+		}),
+	)
+}
+
+func (p *SessionsPane) toggleTranslation(id int) tea.Cmd {
+	session, err := p.sessionService.GetSession(id)
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+
+	enabled := !session.TranslationEnabled
+	err = p.sessionService.UpdateSessionTranslation(id, enabled)
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+
+	toast := i18n.T("notification.translationOff")
+	if enabled {
+		toast = i18n.Tf("notification.translationOn", util.GetSessionName(session.ID, session.SessionName))
+	}
+
+	return tea.Batch(
+		sessions.SendSessionTranslationToggledMsg(id, enabled),
+		util.SendToastMsg(toast, util.InfoSeverity),
+	)
+}
+
+func (p *SessionsPane) toggleReadOnly(id int) tea.Cmd {
+	session, err := p.sessionService.GetSession(id)
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+
+	enabled := !session.ReadOnly
+	err = p.sessionService.UpdateSessionReadOnly(id, enabled)
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+
+	if id == p.currentSession.ID {
+		p.currentSession.ReadOnly = enabled
+	}
+	p.updateSessionsList()
+
+	toast := i18n.Tf("notification.readOnlyOff", util.GetSessionName(session.ID, session.SessionName))
+	if enabled {
+		toast = i18n.Tf("notification.readOnlyOn", util.GetSessionName(session.ID, session.SessionName))
+	}
+
+	return tea.Batch(
+		sessions.SendSessionReadOnlyToggledMsg(id, enabled),
+		util.SendToastMsg(toast, util.InfoSeverity),
+	)
+}
+
+func (p *SessionsPane) toggleBroadcastTarget(id int) tea.Cmd {
+	if idx := slices.Index(p.broadcastTargets, id); idx != -1 {
+		p.broadcastTargets = slices.Delete(p.broadcastTargets, idx, idx+1)
+	} else {
+		p.broadcastTargets = append(p.broadcastTargets, id)
+	}
+
+	p.updateSessionsList()
+	return sessions.SendBroadcastTargetsChangedMsg(p.BroadcastTargets())
+}
+
+// The exported markdown is piped to the hook, the file path is passed as NEKOT_EXPORT_PATH
+func runExportHook(command string, session sessions.Session, path string) tea.Cmd {
+	if command == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return util.MakeRecoverableErrorMsg(err.Error())
+	}
+
+	env := sessionHookEnv(session)
+	env["NEKOT_EXPORT_PATH"] = path
+	return hooks.Run(command, hooks.Event{Hook: hooks.ExportHook, Input: string(content), Env: env})
+}
+
+func sessionHookEnv(session sessions.Session) map[string]string {
+	return map[string]string{
+		"NEKOT_SESSION_ID":   fmt.Sprint(session.ID),
+		"NEKOT_SESSION_NAME": session.SessionName,
+	}
+}
+
 func (p *SessionsPane) handleUpdateCurrentSession(session sessions.Session) tea.Cmd {
 
 	if !p.sessionsListReady {
@@ -365,12 +650,25 @@ func (p *SessionsPane) handleUpdateCurrentSession(session sessions.Session) tea.
 	p.currentSessionId = session.ID
 	p.currentSessionName = session.SessionName
 
-	listItems := constructSessionsListItems(p.sessionsListData, p.currentSessionId)
+	listItems := constructSessionsListItems(p.sessionsListData, p.currentSessionId, p.broadcastTargets)
 	p.sessionsList.SetItems(listItems)
 
 	return sessions.SendUpdateCurrentSessionMsg(session)
 }
 
+func (p SessionsPane) BroadcastTargets() []int {
+	return slices.Clone(p.broadcastTargets)
+}
+
+// Makes the session current, as if it was chosen in the list
+func (p *SessionsPane) SwitchToSession(id int) tea.Cmd {
+	session, err := p.sessionService.GetSession(id)
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+	return p.handleUpdateCurrentSession(session)
+}
+
 func (p *SessionsPane) handleDeleteMode(msg tea.KeyMsg) tea.Cmd {
 	var cmd tea.Cmd
 	p.textInput, cmd = p.textInput.Update(msg)
@@ -382,6 +680,11 @@ func (p *SessionsPane) handleDeleteMode(msg tea.KeyMsg) tea.Cmd {
 		switch decision {
 		case "y":
 			p.sessionService.DeleteSession(p.operationTargetId)
+			cmd = tea.Batch(cmd, clients.ReleaseSessionFiles(p.operationTargetId))
+			if idx := slices.Index(p.broadcastTargets, p.operationTargetId); idx != -1 {
+				p.broadcastTargets = slices.Delete(p.broadcastTargets, idx, idx+1)
+				cmd = tea.Batch(cmd, sessions.SendBroadcastTargetsChangedMsg(p.BroadcastTargets()))
+			}
 			p.updateSessionsList()
 			p.operationTargetId = NoTargetSession
 			p.operationMode = defaultMode
@@ -398,6 +701,149 @@ func (p *SessionsPane) handleDeleteMode(msg tea.KeyMsg) tea.Cmd {
 	return cmd
 }
 
+func (p *SessionsPane) handleShareMode(msg tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+	p.textInput, cmd = p.textInput.Update(msg)
+
+	switch {
+
+	case key.Matches(msg, p.keyMap.apply):
+		decision := p.textInput.Value()
+		switch decision {
+		case "y":
+			cmd = p.shareSession(p.operationTargetId)
+			p.operationMode = defaultMode
+			p.operationTargetId = NoTargetSession
+		case "n":
+			p.operationMode = defaultMode
+			p.operationTargetId = NoTargetSession
+		}
+
+	case key.Matches(msg, p.keyMap.cancel):
+		p.operationMode = defaultMode
+		p.operationTargetId = NoTargetSession
+	}
+
+	return cmd
+}
+
+// Rated answers of all sessions are exported to one file
+func (p SessionsPane) exportRatings() tea.Cmd {
+	allSessions, err := p.sessionService.GetAllSessions()
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+
+	loaded := []sessions.Session{}
+	for _, session := range allSessions {
+		session, err := p.sessionService.GetSession(session.ID)
+		if err != nil {
+			return util.MakeErrorMsg(err.Error())
+		}
+		loaded = append(loaded, session)
+	}
+
+	path, count, err := sessions.ExportRatingsToJSONL(loaded, p.config.SessionExportDir)
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+
+	if count == 0 {
+		return util.SendToastMsg(i18n.T("notification.noRatedAnswers"), util.WarningSeverity)
+	}
+	return util.SendToastMsg(i18n.Tf("notification.ratingsExported", count, path), util.SuccessSeverity)
+}
+
+// Sessions shown in the list, narrowed down with the filter, are exported as fine-tuning examples
+func (p SessionsPane) exportFineTuning() tea.Cmd {
+	loaded := []sessions.Session{}
+	for _, item := range p.sessionsList.VisibleItems() {
+		listItem, ok := item.(components.SessionListItem)
+		if !ok {
+			continue
+		}
+
+		session, err := p.sessionService.GetSession(listItem.SessionId)
+		if err != nil {
+			return util.MakeErrorMsg(err.Error())
+		}
+		loaded = append(loaded, session)
+	}
+
+	toolCalls := p.config.FineTuningToolCalls
+	if toolCalls == "" {
+		toolCalls = util.ExcludeToolCalls
+	}
+
+	path, count, err := sessions.ExportSessionsToFineTuningJSONL(
+		loaded,
+		p.getSystemPrompt,
+		toolCalls,
+		p.config.SessionExportDir)
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+
+	if count == 0 {
+		return util.SendToastMsg(i18n.T("notification.noFineTuningSessions"), util.WarningSeverity)
+	}
+	return util.SendToastMsg(i18n.Tf("notification.fineTuningExported", count, path), util.SuccessSeverity)
+}
+
+// The prompt of the session persona takes precedence over the prompt assigned to the session,
+// which in turn takes precedence over the one of the current preset
+func (p SessionsPane) getSystemPrompt(session sessions.Session) string {
+	if session.Persona != nil && session.Persona.SystemPrompt != "" {
+		return session.Persona.SystemPrompt
+	}
+
+	promptId := p.presetSettings.SystemPromptId
+	if session.SystemPromptId != nil {
+		promptId = session.SystemPromptId
+	}
+
+	if promptId != nil {
+		prompt, err := p.promptsService.GetSystemPrompt(*promptId)
+		if err == nil {
+			return prompt.Content
+		}
+		util.Slog.Warn("failed to load system prompt from the library", "id", *promptId, "error", err)
+	}
+
+	if p.presetSettings.SystemPrompt != nil {
+		return *p.presetSettings.SystemPrompt
+	}
+	return ""
+}
+
+// Uploads the session in the background and copies the link to the clipboard
+func (p SessionsPane) shareSession(sessionId int) tea.Cmd {
+	session, err := p.sessionService.GetSession(sessionId)
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+
+	service := p.config.ShareService
+	endpoint := p.config.ShareEndpoint
+
+	return tea.Batch(
+		util.SendToastMsg(i18n.T("notification.sessionSharing"), util.InfoSeverity),
+		func() tea.Msg {
+			url, err := sessions.ShareSession(context.Background(), session, service, endpoint)
+			if err != nil {
+				util.Slog.Error("failed to share session", "error", err.Error())
+				return util.ErrorEvent{Message: err.Error(), Recoverable: true}
+			}
+
+			util.Slog.Info("session shared", "url", url)
+			if util.CopyToClipboard(url) != nil {
+				return util.ToastMsg{Text: i18n.Tf("notification.sessionShared", url), Severity: util.SuccessSeverity}
+			}
+			return util.ToastMsg{Text: i18n.Tf("notification.sessionSharedCopied", url), Severity: util.SuccessSeverity}
+		},
+	)
+}
+
 func (p *SessionsPane) handleEditMode(msg tea.KeyMsg) tea.Cmd {
 	var cmd tea.Cmd
 	p.textInput, cmd = p.textInput.Update(msg)
@@ -408,6 +854,7 @@ func (p *SessionsPane) handleEditMode(msg tea.KeyMsg) tea.Cmd {
 		if p.textInput.Value() != "" {
 			p.sessionService.UpdateSessionName(p.operationTargetId, p.textInput.Value())
 			p.updateSessionsList()
+			cmd = tea.Batch(cmd, sessions.SendSessionRenamedMsg(p.operationTargetId, p.textInput.Value()))
 			p.operationTargetId = NoTargetSession
 			p.operationMode = defaultMode
 		}
@@ -420,15 +867,17 @@ func (p *SessionsPane) handleEditMode(msg tea.KeyMsg) tea.Cmd {
 	return cmd
 }
 
-func constructSessionsListItems(sessions []sessions.Session, currentSessionId int) []list.Item {
+func constructSessionsListItems(sessions []sessions.Session, currentSessionId int, broadcastTargets []int) []list.Item {
 	items := []list.Item{}
 
 	for _, session := range sessions {
 		anItem := components.SessionListItem{
-			Id:        "session_list_item_" + fmt.Sprint(session.ID),
-			SessionId: session.ID,
-			Text:      session.SessionName,
-			IsActive:  session.ID == currentSessionId,
+			Id:                "session_list_item_" + fmt.Sprint(session.ID),
+			SessionId:         session.ID,
+			Text:              util.GetSessionName(session.ID, session.SessionName),
+			IsActive:          session.ID == currentSessionId,
+			IsBroadcastTarget: slices.Contains(broadcastTargets, session.ID),
+			IsReadOnly:        session.ReadOnly,
 		}
 		items = append(items, anItem)
 	}
@@ -438,13 +887,13 @@ func constructSessionsListItems(sessions []sessions.Session, currentSessionId in
 
 func (p *SessionsPane) updateSessionsList() {
 	p.sessionsListData, _ = p.sessionService.GetAllSessions()
-	items := constructSessionsListItems(p.sessionsListData, p.currentSessionId)
+	items := constructSessionsListItems(p.sessionsListData, p.currentSessionId, p.broadcastTargets)
 	p.sessionsList.SetItems(items)
 }
 
 func (p SessionsPane) listHeader(str ...string) string {
 	return lipgloss.NewStyle().
-		BorderStyle(lipgloss.ThickBorder()).
+		BorderStyle(util.ThickBorder()).
 		BorderBottom(true).
 		Bold(true).
 		Foreground(p.colors.DefaultTextColor).
@@ -480,9 +929,13 @@ func (p SessionsPane) normalListView() string {
 	listWidth := p.sessionsList.GetWidth()
 	for _, session := range p.sessionsListData {
 		isCurrentSession := p.currentSessionId == session.ID
+		name := util.GetSessionName(session.ID, session.SessionName)
+		if slices.Contains(p.broadcastTargets, session.ID) {
+			name = components.GetBroadcastTargetMark() + " " + name
+		}
 		sessionListItems = append(
 			sessionListItems,
-			p.listItem(fmt.Sprint(session.ID), session.SessionName, isCurrentSession, listWidth),
+			p.listItem(fmt.Sprint(session.ID), name, isCurrentSession, listWidth),
 		)
 	}
 
@@ -501,6 +954,61 @@ func (p SessionsPane) AllowFocusChange(isMouseEvent bool) bool {
 	return p.operationMode == defaultMode
 }
 
+// While a response is processed the list can only be browsed: scrolled, filtered and moved through.
+// Actions that change sessions are ignored, as they are when the pane is not updated at all
+func (p SessionsPane) UpdateWhileProcessing(msg tea.Msg) (SessionsPane, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case config.ConfigUpdated,
+		settings.UpdateSettingsEvent,
+		sessions.RefreshSessionsList,
+		sessions.LoadDataFromDB,
+		util.FocusEvent,
+		util.ProcessingStateChanged,
+		tea.WindowSizeMsg:
+		return p.Update(msg)
+
+	case tea.MouseMsg:
+		if msg.Button == tea.MouseButtonWheelUp || msg.Button == tea.MouseButtonWheelDown {
+			return p.Update(msg)
+		}
+
+		// clicking a selected session opens it, here the click only selects
+		if p.isFocused && msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			if idx, _, ok := p.getClickedItem(msg); ok {
+				p.sessionsList.SetSelectedItem(idx)
+			}
+		}
+
+	case tea.KeyMsg:
+		// the list handles moving through it and the filter, other keys are actions of the pane
+		if p.isFocused && p.sessionsListReady && p.operationMode == defaultMode {
+			p.sessionsList, cmd = p.sessionsList.Update(msg)
+		}
+	}
+
+	return p, cmd
+}
+
+func (p SessionsPane) getClickedItem(msg tea.MouseMsg) (int, components.SessionListItem, bool) {
+	if !p.sessionsListReady || !zone.Get("sessions_pane").InBounds(msg) {
+		return 0, components.SessionListItem{}, false
+	}
+
+	for idx, listItem := range p.sessionsList.VisibleItems() {
+		item, _ := listItem.(components.SessionListItem)
+		if zone.Get(item.Id).InBounds(msg) {
+			return idx, item, true
+		}
+	}
+	return 0, components.SessionListItem{}, false
+}
+
+func (p SessionsPane) IsFiltering() bool {
+	return p.sessionsList.IsFiltering()
+}
+
 func (p SessionsPane) createInput(
 	placeholder string,
 	charLimit int,
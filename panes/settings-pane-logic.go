@@ -3,10 +3,13 @@ package panes
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
-	"time"
+	"strings"
 
 	"github.com/BalanceBalls/nekot/components"
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/sessions"
 	"github.com/BalanceBalls/nekot/settings"
 	"github.com/BalanceBalls/nekot/util"
 	"github.com/charmbracelet/bubbles/key"
@@ -24,6 +27,14 @@ func (p *SettingsPane) handlePresetModeMouse(msg tea.MouseMsg) tea.Cmd {
 		p.viewMode = defaultView
 	}
 
+	if zone.Get("set_p_prompts_tab").InBounds(msg) && p.viewMode == presetsView {
+		return p.switchToPrompts()
+	}
+
+	if zone.Get("set_p_personas_tab").InBounds(msg) && p.viewMode == presetsView {
+		return p.switchToPersonas()
+	}
+
 	if msg.Action == tea.MouseActionRelease && msg.Button == tea.MouseButtonLeft && p.viewMode == presetsView {
 		for _, listItem := range p.presetPicker.VisibleItems() {
 			v, _ := listItem.(components.PresetsListItem)
@@ -55,6 +66,13 @@ func (p *SettingsPane) handlePresetMode(msg tea.KeyMsg) tea.Cmd {
 		p.viewMode = defaultView
 		return cmd
 
+	case key.Matches(msg, p.keyMap.presetsMenu):
+		if msg.String() == tea.KeyRight.String() && !p.presetPicker.IsLastPage() {
+			return nil
+		}
+
+		return p.switchToPrompts()
+
 	case key.Matches(msg, p.keyMap.choose):
 		i, ok := p.presetPicker.GetSelectedItem()
 		if ok {
@@ -75,12 +93,325 @@ func (p *SettingsPane) selectPreset(presetId int) tea.Cmd {
 	}
 
 	preset.Model = p.settings.Model
+	preset, err = p.settingsService.ApplyProviderProfile(preset, p.apiProvider)
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+
 	p.viewMode = defaultView
 	p.settings = preset
 
 	return settings.MakeSettingsUpdateMsg(p.settings, nil)
 }
 
+func (p *SettingsPane) handlePromptsModeMouse(msg tea.MouseMsg) tea.Cmd {
+	if zone.Get("set_p_settings_tab").InBounds(msg) && p.viewMode == promptsView {
+		p.viewMode = defaultView
+		p.changeMode = inactive
+		return nil
+	}
+
+	if zone.Get("set_p_presets_tab").InBounds(msg) && p.viewMode == promptsView {
+		p.changeMode = inactive
+		return p.switchToPresets()
+	}
+
+	if zone.Get("set_p_personas_tab").InBounds(msg) && p.viewMode == promptsView {
+		p.changeMode = inactive
+		return p.switchToPersonas()
+	}
+
+	if p.changeMode == inactive && p.viewMode == promptsView {
+		for _, listItem := range p.promptPicker.VisibleItems() {
+			v, _ := listItem.(components.SystemPromptsListItem)
+			if zone.Get(v.Id).InBounds(msg) {
+				return p.assignPromptToPreset(&v.PromptId)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *SettingsPane) handlePromptsMode(msg tea.KeyMsg) tea.Cmd {
+	if p.promptPicker.IsFiltering() || p.promptPicker.IsConfirming() {
+		return nil
+	}
+
+	switch {
+	case key.Matches(msg, p.keyMap.goBack):
+		if msg.String() == tea.KeyLeft.String() && !p.promptPicker.IsFirstPage() {
+			return nil
+		}
+
+		if msg.String() == tea.KeyEsc.String() {
+			p.viewMode = defaultView
+			return nil
+		}
+
+		return p.switchToPresets()
+
+	case key.Matches(msg, p.keyMap.presetsMenu):
+		if msg.String() == tea.KeyRight.String() && !p.promptPicker.IsLastPage() {
+			return nil
+		}
+
+		return p.switchToPersonas()
+
+	case key.Matches(msg, p.keyMap.savePrompt):
+		if p.settings.SystemPrompt == nil || *p.settings.SystemPrompt == "" {
+			return util.SendToastMsg(i18n.T("notification.noSystemPromptToSave"), util.WarningSeverity)
+		}
+
+		return p.configureInput(
+			i18n.T("settings.promptName"),
+			util.EmptyValidator,
+			promptNameChange)
+
+	case key.Matches(msg, p.keyMap.assignPrompt):
+		i, ok := p.promptPicker.GetSelectedItem()
+		if ok {
+			promptId := i.PromptId
+			return sessions.SendAssignSessionSystemPromptMsg(&promptId)
+		}
+
+	case key.Matches(msg, p.keyMap.detachPrompt):
+		return tea.Batch(
+			p.assignPromptToPreset(nil),
+			sessions.SendAssignSessionSystemPromptMsg(nil),
+		)
+
+	case key.Matches(msg, p.keyMap.choose):
+		i, ok := p.promptPicker.GetSelectedItem()
+		if ok {
+			promptId := i.PromptId
+			return p.assignPromptToPreset(&promptId)
+		}
+	}
+
+	return nil
+}
+
+// Assigns a prompt from the library to the current preset.
+// The prompt content is copied, so it can be used as a base in the system prompt editor
+func (p *SettingsPane) assignPromptToPreset(promptId *int) tea.Cmd {
+	p.settings.SystemPromptId = promptId
+	if promptId != nil {
+		prompt, err := p.promptsService.GetSystemPrompt(*promptId)
+		if err != nil {
+			return util.MakeErrorMsg(err.Error())
+		}
+		p.keepSystemPromptVersion(prompt.Content)
+		p.settings.SystemPrompt = &prompt.Content
+	}
+
+	var updErr error
+	p.settings, updErr = p.settingsService.UpdateSettings(p.settings)
+	if updErr != nil {
+		return util.MakeErrorMsg(updErr.Error())
+	}
+
+	return tea.Batch(
+		p.switchToPrompts(),
+		settings.MakeSettingsUpdateMsg(p.settings, nil),
+		util.SendNotificationMsg(util.SysPromptChangedNotification))
+}
+
+func (p *SettingsPane) savePromptToLibrary(name string) tea.Cmd {
+	p.changeMode = inactive
+
+	content := ""
+	if p.settings.SystemPrompt != nil {
+		content = *p.settings.SystemPrompt
+	}
+
+	_, err := p.promptsService.SaveSystemPrompt(name, content)
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+
+	return tea.Batch(
+		p.switchToPrompts(),
+		util.SendNotificationMsg(util.SysPromptChangedNotification))
+}
+
+func (p *SettingsPane) handleHistoryModeMouse(msg tea.MouseMsg) tea.Cmd {
+	if zone.Get("set_p_settings_tab").InBounds(msg) {
+		p.viewMode = defaultView
+		return nil
+	}
+
+	for _, listItem := range p.versionPicker.VisibleItems() {
+		v, _ := listItem.(components.SystemPromptVersionsListItem)
+		if zone.Get(v.Id).InBounds(msg) {
+			return p.showVersionDiff(v)
+		}
+	}
+
+	return nil
+}
+
+func (p *SettingsPane) handleHistoryMode(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, p.keyMap.goBack):
+		if msg.String() == tea.KeyLeft.String() && !p.versionPicker.IsFirstPage() {
+			return nil
+		}
+		p.viewMode = defaultView
+
+	case key.Matches(msg, p.keyMap.promptDiff):
+		if v, ok := p.versionPicker.GetSelectedItem(); ok {
+			return p.showVersionDiff(v)
+		}
+
+	case key.Matches(msg, p.keyMap.choose):
+		if v, ok := p.versionPicker.GetSelectedItem(); ok {
+			return p.restoreSystemPromptVersion(v.Content)
+		}
+	}
+
+	return nil
+}
+
+// The replaced prompt is kept as a version, so a restore can be undone the same way
+func (p *SettingsPane) restoreSystemPromptVersion(content string) tea.Cmd {
+	p.keepSystemPromptVersion(content)
+	p.settings.SystemPrompt = &content
+	p.settings.SystemPromptId = nil
+
+	var updErr error
+	p.settings, updErr = p.settingsService.UpdateSettings(p.settings)
+	if updErr != nil {
+		return util.MakeErrorMsg(updErr.Error())
+	}
+
+	return tea.Batch(
+		p.switchToHistory(),
+		settings.MakeSettingsUpdateMsg(p.settings, nil),
+		util.SendNotificationMsg(util.SysPromptChangedNotification))
+}
+
+func (p SettingsPane) showVersionDiff(version components.SystemPromptVersionsListItem) tea.Cmd {
+	current := ""
+	if p.settings.SystemPrompt != nil {
+		current = *p.settings.SystemPrompt
+	}
+
+	title := i18n.Tf("chat.promptDiffTitle", version.CreatedAt, p.settings.PresetName)
+	return util.ShowSystemPromptDiff(title, util.DiffLines(version.Content, current))
+}
+
+// Stores the system prompt of the preset before it is replaced with another one
+func (p SettingsPane) keepSystemPromptVersion(replacement string) {
+	if p.settings.SystemPrompt == nil || strings.TrimSpace(*p.settings.SystemPrompt) == "" {
+		return
+	}
+
+	if *p.settings.SystemPrompt == replacement {
+		return
+	}
+
+	err := p.promptsService.AddSystemPromptVersion(p.settings.ID, *p.settings.SystemPrompt)
+	if err != nil {
+		util.Slog.Error("failed to keep a system prompt version", "error", err.Error())
+	}
+}
+
+func (p *SettingsPane) handlePersonasModeMouse(msg tea.MouseMsg) tea.Cmd {
+	if zone.Get("set_p_settings_tab").InBounds(msg) && p.viewMode == personasView {
+		p.viewMode = defaultView
+		p.changeMode = inactive
+		return nil
+	}
+
+	if zone.Get("set_p_presets_tab").InBounds(msg) && p.viewMode == personasView {
+		p.changeMode = inactive
+		return p.switchToPresets()
+	}
+
+	if zone.Get("set_p_prompts_tab").InBounds(msg) && p.viewMode == personasView {
+		p.changeMode = inactive
+		return p.switchToPrompts()
+	}
+
+	if p.changeMode == inactive && p.viewMode == personasView {
+		for _, listItem := range p.personaPicker.VisibleItems() {
+			v, _ := listItem.(components.PersonasListItem)
+			if zone.Get(v.Id).InBounds(msg) {
+				return sessions.SendAssignSessionPersonaMsg(&v.PersonaId)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *SettingsPane) handlePersonasMode(msg tea.KeyMsg) tea.Cmd {
+	if p.personaPicker.IsFiltering() || p.personaPicker.IsConfirming() {
+		return nil
+	}
+
+	switch {
+	case key.Matches(msg, p.keyMap.goBack):
+		if msg.String() == tea.KeyLeft.String() && !p.personaPicker.IsFirstPage() {
+			return nil
+		}
+
+		if msg.String() == tea.KeyEsc.String() {
+			p.viewMode = defaultView
+			return nil
+		}
+
+		return p.switchToPrompts()
+
+	case key.Matches(msg, p.keyMap.savePersona):
+		return p.configureInput(
+			i18n.T("settings.personaName"),
+			util.EmptyValidator,
+			personaNameChange)
+
+	case key.Matches(msg, p.keyMap.detachPersona):
+		return sessions.SendAssignSessionPersonaMsg(nil)
+
+	case key.Matches(msg, p.keyMap.choose, p.keyMap.assignPrompt):
+		i, ok := p.personaPicker.GetSelectedItem()
+		if ok {
+			personaId := i.PersonaId
+			return sessions.SendAssignSessionPersonaMsg(&personaId)
+		}
+	}
+
+	return nil
+}
+
+// Saves the model and the system prompt of the current preset as a persona
+func (p *SettingsPane) savePersona(input string) tea.Cmd {
+	p.changeMode = inactive
+
+	name, color := util.ParsePersonaInput(input)
+	if name == "" {
+		return util.MakeRecoverableErrorMsg("Persona name must not be empty")
+	}
+
+	persona := util.Persona{
+		Name:  name,
+		Color: color,
+		Model: p.settings.Model,
+	}
+	if p.settings.SystemPrompt != nil {
+		persona.SystemPrompt = *p.settings.SystemPrompt
+	}
+
+	_, err := p.personasService.SavePersona(persona)
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+
+	return tea.Batch(
+		p.switchToPersonas(),
+		util.SendToastMsg(i18n.Tf("notification.personaSaved", name), util.SuccessSeverity))
+}
+
 func (p *SettingsPane) handleModelModeMouse(msg tea.MouseMsg) tea.Cmd {
 	if zone.Get("set_p_presets_tab").InBounds(msg) && p.viewMode == modelsView {
 		return p.switchToPresets()
@@ -128,6 +459,10 @@ func (p *SettingsPane) selectModel(model string) tea.Cmd {
 	p.settings.Model = string(model)
 	p.viewMode = defaultView
 
+	if !util.GetModelCapabilities(p.apiProvider, p.settings.Model).Tools {
+		p.settings.WebSearchEnabled = false
+	}
+
 	var updateError error
 	p.settings, updateError = settingsService.UpdateSettings(p.settings)
 	if updateError != nil {
@@ -142,6 +477,14 @@ func (p *SettingsPane) handleViewModeMouse(msg tea.MouseMsg) tea.Cmd {
 		return p.switchToPresets()
 	}
 
+	if zone.Get("set_p_prompts_tab").InBounds(msg) && p.viewMode == defaultView {
+		return p.switchToPrompts()
+	}
+
+	if zone.Get("set_p_personas_tab").InBounds(msg) && p.viewMode == defaultView {
+		return p.switchToPersonas()
+	}
+
 	if zone.Get("set_p_preset_item").InBounds(msg) && p.viewMode == defaultView {
 		return p.switchToPresets()
 	}
@@ -151,19 +494,23 @@ func (p *SettingsPane) handleViewModeMouse(msg tea.MouseMsg) tea.Cmd {
 	}
 
 	if zone.Get("max_tokens").InBounds(msg) {
-		return p.configureInput("Enter Max Tokens", util.MaxTokensValidator, maxTokensChange)
+		return p.configureInput(i18n.T("settings.maxTokens"), util.MaxTokensValidator, maxTokensChange)
 	}
 
 	if zone.Get("temperature").InBounds(msg) {
-		return p.configureInput("Enter Temperature "+util.TemperatureRange, util.TemperatureValidator, tempChange)
+		return p.configureInput(i18n.Tf("settings.temperature", util.TemperatureRange), util.TemperatureValidator, tempChange)
 	}
 
 	if zone.Get("frequency").InBounds(msg) {
-		return p.configureInput("Enter Frequency "+util.FrequencyRange, util.FrequencyValidator, frequencyChange)
+		return p.configureInput(i18n.Tf("settings.frequency", util.FrequencyRange), util.FrequencyValidator, frequencyChange)
 	}
 
 	if zone.Get("top_p").InBounds(msg) {
-		return p.configureInput("Enter TopP "+util.TopPRange, util.TopPValidator, topPChange)
+		return p.configureInput(i18n.Tf("settings.topP", util.TopPRange), util.TopPValidator, topPChange)
+	}
+
+	if zone.Get("reasoning_budget").InBounds(msg) {
+		return p.configureInput(i18n.Tf("settings.reasoningBudget", util.ReasoningBudgetRange), util.ReasoningBudgetValidator, reasoningBudgetChange)
 	}
 
 	return nil
@@ -181,7 +528,7 @@ func (p *SettingsPane) handleViewMode(msg tea.KeyMsg) tea.Cmd {
 
 	case key.Matches(msg, p.keyMap.savePreset):
 		cmd = p.configureInput(
-			"Enter name for a preset",
+			i18n.T("settings.presetName"),
 			util.EmptyValidator,
 			presetChange)
 
@@ -193,6 +540,11 @@ func (p *SettingsPane) handleViewMode(msg tea.KeyMsg) tea.Cmd {
 		}
 		cmd = settings.MakeSettingsUpdateMsg(p.settings, nil)
 
+	case key.Matches(msg, p.keyMap.checkConnection):
+		p.checkingConnection = true
+		p.connectionCheck = nil
+		cmd = settings.CheckConnection(p.mainCtx, *p.config, p.settings.Model)
+
 	case key.Matches(msg, p.keyMap.editSysPrompt):
 		content := ""
 		if p.settings.SystemPrompt != nil {
@@ -200,14 +552,19 @@ func (p *SettingsPane) handleViewMode(msg tea.KeyMsg) tea.Cmd {
 		}
 		cmd = util.SwitchToEditor(content, util.SystemMessageEditing, false)
 
+	case key.Matches(msg, p.keyMap.promptHistory):
+		cmd = p.switchToHistory()
+
 	case key.Matches(msg, p.keyMap.editFrequency):
-		cmd = p.configureInput("Enter Frequency "+util.FrequencyRange, util.FrequencyValidator, frequencyChange)
+		cmd = p.configureInput(i18n.Tf("settings.frequency", util.FrequencyRange), util.FrequencyValidator, frequencyChange)
 	case key.Matches(msg, p.keyMap.editTemp):
-		cmd = p.configureInput("Enter Temperature "+util.TemperatureRange, util.TemperatureValidator, tempChange)
+		cmd = p.configureInput(i18n.Tf("settings.temperature", util.TemperatureRange), util.TemperatureValidator, tempChange)
 	case key.Matches(msg, p.keyMap.editTopP):
-		cmd = p.configureInput("Enter TopP "+util.TopPRange, util.TopPValidator, topPChange)
+		cmd = p.configureInput(i18n.Tf("settings.topP", util.TopPRange), util.TopPValidator, topPChange)
+	case key.Matches(msg, p.keyMap.editReasoning):
+		cmd = p.configureInput(i18n.Tf("settings.reasoningBudget", util.ReasoningBudgetRange), util.ReasoningBudgetValidator, reasoningBudgetChange)
 	case key.Matches(msg, p.keyMap.editMaxTokens):
-		cmd = p.configureInput("Enter Max Tokens", util.MaxTokensValidator, maxTokensChange)
+		cmd = p.configureInput(i18n.T("settings.maxTokens"), util.MaxTokensValidator, maxTokensChange)
 	}
 
 	return cmd
@@ -223,15 +580,49 @@ func (p *SettingsPane) switchToPresets() tea.Cmd {
 	return nil
 }
 
+func (p *SettingsPane) switchToPrompts() tea.Cmd {
+	p.viewMode = promptsView
+	prompts, err := p.promptsService.GetSystemPromptsList()
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+	p.updatePromptsList(prompts)
+	return nil
+}
+
+func (p *SettingsPane) switchToPersonas() tea.Cmd {
+	p.viewMode = personasView
+	personas, err := p.personasService.GetPersonasList()
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+	p.updatePersonasList(personas)
+	return nil
+}
+
+func (p *SettingsPane) switchToHistory() tea.Cmd {
+	p.viewMode = historyView
+	versions, err := p.promptsService.GetSystemPromptVersions(p.settings.ID)
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+	p.updateVersionsList(versions)
+	return nil
+}
+
 func (p *SettingsPane) switchToModelsList() tea.Cmd {
 	p.loading = true
 	p.changeMode = inactive
 	return tea.Batch(
 		func() tea.Msg { return p.loadModels(p.config.Provider, p.config.ProviderBaseUrl) },
-		p.spinner.Tick)
+		p.startSpinner())
 }
 
 func (p *SettingsPane) configureInput(title string, validator func(str string) error, mode settingsChangeMode) tea.Cmd {
+	if param, ok := changeModeToParam[mode]; ok && !util.IsSamplingParamSupported(p.apiProvider, param) {
+		return nil
+	}
+
 	ti := textinput.New()
 	ti.PromptStyle = lipgloss.NewStyle().PaddingLeft(util.DefaultElementsPadding)
 	p.textInput = ti
@@ -250,7 +641,9 @@ func (p *SettingsPane) handleSettingsUpdate(msg tea.KeyMsg) tea.Cmd {
 	switch msg.Type {
 
 	case tea.KeyEsc:
-		p.viewMode = defaultView
+		if p.changeMode != promptNameChange && p.changeMode != personaNameChange {
+			p.viewMode = defaultView
+		}
 		p.changeMode = inactive
 		return cmd
 
@@ -261,6 +654,12 @@ func (p *SettingsPane) handleSettingsUpdate(msg tea.KeyMsg) tea.Cmd {
 		}
 
 		switch p.changeMode {
+		case promptNameChange:
+			return p.savePromptToLibrary(inputValue)
+
+		case personaNameChange:
+			return p.savePersona(inputValue)
+
 		case presetChange:
 			err := p.updatePresetName(inputValue)
 			if err != nil {
@@ -293,6 +692,12 @@ func (p *SettingsPane) handleSettingsUpdate(msg tea.KeyMsg) tea.Cmd {
 			if err != nil {
 				return util.MakeErrorMsg(err.Error())
 			}
+
+		case reasoningBudgetChange:
+			err := p.updateReasoningBudget(inputValue)
+			if err != nil {
+				return util.MakeRecoverableErrorMsg(err.Error())
+			}
 		}
 
 		newSettings, err := settingsService.UpdateSettings(p.settings)
@@ -311,8 +716,7 @@ func (p *SettingsPane) handleSettingsUpdate(msg tea.KeyMsg) tea.Cmd {
 }
 
 func (p SettingsPane) loadModels(providerType string, apiUrl string) tea.Msg {
-	ctx, cancel := context.
-		WithTimeout(p.mainCtx, time.Duration(util.DefaultRequestTimeOutSec*time.Second))
+	ctx, cancel := context.WithTimeout(p.mainCtx, p.config.GetRequestTimeout())
 	defer cancel()
 
 	availableModels, err := p.settingsService.GetProviderModels(ctx, providerType, apiUrl)
@@ -361,15 +765,80 @@ func (p *SettingsPane) updatePresetsList(presets []util.Settings) {
 	p.presetPicker = components.NewPresetsList(presetsList, w, h, p.settings.ID, p.colors, p.settingsService)
 }
 
+// Saved prompts are replaced with placeholders in demo mode
+func getPromptName(prompt util.SystemPrompt, idx int) string {
+	if util.IsDemoMode() {
+		return i18n.Tf("demo.promptName", idx+1)
+	}
+	return prompt.Name
+}
+
+func (p *SettingsPane) updatePromptsList(prompts []util.SystemPrompt) {
+	var promptsList []list.Item
+	for i, prompt := range prompts {
+		assignedTo := []string{}
+		if p.settings.SystemPromptId != nil && *p.settings.SystemPromptId == prompt.ID {
+			assignedTo = append(assignedTo, "preset")
+		}
+		if p.sessionPromptId != nil && *p.sessionPromptId == prompt.ID {
+			assignedTo = append(assignedTo, "session")
+		}
+
+		promptsList = append(promptsList, components.SystemPromptsListItem{
+			Id:         "prompts_list_" + fmt.Sprint(i),
+			PromptId:   prompt.ID,
+			Text:       getPromptName(prompt, i),
+			AssignedTo: strings.Join(assignedTo, ","),
+		})
+	}
+
+	w, h := util.CalcModelsListSize(p.terminalWidth, p.terminalHeight)
+	p.promptPicker = components.NewSystemPromptsList(promptsList, w, h, p.colors, p.promptsService)
+}
+
+func (p *SettingsPane) updateVersionsList(versions []util.SystemPromptVersion) {
+	var versionsList []list.Item
+	for i, version := range versions {
+		versionsList = append(versionsList, components.SystemPromptVersionsListItem{
+			Id:        "versions_list_" + fmt.Sprint(i),
+			VersionId: version.ID,
+			Content:   version.Content,
+			CreatedAt: version.CreatedAt,
+		})
+	}
+
+	w, h := util.CalcModelsListSize(p.terminalWidth, p.terminalHeight)
+	p.versionPicker = components.NewSystemPromptVersionsList(versionsList, w, h, p.colors)
+}
+
+func (p *SettingsPane) updatePersonasList(personas []util.Persona) {
+	var personasList []list.Item
+	for i, persona := range personas {
+		personasList = append(personasList, components.PersonasListItem{
+			Id:         "personas_list_" + fmt.Sprint(i),
+			PersonaId:  persona.ID,
+			Text:       persona.Name,
+			Model:      persona.Model,
+			IsAssigned: p.sessionPersonaId != nil && *p.sessionPersonaId == persona.ID,
+		})
+	}
+
+	w, h := util.CalcModelsListSize(p.terminalWidth, p.terminalHeight)
+	p.personaPicker = components.NewPersonasList(personasList, w, h, p.colors, p.personasService)
+}
+
 func (p *SettingsPane) updatePresetName(inputValue string) error {
 	newPreset := util.Settings{
-		Model:        p.settings.Model,
-		MaxTokens:    p.settings.MaxTokens,
-		Frequency:    p.settings.Frequency,
-		SystemPrompt: p.settings.SystemPrompt,
-		TopP:         p.settings.TopP,
-		Temperature:  p.settings.Temperature,
-		PresetName:   inputValue,
+		Model:           p.settings.Model,
+		MaxTokens:       p.settings.MaxTokens,
+		Frequency:       p.settings.Frequency,
+		SystemPrompt:    p.settings.SystemPrompt,
+		TopP:            p.settings.TopP,
+		Temperature:     p.settings.Temperature,
+		PresetName:      inputValue,
+		SystemPromptId:  p.settings.SystemPromptId,
+		Provider:        p.settings.Provider,
+		ReasoningBudget: p.settings.ReasoningBudget,
 	}
 	newId, err := p.settingsService.SavePreset(newPreset)
 	if err != nil {
@@ -424,3 +893,20 @@ func (p *SettingsPane) updateTopP(inputValue string) error {
 	p.changeMode = inactive
 	return nil
 }
+
+func (p *SettingsPane) updateReasoningBudget(inputValue string) error {
+	p.changeMode = inactive
+	if inputValue == util.ReasoningBudgetOff {
+		p.settings.ReasoningBudget = nil
+		return nil
+	}
+
+	if !slices.Contains(util.ReasoningEfforts, inputValue) {
+		if _, err := strconv.Atoi(inputValue); err != nil {
+			return fmt.Errorf("reasoning budget must be one of %s", util.ReasoningBudgetRange)
+		}
+	}
+
+	p.settings.ReasoningBudget = &inputValue
+	return nil
+}
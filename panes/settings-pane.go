@@ -9,6 +9,8 @@ import (
 	"github.com/BalanceBalls/nekot/clients"
 	"github.com/BalanceBalls/nekot/components"
 	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/sessions"
 	"github.com/BalanceBalls/nekot/settings"
 	"github.com/BalanceBalls/nekot/util"
 	"github.com/charmbracelet/bubbles/key"
@@ -20,12 +22,17 @@ import (
 	zone "github.com/lrstanley/bubblezone"
 )
 
+const unsupportedParamText = "not supported"
+
 type settingsViewMode int
 
 const (
 	defaultView settingsViewMode = iota
 	modelsView
 	presetsView
+	promptsView
+	personasView
+	historyView
 )
 
 type settingsChangeMode int
@@ -37,13 +44,17 @@ const (
 	frequencyChange
 	tempChange
 	topPChange
+	reasoningBudgetChange
 	systemPromptChange
+	promptNameChange
+	personaNameChange
 )
 
 type settingsKeyMap struct {
 	editTemp        key.Binding
 	editFrequency   key.Binding
 	editTopP        key.Binding
+	editReasoning   key.Binding
 	editSysPrompt   key.Binding
 	editMaxTokens   key.Binding
 	changeModel     key.Binding
@@ -54,39 +65,80 @@ type settingsKeyMap struct {
 	choose          key.Binding
 	enableWebSearch key.Binding
 	hideReasoning   key.Binding
+	savePrompt      key.Binding
+	assignPrompt    key.Binding
+	detachPrompt    key.Binding
+	savePersona     key.Binding
+	detachPersona   key.Binding
+	checkConnection key.Binding
+	promptHistory   key.Binding
+	promptDiff      key.Binding
 }
 
 var defaultSettingsKeyMap = settingsKeyMap{
-	editTemp:      key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "change temperature")),
-	editFrequency: key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "change frequency")),
-	editTopP:      key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "change top_p")),
-	editSysPrompt: key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "s - edit sys prompt")),
-	editMaxTokens: key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "change max_tokens")),
-	changeModel:   key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "change current model")),
+	editTemp:      key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "keys.changeTemperature")),
+	editFrequency: key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "keys.changeFrequency")),
+	editTopP:      key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "keys.changeTopP")),
+	editReasoning: key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "keys.changeReasoningBudget")),
+	editSysPrompt: key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "keys.editPrompt")),
+	editMaxTokens: key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "keys.changeMaxTokens")),
+	changeModel:   key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "keys.changeModel")),
 	savePreset: key.NewBinding(
 		key.WithKeys("ctrl+p"),
-		key.WithHelp("ctrl+p", "ctrl+p - new preset"),
+		key.WithHelp("ctrl+p", "keys.newPreset"),
 	),
 	reset: key.NewBinding(
 		key.WithKeys("ctrl+r"),
-		key.WithHelp("ctrl+r", "ctrl+r - reset preset"),
+		key.WithHelp("ctrl+r", "keys.resetPreset"),
 	),
 	presetsMenu: key.NewBinding(
 		key.WithKeys("]", tea.KeyRight.String()),
-		key.WithHelp("]", "presets menu"),
+		key.WithHelp("]", "keys.presetsMenu"),
 	),
 	goBack: key.NewBinding(
 		key.WithKeys(tea.KeyEsc.String(), "[", tea.KeyLeft.String()),
-		key.WithHelp("esc, [", "go back"),
+		key.WithHelp("esc, [", "keys.goBack"),
 	),
 	choose: key.NewBinding(key.WithKeys(tea.KeyEnter.String())),
 	enableWebSearch: key.NewBinding(
 		key.WithKeys("ctrl+w"),
-		key.WithHelp("ctrl+w", "toggle web search"),
+		key.WithHelp("ctrl+w", "keys.toggleWebSearch"),
 	),
 	hideReasoning: key.NewBinding(
 		key.WithKeys("ctrl+h"),
-		key.WithHelp("ctrl+h", "hide/show reasoning"),
+		key.WithHelp("ctrl+h", "keys.toggleReasoning"),
+	),
+	savePrompt: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "keys.savePrompt"),
+	),
+	assignPrompt: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "keys.assignPrompt"),
+	),
+	detachPrompt: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "keys.detachPrompt"),
+	),
+	savePersona: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "keys.savePersona"),
+	),
+	detachPersona: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "keys.detachPersona"),
+	),
+	checkConnection: key.NewBinding(
+		key.WithKeys("ctrl+t"),
+		key.WithHelp("ctrl+t", "keys.testConnection"),
+	),
+	promptHistory: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "keys.promptVersions"),
+	),
+	promptDiff: key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("d", "keys.diffPromptVersion"),
 	),
 }
 
@@ -96,6 +148,14 @@ var headingToChangeMode = map[string]settingsChangeMode{
 	"(e) temperature": tempChange,
 	"(f) frequency":   frequencyChange,
 	"(p) top_p":       topPChange,
+	"(b) reasoning":   reasoningBudgetChange,
+}
+
+var changeModeToParam = map[settingsChangeMode]util.SamplingParam{
+	maxTokensChange: util.MaxTokensParam,
+	tempChange:      util.TemperatureParam,
+	frequencyChange: util.FrequencyParam,
+	topPChange:      util.TopPParam,
 }
 
 type SettingsPane struct {
@@ -113,20 +173,33 @@ type SettingsPane struct {
 
 	modelPicker  components.ModelsList
 	presetPicker components.PresetsList
+	promptPicker components.SystemPromptsList
+
+	promptsService  *settings.SystemPromptsService
+	sessionPromptId *int
+	versionPicker   components.SystemPromptVersionsList
+
+	personaPicker    components.PersonasList
+	personasService  *settings.PersonasService
+	sessionPersonaId *int
+
+	checkingConnection bool
+	connectionCheck    *clients.HealthCheckResult
 
 	container lipgloss.Style
 
-	initMode  bool
-	config    *config.Config
-	llmClient util.LlmClient
-	settings  util.Settings
-	mainCtx   context.Context
+	initMode    bool
+	config      *config.Config
+	llmClient   util.LlmClient
+	apiProvider util.ApiProvider
+	settings    util.Settings
+	mainCtx     context.Context
 }
 
 var settingsService *settings.SettingsService
 
 var activeHeader = lipgloss.NewStyle().
-	BorderStyle(lipgloss.ThickBorder()).
+	BorderStyle(util.ThickBorder()).
 	BorderBottom(true).
 	Bold(true).
 	MarginLeft(util.ListItemMarginLeft)
@@ -170,6 +243,13 @@ func (p SettingsPane) presetItemRenderer(value string) string {
 func initSpinner() spinner.Model {
 	s := spinner.New()
 	s.Spinner = spinner.Points
+	if util.IsAsciiMode() {
+		s.Spinner = spinner.Line
+	}
+	if util.IsReducedMotion() {
+		// the spinner is not ticked, so it stays on this frame
+		s.Spinner = spinner.Spinner{Frames: []string{"..."}}
+	}
 	s.Style = spinnerStyle
 
 	return s
@@ -195,10 +275,11 @@ func NewSettingsPane(db *sql.DB, ctx context.Context) SettingsPane {
 	listItemHeadingActive = listItemHeading.Foreground(colors.HighlightColor)
 	presetItemHeading = presetItemHeading.Foreground(colors.AccentColor)
 	activeHeader = activeHeader.Foreground(colors.DefaultTextColor).
+		BorderStyle(util.ThickBorder()).
 		BorderForeground(colors.DefaultTextColor)
 	spinnerStyle = spinnerStyle.Foreground(colors.AccentColor)
 	containerStyle := lipgloss.NewStyle().
-		Border(lipgloss.ThickBorder(), true).
+		Border(util.ThickBorder(), true).
 		BorderForeground(colors.NormalTabBorderColor)
 
 	spinner := initSpinner()
@@ -212,7 +293,10 @@ func NewSettingsPane(db *sql.DB, ctx context.Context) SettingsPane {
 		container:       containerStyle,
 		config:          config,
 		llmClient:       llmClient,
+		apiProvider:     util.GetOpenAiInferenceProvider(config.Provider, config.ProviderBaseUrl),
 		settingsService: settingsService,
+		promptsService:  settings.NewSystemPromptsService(db),
+		personasService: settings.NewPersonasService(db),
 		spinner:         spinner,
 		initMode:        true,
 		loading:         true,
@@ -220,7 +304,28 @@ func NewSettingsPane(db *sql.DB, ctx context.Context) SettingsPane {
 	}
 }
 
+func (p SettingsPane) SetColors(colors util.SchemeColors) SettingsPane {
+	p.colors = colors
+	listItemSpan = listItemSpan.Foreground(colors.DefaultTextColor)
+	listItemHeading = listItemHeading.Foreground(colors.MainColor)
+	listItemHeadingActive = listItemHeading.Foreground(colors.HighlightColor)
+	presetItemHeading = presetItemHeading.Foreground(colors.AccentColor)
+	activeHeader = activeHeader.
+		Foreground(colors.DefaultTextColor).
+		BorderForeground(colors.DefaultTextColor)
+	spinnerStyle = spinnerStyle.Foreground(colors.AccentColor)
+	p.spinner.Style = spinnerStyle
+	return p
+}
+
 func (p *SettingsPane) Init() tea.Cmd {
+	return p.startSpinner()
+}
+
+func (p SettingsPane) startSpinner() tea.Cmd {
+	if util.IsReducedMotion() {
+		return nil
+	}
 	return p.spinner.Tick
 }
 
@@ -252,7 +357,9 @@ func (p SettingsPane) Update(msg tea.Msg) (SettingsPane, tea.Cmd) {
 		p.changeMode = inactive
 
 	case util.SystemPromptUpdatedMsg:
+		p.keepSystemPromptVersion(msg.SystemPrompt)
 		p.settings.SystemPrompt = &msg.SystemPrompt
+		p.settings.SystemPromptId = nil
 		var updErr error
 		p.settings, updErr = p.settingsService.UpdateSettings(p.settings)
 		if updErr != nil {
@@ -262,6 +369,25 @@ func (p SettingsPane) Update(msg tea.Msg) (SettingsPane, tea.Cmd) {
 		cmds = append(cmds, settings.MakeSettingsUpdateMsg(p.settings, nil))
 		cmds = append(cmds, util.SendNotificationMsg(util.SysPromptChangedNotification))
 
+	case sessions.LoadDataFromDB:
+		p.sessionPromptId = msg.Session.SystemPromptId
+		p.sessionPersonaId = msg.Session.PersonaId
+
+	case sessions.UpdateCurrentSession:
+		p.sessionPromptId = msg.Session.SystemPromptId
+		p.sessionPersonaId = msg.Session.PersonaId
+		if p.viewMode == personasView && p.changeMode == inactive {
+			cmd = p.switchToPersonas()
+			cmds = append(cmds, cmd)
+		}
+
+	case sessions.AssignSessionSystemPrompt:
+		p.sessionPromptId = msg.SystemPromptId
+		if p.viewMode == promptsView {
+			cmd = p.switchToPrompts()
+			cmds = append(cmds, cmd)
+		}
+
 	case util.FocusEvent:
 		p.isFocused = msg.IsFocused
 		p.viewMode = defaultView
@@ -297,13 +423,31 @@ func (p SettingsPane) Update(msg tea.Msg) (SettingsPane, tea.Cmd) {
 			cmds = append(cmds, util.SendAsyncDependencyReadyMsg(util.SettingsPaneModule))
 		}
 
+	case settings.ConnectionChecked:
+		p.checkingConnection = false
+		p.connectionCheck = &msg.Result
+
 	case util.ModelsLoaded:
 		p.loading = false
 		p.viewMode = modelsView
 		p.updateModelsList(msg.Models)
 		return p, nil
 
+	case util.WebSearchToggleRequested:
+		if p.initMode {
+			break
+		}
+		return p, p.toggleWebSearch()
+
 	case tea.MouseMsg:
+		// Wheel scrolls the pane under the cursor, regardless of focus
+		if msg.Button == tea.MouseButtonWheelUp || msg.Button == tea.MouseButtonWheelDown {
+			if zone.Get("settings_pane").InBounds(msg) && !p.initMode {
+				cmd = p.scrollActiveList(msg)
+			}
+			return p, cmd
+		}
+
 		if !p.isFocused {
 			break
 		}
@@ -319,6 +463,15 @@ func (p SettingsPane) Update(msg tea.Msg) (SettingsPane, tea.Cmd) {
 			case presetsView:
 				cmd = p.handlePresetModeMouse(msg)
 				cmds = append(cmds, cmd)
+			case promptsView:
+				cmd = p.handlePromptsModeMouse(msg)
+				cmds = append(cmds, cmd)
+			case personasView:
+				cmd = p.handlePersonasModeMouse(msg)
+				cmds = append(cmds, cmd)
+			case historyView:
+				cmd = p.handleHistoryModeMouse(msg)
+				cmds = append(cmds, cmd)
 			}
 		}
 
@@ -328,9 +481,7 @@ func (p SettingsPane) Update(msg tea.Msg) (SettingsPane, tea.Cmd) {
 		}
 
 		if key.Matches(msg, p.keyMap.enableWebSearch) {
-			p.settings.WebSearchEnabled = !p.settings.WebSearchEnabled
-			updatedSettings, err := p.settingsService.UpdateSettings(p.settings)
-			return p, settings.MakeSettingsUpdateMsg(updatedSettings, err)
+			return p, p.toggleWebSearch()
 		}
 
 		if key.Matches(msg, p.keyMap.hideReasoning) {
@@ -354,6 +505,15 @@ func (p SettingsPane) Update(msg tea.Msg) (SettingsPane, tea.Cmd) {
 				case presetsView:
 					cmd = p.handlePresetMode(msg)
 					cmds = append(cmds, cmd)
+				case promptsView:
+					cmd = p.handlePromptsMode(msg)
+					cmds = append(cmds, cmd)
+				case personasView:
+					cmd = p.handlePersonasMode(msg)
+					cmds = append(cmds, cmd)
+				case historyView:
+					cmd = p.handleHistoryMode(msg)
+					cmds = append(cmds, cmd)
 				}
 			}
 		}
@@ -369,16 +529,124 @@ func (p SettingsPane) Update(msg tea.Msg) (SettingsPane, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	}
 
+	if !p.initMode && p.viewMode == promptsView && p.changeMode == inactive {
+		p.promptPicker, cmd = p.promptPicker.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if !p.initMode && p.viewMode == historyView {
+		p.versionPicker, cmd = p.versionPicker.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if !p.initMode && p.viewMode == personasView && p.changeMode == inactive {
+		p.personaPicker, cmd = p.personaPicker.Update(msg)
+		cmds = append(cmds, cmd)
+
+		// the removed persona is detached from the sessions, the current session has to drop it too
+		if p.sessionPersonaId != nil && !p.personaPicker.Contains(*p.sessionPersonaId) {
+			p.sessionPersonaId = nil
+			cmds = append(cmds, sessions.SendAssignSessionPersonaMsg(nil))
+		}
+	}
+
 	return p, tea.Batch(cmds...)
 }
 
+func (p SettingsPane) ShortHelp() []key.Binding {
+	return []key.Binding{
+		p.keyMap.changeModel,
+		p.keyMap.editTemp,
+		p.keyMap.editMaxTokens,
+		p.keyMap.presetsMenu,
+		p.keyMap.enableWebSearch,
+	}
+}
+
+func (p SettingsPane) FullHelp() []key.Binding {
+	return []key.Binding{
+		p.keyMap.changeModel,
+		p.keyMap.editTemp,
+		p.keyMap.editFrequency,
+		p.keyMap.editTopP,
+		p.keyMap.editReasoning,
+		p.keyMap.editMaxTokens,
+		p.keyMap.editSysPrompt,
+		p.keyMap.promptHistory,
+		p.keyMap.savePreset,
+		p.keyMap.reset,
+		p.keyMap.presetsMenu,
+		p.keyMap.goBack,
+		p.keyMap.enableWebSearch,
+		p.keyMap.hideReasoning,
+		p.keyMap.savePrompt,
+		p.keyMap.assignPrompt,
+		p.keyMap.detachPrompt,
+		p.keyMap.savePersona,
+		p.keyMap.detachPersona,
+		p.keyMap.checkConnection,
+	}
+}
+
+func (p SettingsPane) PaletteCommands() []util.PaletteCommand {
+	commands := []util.PaletteCommand{
+		{Binding: p.keyMap.enableWebSearch},
+		{Binding: p.keyMap.hideReasoning},
+	}
+
+	for _, binding := range []key.Binding{
+		p.keyMap.changeModel,
+		p.keyMap.editTemp,
+		p.keyMap.editFrequency,
+		p.keyMap.editTopP,
+		p.keyMap.editReasoning,
+		p.keyMap.editMaxTokens,
+		p.keyMap.editSysPrompt,
+		p.keyMap.promptHistory,
+		p.keyMap.savePreset,
+		p.keyMap.reset,
+		p.keyMap.checkConnection,
+	} {
+		commands = append(commands, util.PaletteCommand{
+			Binding:       binding,
+			Target:        util.SettingsPane,
+			RequiresFocus: true,
+		})
+	}
+
+	return commands
+}
+
+func getTip(binding key.Binding) string {
+	return binding.Help().Key + " - " + i18n.T(binding.Help().Desc)
+}
+
+func renderTabsHeader(activeTab settingsViewMode) string {
+	tabs := []struct {
+		zoneId string
+		title  string
+		view   settingsViewMode
+	}{
+		{"set_p_settings_tab", "Settings", defaultView},
+		{"set_p_presets_tab", "Presets", presetsView},
+		{"set_p_prompts_tab", "Prompts", promptsView},
+		{"set_p_personas_tab", "Personas", personasView},
+	}
+
+	headers := []string{}
+	for _, tab := range tabs {
+		header := inactiveHeader.Render(tab.title)
+		if tab.view == activeTab {
+			header = activeHeader.Render("[" + tab.title + "]")
+		}
+		headers = append(headers, zone.Mark(tab.zoneId, header))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Left, headers...)
+}
+
 func (p SettingsPane) View() string {
 	w, h := util.CalcSettingsPaneSize(p.terminalWidth, p.terminalHeight)
-	defaultHeader := lipgloss.JoinHorizontal(
-		lipgloss.Left,
-		zone.Mark("set_p_settings_tab", activeHeader.Render("[Settings]")),
-		zone.Mark("set_p_presets_tab", inactiveHeader.Render("Presets")),
-	)
+	defaultHeader := renderTabsHeader(defaultView)
 	if p.viewMode == modelsView {
 		return zone.Mark("settings_pane", p.container.Width(w).Render(
 			lipgloss.JoinVertical(lipgloss.Left,
@@ -391,22 +659,57 @@ func (p SettingsPane) View() string {
 	if p.viewMode == presetsView {
 		return zone.Mark("settings_pane", p.container.Width(w).Render(
 			lipgloss.JoinVertical(lipgloss.Left,
-				lipgloss.JoinHorizontal(
-					lipgloss.Left,
-					zone.Mark("set_p_settings_tab", inactiveHeader.Render("Settings")),
-					zone.Mark("set_p_presets_tab", activeHeader.Render("[Presets]")),
-				),
+				renderTabsHeader(presetsView),
 				p.presetPicker.View(),
 			),
 		))
 	}
 
+	if p.viewMode == promptsView {
+		promptsList := p.promptPicker.View()
+		if p.changeMode == promptNameChange {
+			promptsList = p.textInput.View()
+		}
+
+		return zone.Mark("settings_pane", p.container.Width(w).Render(
+			lipgloss.JoinVertical(lipgloss.Left,
+				renderTabsHeader(promptsView),
+				promptsList,
+			),
+		))
+	}
+
+	if p.viewMode == historyView {
+		return zone.Mark("settings_pane", p.container.Width(w).Render(
+			lipgloss.JoinVertical(lipgloss.Left,
+				defaultHeader,
+				p.versionPicker.View(),
+			),
+		))
+	}
+
+	if p.viewMode == personasView {
+		personasList := p.personaPicker.View()
+		if p.changeMode == personaNameChange {
+			personasList = p.textInput.View()
+		}
+
+		return zone.Mark("settings_pane", p.container.Width(w).Render(
+			lipgloss.JoinVertical(lipgloss.Left,
+				renderTabsHeader(personasView),
+				personasList,
+			),
+		))
+	}
+
 	editForm := ""
 	tips := strings.Join([]string{
 		"] [ - switch tabs",
-		p.keyMap.savePreset.Help().Desc,
-		p.keyMap.reset.Help().Desc,
-		p.keyMap.editSysPrompt.Help().Desc}, "\n")
+		getTip(p.keyMap.savePreset),
+		getTip(p.keyMap.reset),
+		getTip(p.keyMap.editSysPrompt),
+		getTip(p.keyMap.promptHistory),
+		getTip(p.keyMap.checkConnection)}, "\n")
 
 	if p.changeMode != inactive {
 		tips = ""
@@ -423,12 +726,16 @@ func (p SettingsPane) View() string {
 	modelRowContent := p.listItemRenderer("(m) model", modelName)
 	if p.loading {
 		modelRowContent = p.listItemRenderer(p.spinner.View(), "")
+		if util.IsReducedMotion() {
+			modelRowContent = p.listItemRenderer("(m) model", "loading...")
+		}
 	}
 
 	var (
 		temp      = "not set"
 		top_p     = "not set"
 		frequency = "not set"
+		reasoning = "not set"
 	)
 
 	if p.settings.Temperature != nil {
@@ -440,6 +747,27 @@ func (p SettingsPane) View() string {
 	if p.settings.Frequency != nil {
 		frequency = fmt.Sprint(*p.settings.Frequency)
 	}
+	if p.settings.ReasoningBudget != nil {
+		reasoning = *p.settings.ReasoningBudget
+	}
+
+	if !util.IsSamplingParamSupported(p.apiProvider, util.TemperatureParam) {
+		temp = unsupportedParamText
+	}
+	if !util.IsSamplingParamSupported(p.apiProvider, util.TopPParam) {
+		top_p = unsupportedParamText
+	}
+	if !util.IsSamplingParamSupported(p.apiProvider, util.FrequencyParam) {
+		frequency = unsupportedParamText
+	}
+
+	maxTokens := fmt.Sprint(p.settings.MaxTokens)
+	capabilities := util.GetModelCapabilities(p.apiProvider, p.settings.Model)
+	if capabilities.MaxContext > 0 {
+		maxTokens += " (ctx " + util.FormatContextSize(capabilities.MaxContext) + ")"
+	}
+
+	connectionRow := p.connectionCheckRenderer()
 
 	tipsHeihgt := len(strings.Split(tips, "\n"))
 	listItemsHeight := h - tipsHeihgt
@@ -462,10 +790,12 @@ func (p SettingsPane) View() string {
 				lipgloss.JoinVertical(lipgloss.Left,
 					zone.Mark("set_p_preset_item", p.presetItemRenderer(p.settings.PresetName)),
 					zone.Mark("models_list", modelRowContent),
-					zone.Mark("max_tokens", p.listItemRenderer("(t) max_tokens", fmt.Sprint(p.settings.MaxTokens))),
+					zone.Mark("max_tokens", p.listItemRenderer("(t) max_tokens", maxTokens)),
 					zone.Mark("temperature", p.listItemRenderer("(e) temperature", temp)),
 					zone.Mark("frequency", p.listItemRenderer("(f) frequency", frequency)),
 					zone.Mark("top_p", p.listItemRenderer("(p) top_p", top_p)),
+					zone.Mark("reasoning_budget", p.listItemRenderer("(b) reasoning", reasoning)),
+					connectionRow,
 				),
 			),
 			lowerRows,
@@ -475,6 +805,76 @@ func (p SettingsPane) View() string {
 	return zone.Mark("settings_pane", rendered)
 }
 
+func (p *SettingsPane) scrollActiveList(msg tea.MouseMsg) tea.Cmd {
+	var cmd tea.Cmd
+
+	switch p.viewMode {
+	case modelsView:
+		p.modelPicker, cmd = p.modelPicker.Update(msg)
+	case presetsView:
+		p.presetPicker, cmd = p.presetPicker.Update(msg)
+	case promptsView:
+		if p.changeMode == inactive {
+			p.promptPicker, cmd = p.promptPicker.Update(msg)
+		}
+	case personasView:
+		if p.changeMode == inactive {
+			p.personaPicker, cmd = p.personaPicker.Update(msg)
+		}
+	}
+
+	return cmd
+}
+
+func (p SettingsPane) connectionCheckRenderer() string {
+	if p.checkingConnection {
+		return listItemHeading.Render(p.spinner.View() + " checking connection")
+	}
+
+	if p.connectionCheck == nil {
+		return ""
+	}
+
+	style := listItemHeading.Foreground(p.colors.ErrorColor)
+	if p.connectionCheck.Ok() {
+		style = listItemHeading.Foreground(p.colors.AccentColor)
+	}
+
+	status := util.TrimListItem(
+		p.connectionCheck.String(),
+		util.CalcMaxSettingItemWidth(p.container.GetWidth()))
+	return style.Render(status)
+}
+
+// While a response is processed the lists can only be scrolled, settings are not changed mid-response
+func (p SettingsPane) UpdateWhileProcessing(msg tea.Msg) (SettingsPane, tea.Cmd) {
+	switch msg := msg.(type) {
+	case settings.UpdateSettingsEvent,
+		util.FocusEvent,
+		spinner.TickMsg,
+		tea.WindowSizeMsg:
+		return p.Update(msg)
+
+	case tea.MouseMsg:
+		if msg.Button == tea.MouseButtonWheelUp || msg.Button == tea.MouseButtonWheelDown {
+			return p.Update(msg)
+		}
+	}
+
+	return p, nil
+}
+
+// Web search is a preset setting, models without tools support can't use it
+func (p *SettingsPane) toggleWebSearch() tea.Cmd {
+	if !util.GetModelCapabilities(p.apiProvider, p.settings.Model).Tools {
+		return nil
+	}
+
+	p.settings.WebSearchEnabled = !p.settings.WebSearchEnabled
+	updatedSettings, err := p.settingsService.UpdateSettings(p.settings)
+	return settings.MakeSettingsUpdateMsg(updatedSettings, err)
+}
+
 func (p SettingsPane) AllowFocusChange(isMouseEvent bool) bool {
 	if isMouseEvent {
 		return p.changeMode != systemPromptChange
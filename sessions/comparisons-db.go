@@ -0,0 +1,37 @@
+package sessions
+
+import (
+	"github.com/BalanceBalls/nekot/util"
+)
+
+var comparisonWinners = [2]string{"a", "b"}
+
+func (ss *SessionService) AddPresetComparison(comparison util.PresetComparison) (int, error) {
+	a, b := comparison.Answers[0], comparison.Answers[1]
+	result, err := ss.DB.Exec(`
+			INSERT INTO preset_comparisons (
+				comparison_prompt,
+				preset_a, model_a, answer_a,
+				preset_b, model_b, answer_b
+			) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, comparison.Prompt, a.Preset, a.Model, a.Content, b.Preset, b.Model, b.Content)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// Marking the other answer later replaces the winner
+func (ss *SessionService) SetComparisonWinner(id int, winner int) error {
+	_, err := ss.DB.Exec(`
+			UPDATE preset_comparisons
+			SET comparison_winner = $1
+			WHERE comparison_id = $2
+	`, comparisonWinners[winner], id)
+	return err
+}
@@ -0,0 +1,119 @@
+package sessions
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BalanceBalls/nekot/clients"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const CompareCommand = "/compare"
+
+const comparisonTimeout = 3 * time.Minute
+
+const compareUsage = `usage: /compare preset-a preset-b prompt, names with spaces go in quotes: /compare "my preset" other prompt`
+
+func IsCompareCommand(prompt string) bool {
+	prompt = strings.TrimSpace(prompt)
+	return prompt == CompareCommand || strings.HasPrefix(prompt, CompareCommand+" ")
+}
+
+// Parses `/compare preset-a preset-b prompt`. Preset names with spaces are quoted
+func ParseCompareCommand(command string) (string, string, string, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(command), CompareCommand)
+
+	presetA, rest := nextCommandArg(rest)
+	presetB, rest := nextCommandArg(rest)
+	prompt := strings.TrimSpace(rest)
+	if presetA == "" || presetB == "" || prompt == "" {
+		return "", "", "", errors.New(compareUsage)
+	}
+	return presetA, presetB, prompt, nil
+}
+
+// First space separated or double quoted argument and the text after it
+func nextCommandArg(text string) (string, string) {
+	text = strings.TrimLeft(text, " \t")
+	if strings.HasPrefix(text, `"`) {
+		if arg, rest, ok := strings.Cut(text[1:], `"`); ok {
+			return arg, rest
+		}
+	}
+
+	end := strings.IndexAny(text, " \t\n")
+	if end == -1 {
+		return text, ""
+	}
+	return text[:end], text[end:]
+}
+
+// Sends the prompt with each of the presets at the same time. The prompt is sent without the chat history,
+// each preset uses its own model, sampling parameters and system prompt
+func (m Orchestrator) ComparePresets(presetA, presetB, prompt string) (tea.Cmd, error) {
+	if err := util.CheckHostAllowed(clients.GetProviderUrl(m.config)); err != nil {
+		return nil, err
+	}
+
+	presets, err := m.settingsService.GetPresetsList()
+	if err != nil {
+		return nil, err
+	}
+
+	requestSettings := [2]util.Settings{}
+	for i, name := range []string{presetA, presetB} {
+		index := findPresetByName(presets, name)
+		if index == -1 {
+			return nil, fmt.Errorf("preset %q not found", name)
+		}
+
+		preset := m.dropUnsupportedFeatures(presets[index])
+		requestSettings[i] = m.withLibrarySystemPrompt(preset, preset.SystemPromptId)
+	}
+
+	// the prompt is stored with the comparison as it was sent
+	if m.config.RedactPrompts {
+		prompt, _ = util.RedactPromptSecrets(prompt, m.config.RedactionPatterns)
+	}
+
+	messages := m.applyPromptMiddleware([]util.LocalStoreMessage{{Role: "user", Content: prompt}})
+	client := m.InferenceClient
+	ctx := m.mainCtx
+	return func() tea.Msg {
+		comparison := util.PresetComparison{Prompt: prompt, Winner: util.NoComparisonWinner}
+		errs := [2]error{}
+
+		var wg sync.WaitGroup
+		for i, settings := range requestSettings {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				answer, err := requestFullCompletion(ctx, client, messages, settings, comparisonTimeout)
+				if err != nil {
+					errs[i] = fmt.Errorf("%s preset: %w", settings.PresetName, err)
+				}
+				comparison.Answers[i] = util.ComparisonAnswer{
+					Preset:  settings.PresetName,
+					Model:   settings.Model,
+					Content: answer,
+				}
+			}()
+		}
+		wg.Wait()
+
+		return PresetComparisonReady{Comparison: comparison, Err: errors.Join(errs[:]...)}
+	}, nil
+}
+
+func findPresetByName(presets []util.Settings, name string) int {
+	for i, preset := range presets {
+		if strings.EqualFold(preset.PresetName, name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -36,10 +36,143 @@ func SendUpdateCurrentSessionMsg(session Session) tea.Cmd {
 	}
 }
 
-type SaveQuickChat struct{}
+type SessionRenamed struct {
+	ID   int
+	Name string
+}
+
+func SendSessionRenamedMsg(id int, name string) tea.Cmd {
+	return func() tea.Msg {
+		return SessionRenamed{
+			ID:   id,
+			Name: name,
+		}
+	}
+}
+
+type SessionTranslationToggled struct {
+	ID      int
+	Enabled bool
+}
+
+func SendSessionTranslationToggledMsg(id int, enabled bool) tea.Cmd {
+	return func() tea.Msg {
+		return SessionTranslationToggled{
+			ID:      id,
+			Enabled: enabled,
+		}
+	}
+}
+
+type SessionReadOnlyToggled struct {
+	ID      int
+	Enabled bool
+}
+
+func SendSessionReadOnlyToggledMsg(id int, enabled bool) tea.Cmd {
+	return func() tea.Msg {
+		return SessionReadOnlyToggled{
+			ID:      id,
+			Enabled: enabled,
+		}
+	}
+}
+
+// A prompt was sent to a read-only session. The prompt pane offers to send it to a copy of the session
+type ReadOnlyPromptRejected struct {
+	Prompt      string
+	Attachments []util.Attachment
+}
+
+func SendReadOnlyPromptRejectedMsg(prompt string, attachments []util.Attachment) tea.Cmd {
+	return func() tea.Msg {
+		return ReadOnlyPromptRejected{
+			Prompt:      prompt,
+			Attachments: attachments,
+		}
+	}
+}
+
+// The current session is duplicated and the prompt is sent to the copy
+type SendToSessionCopy struct {
+	Prompt      string
+	Attachments []util.Attachment
+}
+
+func SendToSessionCopyMsg(prompt string, attachments []util.Attachment) tea.Cmd {
+	return func() tea.Msg {
+		return SendToSessionCopy{
+			Prompt:      prompt,
+			Attachments: attachments,
+		}
+	}
+}
+
+// The quick chat keeps its temporary name if the name is empty
+type SaveQuickChat struct {
+	Name string
+}
+
+func SendSaveQuickChatMsg(name string) tea.Cmd {
+	return func() tea.Msg { return SaveQuickChat{Name: name} }
+}
+
+// The prompt pane asks for the name of the quick chat before it is saved
+type QuickChatNaming struct {
+	SessionId int
+	Name      string
+}
+
+func SendQuickChatNamingMsg(sessionId int, name string) tea.Cmd {
+	return func() tea.Msg {
+		return QuickChatNaming{
+			SessionId: sessionId,
+			Name:      name,
+		}
+	}
+}
+
+// Title suggested by the model for the quick chat being saved
+type QuickChatTitleSuggested struct {
+	SessionId int
+	Title     string
+}
+
+type AssignSessionSystemPrompt struct {
+	SystemPromptId *int
+}
+
+func SendAssignSessionSystemPromptMsg(systemPromptId *int) tea.Cmd {
+	return func() tea.Msg {
+		return AssignSessionSystemPrompt{
+			SystemPromptId: systemPromptId,
+		}
+	}
+}
+
+type AssignSessionPersona struct {
+	PersonaId *int
+}
+
+func SendAssignSessionPersonaMsg(personaId *int) tea.Cmd {
+	return func() tea.Msg {
+		return AssignSessionPersona{
+			PersonaId: personaId,
+		}
+	}
+}
+
+// Sessions marked in the sessions pane. While any are marked, prompts are sent to them instead of the current session
+type BroadcastTargetsChanged struct {
+	SessionIds []int
+}
 
-func SendSaveQuickChatMsg() tea.Cmd {
-	return func() tea.Msg { return SaveQuickChat{} }
+func SendBroadcastTargetsChangedMsg(sessionIds []int) tea.Cmd {
+	return func() tea.Msg {
+		return BroadcastTargetsChanged{
+			SessionIds: sessionIds,
+		}
+	}
 }
 
 type RefreshSessionsList struct{}
@@ -96,3 +229,102 @@ type ToolCallComplete struct {
 	Name      string
 	Result    string
 }
+
+// Suggestions of the next questions, made for the last response of the session.
+// The chat pane shows them only if the session has not changed since
+type FollowUpsReady struct {
+	SessionId     int
+	MessagesCount int
+	Suggestions   []string
+}
+
+type SessionMarksChanged struct {
+	SessionId int
+	Marks     map[string]Mark
+}
+
+func SendSessionMarksChangedMsg(sessionId int, marks map[string]Mark) tea.Cmd {
+	return func() tea.Msg {
+		return SessionMarksChanged{
+			SessionId: sessionId,
+			Marks:     marks,
+		}
+	}
+}
+
+type TogglePinnedMessage struct {
+	Index int
+}
+
+func SendTogglePinnedMessageMsg(index int) tea.Cmd {
+	return func() tea.Msg {
+		return TogglePinnedMessage{
+			Index: index,
+		}
+	}
+}
+
+type PinnedMessagesChanged struct {
+	SessionId int
+	Messages  []util.LocalStoreMessage
+}
+
+// A `/compare` command was sent from the prompt pane
+type ComparisonRequested struct {
+	Command string
+}
+
+func RequestComparison(command string) tea.Cmd {
+	return func() tea.Msg {
+		return ComparisonRequested{Command: command}
+	}
+}
+
+// Both presets answered the compared prompt, or one of them failed
+type PresetComparisonReady struct {
+	Comparison util.PresetComparison
+	Err        error
+}
+
+// Rating the answer again with the same rating removes it
+type RateMessage struct {
+	Index  int
+	Rating int
+}
+
+func SendRateMessageMsg(index, rating int) tea.Cmd {
+	return func() tea.Msg {
+		return RateMessage{
+			Index:  index,
+			Rating: rating,
+		}
+	}
+}
+
+// The note of a rated answer is typed in the prompt pane
+type RatingNoteRequested struct {
+	Index int
+	Note  string
+}
+
+func RequestRatingNote(index int, note string) tea.Cmd {
+	return tea.Sequence(util.SwitchToPane(util.PromptPane), func() tea.Msg {
+		return RatingNoteRequested{Index: index, Note: note}
+	})
+}
+
+type SetRatingNote struct {
+	Index int
+	Note  string
+}
+
+func SendSetRatingNoteMsg(index int, note string) tea.Cmd {
+	return func() tea.Msg {
+		return SetRatingNote{Index: index, Note: note}
+	}
+}
+
+type RatedMessagesChanged struct {
+	SessionId int
+	Messages  []util.LocalStoreMessage
+}
@@ -6,17 +6,38 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
+
+	"github.com/BalanceBalls/nekot/util"
 )
 
-func ExportSessionToMarkdown(session Session, exportDir string) error {
-	if exportDir == "" {
-		var err error
-		exportDir, err = os.Getwd()
-		if err != nil {
-			return err
-		}
+// Answer with its rating and the prompt it answers, a line of the ratings export
+type ratedAnswer struct {
+	Session  string `json:"session"`
+	Model    string `json:"model"`
+	Prompt   string `json:"prompt"`
+	Response string `json:"response"`
+	Rating   int    `json:"rating"`
+	Note     string `json:"note,omitempty"`
+}
+
+type fineTuningMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// A line of the fine-tuning export in the chat format
+type fineTuningExample struct {
+	Messages []fineTuningMessage `json:"messages"`
+}
+
+// Returns the path of the exported file
+func ExportSessionToMarkdown(session Session, exportDir string) (string, error) {
+	exportDir, err := resolveExportDir(exportDir)
+	if err != nil {
+		return "", err
 	}
 
 	content := generateMarkdownContent(session)
@@ -29,7 +50,163 @@ func ExportSessionToMarkdown(session Session, exportDir string) error {
 		fullPath = filepath.Join(exportDir, filename)
 	}
 
-	return os.WriteFile(fullPath, []byte(content), 0644)
+	return fullPath, os.WriteFile(fullPath, []byte(content), 0644)
+}
+
+// Writes the rated answers of the sessions as JSON lines. Returns the path of the file
+// and the number of exported answers, nothing is written if there are none
+func ExportRatingsToJSONL(sessions []Session, exportDir string) (string, int, error) {
+	exportDir, err := resolveExportDir(exportDir)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var sb strings.Builder
+	count := 0
+	for _, session := range sessions {
+		for _, answer := range getRatedAnswers(session) {
+			line, err := json.Marshal(answer)
+			if err != nil {
+				return "", 0, err
+			}
+			sb.Write(line)
+			sb.WriteString("\n")
+			count++
+		}
+	}
+
+	if count == 0 {
+		return "", 0, nil
+	}
+
+	filename := fmt.Sprintf("nekot_ratings_%d.jsonl", time.Now().Unix())
+	fullPath := filepath.Join(exportDir, filename)
+	return fullPath, count, os.WriteFile(fullPath, []byte(sb.String()), 0644)
+}
+
+// Each rated answer is paired with the latest prompt before it, tool calls in between are skipped
+func getRatedAnswers(session Session) []ratedAnswer {
+	answers := []ratedAnswer{}
+	prompt := ""
+	for _, msg := range session.Messages {
+		if msg.Role == "user" {
+			prompt = util.StripParameterOverrides(msg.Content)
+			continue
+		}
+
+		if msg.Rating == 0 && msg.RatingNote == "" {
+			continue
+		}
+
+		answers = append(answers, ratedAnswer{
+			Session:  session.SessionName,
+			Model:    msg.Model,
+			Prompt:   prompt,
+			Response: msg.Content,
+			Rating:   msg.Rating,
+			Note:     msg.RatingNote,
+		})
+	}
+	return answers
+}
+
+// Writes each session as a fine-tuning example in the chat format: the system prompt followed by
+// the prompts and answers. Tool calls are left out or flattened to assistant messages, see getFineTuningMessages.
+// Returns the path of the file and the number of exported sessions, nothing is written if there are none
+func ExportSessionsToFineTuningJSONL(
+	sessions []Session,
+	getSystemPrompt func(session Session) string,
+	toolCalls string,
+	exportDir string,
+) (string, int, error) {
+	exportDir, err := resolveExportDir(exportDir)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var sb strings.Builder
+	count := 0
+	for _, session := range sessions {
+		messages := getFineTuningMessages(session.Messages, toolCalls)
+		if !slices.ContainsFunc(messages, func(m fineTuningMessage) bool { return m.Role == "assistant" }) {
+			continue
+		}
+
+		if systemPrompt := getSystemPrompt(session); systemPrompt != "" {
+			messages = append([]fineTuningMessage{{Role: "system", Content: systemPrompt}}, messages...)
+		}
+
+		line, err := json.Marshal(fineTuningExample{Messages: messages})
+		if err != nil {
+			return "", 0, err
+		}
+		sb.Write(line)
+		sb.WriteString("\n")
+		count++
+	}
+
+	if count == 0 {
+		return "", 0, nil
+	}
+
+	filename := fmt.Sprintf("nekot_finetune_%d.jsonl", time.Now().Unix())
+	fullPath := filepath.Join(exportDir, filename)
+	return fullPath, count, os.WriteFile(fullPath, []byte(sb.String()), 0644)
+}
+
+// Flattened tool calls become assistant messages with the called function, its arguments and the result.
+// Consecutive messages of the same role are merged, so the roles alternate as fine-tuning expects
+func getFineTuningMessages(messages []util.LocalStoreMessage, toolCalls string) []fineTuningMessage {
+	result := []fineTuningMessage{}
+	for _, msg := range messages {
+		content := msg.Content
+		role := msg.Role
+		switch {
+		case msg.Role == "user":
+			content = util.StripParameterOverrides(content)
+		case msg.Role == "tool" && toolCalls == util.FlattenToolCalls:
+			role = "assistant"
+			content = flattenToolCalls(msg.ToolCalls)
+		case msg.Role != "assistant" || len(msg.ToolCalls) != 0:
+			continue
+		}
+
+		content = strings.TrimSpace(content)
+		if content == "" {
+			continue
+		}
+
+		if last := len(result) - 1; last >= 0 && result[last].Role == role {
+			result[last].Content += "\n\n" + content
+			continue
+		}
+		result = append(result, fineTuningMessage{Role: role, Content: content})
+	}
+
+	// an example ends with an answer, the last prompt without one has nothing to learn from
+	if last := len(result) - 1; last >= 0 && result[last].Role == "user" {
+		result = result[:last]
+	}
+	return result
+}
+
+func flattenToolCalls(toolCalls []util.ToolCall) string {
+	var sb strings.Builder
+	for _, tc := range toolCalls {
+		argsJson, _ := json.Marshal(tc.Function.Args)
+		sb.WriteString(fmt.Sprintf("Called %s with %s\n", tc.Function.Name, string(argsJson)))
+		if tc.Result != nil {
+			sb.WriteString("Result:\n" + *tc.Result + "\n")
+		}
+	}
+	return sb.String()
+}
+
+func resolveExportDir(exportDir string) (string, error) {
+	if exportDir != "" {
+		return exportDir, nil
+	}
+	return os.Getwd()
 }
 
 func generateMarkdownContent(session Session) string {
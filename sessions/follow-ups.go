@@ -0,0 +1,181 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BalanceBalls/nekot/clients"
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	maxFollowUps      = 3
+	maxFollowUpLength = 150
+	maxFollowUpTopic  = 60
+	followUpsTimeout  = 30 * time.Second
+)
+
+const followUpsInstruction = "Suggest up to 3 short follow-up questions I could ask next about your last answer. " +
+	"Reply with the questions only, one per line, without numbering or any other text."
+
+var (
+	markdownHeadingRegex = regexp.MustCompile(`(?m)^#{1,3}\s+(.+)$`)
+	// Comments of code blocks look like headings, e.g. in python and shell scripts
+	codeBlockRegex = regexp.MustCompile("(?s)```.*?```")
+	// Numbering and bullets models add to the lines despite the instruction
+	listMarkerRegex = regexp.MustCompile(`^\s*(?:\d+[.)]|[-*•])\s*`)
+)
+
+// Follow-ups are made from the headings of the response or requested from the model, depending on followUpSuggestions
+func (m Orchestrator) suggestFollowUps(response util.LocalStoreMessage) tea.Cmd {
+	if response.Content == "" || m.CurrentSessionTranslation {
+		return nil
+	}
+
+	sessionId := m.CurrentSessionID
+	messagesCount := len(m.ArrayOfMessages)
+	makeResult := func(suggestions []string) tea.Msg {
+		if len(suggestions) == 0 {
+			return nil
+		}
+		return FollowUpsReady{SessionId: sessionId, MessagesCount: messagesCount, Suggestions: suggestions}
+	}
+
+	switch m.config.FollowUpSuggestions {
+	case util.HeuristicFollowUps:
+		return func() tea.Msg {
+			return makeResult(getHeuristicFollowUps(response.Content))
+		}
+
+	case util.ModelFollowUps:
+		if err := util.CheckHostAllowed(clients.GetProviderUrl(m.config)); err != nil {
+			return nil
+		}
+
+		messages := append(slices.Clone(m.applyPromptMiddleware(m.ArrayOfMessages)), util.LocalStoreMessage{
+			Role:    "user",
+			Content: followUpsInstruction,
+		})
+		settings := m.getRequestSettings()
+		settings.WebSearchEnabled = false
+		settings.ReasoningBudget = nil
+
+		client := m.InferenceClient
+		ctx := m.mainCtx
+		return func() tea.Msg {
+			answer, err := requestFullCompletion(ctx, client, messages, settings, followUpsTimeout)
+			if err != nil {
+				util.Slog.Warn("failed to get follow-up suggestions", "error", err.Error())
+				return nil
+			}
+			return makeResult(parseFollowUps(answer))
+		}
+	}
+
+	return nil
+}
+
+// Sends a request outside of the session and collects the whole answer
+func requestFullCompletion(
+	ctx context.Context,
+	client util.LlmClient,
+	messages []util.LocalStoreMessage,
+	settings util.Settings,
+	timeout time.Duration,
+) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resultChan := make(chan util.ProcessApiCompletionResponse)
+	cmdResult := make(chan tea.Msg, 1)
+	go func() {
+		cmdResult <- client.RequestCompletion(ctx, messages, settings, resultChan)()
+	}()
+
+	answer := strings.Builder{}
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+
+		case chunk := <-resultChan:
+			if chunk.Err != nil {
+				return "", chunk.Err
+			}
+
+			for _, choice := range chunk.Result.Choices {
+				if content, ok := choice.Delta["content"].(string); ok {
+					answer.WriteString(content)
+				}
+			}
+
+			if chunk.Final {
+				_, content := util.SplitReasoning(answer.String())
+				return content, nil
+			}
+
+		case msg := <-cmdResult:
+			// Some clients report request errors as an error command instead of a chunk
+			if errCmd, ok := msg.(tea.Cmd); ok && errCmd != nil {
+				msg = errCmd()
+			}
+			if errMsg, ok := msg.(util.ErrorEvent); ok {
+				return "", errors.New(errMsg.Message)
+			}
+			cmdResult = nil
+		}
+	}
+}
+
+func parseFollowUps(answer string) []string {
+	suggestions := []string{}
+	for line := range strings.SplitSeq(answer, "\n") {
+		line = strings.TrimSpace(listMarkerRegex.ReplaceAllString(line, ""))
+		line = strings.Trim(line, "*\"")
+		if line == "" || len(line) > maxFollowUpLength {
+			continue
+		}
+
+		suggestions = append(suggestions, line)
+		if len(suggestions) == maxFollowUps {
+			break
+		}
+	}
+	return suggestions
+}
+
+// Topics of the response sections go first, generic questions fill the rest
+func getHeuristicFollowUps(response string) []string {
+	suggestions := []string{}
+	text := codeBlockRegex.ReplaceAllString(response, "")
+	for _, match := range markdownHeadingRegex.FindAllStringSubmatch(text, -1) {
+		topic := strings.Trim(strings.TrimSpace(match[1]), "*_`:")
+		if topic == "" || len(topic) > maxFollowUpTopic || strings.EqualFold(topic, "sources") {
+			continue
+		}
+
+		suggestions = append(suggestions, i18n.Tf("followUps.topic", topic))
+		if len(suggestions) == maxFollowUps-1 {
+			break
+		}
+	}
+
+	generic := []string{i18n.T("followUps.example"), i18n.T("followUps.details"), i18n.T("followUps.alternatives")}
+	if strings.Contains(response, "```") {
+		generic[0] = i18n.T("followUps.code")
+	}
+
+	for _, suggestion := range generic {
+		if len(suggestions) == maxFollowUps {
+			break
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return util.RemoveDuplicates(suggestions)
+}
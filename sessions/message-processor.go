@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"errors"
 	"slices"
-	"sort"
 	"strings"
 
 	"github.com/BalanceBalls/nekot/util"
@@ -32,8 +31,8 @@ type MessageProcessor struct {
 }
 
 var (
-	legacyThinkStartToken = "<think>"
-	legacyThinkEndToken   = "</think>"
+	legacyThinkStartToken = util.ThinkStartToken
+	legacyThinkEndToken   = util.ThinkEndToken
 )
 
 func NewMessageProcessor(
@@ -51,7 +50,7 @@ func NewMessageProcessor(
 }
 
 var stageChangeMap = map[util.ProcessingState][]util.ProcessingState{
-	util.Idle:                   {util.ProcessingChunks, util.AwaitingToolCallResult, util.Error},
+	util.Idle:                   {util.ProcessingChunks, util.AwaitingToolCallResult, util.Finalized, util.Error},
 	util.ProcessingChunks:       {util.AwaitingFinalization, util.AwaitingToolCallResult, util.Finalized, util.Error},
 	util.AwaitingToolCallResult: {util.ProcessingChunks, util.AwaitingFinalization, util.Finalized, util.Error},
 	util.AwaitingFinalization:   {util.Finalized, util.Error},
@@ -80,12 +79,6 @@ func (p MessageProcessor) Process(
 		return result, nil
 	}
 
-	if p.isDuplicate(chunk) {
-		result.IsSkipped = true
-		util.Slog.Debug("skipped duplicate chunk", "id", chunk.ID)
-		return result, nil
-	}
-
 	if toolCalls, ok := p.hasToolCalls(chunk); ok {
 		result.ToolCalls = toolCalls
 		result.CurrentResponseDataChunks = append(p.ResponseDataChunks, chunk)
@@ -96,8 +89,8 @@ func (p MessageProcessor) Process(
 
 	result.ToolCalls = nil
 
-	if p.isFinalChunk(chunk) {
-		result.CurrentResponseDataChunks = append(p.ResponseDataChunks, chunk)
+	// the stream is over, whether the finish reason was received or not
+	if chunk.Final {
 		result = p.finalizeProcessing(result)
 		return result, nil
 	}
@@ -109,9 +102,9 @@ func (p MessageProcessor) Process(
 	}
 
 	if p.isLastResponseChunk(chunk) {
-		result.State = p.setProcessingState(util.AwaitingFinalization, result)
+		result.State = p.setProcessingState(util.AwaitingFinalization)
 	} else {
-		result.State = p.setProcessingState(util.ProcessingChunks, result)
+		result.State = p.setProcessingState(util.ProcessingChunks)
 	}
 
 	result, bufferErr := result.composeProcessingResult(p, chunk)
@@ -124,7 +117,7 @@ func (p MessageProcessor) Process(
 
 func (p MessageProcessor) finalizeProcessing(result ProcessingResult) ProcessingResult {
 	result.JSONResponse = p.prepareResponseJSONForDB(nil)
-	result.State = p.setProcessingState(util.Finalized, result)
+	result.State = p.setProcessingState(util.Finalized)
 	return result
 }
 
@@ -132,27 +125,15 @@ func (p MessageProcessor) prepareToolCallInterruption(
 	result ProcessingResult,
 	chunk util.ProcessApiCompletionResponse) ProcessingResult {
 	result.JSONResponse = p.prepareResponseJSONForDB(&chunk)
-	result.State = p.setProcessingState(util.AwaitingToolCallResult, result)
+	result.State = p.setProcessingState(util.AwaitingToolCallResult)
 	return result
 }
 
-func (p MessageProcessor) orderChunks() MessageProcessor {
-	sort.Slice(p.ResponseDataChunks, func(i, j int) bool {
-		return p.ResponseDataChunks[i].ID < p.ResponseDataChunks[j].ID
-	})
-	return p
-}
-
-func (p MessageProcessor) setProcessingState(newState util.ProcessingState, result ProcessingResult) util.ProcessingState {
+func (p MessageProcessor) setProcessingState(newState util.ProcessingState) util.ProcessingState {
 	if p.CurrentState == newState {
 		return newState
 	}
 
-	if p.CurrentState == util.AwaitingToolCallResult && newState == util.Finalized && !result.IsCancelled {
-		util.Slog.Warn("this state change only allowed for cancellation", "old state", p.CurrentState, "new state", newState)
-		return p.CurrentState
-	}
-
 	if slices.Contains(stageChangeMap[p.CurrentState], newState) {
 		util.Slog.Debug("state changed", "old state", p.CurrentState, "new state", newState)
 		return newState
@@ -162,16 +143,6 @@ func (p MessageProcessor) setProcessingState(newState util.ProcessingState, resu
 	return p.CurrentState
 }
 
-func (p MessageProcessor) isDuplicate(chunk util.ProcessApiCompletionResponse) bool {
-	if slices.ContainsFunc(p.ResponseDataChunks, func(c util.ProcessApiCompletionResponse) bool {
-		return c.ID == chunk.ID
-	}) {
-		util.Slog.Debug("there is already a chunk with such id", "id", chunk.ID)
-		return true
-	}
-	return false
-}
-
 func (p MessageProcessor) hasToolCalls(chunk util.ProcessApiCompletionResponse) ([]util.ToolCall, bool) {
 
 	if len(chunk.Result.Choices) == 0 {
@@ -226,7 +197,7 @@ func (p MessageProcessor) handleErrors(
 		return result, nil
 	}
 
-	result.State = p.setProcessingState(util.Error, result)
+	result.State = p.setProcessingState(util.Error)
 	return result, chunk.Err
 }
 
@@ -252,12 +223,21 @@ func (r ProcessingResult) composeProcessingResult(
 		return r, nil
 	}
 
-	if reasoning, ok := p.getChunkReasoningData(newChunk, p.ResponseDataChunks); ok {
-		updatedResponseBuffer = updatedResponseBuffer + reasoning
+	// Reasoning from reasoning fields is wrapped in <think> tags like the legacy reasoning in content,
+	// so the chat can render it separately from the answer while streaming
+	delta := newChunk.Result.Choices[0].Delta
+	if reasoning, ok := getReasoningContent(delta); ok && reasoning != "" {
+		if !isThinkingOpen(updatedResponseBuffer) {
+			updatedResponseBuffer += legacyThinkStartToken
+		}
+		updatedResponseBuffer += reasoning
 	}
 
-	if choiceString, ok := getContent(newChunk.Result.Choices[0].Delta); ok {
-		updatedResponseBuffer = updatedResponseBuffer + choiceString
+	if choiceString, ok := getContent(delta); ok && choiceString != "" {
+		if isThinkingOpen(updatedResponseBuffer) && !anyContentContainsText(p.ResponseDataChunks, legacyThinkStartToken) {
+			updatedResponseBuffer += legacyThinkEndToken
+		}
+		updatedResponseBuffer += choiceString
 	}
 
 	r.CurrentResponse = updatedResponseBuffer
@@ -265,10 +245,6 @@ func (r ProcessingResult) composeProcessingResult(
 	return r, nil
 }
 
-func (p MessageProcessor) isFinalChunk(msg util.ProcessApiCompletionResponse) bool {
-	return msg.Final && p.CurrentState == util.AwaitingFinalization
-}
-
 func (p MessageProcessor) isLastResponseChunk(msg util.ProcessApiCompletionResponse) bool {
 	choice := msg.Result.Choices[0]
 	if _, ok := getReasoningContent(choice.Delta); ok {
@@ -312,7 +288,6 @@ func (p MessageProcessor) prepareResponseJSONForDB(currentChunk *util.ProcessApi
 		p.ResponseDataChunks = append(p.ResponseDataChunks, *currentChunk)
 	}
 
-	p = p.orderChunks()
 	processed := []util.ProcessApiCompletionResponse{}
 	for _, responseChunk := range p.ResponseDataChunks {
 		processed = append(processed, responseChunk)
@@ -335,7 +310,12 @@ func (p MessageProcessor) prepareResponseJSONForDB(currentChunk *util.ProcessApi
 		}
 	}
 
-	// newMessage.Resoning = formatThinkingContent(newMessage.Resoning)
+	// Legacy reasoning is stored separately, so it is not rendered twice
+	if newMessage.Resoning != "" && strings.Contains(newMessage.Content, legacyThinkStartToken) {
+		_, newMessage.Content = util.SplitReasoning(newMessage.Content)
+		newMessage.Resoning = formatThinkingContent(newMessage.Resoning)
+	}
+
 	return newMessage
 }
 
@@ -378,6 +358,21 @@ func anyChunkContainsText(chunks []util.ProcessApiCompletionResponse, text strin
 	)
 }
 
+func isThinkingOpen(buffer string) bool {
+	return strings.LastIndex(buffer, legacyThinkStartToken) > strings.LastIndex(buffer, legacyThinkEndToken)
+}
+
+// Unlike anyChunkContainsText, reasoning fields are not checked
+func anyContentContainsText(chunks []util.ProcessApiCompletionResponse, text string) bool {
+	return slices.ContainsFunc(chunks, func(c util.ProcessApiCompletionResponse) bool {
+		if len(c.Result.Choices) == 0 {
+			return false
+		}
+		content, ok := getContent(c.Result.Choices[0].Delta)
+		return ok && strings.Contains(content, text)
+	})
+}
+
 func (p MessageProcessor) getChunkReasoningData(
 	chunk util.ProcessApiCompletionResponse,
 	previousChunks []util.ProcessApiCompletionResponse,
@@ -0,0 +1,156 @@
+package sessions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/util"
+)
+
+const defaultTranslationLanguage = "English"
+
+// Used by stripBoilerplate middleware without patterns: filler openers and closers of responses
+var defaultBoilerplatePatterns = []string{
+	`(?i)^\s*(sure|certainly|of course|absolutely|great question)[!,.][^\n]*\n+`,
+	`(?i)\n+[^\n]*(let me know if|i hope this helps|feel free to ask)[^\n]*\s*$`,
+}
+
+var variableRegex = regexp.MustCompile(`{{\s*(\w+)\s*}}`)
+
+// Transforms messages right before they are sent to the provider.
+// Stored and displayed messages are not affected
+type promptMiddleware func(messages []util.LocalStoreMessage) []util.LocalStoreMessage
+
+// Transforms a response before it is stored
+type responseMiddleware func(content string) string
+
+// Parameter prefixes are removed first, they are read from the stored prompt by the orchestrator.
+// Translation mode goes next, so the following middleware doesn't end up in the text to translate
+func (m Orchestrator) getPromptMiddleware() []promptMiddleware {
+	middleware := []promptMiddleware{mapUserMessages(util.StripParameterOverrides)}
+	if m.CurrentSessionTranslation {
+		middleware = append(middleware, translationMiddleware(m.config.TranslationLanguage, m.config.TranslationTone))
+	}
+
+	for _, step := range m.config.Middleware {
+		switch step.Type {
+		case config.AppendInstructionMiddleware:
+			middleware = append(middleware, appendToLastUserMessage(func() string { return step.Text }))
+		case config.DateTimeMiddleware:
+			middleware = append(middleware, appendToLastUserMessage(func() string {
+				return "Current date and time: " + time.Now().Format(time.RFC1123)
+			}))
+		case config.RedactSecretsMiddleware:
+			middleware = append(middleware, mapUserMessages(util.RedactSecrets))
+		case config.VariablesMiddleware:
+			middleware = append(middleware, mapUserMessages(func(content string) string {
+				return substituteVariables(content, step.Values)
+			}))
+		}
+	}
+	return middleware
+}
+
+func (m Orchestrator) getResponseMiddleware() []responseMiddleware {
+	middleware := []responseMiddleware{}
+	for _, step := range m.config.Middleware {
+		if step.Type == config.StripBoilerplateMiddleware {
+			middleware = append(middleware, stripBoilerplate(step.Patterns))
+		}
+	}
+	return middleware
+}
+
+// Messages are copied, so middleware can change them freely
+func (m Orchestrator) applyPromptMiddleware(messages []util.LocalStoreMessage) []util.LocalStoreMessage {
+	middleware := m.getPromptMiddleware()
+	result := make([]util.LocalStoreMessage, len(messages))
+	copy(result, messages)
+
+	for _, transform := range middleware {
+		result = transform(result)
+	}
+	return result
+}
+
+func (m Orchestrator) applyResponseMiddleware(content string) string {
+	for _, transform := range m.getResponseMiddleware() {
+		content = transform(content)
+	}
+	return content
+}
+
+// Wraps every user message with an instruction to translate it
+func translationMiddleware(language, tone string) promptMiddleware {
+	if language == "" {
+		language = defaultTranslationLanguage
+	}
+
+	instruction := fmt.Sprintf("Translate the text below to %s", language)
+	if tone != "" {
+		instruction += fmt.Sprintf(" using a %s tone", tone)
+	}
+	instruction += ". Preserve the meaning and formatting, output only the translation."
+
+	return mapUserMessages(func(content string) string {
+		return fmt.Sprintf("%s\n\n<text>\n%s\n</text>", instruction, content)
+	})
+}
+
+// Instructions are only added to the latest prompt, so they are not repeated through the conversation
+func appendToLastUserMessage(getText func() string) promptMiddleware {
+	return func(messages []util.LocalStoreMessage) []util.LocalStoreMessage {
+		for i := len(messages) - 1; i >= 0; i-- {
+			if messages[i].Role == "user" {
+				messages[i].Content += "\n\n" + getText()
+				break
+			}
+		}
+		return messages
+	}
+}
+
+func mapUserMessages(transform func(content string) string) promptMiddleware {
+	return func(messages []util.LocalStoreMessage) []util.LocalStoreMessage {
+		for i, message := range messages {
+			if message.Role == "user" {
+				messages[i].Content = transform(message.Content)
+			}
+		}
+		return messages
+	}
+}
+
+// Unknown variables are left as is
+func substituteVariables(content string, values map[string]string) string {
+	return variableRegex.ReplaceAllStringFunc(content, func(match string) string {
+		if value, ok := values[variableRegex.FindStringSubmatch(match)[1]]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// Patterns are validated on startup
+func stripBoilerplate(patterns []string) responseMiddleware {
+	if len(patterns) == 0 {
+		patterns = defaultBoilerplatePatterns
+	}
+
+	regexps := []*regexp.Regexp{}
+	for _, pattern := range patterns {
+		if re, err := regexp.Compile(pattern); err == nil {
+			regexps = append(regexps, re)
+		}
+	}
+
+	return func(content string) string {
+		for _, re := range regexps {
+			content = re.ReplaceAllString(content, "")
+		}
+		return strings.TrimSpace(content)
+	}
+}
@@ -0,0 +1,88 @@
+package sessions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultNoteTag = "nekot"
+
+type Note struct {
+	Title   string
+	Model   string
+	Tags    []string
+	Content string
+}
+
+// Writes the note into the notes vault (e.g. an Obsidian vault) with a frontmatter
+// that notes apps can index: title, date, model and tags. Returns the path of the note
+func SaveNote(vaultDir string, note Note) (string, error) {
+	if vaultDir == "" {
+		return "", errors.New("notesDir is not set, set it in the config to save notes")
+	}
+
+	err := os.MkdirAll(vaultDir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	name := now.Format("2006-01-02") + "_" + sanitizeFilename(note.Title)
+	fullPath := filepath.Join(vaultDir, name+".md")
+
+	if _, err := os.Stat(fullPath); err == nil {
+		fullPath = filepath.Join(vaultDir, fmt.Sprintf("%s_%d.md", name, now.Unix()))
+	}
+
+	content := generateFrontmatter(note, now) + note.Content
+	return fullPath, os.WriteFile(fullPath, []byte(content), 0644)
+}
+
+// A note with the last response of the session
+func NewResponseNote(session Session, response string, model string, tags []string) Note {
+	return Note{
+		Title:   session.SessionName,
+		Model:   model,
+		Tags:    tags,
+		Content: fmt.Sprintf("# %s\n\n%s\n", session.SessionName, response),
+	}
+}
+
+// A note with the whole conversation, same as the markdown export
+func NewSessionNote(session Session, model string, tags []string) Note {
+	return Note{
+		Title:   session.SessionName,
+		Model:   model,
+		Tags:    tags,
+		Content: generateMarkdownContent(session),
+	}
+}
+
+func generateFrontmatter(note Note, date time.Time) string {
+	var sb strings.Builder
+
+	sb.WriteString("---\n")
+	sb.WriteString(fmt.Sprintf("title: %s\n", strconv.Quote(note.Title)))
+	sb.WriteString(fmt.Sprintf("date: %s\n", date.Format(time.RFC3339)))
+	if note.Model != "" {
+		sb.WriteString(fmt.Sprintf("model: %s\n", strconv.Quote(note.Model)))
+	}
+
+	sb.WriteString("tags:\n")
+	sb.WriteString(fmt.Sprintf("  - %s\n", defaultNoteTag))
+	for _, tag := range note.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == defaultNoteTag {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("  - %s\n", strconv.Quote(tag)))
+	}
+	sb.WriteString("---\n\n")
+
+	return sb.String()
+}
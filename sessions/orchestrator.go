@@ -6,16 +6,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/BalanceBalls/nekot/clients"
 	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/extensions/hooks"
+	"github.com/BalanceBalls/nekot/extensions/mermaid"
 	"github.com/BalanceBalls/nekot/extensions/websearch"
+	"github.com/BalanceBalls/nekot/i18n"
 	"github.com/BalanceBalls/nekot/settings"
 	"github.com/BalanceBalls/nekot/user"
 	"github.com/BalanceBalls/nekot/util"
-	"github.com/atotto/clipboard"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -23,6 +26,7 @@ type Orchestrator struct {
 	sessionService  *SessionService
 	userService     *user.UserService
 	settingsService *settings.SettingsService
+	promptsService  *settings.SystemPromptsService
 	config          config.Config
 
 	mu                        *sync.RWMutex
@@ -31,6 +35,10 @@ type Orchestrator struct {
 	CurrentSessionID          int
 	CurrentSessionName        string
 	CurrentSessionIsTemporary bool
+	CurrentSessionPromptId    *int
+	CurrentSessionTranslation bool
+	CurrentSessionPersona     *util.Persona
+	CurrentSessionReadOnly    bool
 	ArrayOfProcessResult      []util.ProcessApiCompletionResponse
 	ArrayOfMessages           []util.LocalStoreMessage
 	CurrentAnswer             string
@@ -38,6 +46,8 @@ type Orchestrator struct {
 	ResponseProcessingState   util.ProcessingState
 	AllSessions               []Session
 	ProcessingMode            string
+	// Set by flow steps that enable or disable web search regardless of the preset
+	WebSearchOverride *bool
 
 	settingsReady    bool
 	dataLoaded       bool
@@ -45,6 +55,13 @@ type Orchestrator struct {
 	mainCtx          context.Context
 	processingCtx    context.Context
 	processingCancel context.CancelFunc
+	// Whether the provider reported usage for the current request
+	usageReported bool
+	lastAutosave  time.Time
+	// Chunks are accepted from the latest request only, until its stream is over
+	requestsCount   int
+	activeRequestId int
+	nextChunkSeq    int
 }
 
 func NewOrchestrator(db *sql.DB, ctx context.Context) Orchestrator {
@@ -71,6 +88,7 @@ func NewOrchestrator(db *sql.DB, ctx context.Context) Orchestrator {
 		sessionService:          ss,
 		userService:             us,
 		settingsService:         settingsService,
+		promptsService:          settings.NewSystemPromptsService(db),
 		InferenceClient:         llmClient,
 		ResponseProcessingState: util.Idle,
 		mu:                      &sync.RWMutex{},
@@ -79,8 +97,7 @@ func NewOrchestrator(db *sql.DB, ctx context.Context) Orchestrator {
 
 func (m Orchestrator) Init() tea.Cmd {
 
-	initCtx, cancel := context.
-		WithTimeout(m.mainCtx, time.Duration(util.DefaultRequestTimeOutSec*time.Second))
+	initCtx, cancel := context.WithTimeout(m.mainCtx, m.config.GetRequestTimeout())
 
 	settingsData := func() tea.Msg {
 		defer cancel()
@@ -134,16 +151,30 @@ func (m Orchestrator) Update(msg tea.Msg) (Orchestrator, tea.Cmd) {
 	case util.CopyLastMsg:
 		latestBotMessage, err := m.GetLatestBotMessage()
 		if err == nil {
-			clipboard.WriteAll(latestBotMessage)
+			util.CopyToClipboard(latestBotMessage)
 			cmds = append(cmds, util.SendNotificationMsg(util.CopiedNotification))
 		}
 
 	case util.CopyAllMsgs:
-		clipboard.WriteAll(m.GetMessagesAsString())
+		util.CopyToClipboard(m.GetMessagesAsString())
 		cmds = append(cmds, util.SendNotificationMsg(util.CopiedNotification))
 
+	case util.SaveToNotesMsg:
+		cmds = append(cmds, m.saveToNotes(msg.WholeSession, msg.Text))
+
+	case util.ExportDiagramsMsg:
+		cmds = append(cmds, m.exportDiagrams())
+
+	case config.ConfigUpdated:
+		m.config = msg.Config
+
 	case SaveQuickChat:
 		if m.CurrentSessionIsTemporary {
+			if msg.Name != "" {
+				if err := m.sessionService.UpdateSessionName(m.CurrentSessionID, msg.Name); err != nil {
+					return m, util.MakeErrorMsg(err.Error())
+				}
+			}
 			m.sessionService.SaveQuickChat(m.CurrentSessionID)
 			updatedSession, _ := m.sessionService.GetSession(m.CurrentSessionID)
 			cmds = append(cmds, SendUpdateCurrentSessionMsg(updatedSession))
@@ -151,6 +182,32 @@ func (m Orchestrator) Update(msg tea.Msg) (Orchestrator, tea.Cmd) {
 			cmds = append(cmds, util.SendNotificationMsg(util.SessionSavedNotification))
 		}
 
+	case AssignSessionSystemPrompt:
+		err := m.sessionService.UpdateSessionSystemPrompt(m.CurrentSessionID, msg.SystemPromptId)
+		if err != nil {
+			return m, util.MakeErrorMsg(err.Error())
+		}
+		m.CurrentSessionPromptId = msg.SystemPromptId
+		cmds = append(cmds, util.SendNotificationMsg(util.SysPromptChangedNotification))
+
+	case AssignSessionPersona:
+		err := m.sessionService.UpdateSessionPersona(m.CurrentSessionID, msg.PersonaId)
+		if err != nil {
+			return m, util.MakeErrorMsg(err.Error())
+		}
+
+		updatedSession, err := m.sessionService.GetSession(m.CurrentSessionID)
+		if err != nil {
+			return m, util.MakeErrorMsg(err.Error())
+		}
+
+		notification := i18n.T("notification.personaDetached")
+		if updatedSession.Persona != nil {
+			notification = i18n.Tf("notification.personaAssigned", updatedSession.Persona.Name)
+		}
+		cmds = append(cmds, SendUpdateCurrentSessionMsg(updatedSession))
+		cmds = append(cmds, util.SendToastMsg(notification, util.SuccessSeverity))
+
 	case UpdateCurrentSession:
 		if !msg.Session.IsTemporary {
 			m.sessionService.SweepTemporarySessions()
@@ -159,6 +216,21 @@ func (m Orchestrator) Update(msg tea.Msg) (Orchestrator, tea.Cmd) {
 
 		m.setCurrentSessionData(msg.Session)
 
+	case SessionRenamed:
+		if msg.ID == m.CurrentSessionID {
+			m.CurrentSessionName = msg.Name
+		}
+
+	case SessionTranslationToggled:
+		if msg.ID == m.CurrentSessionID {
+			m.CurrentSessionTranslation = msg.Enabled
+		}
+
+	case SessionReadOnlyToggled:
+		if msg.ID == m.CurrentSessionID {
+			m.CurrentSessionReadOnly = msg.Enabled
+		}
+
 	case LoadDataFromDB:
 		util.Slog.Debug("orchestrator loaded data from db", "Session name:", msg.Session.SessionName)
 		m.setCurrentSessionData(msg.Session)
@@ -172,8 +244,10 @@ func (m Orchestrator) Update(msg tea.Msg) (Orchestrator, tea.Cmd) {
 		m.Settings = msg.Settings
 		m.settingsReady = true
 
-	case util.ProcessApiCompletionResponse:
-		cmds = append(cmds, m.hanldeProcessAPICompletionResponse(msg))
+	case util.CompletionChunksBatch:
+		for _, chunk := range msg.Chunks {
+			cmds = append(cmds, m.hanldeProcessAPICompletionResponse(chunk))
+		}
 		cmds = append(cmds, SendResponseChunkProcessedMsg(m.CurrentAnswer, m.ArrayOfMessages, false))
 
 	case ToolCallRequest:
@@ -185,6 +259,20 @@ func (m Orchestrator) Update(msg tea.Msg) (Orchestrator, tea.Cmd) {
 
 	case InferenceFinalized:
 		return m, m.finishResponseProcessing(msg.Response, msg.IsToolCall)
+
+	case TogglePinnedMessage:
+		cmds = append(cmds, m.togglePinnedMessage(msg.Index))
+
+	case RateMessage:
+		cmds = append(cmds, m.rateMessage(msg.Index, msg.Rating))
+
+	case SetRatingNote:
+		cmds = append(cmds, m.setRatingNote(msg.Index, msg.Note))
+
+	case SessionMarksChanged:
+		if err := m.sessionService.UpdateSessionMarks(msg.SessionId, msg.Marks); err != nil {
+			return m, util.MakeErrorMsg(err.Error())
+		}
 	}
 
 	if m.dataLoaded && m.settingsReady && !m.initialized {
@@ -200,7 +288,12 @@ func (m *Orchestrator) GetCompletion(
 	resp chan util.ProcessApiCompletionResponse,
 ) tea.Cmd {
 	m.setProcessingContext(ctx)
-	return m.InferenceClient.RequestCompletion(m.processingCtx, m.ArrayOfMessages, m.Settings, resp)
+	if err := util.CheckHostAllowed(clients.GetProviderUrl(m.config)); err != nil {
+		return m.asRequestFailure(util.MakeErrorMsg(err.Error()))
+	}
+
+	messages := m.applyPromptMiddleware(m.ArrayOfMessages)
+	return m.asRequestFailure(m.InferenceClient.RequestCompletion(m.processingCtx, messages, m.getRequestSettings(), resp))
 }
 
 func (m *Orchestrator) ResumeCompletion(
@@ -210,9 +303,33 @@ func (m *Orchestrator) ResumeCompletion(
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.setProcessingContext(ctx)
+	if err := util.CheckHostAllowed(clients.GetProviderUrl(m.config)); err != nil {
+		return m.asRequestFailure(util.MakeErrorMsg(err.Error()))
+	}
+
 	updatedSession, _ := m.sessionService.GetSession(m.CurrentSessionID)
 	m.setCurrentSessionData(updatedSession)
-	return m.InferenceClient.RequestCompletion(m.processingCtx, updatedSession.Messages, m.Settings, resp)
+	messages := m.applyPromptMiddleware(updatedSession.Messages)
+	return m.asRequestFailure(m.InferenceClient.RequestCompletion(m.processingCtx, messages, m.getRequestSettings(), resp))
+}
+
+// Clients report requests that failed to start with an error command.
+// Errors of a cancelled request are dropped, a new request may be running already
+func (m *Orchestrator) asRequestFailure(cmd tea.Cmd) tea.Cmd {
+	ctx := m.processingCtx
+	return func() tea.Msg {
+		msg := cmd()
+		errMsg, ok := msg.(util.ErrorEvent)
+		if !ok {
+			return msg
+		}
+
+		if ctx.Err() != nil {
+			util.Slog.Debug("dropped error of a cancelled request", "error", errMsg.Message)
+			return nil
+		}
+		return util.MarkRequestFailure(errMsg)
+	}
 }
 
 func (m *Orchestrator) Cancel() {
@@ -221,10 +338,49 @@ func (m *Orchestrator) Cancel() {
 	}
 }
 
+// Providers report usage at the end of a stream, so an interrupted response is never accounted for.
+// Estimates tokens consumed before cancel, adds them to the session stats and returns the estimate
+func (m *Orchestrator) AccountCancelledResponse() util.TokenUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	processor := NewMessageProcessor(m.ArrayOfProcessResult, m.ResponseBuffer, m.ResponseProcessingState, m.getSessionSettings())
+	return m.accountEstimatedUsage(processor.prepareResponseJSONForDB(nil))
+}
+
+// Estimates tokens of the request and the response with the local tokenizer and adds them to the session stats.
+// The session is marked as having estimated stats
+func (m *Orchestrator) accountEstimatedUsage(response util.LocalStoreMessage) util.TokenUsage {
+	usage := util.TokenUsage{
+		Completion: util.CountTokens(response.Content) + util.CountTokens(response.Resoning),
+	}
+
+	if usage.Completion == 0 {
+		return usage
+	}
+
+	systemPrompt := m.config.SystemMessage
+	requestSettings := m.getRequestSettings()
+	if requestSettings.SystemPrompt != nil {
+		systemPrompt = *requestSettings.SystemPrompt
+	}
+
+	usage.Prompt = util.CountTokens(systemPrompt)
+	for _, msg := range m.ArrayOfMessages {
+		usage.Prompt += util.CountTokens(msg.Content)
+	}
+	usage.Total = usage.Prompt + usage.Completion
+
+	m.sessionService.AddSessionTokensStats(m.CurrentSessionID, usage.Prompt, usage.Completion, true)
+	return usage
+}
+
 func (m *Orchestrator) FinalizeResponseOnCancel() tea.Cmd {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.activeRequestId = 0
+
 	hasBufferedContent := len(m.ArrayOfProcessResult) > 0 || m.CurrentAnswer != "" || m.ResponseBuffer != ""
 	if !hasBufferedContent {
 		if m.ResponseProcessingState != util.Idle {
@@ -237,7 +393,7 @@ func (m *Orchestrator) FinalizeResponseOnCancel() tea.Cmd {
 		return nil
 	}
 
-	processor := NewMessageProcessor(m.ArrayOfProcessResult, m.ResponseBuffer, m.ResponseProcessingState, m.Settings)
+	processor := NewMessageProcessor(m.ArrayOfProcessResult, m.ResponseBuffer, m.ResponseProcessingState, m.getSessionSettings())
 	response := processor.prepareResponseJSONForDB(nil)
 
 	if response.Content == "" && response.Resoning == "" && len(response.ToolCalls) == 0 {
@@ -256,7 +412,14 @@ func (m *Orchestrator) setProcessingContext(ctx context.Context) {
 		m.processingCancel()
 	}
 
+	m.requestsCount++
+	m.activeRequestId = m.requestsCount
+	m.nextChunkSeq = 0
+
+	ctx = util.WithRequestId(util.WithSessionId(ctx, m.CurrentSessionID), m.activeRequestId)
 	m.processingCtx, m.processingCancel = context.WithCancel(ctx)
+	m.usageReported = false
+	m.lastAutosave = time.Now()
 }
 
 func (m Orchestrator) GetCurrentSessionId() int {
@@ -284,6 +447,59 @@ func (m Orchestrator) GetLatestBotMessage() (string, error) {
 	)
 }
 
+// Saves the last response, a part of it or the whole session as a note into the notes vault
+func (m Orchestrator) saveToNotes(wholeSession bool, text string) tea.Cmd {
+	session, err := m.sessionService.GetSession(m.CurrentSessionID)
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+
+	if len(session.Messages) == 0 {
+		return nil
+	}
+
+	lastMessage := session.Messages[len(session.Messages)-1]
+	model := lastMessage.Model
+	if model == "" {
+		model = m.getSessionSettings().Model
+	}
+
+	if text == "" {
+		text = lastMessage.Content
+	}
+
+	note := NewResponseNote(session, text, model, m.config.NotesTags)
+	if wholeSession {
+		note = NewSessionNote(session, model, m.config.NotesTags)
+	}
+
+	_, err = SaveNote(m.config.NotesDir, note)
+	if err != nil {
+		return util.MakeRecoverableErrorMsg(err.Error())
+	}
+
+	return util.SendNotificationMsg(util.NoteSavedNotification)
+}
+
+// Diagrams are taken from the last response, including the messages of its tool calls
+func (m Orchestrator) exportDiagrams() tea.Cmd {
+	diagrams := []string{}
+	for i := len(m.ArrayOfMessages) - 1; i >= 0 && m.ArrayOfMessages[i].Role != "user"; i-- {
+		diagrams = append(mermaid.FindDiagrams(m.ArrayOfMessages[i].Content), diagrams...)
+	}
+
+	if len(diagrams) == 0 {
+		return util.SendToastMsg(i18n.T("notification.noDiagrams"), util.WarningSeverity)
+	}
+
+	return mermaid.Export(diagrams, mermaid.Options{
+		Dir:     m.config.SessionExportDir,
+		Name:    sanitizeFilename(m.CurrentSessionName),
+		Command: m.config.MermaidCommand,
+		Format:  m.config.MermaidFormat,
+	})
+}
+
 func (m Orchestrator) GetMessagesAsString() string {
 	var messages string
 	for _, message := range m.ArrayOfMessages {
@@ -302,11 +518,70 @@ func (m Orchestrator) GetMessagesAsString() string {
 
 func (m *Orchestrator) setCurrentSessionData(session Session) {
 	m.CurrentSessionIsTemporary = session.IsTemporary
+	m.CurrentSessionPromptId = session.SystemPromptId
 	m.CurrentSessionID = session.ID
 	m.CurrentSessionName = session.SessionName
+	m.CurrentSessionTranslation = session.TranslationEnabled
+	m.CurrentSessionPersona = session.Persona
+	m.CurrentSessionReadOnly = session.ReadOnly
 	m.ArrayOfMessages = session.Messages
 }
 
+// Settings of the preset with the model of the session persona
+func (m Orchestrator) getSessionSettings() util.Settings {
+	sessionSettings := m.Settings
+	if m.CurrentSessionPersona != nil && m.CurrentSessionPersona.Model != "" {
+		sessionSettings.Model = m.CurrentSessionPersona.Model
+	}
+	return sessionSettings
+}
+
+// Drops features the current model does not support and resolves the system prompt from the library.
+// Sampling parameters follow the parameter schedule and the prefixes of the latest prompt.
+// The prompt of the session persona takes precedence over the prompt assigned to the session,
+// which in turn takes precedence over the one assigned to the preset
+func (m Orchestrator) getRequestSettings() util.Settings {
+	requestSettings := m.applyParameterOverrides(m.getSessionSettings())
+	if m.WebSearchOverride != nil {
+		requestSettings.WebSearchEnabled = *m.WebSearchOverride
+	}
+	requestSettings = m.dropUnsupportedFeatures(requestSettings)
+
+	if m.CurrentSessionPersona != nil && m.CurrentSessionPersona.SystemPrompt != "" {
+		requestSettings.SystemPrompt = &m.CurrentSessionPersona.SystemPrompt
+		return requestSettings
+	}
+
+	promptId := requestSettings.SystemPromptId
+	if m.CurrentSessionPromptId != nil {
+		promptId = m.CurrentSessionPromptId
+	}
+	return m.withLibrarySystemPrompt(requestSettings, promptId)
+}
+
+func (m Orchestrator) dropUnsupportedFeatures(settings util.Settings) util.Settings {
+	provider := util.GetOpenAiInferenceProvider(m.config.Provider, m.config.ProviderBaseUrl)
+	if !util.GetModelCapabilities(provider, settings.Model).Tools || util.IsOfflineMode() {
+		settings.WebSearchEnabled = false
+	}
+	return settings
+}
+
+func (m Orchestrator) withLibrarySystemPrompt(settings util.Settings, promptId *int) util.Settings {
+	if promptId == nil {
+		return settings
+	}
+
+	prompt, err := m.promptsService.GetSystemPrompt(*promptId)
+	if err != nil {
+		util.Slog.Warn("failed to load system prompt from the library", "id", *promptId, "error", err)
+		return settings
+	}
+
+	settings.SystemPrompt = &prompt.Content
+	return settings
+}
+
 func (m *Orchestrator) hanldeProcessAPICompletionResponse(
 	msg util.ProcessApiCompletionResponse,
 ) tea.Cmd {
@@ -314,6 +589,16 @@ func (m *Orchestrator) hanldeProcessAPICompletionResponse(
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if msg.RequestId != m.activeRequestId {
+		util.Slog.Debug("dropped chunk of a finished request", "request id", msg.RequestId, "seq", msg.Seq)
+		return nil
+	}
+
+	if msg.Seq != m.nextChunkSeq {
+		util.Slog.Warn("chunk is out of sequence", "expected", m.nextChunkSeq, "seq", msg.Seq)
+	}
+	m.nextChunkSeq = msg.Seq + 1
+
 	util.Slog.Debug("processing state before new chunk",
 		"state", m.ResponseProcessingState,
 		"chunks ready", len(m.ArrayOfProcessResult),
@@ -321,21 +606,26 @@ func (m *Orchestrator) hanldeProcessAPICompletionResponse(
 		"isFinal", msg.Final)
 
 	prevProcessingState := m.ResponseProcessingState
-	p := NewMessageProcessor(m.ArrayOfProcessResult, m.ResponseBuffer, m.ResponseProcessingState, m.Settings)
+	p := NewMessageProcessor(m.ArrayOfProcessResult, m.ResponseBuffer, m.ResponseProcessingState, m.getSessionSettings())
 	result, err := p.Process(msg)
 
 	util.Slog.Debug("processed chunk",
-		"id", msg.ID,
+		"seq", msg.Seq,
 		"chunks ready", len(result.CurrentResponseDataChunks),
 		"state", result.State)
 
+	// tool calls end the stream as well, the final chunk that follows them is not needed
+	if msg.Final || err != nil || result.IsCancelled || len(result.ToolCalls) > 0 {
+		m.activeRequestId = 0
+	}
+
 	if err != nil {
 		util.Slog.Error("error occured on processing a chunk", "chunk", msg, "error", err.Error())
-		return m.resetStateAndCreateError(err.Error())
+		return m.resetStateAndCreateRequestFailure(err)
 	}
 
 	m.handleTokenStatsUpdate(result)
-	m.appendAndOrderProcessResults(result)
+	m.applyProcessingResult(result)
 
 	if result.IsSkipped {
 		util.Slog.Info("result skipped", "data", msg.Result)
@@ -350,6 +640,10 @@ func (m *Orchestrator) hanldeProcessAPICompletionResponse(
 		)
 	}
 
+	if len(result.ToolCalls) > 0 && !m.usageReported {
+		m.accountEstimatedUsage(result.JSONResponse)
+	}
+
 	if len(result.ToolCalls) > 0 {
 		var cmds []tea.Cmd
 		util.Slog.Debug("processed chunk with a tool call",
@@ -370,9 +664,14 @@ func (m *Orchestrator) hanldeProcessAPICompletionResponse(
 
 	if result.State == util.Finalized {
 		util.Slog.Debug("result finalized", "json result", result.JSONResponse.Content)
+		if !m.usageReported {
+			m.accountEstimatedUsage(result.JSONResponse)
+		}
 		return FinalizeResponse(result.JSONResponse, false)
 	}
 
+	m.autosaveResponse()
+
 	if prevProcessingState != result.State {
 		return util.SendProcessingStateChangedMsg(result.State)
 	}
@@ -380,16 +679,46 @@ func (m *Orchestrator) hanldeProcessAPICompletionResponse(
 	return nil
 }
 
+// Saves the session with the part of the response streamed so far, so a long response
+// is not lost if the app is closed or crashes. The complete response replaces it
+func (m *Orchestrator) autosaveResponse() {
+	interval := m.config.GetAutosaveInterval()
+	if interval == 0 || time.Since(m.lastAutosave) < interval {
+		return
+	}
+	m.lastAutosave = time.Now()
+
+	processor := NewMessageProcessor(m.ArrayOfProcessResult, m.ResponseBuffer, m.ResponseProcessingState, m.getSessionSettings())
+	response := processor.prepareResponseJSONForDB(nil)
+	if response.Content == "" && response.Resoning == "" {
+		return
+	}
+
+	messages := append(slices.Clone(m.ArrayOfMessages), response)
+	if err := m.sessionService.UpdateSessionMessages(m.CurrentSessionID, messages); err != nil {
+		util.Slog.Warn("failed to autosave the response", "error", err.Error())
+	}
+}
+
 func (m *Orchestrator) doWebSearch(ctx context.Context, id string, args map[string]string) tea.Cmd {
+	timeout := m.config.GetWebSearchTimeout()
+	options := websearch.Options{
+		Pages:   m.config.GetWebSearchPages(),
+		Results: m.config.GetWebSearchResults(),
+	}
+
 	return func() tea.Msg {
 		toolName := "web_search"
-		result, err := websearch.PrepareContextFromWebSearch(ctx, args["query"])
+		searchCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		result, err := websearch.PrepareContextFromWebSearch(searchCtx, args["query"], options)
 		if err != nil {
 			if errors.Is(err, context.Canceled) {
 				return nil
 			}
 			util.Slog.Error("web search failed", "error", err.Error())
-			return util.ErrorEvent{Message: err.Error()}
+			return util.MarkRequestFailure(util.ErrorEvent{Message: err.Error()})
 		}
 
 		jsonData, err := json.Marshal(result)
@@ -417,6 +746,7 @@ func (m *Orchestrator) finishResponseProcessing(response util.LocalStoreMessage,
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	response.Content = m.applyResponseMiddleware(response.Content)
 	m.ArrayOfMessages = append(
 		m.ArrayOfMessages,
 		response,
@@ -424,7 +754,7 @@ func (m *Orchestrator) finishResponseProcessing(response util.LocalStoreMessage,
 
 	err := m.sessionService.UpdateSessionMessages(m.CurrentSessionID, m.ArrayOfMessages)
 	if err != nil {
-		return m.resetStateAndCreateError(err.Error())
+		return m.resetStateAndCreateError(err)
 	}
 
 	nextProcessingState := util.Idle
@@ -440,31 +770,66 @@ func (m *Orchestrator) finishResponseProcessing(response util.LocalStoreMessage,
 	m.ResponseBuffer = ""
 	m.ArrayOfProcessResult = []util.ProcessApiCompletionResponse{}
 
+	var hookCmd, followUpsCmd tea.Cmd
+	if !isToolCall {
+		followUpsCmd = m.suggestFollowUps(response)
+		hookCmd = hooks.Run(m.config.Hooks.OnResponseComplete, hooks.Event{
+			Hook:  hooks.ResponseCompleteHook,
+			Input: response.Content,
+			Env: map[string]string{
+				"NEKOT_SESSION_ID":   fmt.Sprint(m.CurrentSessionID),
+				"NEKOT_SESSION_NAME": m.CurrentSessionName,
+				"NEKOT_MODEL":        response.Model,
+			},
+		})
+	}
+
 	return tea.Batch(
 		util.SendProcessingStateChangedMsg(nextProcessingState),
-		SendResponseChunkProcessedMsg(m.CurrentAnswer, m.ArrayOfMessages, true),
+		// suggestions are shown once the chat pane has the complete response
+		tea.Sequence(SendResponseChunkProcessedMsg(m.CurrentAnswer, m.ArrayOfMessages, true), followUpsCmd),
+		hookCmd,
 	)
 }
 
 func (m *Orchestrator) handleTokenStatsUpdate(processingResult ProcessingResult) {
 	if processingResult.PromptTokens > 0 || processingResult.CompletionTokens > 0 {
+		m.usageReported = true
 		m.sessionService.AddSessionTokensStats(
 			m.CurrentSessionID,
 			processingResult.PromptTokens,
 			processingResult.CompletionTokens,
+			false,
 		)
 	}
 }
 
-func (m *Orchestrator) appendAndOrderProcessResults(processingResult ProcessingResult) {
+func (m *Orchestrator) applyProcessingResult(processingResult ProcessingResult) {
 	m.ResponseBuffer = processingResult.CurrentResponse
 	m.ArrayOfProcessResult = processingResult.CurrentResponseDataChunks
 	m.ResponseProcessingState = processingResult.State
 }
 
-func (m *Orchestrator) resetStateAndCreateError(errMsg string) tea.Cmd {
+func (m *Orchestrator) resetStateAndCreateError(err error) tea.Cmd {
+	m.resetProcessingState()
+	return tea.Batch(
+		util.MakeErrorMsgFromErr(err, m.getSessionSettings().Model),
+		util.SendProcessingStateChangedMsg(util.Idle),
+	)
+}
+
+// The stream of the active request failed, the prompt is put back so it can be sent again
+func (m *Orchestrator) resetStateAndCreateRequestFailure(err error) tea.Cmd {
+	m.resetProcessingState()
+	errCmd := util.MakeErrorMsgFromErr(err, m.getSessionSettings().Model)
+	return tea.Batch(
+		func() tea.Msg { return util.MarkRequestFailure(errCmd().(util.ErrorEvent)) },
+		util.SendProcessingStateChangedMsg(util.Idle),
+	)
+}
+
+func (m *Orchestrator) resetProcessingState() {
 	m.ArrayOfProcessResult = []util.ProcessApiCompletionResponse{}
 	m.CurrentAnswer = ""
 	m.ResponseProcessingState = util.Idle
-	return tea.Batch(util.MakeErrorMsg(errMsg), util.SendProcessingStateChangedMsg(util.Idle))
 }
@@ -0,0 +1,52 @@
+package sessions
+
+import (
+	"github.com/BalanceBalls/nekot/util"
+)
+
+// Applies the steps of the parameter schedule the conversation has reached,
+// then the parameter prefixes of the latest prompt, which take precedence
+func (m Orchestrator) applyParameterOverrides(settings util.Settings) util.Settings {
+	turns, latestPrompt := 0, ""
+	for _, message := range m.ArrayOfMessages {
+		if message.Role == "user" {
+			turns++
+			latestPrompt = message.Content
+		}
+	}
+
+	// The latest prompt is the one being answered
+	answeredTurns := max(turns-1, 0)
+	for _, step := range m.config.ParameterSchedule {
+		if answeredTurns < step.AfterTurns {
+			continue
+		}
+
+		if step.Temperature != nil {
+			settings.Temperature = step.Temperature
+		}
+		if step.TopP != nil {
+			settings.TopP = step.TopP
+		}
+		if step.MaxTokens != nil {
+			settings.MaxTokens = *step.MaxTokens
+		}
+	}
+
+	overrides, _, err := util.ParseParameterOverrides(latestPrompt)
+	if err != nil {
+		util.Slog.Warn("parameter prefixes of the prompt are ignored", "error", err.Error())
+		return settings
+	}
+
+	if overrides.Temperature != nil {
+		settings.Temperature = overrides.Temperature
+	}
+	if overrides.TopP != nil {
+		settings.TopP = overrides.TopP
+	}
+	if overrides.MaxTokens != nil {
+		settings.MaxTokens = *overrides.MaxTokens
+	}
+	return settings
+}
@@ -0,0 +1,44 @@
+package sessions
+
+import (
+	"slices"
+
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Pins are stored with the messages. Messages are copied,
+// the chat pane keeps rendering the previous array until it gets the new one
+func (m *Orchestrator) togglePinnedMessage(index int) tea.Cmd {
+	if !m.IsIdle() {
+		return util.SendToastMsg(i18n.T("notification.pinWhileProcessing"), util.WarningSeverity)
+	}
+
+	if index < 0 || index >= len(m.ArrayOfMessages) || !util.IsPinnable(m.ArrayOfMessages[index]) {
+		return util.SendToastMsg(i18n.T("notification.nothingToPin"), util.WarningSeverity)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := slices.Clone(m.ArrayOfMessages)
+	messages[index].Pinned = !messages[index].Pinned
+
+	err := m.sessionService.UpdateSessionMessages(m.CurrentSessionID, messages)
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+	m.ArrayOfMessages = messages
+
+	notification := i18n.T("notification.messageUnpinned")
+	if messages[index].Pinned {
+		notification = i18n.T("notification.messagePinned")
+	}
+
+	sessionId := m.CurrentSessionID
+	return tea.Batch(
+		func() tea.Msg { return PinnedMessagesChanged{SessionId: sessionId, Messages: messages} },
+		util.SendToastMsg(notification, util.SuccessSeverity),
+	)
+}
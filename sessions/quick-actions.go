@@ -0,0 +1,27 @@
+package sessions
+
+import (
+	"fmt"
+
+	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/util"
+)
+
+var quickActionPrompts = map[string]string{
+	util.SummarizeAction:     "Give a TL;DR of your last answer in a few sentences.",
+	util.ExplainSimplyAction: "Explain your last answer like I'm five: simple words, no jargon, a short analogy if it helps.",
+	util.ShortenAction:       "Rewrite your last answer to be more concise. Keep the key points and code, drop the rest.",
+}
+
+// The prompt of a quick action. Translation uses the language of translation mode
+func GetQuickActionPrompt(action string, cfg config.Config) string {
+	if action != util.TranslateAction {
+		return quickActionPrompts[action]
+	}
+
+	language := cfg.TranslationLanguage
+	if language == "" {
+		language = defaultTranslationLanguage
+	}
+	return fmt.Sprintf("Translate your last answer to %s. Preserve the meaning and formatting, output only the translation.", language)
+}
@@ -0,0 +1,62 @@
+package sessions
+
+import (
+	"slices"
+
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Ratings are stored with the messages, like pins. Removing the rating removes its note as well
+func (m *Orchestrator) rateMessage(index, rating int) tea.Cmd {
+	return m.updateRating(index, func(message *util.LocalStoreMessage) string {
+		if message.Rating == rating {
+			message.Rating = 0
+			message.RatingNote = ""
+			return i18n.T("notification.ratingRemoved")
+		}
+
+		message.Rating = rating
+		return i18n.T("notification.messageRated")
+	})
+}
+
+func (m *Orchestrator) setRatingNote(index int, note string) tea.Cmd {
+	return m.updateRating(index, func(message *util.LocalStoreMessage) string {
+		message.RatingNote = note
+		if note == "" {
+			return i18n.T("notification.ratingNoteRemoved")
+		}
+		return i18n.T("notification.ratingNoteSaved")
+	})
+}
+
+// Messages are copied, the chat pane keeps rendering the previous array until it gets the new one
+func (m *Orchestrator) updateRating(index int, update func(message *util.LocalStoreMessage) string) tea.Cmd {
+	if !m.IsIdle() {
+		return util.SendToastMsg(i18n.T("notification.rateWhileProcessing"), util.WarningSeverity)
+	}
+
+	if index < 0 || index >= len(m.ArrayOfMessages) || !util.IsRateable(m.ArrayOfMessages[index]) {
+		return util.SendToastMsg(i18n.T("notification.nothingToRate"), util.WarningSeverity)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := slices.Clone(m.ArrayOfMessages)
+	notification := update(&messages[index])
+
+	err := m.sessionService.UpdateSessionMessages(m.CurrentSessionID, messages)
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+	m.ArrayOfMessages = messages
+
+	sessionId := m.CurrentSessionID
+	return tea.Batch(
+		func() tea.Msg { return RatedMessagesChanged{SessionId: sessionId, Messages: messages} },
+		util.SendToastMsg(notification, util.SuccessSeverity),
+	)
+}
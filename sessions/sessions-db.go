@@ -16,6 +16,25 @@ type Session struct {
 	PromptTokens     int
 	CompletionTokens int
 	IsTemporary      bool
+	SystemPromptId   *int
+	// User messages are wrapped with a translation instruction before they are sent
+	TranslationEnabled bool
+	// Some of the token stats were estimated locally, because the provider didn't report usage
+	UsageEstimated bool
+	// Vim-style marks of the chat pane by their letter
+	Marks map[string]Mark
+	// Persona the session talks to, its model and system prompt take precedence over the preset
+	PersonaId *int
+	Persona   *util.Persona
+	// Prompts can't be sent to the session, it can only be read or duplicated
+	ReadOnly bool
+}
+
+// Position in a session: a message and a line within the rendered message.
+// Lines depend on the pane width, so a mark may drift a bit after resizing
+type Mark struct {
+	Message int `json:"message"`
+	Line    int `json:"line"`
 }
 
 type SessionService struct {
@@ -56,7 +75,9 @@ SELECT sessions_id, sessions_messages, sessions_created_at, sessions_session_nam
 }
 
 func (ss *SessionService) GetSession(id int) (Session, error) {
-	var messages string
+	var messages, marks string
+	var personaId sql.NullInt64
+	var personaName, personaColor, personaModel, personaPrompt sql.NullString
 	rows, err := ss.DB.Query(
 		`SELECT
 			sessions_id,
@@ -65,8 +86,19 @@ func (ss *SessionService) GetSession(id int) (Session, error) {
 			sessions_session_name,
 			prompt_tokens,
 			completion_tokens,
-			is_temporary
+			is_temporary,
+			system_prompt_id,
+			translation_enabled,
+			usage_estimated,
+			marks,
+			read_only,
+			personas.persona_id,
+			persona_name,
+			persona_color,
+			persona_model,
+			persona_system_prompt
 		FROM sessions
+		LEFT JOIN personas ON personas.persona_id = sessions.persona_id
 		WHERE sessions_id=$1`,
 		id,
 	)
@@ -87,7 +119,17 @@ func (ss *SessionService) GetSession(id int) (Session, error) {
 			&aSession.SessionName,
 			&aSession.PromptTokens,
 			&aSession.CompletionTokens,
-			&aSession.IsTemporary); err != nil {
+			&aSession.IsTemporary,
+			&aSession.SystemPromptId,
+			&aSession.TranslationEnabled,
+			&aSession.UsageEstimated,
+			&marks,
+			&aSession.ReadOnly,
+			&personaId,
+			&personaName,
+			&personaColor,
+			&personaModel,
+			&personaPrompt); err != nil {
 			return Session{}, err
 		}
 	} else {
@@ -103,6 +145,23 @@ func (ss *SessionService) GetSession(id int) (Session, error) {
 	if err != nil {
 		return Session{}, err
 	}
+
+	err = json.Unmarshal([]byte(marks), &aSession.Marks)
+	if err != nil {
+		return Session{}, err
+	}
+
+	if personaId.Valid {
+		id := int(personaId.Int64)
+		aSession.PersonaId = &id
+		aSession.Persona = &util.Persona{
+			ID:           id,
+			Name:         personaName.String,
+			Color:        personaColor.String,
+			Model:        personaModel.String,
+			SystemPrompt: personaPrompt.String,
+		}
+	}
 	return aSession, nil
 }
 
@@ -115,7 +174,8 @@ func (ss *SessionService) GetAllSessions() ([]Session, error) {
 			sessions_session_name,
 			prompt_tokens,
 			completion_tokens,
-			is_temporary
+			is_temporary,
+			read_only
 		FROM sessions
 		WHERE is_temporary = 0
 		ORDER BY sessions_id DESC`,
@@ -133,6 +193,7 @@ func (ss *SessionService) GetAllSessions() ([]Session, error) {
 			&aSession.PromptTokens,
 			&aSession.CompletionTokens,
 			&aSession.IsTemporary,
+			&aSession.ReadOnly,
 		)
 		sessions = append(sessions, aSession)
 	}
@@ -141,6 +202,36 @@ func (ss *SessionService) GetAllSessions() ([]Session, error) {
 	return sessions, nil
 }
 
+// Sessions with pinned messages, newest first. Messages are stored as json blobs,
+// they are cast to text for the search and are not loaded
+func (ss *SessionService) GetPinnedSessions(limit int) ([]Session, error) {
+	rows, err := ss.DB.Query(
+		`SELECT
+			sessions_id,
+			sessions_created_at,
+			sessions_session_name
+		FROM sessions
+		WHERE is_temporary = 0 AND CAST(sessions_messages AS TEXT) LIKE '%"pinned":true%'
+		ORDER BY sessions_id DESC
+		LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return []Session{}, err
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		aSession := Session{}
+		if err := rows.Scan(&aSession.ID, &aSession.CreatedAt, &aSession.SessionName); err != nil {
+			return []Session{}, err
+		}
+		sessions = append(sessions, aSession)
+	}
+	return sessions, rows.Err()
+}
+
 func (ss *SessionService) UpdateSessionMessages(id int, messages []util.LocalStoreMessage) error {
 	jsonData, err := json.Marshal(messages)
 	if err != nil {
@@ -160,14 +251,15 @@ func (ss *SessionService) UpdateSessionMessages(id int, messages []util.LocalSto
 	return nil
 }
 
-func (ss *SessionService) AddSessionTokensStats(id int, promptTokens, completionTokens int) error {
+func (ss *SessionService) AddSessionTokensStats(id int, promptTokens, completionTokens int, estimated bool) error {
 	_, err := ss.DB.Exec(`
 			UPDATE sessions
 			SET
 				prompt_tokens = prompt_tokens + $1,
-				completion_tokens = completion_tokens + $2
-			WHERE sessions_id = $3
-	`, promptTokens, completionTokens, id)
+				completion_tokens = completion_tokens + $2,
+				usage_estimated = MAX(usage_estimated, $3)
+			WHERE sessions_id = $4
+	`, promptTokens, completionTokens, estimated, id)
 
 	if err != nil {
 		util.Slog.Error("failed to update session token statistics", "error", err.Error())
@@ -189,6 +281,76 @@ func (ss *SessionService) UpdateSessionName(id int, name string) error {
 	return nil
 }
 
+func (ss *SessionService) UpdateSessionSystemPrompt(id int, systemPromptId *int) error {
+	_, err := ss.DB.Exec(`
+			UPDATE sessions
+			SET system_prompt_id = $1
+			where sessions_id = $2
+	`, systemPromptId, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (ss *SessionService) UpdateSessionPersona(id int, personaId *int) error {
+	_, err := ss.DB.Exec(`
+			UPDATE sessions
+			SET persona_id = $1
+			where sessions_id = $2
+	`, personaId, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (ss *SessionService) UpdateSessionTranslation(id int, enabled bool) error {
+	_, err := ss.DB.Exec(`
+			UPDATE sessions
+			SET translation_enabled = $1
+			where sessions_id = $2
+	`, enabled, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (ss *SessionService) UpdateSessionReadOnly(id int, readOnly bool) error {
+	_, err := ss.DB.Exec(`
+			UPDATE sessions
+			SET read_only = $1
+			where sessions_id = $2
+	`, readOnly, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (ss *SessionService) UpdateSessionMarks(id int, marks map[string]Mark) error {
+	jsonData, err := json.Marshal(marks)
+	if err != nil {
+		return err
+	}
+
+	_, err = ss.DB.Exec(`
+			UPDATE sessions
+			SET marks = $1
+			where sessions_id = $2
+	`, string(jsonData), id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (ss *SessionService) InsertNewSession(
 	name string,
 	messages []util.LocalStoreMessage,
@@ -228,6 +390,34 @@ func (ss *SessionService) InsertNewSession(
 	return newSession, nil
 }
 
+// Copies the messages, marks, system prompt, persona and translation mode of the session.
+// Token stats start from zero, the copy hasn't used any tokens yet. The copy is never read-only
+func (ss *SessionService) DuplicateSession(id int, name string) (Session, error) {
+	result, err := ss.DB.Exec(`
+		INSERT INTO sessions (
+			sessions_session_name,
+			sessions_messages,
+			system_prompt_id,
+			translation_enabled,
+			marks,
+			persona_id
+		)
+		SELECT $1, sessions_messages, system_prompt_id, translation_enabled, marks, persona_id
+		FROM sessions
+		WHERE sessions_id = $2
+	`, name, id)
+	if err != nil {
+		return Session{}, err
+	}
+
+	lastInsertID, err := result.LastInsertId()
+	if err != nil {
+		return Session{}, err
+	}
+
+	return ss.GetSession(int(lastInsertID))
+}
+
 func (ss *SessionService) DeleteSession(id int) error {
 	existing, err := ss.GetAllSessions()
 
@@ -0,0 +1,153 @@
+package sessions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/BalanceBalls/nekot/util"
+)
+
+const (
+	gistEndpoint = "https://api.github.com/gists"
+	shareTimeout = 30 * time.Second
+)
+
+// Uploads the session as markdown and returns the link to it.
+// Gists are created as secret gists, they are not listed but anyone with the link can read them
+func ShareSession(ctx context.Context, session Session, service string, endpoint string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, shareTimeout)
+	defer cancel()
+
+	content := generateMarkdownContent(session)
+	filename := sanitizeFilename(session.SessionName) + ".md"
+
+	switch service {
+	case util.GistShareService:
+		return uploadGist(ctx, session.SessionName, filename, content)
+	case util.PasteShareService, "":
+		if endpoint == "" {
+			endpoint = util.DefaultPasteEndpoint
+		}
+		return uploadPaste(ctx, endpoint, filename, content)
+	}
+
+	return "", fmt.Errorf("unknown share service: %s", service)
+}
+
+type gistFile struct {
+	Content string `json:"content"`
+}
+
+type gistRequest struct {
+	Description string              `json:"description"`
+	Public      bool                `json:"public"`
+	Files       map[string]gistFile `json:"files"`
+}
+
+type gistResponse struct {
+	HtmlUrl string `json:"html_url"`
+}
+
+func uploadGist(ctx context.Context, description, filename, content string) (string, error) {
+	if err := util.CheckHostAllowed(gistEndpoint); err != nil {
+		return "", err
+	}
+
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		return "", errors.New("GITHUB_TOKEN is not set, it is required to create gists")
+	}
+
+	body, err := json.Marshal(gistRequest{
+		Description: description,
+		Public:      false,
+		Files:       map[string]gistFile{filename: {Content: content}},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, gistEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to create gist: %s %s", resp.Status, string(respBody))
+	}
+
+	var gist gistResponse
+	err = json.NewDecoder(resp.Body).Decode(&gist)
+	if err != nil {
+		return "", err
+	}
+
+	return gist.HtmlUrl, nil
+}
+
+// Works with 0x0.st and compatible services: the file is sent as multipart form, the link is returned as plain text
+func uploadPaste(ctx context.Context, endpoint, filename, content string) (string, error) {
+	if err := util.CheckHostAllowed(endpoint); err != nil {
+		return "", err
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = part.Write([]byte(content))
+	if err != nil {
+		return "", err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("User-Agent", "nekot")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to upload session: %s %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	return strings.TrimSpace(string(respBody)), nil
+}
@@ -0,0 +1,69 @@
+package sessions
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/BalanceBalls/nekot/clients"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const maxTitleLength = 100
+
+const titleInstruction = "Suggest a short title for our conversation, no longer than a few words. " +
+	"Reply with the title only, without quotes or any other text."
+
+// Asks the model for a title of the current session. Nothing is suggested for an empty session
+func (m Orchestrator) SuggestSessionTitle() tea.Cmd {
+	if len(m.ArrayOfMessages) == 0 {
+		return nil
+	}
+
+	if err := util.CheckHostAllowed(clients.GetProviderUrl(m.config)); err != nil {
+		return nil
+	}
+
+	messages := append(slices.Clone(m.applyPromptMiddleware(m.ArrayOfMessages)), util.LocalStoreMessage{
+		Role:    "user",
+		Content: titleInstruction,
+	})
+	settings := m.getRequestSettings()
+	settings.WebSearchEnabled = false
+	settings.ReasoningBudget = nil
+
+	sessionId := m.CurrentSessionID
+	client := m.InferenceClient
+	ctx := m.mainCtx
+	return func() tea.Msg {
+		answer, err := requestFullCompletion(ctx, client, messages, settings, followUpsTimeout)
+		if err != nil {
+			util.Slog.Warn("failed to get a session title suggestion", "error", err.Error())
+			return nil
+		}
+
+		title := parseTitle(answer)
+		if title == "" {
+			return nil
+		}
+		return QuickChatTitleSuggested{SessionId: sessionId, Title: title}
+	}
+}
+
+// First line of the answer without the quotes and emphasis models wrap titles in
+func parseTitle(answer string) string {
+	for line := range strings.SplitSeq(answer, "\n") {
+		line = strings.TrimSpace(listMarkerRegex.ReplaceAllString(line, ""))
+		line = strings.TrimSpace(strings.Trim(line, "*\"'`#"))
+		if line == "" {
+			continue
+		}
+
+		runes := []rune(line)
+		if len(runes) > maxTitleLength {
+			line = strings.TrimSpace(string(runes[:maxTitleLength]))
+		}
+		return line
+	}
+	return ""
+}
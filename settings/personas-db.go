@@ -0,0 +1,120 @@
+package settings
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/BalanceBalls/nekot/util"
+)
+
+type PersonasService struct {
+	DB *sql.DB
+}
+
+func NewPersonasService(db *sql.DB) *PersonasService {
+	return &PersonasService{
+		DB: db,
+	}
+}
+
+func (ps *PersonasService) GetPersona(id int) (util.Persona, error) {
+	persona := util.Persona{}
+	row := ps.DB.QueryRow(
+		`select
+			persona_id,
+			persona_name,
+			persona_color,
+			persona_model,
+			persona_system_prompt
+		from personas where persona_id=$1`,
+		id,
+	)
+	err := row.Scan(
+		&persona.ID,
+		&persona.Name,
+		&persona.Color,
+		&persona.Model,
+		&persona.SystemPrompt,
+	)
+
+	if err != nil {
+		return persona, err
+	}
+
+	return persona, nil
+}
+
+func (ps *PersonasService) GetPersonasList() ([]util.Persona, error) {
+	rows, err := ps.DB.Query(
+		`select
+			persona_id,
+			persona_name,
+			persona_color,
+			persona_model,
+			persona_system_prompt
+		from personas
+		order by persona_id`,
+	)
+
+	if err != nil {
+		return []util.Persona{}, err
+	}
+	defer rows.Close()
+
+	personas := []util.Persona{}
+	for rows.Next() {
+		persona := util.Persona{}
+		rows.Scan(
+			&persona.ID,
+			&persona.Name,
+			&persona.Color,
+			&persona.Model,
+			&persona.SystemPrompt,
+		)
+		personas = append(personas, persona)
+	}
+
+	return personas, nil
+}
+
+func (ps *PersonasService) SavePersona(persona util.Persona) (int, error) {
+	insert := `
+		INSERT INTO personas
+			(persona_name, persona_color, persona_model, persona_system_prompt)
+		VALUES
+			($1, $2, $3, $4)
+	`
+
+	result, err := ps.DB.Exec(insert, persona.Name, persona.Color, persona.Model, persona.SystemPrompt)
+
+	errId := -999999
+	if err != nil {
+		return errId, err
+	}
+	newId, err := result.LastInsertId()
+	if err != nil {
+		return errId, fmt.Errorf("Failed to get last inserted id")
+	}
+	return int(newId), nil
+}
+
+// Removes the persona and detaches it from every session it was assigned to
+func (ps *PersonasService) RemovePersona(id int) error {
+	tx, err := ps.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`update sessions set persona_id = NULL where persona_id=$1;`, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`delete from personas where persona_id=$1;`, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
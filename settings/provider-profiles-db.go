@@ -0,0 +1,119 @@
+package settings
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/BalanceBalls/nekot/util"
+)
+
+type ProviderProfile struct {
+	Provider    int
+	MaxTokens   int
+	Frequency   *float32
+	TopP        *float32
+	Temperature *float32
+}
+
+func (ss *SettingsService) GetProviderProfile(provider int) (ProviderProfile, error) {
+	profile := ProviderProfile{}
+	row := ss.DB.QueryRow(
+		`select
+			provider,
+			max_tokens,
+			frequency,
+			top_p,
+			temperature
+		from provider_profiles where provider=$1`,
+		provider,
+	)
+	err := row.Scan(
+		&profile.Provider,
+		&profile.MaxTokens,
+		&profile.Frequency,
+		&profile.TopP,
+		&profile.Temperature,
+	)
+
+	if err != nil {
+		return profile, err
+	}
+
+	return profile, nil
+}
+
+func (ss *SettingsService) SaveProviderProfile(profile ProviderProfile) error {
+	upsert := `
+		INSERT INTO provider_profiles
+			(provider, max_tokens, frequency, top_p, temperature)
+		VALUES
+			($1, $2, $3, $4, $5)
+		ON CONFLICT(provider) DO UPDATE SET
+			max_tokens=$2,
+			frequency=$3,
+			top_p=$4,
+			temperature=$5;
+	`
+
+	_, err := ss.DB.Exec(
+		upsert,
+		profile.Provider,
+		profile.MaxTokens,
+		profile.Frequency,
+		profile.TopP,
+		profile.Temperature,
+	)
+	return err
+}
+
+// Stores sampling values of the provider the preset was used with,
+// then restores the values previously used with the current provider.
+// Values not supported by the current provider are dropped
+func (ss *SettingsService) ApplyProviderProfile(
+	current util.Settings,
+	provider util.ApiProvider,
+) (util.Settings, error) {
+	providerId := int(provider)
+	if current.Provider != nil && *current.Provider == providerId {
+		return current, nil
+	}
+
+	if current.Provider != nil {
+		err := ss.SaveProviderProfile(ProviderProfile{
+			Provider:    *current.Provider,
+			MaxTokens:   current.MaxTokens,
+			Frequency:   current.Frequency,
+			TopP:        current.TopP,
+			Temperature: current.Temperature,
+		})
+		if err != nil {
+			return current, err
+		}
+
+		profile, err := ss.GetProviderProfile(providerId)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return current, err
+		}
+
+		if err == nil {
+			util.Slog.Debug("applying provider profile", "provider", providerId)
+			current.MaxTokens = profile.MaxTokens
+			current.Frequency = profile.Frequency
+			current.TopP = profile.TopP
+			current.Temperature = profile.Temperature
+		}
+	}
+
+	if !util.IsSamplingParamSupported(provider, util.FrequencyParam) {
+		current.Frequency = nil
+	}
+	if !util.IsSamplingParamSupported(provider, util.TopPParam) {
+		current.TopP = nil
+	}
+	if !util.IsSamplingParamSupported(provider, util.TemperatureParam) {
+		current.Temperature = nil
+	}
+
+	current.Provider = &providerId
+	return ss.UpdateSettings(current)
+}
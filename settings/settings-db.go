@@ -48,7 +48,10 @@ func (ss *SettingsService) GetPreset(id int) (util.Settings, error) {
 			temperature,
 			preset_name,
 			web_search_enabled,
-			hide_reasoning
+			hide_reasoning,
+			system_prompt_id,
+			provider,
+			reasoning_budget
 		from settings where settings_id=$1`,
 		id,
 	)
@@ -63,6 +66,9 @@ func (ss *SettingsService) GetPreset(id int) (util.Settings, error) {
 		&settings.PresetName,
 		&settings.WebSearchEnabled,
 		&settings.HideReasoning,
+		&settings.SystemPromptId,
+		&settings.Provider,
+		&settings.ReasoningBudget,
 	)
 
 	if err != nil {
@@ -85,7 +91,10 @@ func (ss *SettingsService) GetSettings(ctx context.Context, id int, cfg config.C
 			temperature,
 			preset_name,
 			web_search_enabled,
-			hide_reasoning
+			hide_reasoning,
+			system_prompt_id,
+			provider,
+			reasoning_budget
 		from settings where settings_id=$1`,
 		id,
 	)
@@ -100,6 +109,9 @@ func (ss *SettingsService) GetSettings(ctx context.Context, id int, cfg config.C
 		&settings.PresetName,
 		&settings.WebSearchEnabled,
 		&settings.HideReasoning,
+		&settings.SystemPromptId,
+		&settings.Provider,
+		&settings.ReasoningBudget,
 	)
 
 	availableModels, modelsError := ss.GetProviderModels(ctx, cfg.Provider, cfg.ProviderBaseUrl)
@@ -154,6 +166,15 @@ func (ss *SettingsService) GetSettings(ctx context.Context, id int, cfg config.C
 		}
 	}
 
+	provider := util.GetOpenAiInferenceProvider(cfg.Provider, cfg.ProviderBaseUrl)
+	settings, err = ss.ApplyProviderProfile(settings, provider)
+	if err != nil {
+		return UpdateSettingsEvent{
+			Settings: settings,
+			Err:      err,
+		}
+	}
+
 	return UpdateSettingsEvent{
 		Settings: settings,
 		Err:      nil,
@@ -267,7 +288,10 @@ func (ss *SettingsService) GetPresetsList() ([]util.Settings, error) {
 			temperature,
 			preset_name,
 			web_search_enabled,
-			hide_reasoning
+			hide_reasoning,
+			system_prompt_id,
+			provider,
+			reasoning_budget
 		from settings`,
 	)
 
@@ -288,6 +312,9 @@ func (ss *SettingsService) GetPresetsList() ([]util.Settings, error) {
 			&preset.PresetName,
 			&preset.WebSearchEnabled,
 			&preset.HideReasoning,
+			&preset.SystemPromptId,
+			&preset.Provider,
+			&preset.ReasoningBudget,
 		)
 		presets = append(presets, preset)
 	}
@@ -308,6 +335,9 @@ func (ss *SettingsService) ResetToDefault(current util.Settings) (util.Settings,
 		PresetName:       current.PresetName,
 		WebSearchEnabled: false,
 		HideReasoning:    false,
+		SystemPromptId:   current.SystemPromptId,
+		Provider:         current.Provider,
+		ReasoningBudget:  nil,
 	}
 
 	_, err := ss.UpdateSettings(defaultSettings)
@@ -322,9 +352,9 @@ func (ss *SettingsService) ResetToDefault(current util.Settings) (util.Settings,
 func (ss *SettingsService) SavePreset(newSettings util.Settings) (int, error) {
 	upsert := `
 		INSERT INTO settings
-			(settings_model, settings_max_tokens, settings_frequency, temperature, top_p, system_msg, preset_name, web_search_enabled, hide_reasoning)
+			(settings_model, settings_max_tokens, settings_frequency, temperature, top_p, system_msg, preset_name, web_search_enabled, hide_reasoning, system_prompt_id, provider, reasoning_budget)
 		VALUES
-			($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING settings_id
 	`
 
@@ -339,6 +369,9 @@ func (ss *SettingsService) SavePreset(newSettings util.Settings) (int, error) {
 		newSettings.PresetName,
 		newSettings.WebSearchEnabled,
 		newSettings.HideReasoning,
+		newSettings.SystemPromptId,
+		newSettings.Provider,
+		newSettings.ReasoningBudget,
 	)
 
 	errId := -999999
@@ -363,16 +396,31 @@ func (ss *SettingsService) RemovePreset(id int) error {
 		return errors.New("Cannot remove the only settings preset")
 	}
 
-	_, err = ss.DB.Exec(`delete from settings where settings_id=$1;`, id)
-	return err
+	tx, err := ss.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`delete from system_prompt_versions where settings_id=$1;`, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`delete from settings where settings_id=$1;`, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (ss *SettingsService) UpdateSettings(newSettings util.Settings) (util.Settings, error) {
 	upsert := `
 		INSERT INTO settings
-			(settings_id, settings_model, settings_max_tokens, settings_frequency, temperature, top_p, system_msg, preset_name, web_search_enabled, hide_reasoning)
+			(settings_id, settings_model, settings_max_tokens, settings_frequency, temperature, top_p, system_msg, preset_name, web_search_enabled, hide_reasoning, system_prompt_id, provider, reasoning_budget)
 		VALUES
-			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		ON CONFLICT(settings_id) DO UPDATE SET
 			settings_model=$2,
 			settings_max_tokens=$3,
@@ -382,7 +430,10 @@ func (ss *SettingsService) UpdateSettings(newSettings util.Settings) (util.Setti
 			system_msg=$7,
 			preset_name=$8,
 			web_search_enabled=$9,
-			hide_reasoning=$10;
+			hide_reasoning=$10,
+			system_prompt_id=$11,
+			provider=$12,
+			reasoning_budget=$13;
 	`
 
 	_, err := ss.DB.Exec(
@@ -397,6 +448,9 @@ func (ss *SettingsService) UpdateSettings(newSettings util.Settings) (util.Setti
 		newSettings.PresetName,
 		newSettings.WebSearchEnabled,
 		newSettings.HideReasoning,
+		newSettings.SystemPromptId,
+		newSettings.Provider,
+		newSettings.ReasoningBudget,
 	)
 	if err != nil {
 		return newSettings, err
@@ -1,6 +1,10 @@
 package settings
 
 import (
+	"context"
+
+	"github.com/BalanceBalls/nekot/clients"
+	"github.com/BalanceBalls/nekot/config"
 	"github.com/BalanceBalls/nekot/util"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -15,3 +19,13 @@ func MakeSettingsUpdateMsg(s util.Settings, err error) tea.Cmd {
 		return UpdateSettingsEvent{Settings: s, Err: err}
 	}
 }
+
+type ConnectionChecked struct {
+	Result clients.HealthCheckResult
+}
+
+func CheckConnection(ctx context.Context, cfg config.Config, model string) tea.Cmd {
+	return func() tea.Msg {
+		return ConnectionChecked{Result: clients.CheckConnection(ctx, cfg, model)}
+	}
+}
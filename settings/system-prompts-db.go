@@ -0,0 +1,178 @@
+package settings
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/BalanceBalls/nekot/util"
+)
+
+// Versions of a preset system prompt kept for rollback
+const maxSystemPromptVersions = 20
+
+type SystemPromptsService struct {
+	DB *sql.DB
+}
+
+func NewSystemPromptsService(db *sql.DB) *SystemPromptsService {
+	return &SystemPromptsService{
+		DB: db,
+	}
+}
+
+func (ps *SystemPromptsService) GetSystemPrompt(id int) (util.SystemPrompt, error) {
+	prompt := util.SystemPrompt{}
+	row := ps.DB.QueryRow(
+		`select
+			system_prompt_id,
+			system_prompt_name,
+			system_prompt_content
+		from system_prompts where system_prompt_id=$1`,
+		id,
+	)
+	err := row.Scan(
+		&prompt.ID,
+		&prompt.Name,
+		&prompt.Content,
+	)
+
+	if err != nil {
+		return prompt, err
+	}
+
+	return prompt, nil
+}
+
+func (ps *SystemPromptsService) GetSystemPromptsList() ([]util.SystemPrompt, error) {
+	rows, err := ps.DB.Query(
+		`select
+			system_prompt_id,
+			system_prompt_name,
+			system_prompt_content
+		from system_prompts
+		order by system_prompt_id`,
+	)
+
+	if err != nil {
+		return []util.SystemPrompt{}, err
+	}
+	defer rows.Close()
+
+	prompts := []util.SystemPrompt{}
+	for rows.Next() {
+		prompt := util.SystemPrompt{}
+		rows.Scan(
+			&prompt.ID,
+			&prompt.Name,
+			&prompt.Content,
+		)
+		prompts = append(prompts, prompt)
+	}
+
+	return prompts, nil
+}
+
+func (ps *SystemPromptsService) SaveSystemPrompt(name string, content string) (int, error) {
+	insert := `
+		INSERT INTO system_prompts
+			(system_prompt_name, system_prompt_content)
+		VALUES
+			($1, $2)
+	`
+
+	result, err := ps.DB.Exec(insert, name, content)
+
+	errId := -999999
+	if err != nil {
+		return errId, err
+	}
+	newId, err := result.LastInsertId()
+	if err != nil {
+		return errId, fmt.Errorf("Failed to get last inserted id")
+	}
+	return int(newId), nil
+}
+
+// Removes the prompt from the library and detaches it
+// from every preset and session it was assigned to
+func (ps *SystemPromptsService) RemoveSystemPrompt(id int) error {
+	tx, err := ps.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`update settings set system_prompt_id = NULL where system_prompt_id=$1;`, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`update sessions set system_prompt_id = NULL where system_prompt_id=$1;`, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`delete from system_prompts where system_prompt_id=$1;`, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Replaced system prompts of a preset, so an overwritten prompt can be rolled back.
+// A prompt equal to the latest version is not stored again and only the last versions are kept
+func (ps *SystemPromptsService) AddSystemPromptVersion(settingsId int, content string) error {
+	_, err := ps.DB.Exec(`
+		INSERT INTO system_prompt_versions (settings_id, version_content)
+		SELECT $1, $2
+		WHERE $2 IS NOT (
+			SELECT version_content FROM system_prompt_versions
+			WHERE settings_id = $1
+			ORDER BY version_id DESC
+			LIMIT 1
+		)
+	`, settingsId, content)
+	if err != nil {
+		return err
+	}
+
+	_, err = ps.DB.Exec(`
+		DELETE FROM system_prompt_versions
+		WHERE settings_id = $1 AND version_id NOT IN (
+			SELECT version_id FROM system_prompt_versions
+			WHERE settings_id = $1
+			ORDER BY version_id DESC
+			LIMIT $2
+		)
+	`, settingsId, maxSystemPromptVersions)
+	return err
+}
+
+// Versions of the preset system prompt, the latest first
+func (ps *SystemPromptsService) GetSystemPromptVersions(settingsId int) ([]util.SystemPromptVersion, error) {
+	rows, err := ps.DB.Query(
+		`select
+			version_id,
+			version_content,
+			version_created_at
+		from system_prompt_versions
+		where settings_id=$1
+		order by version_id desc`,
+		settingsId,
+	)
+	if err != nil {
+		return []util.SystemPromptVersion{}, err
+	}
+	defer rows.Close()
+
+	versions := []util.SystemPromptVersion{}
+	for rows.Next() {
+		version := util.SystemPromptVersion{}
+		if err := rows.Scan(&version.ID, &version.Content, &version.CreatedAt); err != nil {
+			return []util.SystemPromptVersion{}, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
@@ -0,0 +1,235 @@
+package updater
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/BalanceBalls/nekot/util"
+)
+
+const binaryName = "nekot"
+
+// Downloads the latest release for the current platform and replaces the running binary.
+// Returns the installed version, or an empty string if the current version is the latest one
+func SelfUpdate(ctx context.Context) (string, error) {
+	release, err := GetLatestRelease(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if currentVersion != devVersion && !util.IsNewerVersion(currentVersion, release.TagName) {
+		return "", nil
+	}
+
+	archiveName := getArchiveName(release.TagName)
+	archiveUrl, ok := findAsset(release, archiveName)
+	if !ok {
+		return "", fmt.Errorf("release %s has no build for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+
+	archive, err := download(ctx, archiveUrl)
+	if err != nil {
+		return "", err
+	}
+
+	err = verifyChecksum(ctx, release, archiveName, archive)
+	if err != nil {
+		return "", err
+	}
+
+	binary, err := extractBinary(archiveName, archive)
+	if err != nil {
+		return "", err
+	}
+
+	err = replaceExecutable(binary)
+	if err != nil {
+		return "", err
+	}
+
+	return release.TagName, nil
+}
+
+// Archives are named by goreleaser: nekot_1.2.3_linux_amd64.tar.gz
+func getArchiveName(tag string) string {
+	arch := runtime.GOARCH
+	if arch == "arm" {
+		arch = "armv6"
+	}
+
+	ext := "tar.gz"
+	if runtime.GOOS == "windows" {
+		ext = "zip"
+	}
+
+	version := strings.TrimPrefix(tag, "v")
+	return fmt.Sprintf("%s_%s_%s_%s.%s", binaryName, version, runtime.GOOS, arch, ext)
+}
+
+func getChecksumsName() string {
+	if runtime.GOOS == "windows" {
+		return "checksums-win.txt"
+	}
+	return "checksums.txt"
+}
+
+func findAsset(release Release, name string) (string, bool) {
+	for _, asset := range release.Assets {
+		if asset.Name == name {
+			return asset.BrowserDownloadUrl, true
+		}
+	}
+	return "", false
+}
+
+func download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func verifyChecksum(ctx context.Context, release Release, archiveName string, archive []byte) error {
+	checksumsUrl, ok := findAsset(release, getChecksumsName())
+	if !ok {
+		return errors.New("release has no checksums file, refusing to install an unverified binary")
+	}
+
+	checksums, err := download(ctx, checksumsUrl)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(archive)
+	actual := hex.EncodeToString(hash[:])
+
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || fields[1] != archiveName {
+			continue
+		}
+
+		if fields[0] != actual {
+			return fmt.Errorf("checksum mismatch for %s", archiveName)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("no checksum found for %s", archiveName)
+}
+
+func extractBinary(archiveName string, archive []byte) ([]byte, error) {
+	name := binaryName
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
+	if strings.HasSuffix(archiveName, ".zip") {
+		reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range reader.File {
+			if filepath.Base(file.Name) != name {
+				continue
+			}
+
+			content, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer content.Close()
+			return io.ReadAll(content)
+		}
+		return nil, fmt.Errorf("%s not found in %s", name, archiveName)
+	}
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == name {
+			return io.ReadAll(tarReader)
+		}
+	}
+
+	return nil, fmt.Errorf("%s not found in %s", name, archiveName)
+}
+
+// The new binary is written next to the current one and renamed over it.
+// Windows does not allow replacing a running executable, so it is moved aside first
+func replaceExecutable(binary []byte) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	executable, err = filepath.EvalSymlinks(executable)
+	if err != nil {
+		return err
+	}
+
+	newPath := executable + ".new"
+	err = os.WriteFile(newPath, binary, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to write the new binary, check permissions of %s: %w", filepath.Dir(executable), err)
+	}
+
+	if runtime.GOOS == "windows" {
+		oldPath := executable + ".old"
+		os.Remove(oldPath)
+		err = os.Rename(executable, oldPath)
+		if err != nil {
+			os.Remove(newPath)
+			return err
+		}
+	}
+
+	err = os.Rename(newPath, executable)
+	if err != nil {
+		os.Remove(newPath)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	latestReleaseUrl   = "https://api.github.com/repos/BalanceBalls/nekot/releases/latest"
+	releaseCheckTimout = 10 * time.Second
+	devVersion         = "dev"
+)
+
+// Set from main, release builds get the version from ldflags
+var currentVersion = devVersion
+
+func SetCurrentVersion(version string) {
+	if version != "" {
+		currentVersion = version
+	}
+}
+
+func GetCurrentVersion() string {
+	return currentVersion
+}
+
+type Release struct {
+	TagName string         `json:"tag_name"`
+	HtmlUrl string         `json:"html_url"`
+	Assets  []ReleaseAsset `json:"assets"`
+}
+
+type ReleaseAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadUrl string `json:"browser_download_url"`
+}
+
+func GetLatestRelease(ctx context.Context) (Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, latestReleaseUrl, nil)
+	if err != nil {
+		return Release{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Release{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("failed to get the latest release: %s", resp.Status)
+	}
+
+	var release Release
+	err = json.NewDecoder(resp.Body).Decode(&release)
+	return release, err
+}
+
+// Checks GitHub releases in the background. Dev builds are never checked.
+// Failures are only logged, the check is not important enough to bother the user
+func CheckForUpdates(ctx context.Context) tea.Cmd {
+	if currentVersion == devVersion {
+		return nil
+	}
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(ctx, releaseCheckTimout)
+		defer cancel()
+
+		release, err := GetLatestRelease(ctx)
+		if err != nil {
+			util.Slog.Warn("failed to check for updates", "error", err.Error())
+			return nil
+		}
+
+		if !util.IsNewerVersion(currentVersion, release.TagName) {
+			util.Slog.Debug("no updates available", "current", currentVersion, "latest", release.TagName)
+			return nil
+		}
+
+		return util.UpdateAvailable{Version: release.TagName, Url: release.HtmlUrl}
+	}
+}
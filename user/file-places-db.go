@@ -0,0 +1,90 @@
+package user
+
+import "database/sql"
+
+// Places shown at the top of the file picker
+const (
+	RecentFilePlace     = "recent"
+	FavoriteDirPlace    = "favorite"
+	maxRecentFilePlaces = 5
+)
+
+type FilePlacesService struct {
+	DB *sql.DB
+}
+
+func NewFilePlacesService(db *sql.DB) *FilePlacesService {
+	return &FilePlacesService{
+		DB: db,
+	}
+}
+
+// Paths of the kind, most recently used first
+func (fs *FilePlacesService) GetPlaces(kind string) ([]string, error) {
+	rows, err := fs.DB.Query(
+		`SELECT place_path
+		FROM file_places
+		WHERE place_kind = $1
+		ORDER BY place_used_at DESC, rowid DESC`,
+		kind,
+	)
+	if err != nil {
+		return []string{}, err
+	}
+	defer rows.Close()
+
+	paths := []string{}
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return []string{}, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
+
+// Only the last few attached files are kept. Time is stored with milliseconds,
+// so files attached one after another keep their order
+func (fs *FilePlacesService) AddRecentFile(path string) error {
+	_, err := fs.DB.Exec(`
+		INSERT INTO file_places (place_path, place_kind, place_used_at)
+		VALUES ($1, $2, strftime('%Y-%m-%d %H:%M:%f', 'now'))
+		ON CONFLICT (place_path, place_kind) DO UPDATE SET place_used_at = excluded.place_used_at
+	`, path, RecentFilePlace)
+	if err != nil {
+		return err
+	}
+
+	_, err = fs.DB.Exec(`
+		DELETE FROM file_places
+		WHERE place_kind = $1 AND place_path NOT IN (
+			SELECT place_path FROM file_places
+			WHERE place_kind = $1
+			ORDER BY place_used_at DESC, rowid DESC
+			LIMIT $2
+		)
+	`, RecentFilePlace, maxRecentFilePlaces)
+	return err
+}
+
+// Adds the directory to favorites or removes it, reports whether it is a favorite now
+func (fs *FilePlacesService) ToggleFavoriteDir(path string) (bool, error) {
+	result, err := fs.DB.Exec(`
+		DELETE FROM file_places
+		WHERE place_path = $1 AND place_kind = $2
+	`, path, FavoriteDirPlace)
+	if err != nil {
+		return false, err
+	}
+
+	if removed, _ := result.RowsAffected(); removed > 0 {
+		return false, nil
+	}
+
+	_, err = fs.DB.Exec(`
+		INSERT INTO file_places (place_path, place_kind)
+		VALUES ($1, $2)
+	`, path, FavoriteDirPlace)
+	return err == nil, err
+}
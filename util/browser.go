@@ -0,0 +1,82 @@
+package util
+
+import (
+	"net/url"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/BalanceBalls/nekot/i18n"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var urlRegex = regexp.MustCompile("https?://[^\\s<>()\\[\\]{}\"'`]+")
+
+// Links of a text in the order of appearance, without duplicates.
+// Trailing punctuation is not a part of a link, e.g. at the end of a sentence
+func ExtractUrls(text string) []string {
+	urls := []string{}
+	for _, match := range urlRegex.FindAllString(text, -1) {
+		link := strings.TrimRight(match, ".,;:!?*_~")
+		if _, err := url.ParseRequestURI(link); err == nil {
+			urls = append(urls, link)
+		}
+	}
+	return RemoveDuplicates(urls)
+}
+
+// Links of the last response: the answer itself and the sources of web searches made for it
+func GetLastResponseUrls(messages []LocalStoreMessage) []string {
+	urls := []string{}
+	for i := len(messages) - 1; i >= 0 && messages[i].Role != "user"; i-- {
+		responseUrls := ExtractUrls(messages[i].Content)
+		for _, source := range GetToolCallSources(messages[i].ToolCalls) {
+			responseUrls = append(responseUrls, source.Url)
+		}
+		urls = append(responseUrls, urls...)
+	}
+	return RemoveDuplicates(urls)
+}
+
+// Opens the link with the default browser of the system.
+// Only web links are opened, anything else could run a local program
+func OpenUrl(link string) tea.Cmd {
+	return func() tea.Msg {
+		parsedUrl, err := url.Parse(link)
+		if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") {
+			return ToastMsg{Text: i18n.Tf("notification.linkOpenFailed", link), Severity: ErrorSeverity}
+		}
+
+		if err := openWithSystem(link); err != nil {
+			Slog.Error("failed to open a link", "link", link, "error", err.Error())
+			return ToastMsg{Text: i18n.Tf("notification.linkOpenFailed", link), Severity: ErrorSeverity}
+		}
+		return nil
+	}
+}
+
+// Opens a file created by the app, e.g. an exported image, with the default program for its type
+func OpenPath(path string) error {
+	return openWithSystem(path)
+}
+
+func openWithSystem(target string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", target)
+	case "windows":
+		// the empty argument is the window title, ampersands would split the command
+		cmd = exec.Command("cmd", "/c", "start", "", strings.ReplaceAll(target, "&", "^&"))
+	default:
+		cmd = exec.Command("xdg-open", target)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	go cmd.Wait()
+	return nil
+}
@@ -0,0 +1,181 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ModelCapabilities struct {
+	Vision     bool
+	Tools      bool
+	Reasoning  bool
+	MaxContext int
+}
+
+// Used when capabilities of a model can not be inferred.
+// Nothing is restricted in that case, so the API decides what is allowed
+var UnknownModelCapabilities = ModelCapabilities{
+	Vision:    true,
+	Tools:     true,
+	Reasoning: true,
+}
+
+var (
+	openAiVisionPrefixes    = []string{"gpt-4o", "gpt-4.1", "gpt-4-turbo", "gpt-5", "o1", "o3", "o4"}
+	openAiNoToolsPrefixes   = []string{"o1-mini", "o1-preview", "gpt-4o-search", "gpt-5-chat"}
+	mistralVisionKeywords   = []string{"pixtral", "medium", "small"}
+	localVisionKeywords     = []string{"vision", "llava", "vl", "gemma3", "minicpm-v", "moondream"}
+	localTextOnlyKeywords   = []string{"codellama", "deepseek-coder", "deepseek-r1", "qwq", "starcoder", "tinyllama", "embed"}
+	localReasoningKeywords  = []string{"r1", "qwq", "qwen3", "think", "reason", "gpt-oss", "magistral"}
+	geminiReasoningKeywords = []string{"2.5", "thinking"}
+	claudeReasoningKeywords = []string{"3-7", "sonnet-4", "opus-4", "haiku-4"}
+	xAiVisionKeywords       = []string{"vision", "grok-4"}
+	perplexityReasoning     = []string{"reasoning", "deep-research"}
+)
+
+var openAiContextWindows = []struct {
+	prefix string
+	size   int
+}{
+	{"gpt-4.1", 1_047_576},
+	{"gpt-5", 400_000},
+	{"gpt-4o", 128_000},
+	{"gpt-4-turbo", 128_000},
+	{"gpt-4", 8_192},
+	{"gpt-3.5", 16_385},
+	{"o1-mini", 128_000},
+	{"o1", 200_000},
+	{"o3", 200_000},
+	{"o4", 200_000},
+}
+
+var xAiContextWindows = []struct {
+	prefix string
+	size   int
+}{
+	{"grok-4-fast", 2_000_000},
+	{"grok-4", 256_000},
+	{"grok-code", 256_000},
+	{"grok-3", 131_072},
+}
+
+func GetModelCapabilities(provider ApiProvider, model string) ModelCapabilities {
+	name := strings.ToLower(model)
+
+	switch provider {
+	case OpenAi:
+		capabilities := ModelCapabilities{
+			Vision:    hasAnyPrefix(name, openAiVisionPrefixes),
+			Tools:     !hasAnyPrefix(name, openAiNoToolsPrefixes),
+			Reasoning: isOpenAiReasoningModel(name) || isOpenAiGpt5Model(name),
+		}
+		for _, window := range openAiContextWindows {
+			if strings.HasPrefix(name, window.prefix) {
+				capabilities.MaxContext = window.size
+				break
+			}
+		}
+		return capabilities
+
+	case Gemini:
+		return ModelCapabilities{
+			Vision:     true,
+			Tools:      true,
+			Reasoning:  containsAny(name, geminiReasoningKeywords),
+			MaxContext: 1_048_576,
+		}
+
+	case Vertex:
+		if IsAnthropicModel(name) {
+			return getClaudeCapabilities(name)
+		}
+		return GetModelCapabilities(Gemini, model)
+
+	case Bedrock:
+		if IsAnthropicModel(name) {
+			return getClaudeCapabilities(name)
+		}
+		return UnknownModelCapabilities
+
+	case Mistral:
+		return ModelCapabilities{
+			Vision:     containsAny(name, mistralVisionKeywords),
+			Tools:      true,
+			Reasoning:  strings.Contains(name, "magistral"),
+			MaxContext: 128_000,
+		}
+
+	case XAi:
+		capabilities := ModelCapabilities{
+			Vision:     containsAny(name, xAiVisionKeywords),
+			Tools:      true,
+			Reasoning:  isXAiReasoningModel(name),
+			MaxContext: 131_072,
+		}
+		for _, window := range xAiContextWindows {
+			if strings.HasPrefix(name, window.prefix) {
+				capabilities.MaxContext = window.size
+				break
+			}
+		}
+		return capabilities
+
+	// Sonar models search the web on their own and have no tool calling
+	case Perplexity:
+		capabilities := ModelCapabilities{
+			Vision:     true,
+			Reasoning:  containsAny(name, perplexityReasoning),
+			MaxContext: 128_000,
+		}
+		if name == "sonar-pro" {
+			capabilities.MaxContext = 200_000
+		}
+		return capabilities
+
+	// Unknown local models may still accept images, only known text-only families are restricted
+	case Local:
+		capabilities := UnknownModelCapabilities
+		capabilities.Vision = containsAny(name, localVisionKeywords) || !containsAny(name, localTextOnlyKeywords)
+		capabilities.Reasoning = containsAny(name, localReasoningKeywords)
+		return capabilities
+	}
+
+	return UnknownModelCapabilities
+}
+
+func getClaudeCapabilities(name string) ModelCapabilities {
+	return ModelCapabilities{
+		Vision:     true,
+		Tools:      true,
+		Reasoning:  containsAny(name, claudeReasoningKeywords),
+		MaxContext: 200_000,
+	}
+}
+
+func FormatContextSize(size int) string {
+	switch {
+	case size >= 1_000_000:
+		return fmt.Sprintf("%dM", size/1_000_000)
+	case size >= 1_000:
+		return fmt.Sprintf("%dk", size/1_000)
+	}
+	return fmt.Sprint(size)
+}
+
+func hasAnyPrefix(value string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(value string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.Contains(value, keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package util
+
+import (
+	"sync"
+
+	"github.com/atotto/clipboard"
+)
+
+var (
+	clipboardMu  sync.Mutex
+	lastAppCopy  string
+	hasAppCopied bool
+)
+
+// Copies text to the system clipboard and remembers it,
+// so the clipboard watcher doesn't offer to insert the app's own copies
+func CopyToClipboard(text string) error {
+	clipboardMu.Lock()
+	defer clipboardMu.Unlock()
+
+	lastAppCopy = text
+	hasAppCopied = true
+	return clipboard.WriteAll(text)
+}
+
+func IsCopiedByApp(text string) bool {
+	clipboardMu.Lock()
+	defer clipboardMu.Unlock()
+
+	return hasAppCopied && lastAppCopy == text
+}
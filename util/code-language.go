@@ -0,0 +1,143 @@
+package util
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var languagesByExtension = map[string]string{
+	".go":    "go",
+	".py":    "python",
+	".rs":    "rust",
+	".js":    "javascript",
+	".mjs":   "javascript",
+	".jsx":   "jsx",
+	".ts":    "typescript",
+	".tsx":   "tsx",
+	".java":  "java",
+	".kt":    "kotlin",
+	".c":     "c",
+	".h":     "c",
+	".cpp":   "cpp",
+	".hpp":   "cpp",
+	".cs":    "csharp",
+	".rb":    "ruby",
+	".php":   "php",
+	".swift": "swift",
+	".lua":   "lua",
+	".sh":    "bash",
+	".bash":  "bash",
+	".zsh":   "zsh",
+	".sql":   "sql",
+	".html":  "html",
+	".css":   "css",
+	".json":  "json",
+	".yaml":  "yaml",
+	".yml":   "yaml",
+	".toml":  "toml",
+	".md":    "markdown",
+	".xml":   "xml",
+}
+
+var shebangLanguages = map[string]string{
+	"python":  "python",
+	"python3": "python",
+	"bash":    "bash",
+	"sh":      "sh",
+	"zsh":     "zsh",
+	"node":    "javascript",
+	"ruby":    "ruby",
+	"perl":    "perl",
+	"php":     "php",
+	"lua":     "lua",
+}
+
+// File names in a header comment, like "// main.go" or "# scripts/build.py"
+var fileNameRegex = regexp.MustCompile(`[\w./-]+\.(\w+)\b`)
+
+// Checked in order, the first matching pattern wins, so more specific languages go first
+var languagePatterns = []struct {
+	lang    string
+	pattern *regexp.Regexp
+}{
+	{"php", regexp.MustCompile(`^<\?php`)},
+	{"html", regexp.MustCompile(`(?i)^<(!doctype html|html|head|body|div|span|p|a|ul|table)\b`)},
+	{"xml", regexp.MustCompile(`^<\?xml`)},
+	{"go", regexp.MustCompile(`(?m)^package \w+$|^func (\(\w+ \*?\w+\) )?\w+\(|:= `)},
+	{"rust", regexp.MustCompile(`(?m)^\s*(pub )?(fn|impl|struct|enum|mod|use) [\w:{]+|let mut `)},
+	{"python", regexp.MustCompile(`(?m)^\s*(def \w+\(.*\):|class \w+(\(.*\))?:|from [\w.]+ import |import \w+$|if __name__ ==)`)},
+	{"cpp", regexp.MustCompile(`(?m)^#include <\w+>$|std::|^template\s*<`)},
+	{"c", regexp.MustCompile(`(?m)^#include [<"][\w/]+\.h[>"]|^int main\(`)},
+	{"csharp", regexp.MustCompile(`(?m)^using System|^namespace [\w.]+|Console\.Write`)},
+	{"java", regexp.MustCompile(`(?m)^import java\.|public (static )?(class|void|interface) |System\.out\.`)},
+	{"typescript", regexp.MustCompile(`(?m)^\s*(export )?(interface|type) \w+|: (string|number|boolean)\b`)},
+	{"javascript", regexp.MustCompile(`(?m)^\s*(const|let|var) \w+ = |function \w*\(|=> |console\.log|require\(`)},
+	{"sql", regexp.MustCompile(`(?i)^\s*(select .+ from|insert into|update \w+ set|delete from|create (table|index|view))\b`)},
+	{"css", regexp.MustCompile(`(?m)^[.#]?[\w-]+( [.#]?[\w-]+)* \{$`)},
+	{"bash", regexp.MustCompile(`(?m)^\s*(\$ |echo |export \w+=|if \[|for \w+ in )`)},
+	{"yaml", regexp.MustCompile(`(?m)^(---$|[\w-]+:( .+)?$)`)},
+}
+
+// Guesses the language of a code snippet for a markdown code fence.
+// The shebang and a file name in the first line are checked before the content,
+// an empty string is returned when nothing matches
+func DetectCodeLanguage(code string) string {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return ""
+	}
+
+	firstLine, _, _ := strings.Cut(code, "\n")
+	if lang := getShebangLanguage(firstLine); lang != "" {
+		return lang
+	}
+
+	if isComment(firstLine) {
+		if match := fileNameRegex.FindString(firstLine); match != "" {
+			if lang, ok := languagesByExtension[strings.ToLower(filepath.Ext(match))]; ok {
+				return lang
+			}
+		}
+	}
+
+	if (strings.HasPrefix(code, "{") || strings.HasPrefix(code, "[")) && json.Valid([]byte(code)) {
+		return "json"
+	}
+
+	for _, language := range languagePatterns {
+		if language.pattern.MatchString(code) {
+			return language.lang
+		}
+	}
+	return ""
+}
+
+func getShebangLanguage(line string) string {
+	if !strings.HasPrefix(line, "#!") {
+		return ""
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+
+	// "#!/usr/bin/env python3" names the interpreter in the second field
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" && len(fields) > 1 {
+		interpreter = fields[1]
+	}
+	return shebangLanguages[interpreter]
+}
+
+func isComment(line string) bool {
+	line = strings.TrimSpace(line)
+	for _, prefix := range []string{"//", "#", "--", "/*", "<!--", ";"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
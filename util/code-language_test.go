@@ -0,0 +1,76 @@
+package util
+
+import "testing"
+
+func TestDetectCodeLanguage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		code     string
+		expected string
+	}{
+		{
+			name:     "Shebang With Env",
+			code:     "#!/usr/bin/env python3\nprint('hi')",
+			expected: "python",
+		},
+		{
+			name:     "Shebang With Path",
+			code:     "#!/bin/bash\nls -la",
+			expected: "bash",
+		},
+		{
+			name:     "File Name In Header Comment",
+			code:     "// src/app.ts\nexport default {}",
+			expected: "typescript",
+		},
+		{
+			name:     "Go",
+			code:     "package main\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}",
+			expected: "go",
+		},
+		{
+			name:     "Python",
+			code:     "def add(a, b):\n    return a + b",
+			expected: "python",
+		},
+		{
+			name:     "Rust",
+			code:     "fn main() {\n    let mut x = 1;\n}",
+			expected: "rust",
+		},
+		{
+			name:     "JavaScript",
+			code:     "const sum = (a, b) => a + b;\nconsole.log(sum(1, 2));",
+			expected: "javascript",
+		},
+		{
+			name:     "JSON",
+			code:     "{\"name\": \"nekot\", \"tags\": [1, 2]}",
+			expected: "json",
+		},
+		{
+			name:     "SQL",
+			code:     "SELECT id, name FROM sessions WHERE id = 1;",
+			expected: "sql",
+		},
+		{
+			name:     "Plain Text",
+			code:     "Just a sentence without any code in it.",
+			expected: "",
+		},
+		{
+			name:     "Empty",
+			code:     "  \n ",
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := DetectCodeLanguage(tc.code)
+			if result != tc.expected {
+				t.Errorf("DetectCodeLanguage(%q) = %q; want %q", tc.code, result, tc.expected)
+			}
+		})
+	}
+}
@@ -3,10 +3,13 @@ package util
 import (
 	"database/sql"
 	"embed"
+	"errors"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
 	"strings"
 
 	"github.com/pressly/goose/v3"
@@ -30,20 +33,115 @@ func GetAppDirName() string {
 	return "." + binaryName
 }
 
-func GetAppDataPath() (string, error) {
-	// Get the user's home directory
+// Overrides the data directory (chat.db, logs). Set from the --data-dir flag
+var dataDirOverride string
+
+func SetDataDirOverride(dir string) {
+	dataDirOverride = dir
+}
+
+const DefaultProfileName = "default"
+
+var profileNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Currently active profile. Empty value means the default profile
+var profile string
+
+func SetProfile(name string) {
+	if name == DefaultProfileName {
+		name = ""
+	}
+	profile = name
+}
+
+func GetProfile() string {
+	if profile == "" {
+		return DefaultProfileName
+	}
+	return profile
+}
+
+func ValidateProfileName(name string) error {
+	if !profileNameRegex.MatchString(name) {
+		return errors.New("profile name can only contain letters, digits, '-' and '_'")
+	}
+	return nil
+}
+
+// Each profile, except for the default one, gets its own subdirectory
+func withProfile(path string) string {
+	if profile == "" {
+		return path
+	}
+	return filepath.Join(path, "profiles", profile)
+}
+
+// Returns the app name used for XDG directories: the dir name without the leading dot
+func getAppName() string {
+	return strings.TrimPrefix(GetAppDirName(), ".")
+}
+
+// Returns the pre-XDG app directory (~/.nekot)
+func GetLegacyAppPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
 	}
 
-	// Define the application-specific part of the path
-	appDirName := GetAppDirName()
+	return filepath.Join(homeDir, GetAppDirName()), nil
+}
+
+// Resolves an XDG base directory for the app.
+// Falls back to the legacy dot-directory on Windows, where XDG is not a thing
+func getXdgAppPath(envVar string, defaultDir ...string) (string, error) {
+	if runtime.GOOS == "windows" {
+		return GetLegacyAppPath()
+	}
+
+	baseDir := os.Getenv(envVar)
+	if baseDir == "" || !filepath.IsAbs(baseDir) {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		baseDir = filepath.Join(append([]string{homeDir}, defaultDir...)...)
+	}
+
+	return filepath.Join(baseDir, getAppName()), nil
+}
+
+// Returns the directory for chat.db and logs: $XDG_DATA_HOME/nekot or the --data-dir override.
+// Non-default profiles are stored in the `profiles/<name>` subdirectory.
+// The directory is created if it does not exist
+func GetAppDataPath() (string, error) {
+	fullPath := dataDirOverride
+	if fullPath == "" {
+		xdgPath, err := getXdgAppPath("XDG_DATA_HOME", ".local", "share")
+		if err != nil {
+			return "", err
+		}
+		fullPath = xdgPath
+	}
+	fullPath = withProfile(fullPath)
+
+	err := os.MkdirAll(fullPath, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	return fullPath, nil
+}
 
-	// Combine them to form the full path
-	fullPath := filepath.Join(homeDir, appDirName)
+// Returns the directory for config.json: $XDG_CONFIG_HOME/nekot.
+// Non-default profiles are stored in the `profiles/<name>` subdirectory.
+// The directory is created if it does not exist
+func GetAppConfigPath() (string, error) {
+	fullPath, err := getXdgAppPath("XDG_CONFIG_HOME", ".config")
+	if err != nil {
+		return "", err
+	}
+	fullPath = withProfile(fullPath)
 
-	// Optionally, create the directory if it doesn't already exist
 	err = os.MkdirAll(fullPath, 0755)
 	if err != nil {
 		return "", err
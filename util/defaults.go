@@ -1,16 +1,27 @@
 package util
 
-import (
-	_ "embed"
-)
-
-//go:embed short-manual.md
-var ManualContent string
-
 const DefaultSettingsId = 0
 const DefaultRequestTimeOutSec = 5
-const ChunkIndexStart = 1
 const WordWrapDelta = 7
+const MinContentMaxWidth = 40
+const DefaultNotificationDurationSec = 2
+const DefaultWebSearchTimeoutSec = 30
+const DefaultWebSearchPages = 10
+const DefaultWebSearchResults = 2
+const MaxNotificationHistory = 100
+
+const GistShareService = "gist"
+const PasteShareService = "0x0"
+const DefaultPasteEndpoint = "https://0x0.st"
+
+const HeuristicFollowUps = "heuristic"
+const ModelFollowUps = "model"
+
+// How tool calls are written to the fine-tuning export
+const ExcludeToolCalls = "exclude"
+const FlattenToolCalls = "flatten"
+
+const SvgDiagramFormat = "svg"
+const PngDiagramFormat = "png"
 
 const ErrorHelp = "\n\n > *Mechanism, I restore thy spirit!\n > Let the God-Machine breathe half-life \n > unto thy veins and render thee functional* "
-const QuickChatWarning = " > *Quick chat is active.* \n > The conversation will not be stored as a session. \n > Use `ctrl+x` to save a quick chat \n <!-------->"
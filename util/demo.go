@@ -0,0 +1,71 @@
+package util
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/BalanceBalls/nekot/i18n"
+)
+
+const RedactedPlaceholder = "[REDACTED]"
+
+// Presentation mode: secrets, session names and saved prompts are hidden from the screen
+var demoMode bool
+
+func SetDemoMode(enabled bool) {
+	demoMode = enabled
+}
+
+func IsDemoMode() bool {
+	return demoMode
+}
+
+// Session names are replaced with placeholders in demo mode
+func GetSessionName(id int, name string) string {
+	if demoMode {
+		return i18n.Tf("demo.sessionName", id)
+	}
+	return name
+}
+
+var apiKeyEnvVars = []string{"OPENAI_API_KEY", "GEMINI_API_KEY", "OPENROUTER_API_KEY"}
+
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`sk-[A-Za-z0-9_\-]{16,}`),
+	regexp.MustCompile(`AIza[A-Za-z0-9_\-]{30,}`),
+	regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9_\-.=]{8,}`),
+	regexp.MustCompile(`(?i)([?&](?:key|api_key|token)=)[^&\s"']+`),
+}
+
+// Masks API keys: values of the provider key variables and well known key formats
+func RedactSecrets(text string) string {
+	for _, envVar := range apiKeyEnvVars {
+		value := os.Getenv(envVar)
+		if len(value) >= 8 {
+			text = strings.ReplaceAll(text, value, RedactedPlaceholder)
+		}
+	}
+
+	for _, pattern := range secretPatterns {
+		if pattern.NumSubexp() > 0 {
+			text = pattern.ReplaceAllString(text, "${1}"+RedactedPlaceholder)
+			continue
+		}
+		text = pattern.ReplaceAllString(text, RedactedPlaceholder)
+	}
+
+	return text
+}
+
+// Copy of the event with secrets masked in the message and in request details
+func (e ErrorEvent) Redacted() ErrorEvent {
+	e.Message = RedactSecrets(e.Message)
+	if e.Details != nil {
+		details := *e.Details
+		details.Body = RedactSecrets(details.Body)
+		details.RequestId = RedactSecrets(details.RequestId)
+		e.Details = &details
+	}
+	return e
+}
@@ -0,0 +1,81 @@
+package util
+
+import "strings"
+
+type DiffKind int
+
+const (
+	DiffEqual DiffKind = iota
+	DiffAdded
+	DiffRemoved
+)
+
+type DiffLine struct {
+	Kind DiffKind
+	Text string
+}
+
+// Line diff of two texts based on their longest common subsequence of lines.
+// Removed lines go before the added lines that replace them
+func DiffLines(old, new string) []DiffLine {
+	a := splitDiffLines(old)
+	b := splitDiffLines(new)
+
+	// common[i][j] is the length of the common subsequence of a[i:] and b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	lines := []DiffLine{}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, DiffLine{Kind: DiffEqual, Text: a[i]})
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, DiffLine{Kind: DiffRemoved, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, DiffLine{Kind: DiffAdded, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, DiffLine{Kind: DiffRemoved, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, DiffLine{Kind: DiffAdded, Text: b[j]})
+	}
+	return lines
+}
+
+// An empty text has no lines, rather than a single empty one
+func splitDiffLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(text, "\n")
+}
+
+// Unified diff style line: "+" for added, "-" for removed and a space for unchanged lines
+func (l DiffLine) String() string {
+	switch l.Kind {
+	case DiffAdded:
+		return "+ " + l.Text
+	case DiffRemoved:
+		return "- " + l.Text
+	}
+	return "  " + l.Text
+}
@@ -0,0 +1,63 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	testCases := []struct {
+		name     string
+		old      string
+		new      string
+		expected []DiffLine
+	}{
+		{
+			name: "Equal Texts",
+			old:  "a\nb",
+			new:  "a\nb",
+			expected: []DiffLine{
+				{Kind: DiffEqual, Text: "a"},
+				{Kind: DiffEqual, Text: "b"},
+			},
+		},
+		{
+			name: "Replaced Line",
+			old:  "a\nb\nc",
+			new:  "a\nx\nc",
+			expected: []DiffLine{
+				{Kind: DiffEqual, Text: "a"},
+				{Kind: DiffRemoved, Text: "b"},
+				{Kind: DiffAdded, Text: "x"},
+				{Kind: DiffEqual, Text: "c"},
+			},
+		},
+		{
+			name: "Added And Removed Lines",
+			old:  "a\nb",
+			new:  "b\nc",
+			expected: []DiffLine{
+				{Kind: DiffRemoved, Text: "a"},
+				{Kind: DiffEqual, Text: "b"},
+				{Kind: DiffAdded, Text: "c"},
+			},
+		},
+		{
+			name: "Empty Old Text",
+			old:  "",
+			new:  "a",
+			expected: []DiffLine{
+				{Kind: DiffAdded, Text: "a"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := DiffLines(tc.old, tc.new)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("DiffLines(%q, %q) = %v; want %v", tc.old, tc.new, actual, tc.expected)
+			}
+		})
+	}
+}
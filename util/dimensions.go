@@ -18,6 +18,8 @@ const (
 	PromptPanePadding     = 2
 	PromptPaneMarginTop   = 0
 	StatusBarPaneHeight   = 5
+	StatusLineHeight      = 1
+	AttachmentChipsHeight = 1
 	EditModeUIElementsSum = 4
 
 	ChatPaneMarginRight = 1
@@ -51,21 +53,99 @@ const (
 Pane sizes are calculated with proportions:
 - Prompt pane:
   - Width: full termial witdh minus paddings
-  - Height: a constant for height and a constant for top margin
+  - Height: a constant for height and a constant for top margin, plus the rows added in the view mode
 
 - Chat pane:
-  - Width: takes 2/3 of the terminal width
-  - Height: full terminal height minus the prompt pane height
+  - Width: takes 2/3 of the terminal width by default, the ratio is configurable. In zen mode takes full width, limited by the zen mode max width
+  - Height: full terminal height minus the prompt pane and the status line height
 
 - Settings pane:
-  - Width: takes 1/3 of the terminal width, minus paddings
+  - Width: takes the rest of the terminal width (1/3 by default), minus paddings
   - Height: takes 1/3 of the chat pane height, minus paddings
 
 - Sessions pane:
-  - Width: takes 1/3 of the terminal width, minus paddings
+  - Width: takes the rest of the terminal width (1/3 by default), minus paddings
   - Height: takes 2/3 of the chat pane height, minus paddings
 */
 
+// Chat pane share of the terminal width in normal mode. The rest is taken by the side panes
+const (
+	DefaultChatPaneWidthRatio = 2.0 / 3.0
+	MinChatPaneWidthRatio     = 0.4
+	MaxChatPaneWidthRatio     = 0.8
+	ChatPaneWidthRatioStep    = 0.05
+)
+
+var chatPaneWidthRatio = DefaultChatPaneWidthRatio
+
+// Sets the chat pane width ratio, clamped to the allowed range. Zero resets to default
+func SetChatPaneWidthRatio(ratio float64) {
+	if ratio == 0 {
+		ratio = DefaultChatPaneWidthRatio
+	}
+	chatPaneWidthRatio = math.Min(math.Max(ratio, MinChatPaneWidthRatio), MaxChatPaneWidthRatio)
+}
+
+func GetChatPaneWidthRatio() float64 {
+	return chatPaneWidthRatio
+}
+
+// Rows added to the prompt pane with ctrl+up/ctrl+down or by dragging its border, the chat pane gives them up.
+// Each view mode keeps its own number of rows
+const (
+	PromptPaneRowsStep = 2
+	MinChatPaneHeight  = 10
+)
+
+// View modes with a resizable prompt pane by their key in the promptPaneRows config option
+var PromptPaneModes = map[string]ViewMode{
+	"normal": NormalMode,
+	"zen":    ZenMode,
+	"editor": TextEditMode,
+}
+
+var promptPaneRows = map[ViewMode]int{}
+
+func SetPromptPaneRows(mode ViewMode, rows int) {
+	promptPaneRows[mode] = max(rows, 0)
+}
+
+func GetPromptPaneRows(mode ViewMode) int {
+	return promptPaneRows[mode]
+}
+
+// Side panes are hidden in small terminals.
+// They can be shown as a drawer that takes the place of the chat pane
+var sidebarDrawerOpen bool
+
+func SetSidebarDrawerOpen(isOpen bool) {
+	sidebarDrawerOpen = isOpen
+}
+
+func IsSidebarDrawerOpen() bool {
+	return sidebarDrawerOpen
+}
+
+// Max width of the chat pane in zen mode, keeps text readable on wide terminals.
+// Zero means no limit
+var zenModeMaxWidth int
+
+func SetZenModeMaxWidth(width int) {
+	zenModeMaxWidth = width
+}
+
+// Attachments of the prompt are shown as chips above the prompt input,
+// the chat pane gives up a row for them
+var attachmentChipsShown bool
+
+func SetAttachmentChipsShown(isShown bool) {
+	attachmentChipsShown = isShown
+}
+
+func AreAttachmentChipsShown() bool {
+	return attachmentChipsShown
+}
+
 func twoThirds(reference int) int {
 	return int(math.Round(float64(reference) * (2.0 / 3.0)))
 }
@@ -74,6 +154,21 @@ func oneThird(reference int) int {
 	return int(math.Round(float64(reference) / 3.0))
 }
 
+func chatPaneWidth(tw int) int {
+	return int(math.Round(float64(tw) * chatPaneWidthRatio))
+}
+
+func sidePaneWidth(tw int) int {
+	return tw - chatPaneWidth(tw)
+}
+
+func sidePaneContentWidth(tw int) int {
+	if tw < WidthMinScalingLimit && sidebarDrawerOpen {
+		return tw - SidePaneLeftPadding
+	}
+	return sidePaneWidth(tw) - SidePaneLeftPadding
+}
+
 func ensureNonNegative(number int) int {
 	if number < 0 {
 		return 0
@@ -85,14 +180,14 @@ func CalcPromptPaneSize(tw, th int, mode ViewMode) (w, h int) {
 
 	switch mode {
 	case TextEditMode:
-		paneHeight := oneThird(th)
+		paneHeight := oneThird(th) + promptPaneRows[mode]
 		return tw - PromptPanePadding, paneHeight
 	case FilePickerMode:
 		paneHeight := oneThird(th)
 		return tw - PromptPanePadding, paneHeight
 	}
 
-	return tw - PromptPanePadding, PromptPaneHeight
+	return tw - PromptPanePadding, PromptPaneHeight + promptPaneRows[mode]
 }
 
 func CalcVisualModeViewSize(tw, th int) (w, h int) {
@@ -111,35 +206,43 @@ func CalcChatPaneSize(tw, th int, mode ViewMode) (w, h int) {
 
 	switch mode {
 	case NormalMode:
-		paneHeight = th - PromptPaneHeight
+		paneHeight = th - PromptPaneHeight - StatusLineHeight
 		if isSmallScale {
 			paneWidth = tw - DefaultElementsPadding
 		} else {
-			paneWidth = twoThirds(tw)
+			paneWidth = chatPaneWidth(tw)
 		}
 	case ZenMode:
-		paneHeight = th - PromptPaneHeight
+		paneHeight = th - PromptPaneHeight - StatusLineHeight
 		paneWidth = tw - DefaultElementsPadding
+		if zenModeMaxWidth > 0 && paneWidth > zenModeMaxWidth {
+			paneWidth = zenModeMaxWidth
+		}
 	case TextEditMode:
-		paneHeight = twoThirds(th) - EditModeUIElementsSum - 1
+		paneHeight = twoThirds(th) - EditModeUIElementsSum - 1 - StatusLineHeight
 		paneWidth = tw - DefaultElementsPadding
 	case FilePickerMode:
-		paneHeight = twoThirds(th) - EditModeUIElementsSum - 2
+		paneHeight = twoThirds(th) - EditModeUIElementsSum - 2 - StatusLineHeight
 		paneWidth = tw - DefaultElementsPadding
 	}
 
+	if attachmentChipsShown {
+		paneHeight -= AttachmentChipsHeight
+	}
+
+	paneHeight -= promptPaneRows[mode]
 	return paneWidth, paneHeight
 }
 
 func CalcSettingsPaneSize(tw, th int) (w, h int) {
 	_, chatPaneHeight := CalcChatPaneSize(tw, th, NormalMode)
-	settingsPaneWidth := oneThird(tw) - SidePaneLeftPadding
+	settingsPaneWidth := sidePaneContentWidth(tw)
 	settingsPaneHeight := oneThird(chatPaneHeight) - SettingsPaneHeightCounterweight
 
 	settingsPaneWidth = ensureNonNegative(settingsPaneWidth)
 	settingsPaneHeight = ensureNonNegative(settingsPaneHeight)
 
-	if tw < WidthMinScalingLimit {
+	if tw < WidthMinScalingLimit && !sidebarDrawerOpen {
 		return 0, settingsPaneHeight
 	}
 	return settingsPaneWidth, settingsPaneHeight
@@ -153,7 +256,7 @@ func CalcModelsListSize(tw, th int) (w, h int) {
 	modelsListWidth = ensureNonNegative(modelsListWidth)
 	modelsListHeight = ensureNonNegative(modelsListHeight)
 
-	if tw < WidthMinScalingLimit {
+	if tw < WidthMinScalingLimit && !sidebarDrawerOpen {
 		return 0, modelsListHeight
 	}
 	return modelsListWidth, modelsListHeight
@@ -161,13 +264,13 @@ func CalcModelsListSize(tw, th int) (w, h int) {
 
 func CalcSessionsPaneSize(tw, th int) (w, h int) {
 	_, chatPaneHeight := CalcChatPaneSize(tw, th, NormalMode)
-	sessionsPaneWidth := oneThird(tw) - SidePaneLeftPadding
+	sessionsPaneWidth := sidePaneContentWidth(tw)
 	sessionsPaneHeight := twoThirds(chatPaneHeight) - StatusBarPaneHeight - SessionsPaneHeightCounterweight
 
 	sessionsPaneWidth = ensureNonNegative(sessionsPaneWidth)
 	sessionsPaneHeight = ensureNonNegative(sessionsPaneHeight)
 
-	if tw < WidthMinScalingLimit {
+	if tw < WidthMinScalingLimit && !sidebarDrawerOpen {
 		return 0, sessionsPaneHeight
 	}
 	return sessionsPaneWidth, sessionsPaneHeight
@@ -175,13 +278,13 @@ func CalcSessionsPaneSize(tw, th int) (w, h int) {
 
 func CalcSessionsListSize(tw, th, tipsOffset int) (w, h int) {
 	_, chatPaneHeight := CalcChatPaneSize(tw, th, NormalMode)
-	sessionsPaneListWidth := oneThird(tw) - SidePaneLeftPadding
+	sessionsPaneListWidth := sidePaneContentWidth(tw)
 	sessionsPaneListHeight := twoThirds(chatPaneHeight) - StatusBarPaneHeight - SessionsPaneHeightCounterweight - tipsOffset
 
 	sessionsPaneListWidth = ensureNonNegative(sessionsPaneListWidth)
 	sessionsPaneListHeight = ensureNonNegative(sessionsPaneListHeight)
 
-	if tw < WidthMinScalingLimit {
+	if tw < WidthMinScalingLimit && !sidebarDrawerOpen {
 		return 0, sessionsPaneListHeight
 	}
 	return sessionsPaneListWidth, sessionsPaneListHeight
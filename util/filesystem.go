@@ -1,6 +1,8 @@
 package util
 
 import (
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 )
@@ -18,10 +20,157 @@ func DeleteFilesIfDevMode() {
 			Slog.Error("failed to delete database file", "error", err)
 		}
 
-		pathToPersistedFile := filepath.Join(appPath, "config.json")
+		configPath, err := GetAppConfigPath()
+		if err != nil {
+			panic(err)
+		}
+
+		pathToPersistedFile := filepath.Join(configPath, "config.json")
 		err = os.Remove(pathToPersistedFile)
 		if err != nil {
 			Slog.Error("failed to delete config file", "error", err)
 		}
 	}
 }
+
+// The database is moved with its WAL and shared memory files as one unit, sqlite would replay
+// a WAL next to a different database. The database goes last, so an interrupted move is finished on the next run
+var legacyDbFiles = []string{"chat.db-wal", "chat.db-shm", "chat.db"}
+var legacyDataFiles = []string{"debug.log"}
+var legacyConfigFiles = []string{"config.json"}
+
+// Moves files from the legacy ~/.nekot directory to the XDG locations.
+// Files that already exist at the new location are left untouched,
+// so the migration effectively runs once.
+// Legacy files belong to the default profile and are not moved into a custom data dir
+func MigrateLegacyAppDir() error {
+	if profile != "" {
+		return nil
+	}
+
+	legacyPath, err := GetLegacyAppPath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(legacyPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	dataPath, err := GetAppDataPath()
+	if err != nil {
+		return err
+	}
+
+	configPath, err := GetAppConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if dataDirOverride == "" {
+		err = moveDbFiles(legacyPath, dataPath)
+		if err != nil {
+			return err
+		}
+
+		err = moveFiles(legacyPath, dataPath, legacyDataFiles)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = moveFiles(legacyPath, configPath, legacyConfigFiles)
+	if err != nil {
+		return err
+	}
+
+	// Only succeeds when nothing is left in the legacy directory
+	os.Remove(legacyPath)
+	return nil
+}
+
+func moveFiles(fromDir string, toDir string, files []string) error {
+	if fromDir == toDir {
+		return nil
+	}
+
+	for _, file := range files {
+		from := filepath.Join(fromDir, file)
+		to := filepath.Join(toDir, file)
+
+		if _, err := os.Stat(from); os.IsNotExist(err) {
+			continue
+		}
+
+		if _, err := os.Stat(to); err == nil {
+			continue
+		}
+
+		err := moveFile(from, to)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Database files are left in the legacy directory if there is a database at the new location already
+func moveDbFiles(fromDir string, toDir string) error {
+	if fromDir == toDir {
+		return nil
+	}
+
+	if _, err := os.Stat(filepath.Join(fromDir, "chat.db")); os.IsNotExist(err) {
+		return nil
+	}
+
+	if _, err := os.Stat(filepath.Join(toDir, "chat.db")); err == nil {
+		return nil
+	}
+
+	for _, file := range legacyDbFiles {
+		from := filepath.Join(fromDir, file)
+		if _, err := os.Stat(from); os.IsNotExist(err) {
+			continue
+		}
+
+		err := moveFile(from, filepath.Join(toDir, file))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func moveFile(from string, to string) error {
+	err := os.Rename(from, to)
+	if err == nil {
+		return nil
+	}
+
+	// Rename fails across filesystems, fall back to copying
+	var linkErr *os.LinkError
+	if !errors.As(err, &linkErr) {
+		return err
+	}
+
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return err
+	}
+
+	return os.Remove(from)
+}
@@ -7,74 +7,78 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/BalanceBalls/nekot/i18n"
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/rivo/uniseg"
 )
 
+var toolCallIcon = "🔧"
+
+// Returns the rendered messages and the line each of them starts at
 func GetMessagesAsPrettyString(
 	msgsToRender []LocalStoreMessage,
 	w int,
 	colors SchemeColors,
 	isQuickChat bool,
 	settings Settings,
-) string {
-	var messages string
-
-	for _, message := range msgsToRender {
-
-		messageToUse := message.Content
-
-		switch message.Role {
-		case "user":
-			messageToUse = RenderUserMessage(message, w, colors, false)
-		case "assistant":
-			messageToUse = RenderBotMessage(message, w, colors, false, settings)
-		case "tool":
-			messageToUse = RenderToolCall(message, w, colors, false, settings)
-		}
-
-		if messages == "" {
-			messages = messageToUse
-			continue
-		}
-
-		messages = messages + "\n" + messageToUse
-	}
+) (string, []int) {
+	messages, offsets := renderMessages(msgsToRender, w, colors, false, settings)
 
 	if isQuickChat {
 		quickChatDisclaimer := GetQuickChatDisclaimer(w, colors)
 		messages = quickChatDisclaimer + "\n" + messages
+
+		disclaimerLines := strings.Count(quickChatDisclaimer, "\n") + 1
+		for i := range offsets {
+			offsets[i] += disclaimerLines
+		}
 	}
 
-	return messages
+	return messages, offsets
+}
+
+func GetVisualModeView(msgsToRender []LocalStoreMessage, w int, colors SchemeColors, settings Settings) (string, []int) {
+	return renderMessages(msgsToRender, w-TextSelectorMaxWidthCorrection, colors, true, settings)
 }
 
-func GetVisualModeView(msgsToRender []LocalStoreMessage, w int, colors SchemeColors, settings Settings) string {
+func renderMessages(
+	msgsToRender []LocalStoreMessage,
+	w int,
+	colors SchemeColors,
+	isVisualMode bool,
+	settings Settings,
+) (string, []int) {
 	var messages string
-	w = w - TextSelectorMaxWidthCorrection
-	for _, message := range msgsToRender {
+	offsets := make([]int, len(msgsToRender))
+	linesCount := 0
+	w = getContentWidth(w)
+
+	for i, message := range msgsToRender {
 
 		messageToUse := message.Content
 
 		switch message.Role {
 		case "user":
-			messageToUse = RenderUserMessage(message, w, colors, true)
+			messageToUse = RenderUserMessage(message, w, colors, isVisualMode)
 		case "assistant":
-			messageToUse = RenderBotMessage(message, w, colors, true, settings)
+			messageToUse = RenderBotMessage(message, w, colors, isVisualMode, settings)
 		case "tool":
-			messageToUse = RenderToolCall(message, w, colors, true, settings)
+			messageToUse = RenderToolCall(message, w, colors, isVisualMode, settings)
 		}
 
 		if messages == "" {
 			messages = messageToUse
+			linesCount = strings.Count(messageToUse, "\n")
 			continue
 		}
 
+		offsets[i] = linesCount + 1
+		linesCount += strings.Count(messageToUse, "\n") + 1
 		messages = messages + "\n" + messageToUse
 	}
 
-	return messages
+	return messages, offsets
 }
 
 func RenderUserMessage(userMessage LocalStoreMessage, width int, colors SchemeColors, isVisualMode bool) string {
@@ -85,13 +89,20 @@ func RenderUserMessage(userMessage LocalStoreMessage, width int, colors SchemeCo
 	)
 	msg := userMessage.Content
 	if isVisualMode {
-		msg = "\n💁 " + msg
+		msg = "\n" + getSpeakerPrefix(userIcon) + msg
 		userMsg, _ := renderer.Render(msg)
 		output := strings.TrimSpace(userMsg)
 		return lipgloss.NewStyle().Render("\n" + output + "\n")
 	}
 
-	msg = "\n💁 **[Prooompter]**\n" + msg + "\n"
+	header := "\n" + getUserHeader()
+	if userMessage.Pinned {
+		header += pinnedMarker
+	}
+	msg = header + "\n" + msg + "\n"
+	if userMessage.RedactedSecrets > 0 {
+		msg += "\n*" + i18n.Tf("chat.secretsRedacted", userMessage.RedactedSecrets) + "*\n"
+	}
 	if len(userMessage.Attachments) != 0 {
 		attachments := "\n *Attachments:* \n"
 		for _, file := range userMessage.Attachments {
@@ -101,11 +112,11 @@ func RenderUserMessage(userMessage LocalStoreMessage, width int, colors SchemeCo
 		msg += attachments
 	}
 
-	userMsg, _ := renderer.Render(msg)
+	userMsg := renderMarkdown(renderer, msg, colors)
 	output := strings.TrimSpace(userMsg)
 	return lipgloss.NewStyle().
 		BorderLeft(true).
-		BorderStyle(lipgloss.InnerHalfBlockBorder()).
+		BorderStyle(HalfBlockBorder()).
 		BorderLeftForeground(colors.NormalTabBorderColor).
 		Render("\n" + output + "\n")
 }
@@ -116,24 +127,45 @@ func RenderErrorMessage(msg string, width int, colors SchemeColors) string {
 		glamour.WithWordWrap(width-WordWrapDelta),
 		colors.RendererThemeOption,
 	)
-	msg = " ⛔ **Encountered error:**\n ```json\n" + msg + "\n```"
+	msg = i18n.T("error.encountered") + "\n ```json\n" + msg + "\n```"
 	errMsg, _ := renderer.Render(msg)
 	errOutput := strings.TrimSpace(errMsg)
 
 	instructions, _ := renderer.Render(
-		"\n## Inspect the error, fix the problem and restart the app\n\n" + ErrorHelp,
+		"\n## " + i18n.T("error.restart") + "\n\n" +
+			i18n.T("error.detailsHint") + "\n" + ErrorHelp,
 	)
 	instructionsOutput := strings.TrimSpace(instructions)
 
 	return lipgloss.NewStyle().
 		BorderLeft(true).
-		BorderStyle(lipgloss.InnerHalfBlockBorder()).
+		BorderStyle(HalfBlockBorder()).
 		BorderLeftForeground(colors.ErrorColor).
 		Width(width).
 		Foreground(colors.HighlightColor).
 		Render(errOutput + "\n\n" + instructionsOutput)
 }
 
+// Renders an error under the last turn of a conversation
+func RenderInlineErrorMessage(msg string, width int, colors SchemeColors) string {
+	renderer, _ := glamour.NewTermRenderer(
+		glamour.WithPreservedNewLines(),
+		glamour.WithWordWrap(width-WordWrapDelta),
+		colors.RendererThemeOption,
+	)
+	msg = i18n.T("error.requestFailed") + "\n ```json\n" + msg + "\n```\n" +
+		" *" + i18n.T("error.continue") + "*\n" +
+		" *" + i18n.T("error.detailsHint") + "*"
+	errMsg, _ := renderer.Render(msg)
+
+	return lipgloss.NewStyle().
+		BorderLeft(true).
+		BorderStyle(HalfBlockBorder()).
+		BorderLeftForeground(colors.ErrorColor).
+		Foreground(colors.HighlightColor).
+		Render("\n" + strings.TrimSpace(errMsg) + "\n")
+}
+
 func RenderBotMessage(
 	msg LocalStoreMessage,
 	width int,
@@ -174,6 +206,11 @@ func RenderBotMessage(
 		content += "\n  \n"
 	}
 
+	// sources are rendered separately, glamour would mangle hyperlinks
+	var sources []SourceLink
+	msg.Content, sources = SplitSources(msg.Content)
+	msg.Content = RenderLatex(msg.Content)
+
 	// markdown renderer glitches when code block appears on a line with different text
 	if strings.HasPrefix(msg.Content, "```") {
 		msg.Content = "\n" + msg.Content
@@ -181,29 +218,148 @@ func RenderBotMessage(
 
 	content += msg.Content
 	modelName := ""
-	icon := "\n 🤖 "
+	icon := "\n " + getSpeakerPrefix(assistantIcon)
 	if len(msg.Model) > 0 {
-		modelName = "**[" + msg.Model + "]**\n"
+		modelName = "**[" + msg.Model + "]**"
+	}
+	if msg.Pinned {
+		modelName += pinnedMarker
+	}
+	modelName += getRatingMarker(msg)
+	if modelName != "" {
+		modelName += "\n"
 	}
 
 	content = cleanContent(content)
 
+	// the icon is kept in the visual mode, it is stripped from the copied text unlike a persona name
 	if isVisualMode {
 		content = icon + content
 		userMsg, _ := renderer.Render(content)
 		output := strings.TrimSpace(userMsg)
+		if len(sources) > 0 {
+			output += "\n\n" + RenderSources(sources, width, colors, false)
+		}
 		return lipgloss.NewStyle().Render(output + "\n")
 	}
 
+	hasPersona := currentPersona != nil && currentPersona.Name != ""
+	if hasPersona {
+		icon = "\n " + currentPersona.Name + " "
+	}
+
 	content = icon + modelName + content + "\n"
-	aiResponse, _ := renderer.Render(content)
+	aiResponse := renderMarkdown(renderer, content, colors)
 	output := strings.TrimSpace(aiResponse)
-	return lipgloss.NewStyle().
+	if hasPersona {
+		output = colorPersonaLabel(output, colors)
+	}
+	if len(sources) > 0 {
+		output += "\n\n" + RenderSources(sources, width, colors, true)
+	}
+
+	style := lipgloss.NewStyle().
 		BorderLeft(true).
-		BorderStyle(lipgloss.InnerHalfBlockBorder()).
-		BorderLeftForeground(colors.ActiveTabBorderColor).
-		Width(width - 1).
-		Render(output)
+		BorderStyle(HalfBlockBorder()).
+		BorderLeftForeground(colors.ActiveTabBorderColor)
+	// the width would wrap the code lines again
+	if wrapCodeBlocks {
+		style = style.Width(width - 1)
+	}
+	return style.Render(output)
+}
+
+// Renders follow-up suggestions as numbered chips with a hint on how to use them
+func RenderFollowUps(suggestions []string, width int, colors SchemeColors) string {
+	numberStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.AdaptiveColor{Dark: "#000000", Light: "#ffffff"}).
+		Background(colors.AccentColor)
+	textStyle := lipgloss.NewStyle().
+		Foreground(colors.DefaultTextColor).
+		Width(width - WordWrapDelta)
+
+	chips := []string{}
+	for i, suggestion := range suggestions {
+		chip := numberStyle.Render(fmt.Sprintf(" %d ", i+1)) + " " + suggestion
+		chips = append(chips, " "+textStyle.Render(chip))
+	}
+	chips = append(chips, HelpStyle.Render(i18n.T("followUps.hint")))
+
+	return strings.Join(chips, "\n")
+}
+
+const (
+	ThinkStartToken = "<think>"
+	ThinkEndToken   = "</think>"
+)
+
+// Number of the latest reasoning lines shown while the model is thinking
+const reasoningPreviewLines = 6
+
+// Splits a streamed response into reasoning wrapped in <think> tags and the answer
+func SplitReasoning(response string) (string, string) {
+	start := strings.Index(response, ThinkStartToken)
+	if start == -1 {
+		return "", response
+	}
+
+	reasoning := response[start+len(ThinkStartToken):]
+	answer := response[:start]
+
+	end := strings.Index(reasoning, ThinkEndToken)
+	if end == -1 {
+		return strings.TrimSpace(reasoning), strings.TrimSpace(answer)
+	}
+
+	answer += reasoning[end+len(ThinkEndToken):]
+	return strings.TrimSpace(reasoning[:end]), strings.TrimSpace(answer)
+}
+
+// Renders a response that is being streamed. Reasoning is shown in a dimmed block with the latest lines
+// while the model is thinking and collapses to a summary once the answer starts
+func RenderStreamingResponse(
+	reasoning string,
+	answer string,
+	width int,
+	colors SchemeColors,
+	settings Settings,
+) string {
+	width = getContentWidth(width)
+	if reasoning == "" {
+		return RenderBotMessage(LocalStoreMessage{Content: answer, Role: "assistant"}, width, colors, false, settings)
+	}
+
+	reasoningBlock := renderReasoningBlock(reasoning, answer == "" && !settings.HideReasoning, width, colors)
+	if answer == "" {
+		return reasoningBlock
+	}
+
+	response := RenderBotMessage(LocalStoreMessage{Content: answer, Role: "assistant"}, width, colors, false, settings)
+	return reasoningBlock + "\n" + response
+}
+
+func renderReasoningBlock(reasoning string, expanded bool, width int, colors SchemeColors) string {
+	style := lipgloss.NewStyle().
+		Faint(true).
+		Italic(true).
+		Foreground(colors.DefaultTextColor).
+		BorderLeft(true).
+		BorderStyle(ThickBorder()).
+		BorderLeftForeground(colors.NormalTabBorderColor).
+		PaddingLeft(1)
+
+	if !expanded {
+		return style.Render(i18n.Tf("chat.reasoningSummary", CountTokens(reasoning)))
+	}
+
+	wrapped := lipgloss.NewStyle().Width(width - WordWrapDelta).Render(reasoning)
+	lines := strings.Split(wrapped, "\n")
+	if len(lines) > reasoningPreviewLines {
+		lines = lines[len(lines)-reasoningPreviewLines:]
+	}
+
+	return style.Render(i18n.T("chat.thinking") + "\n" + strings.Join(lines, "\n"))
 }
 
 func RenderToolCall(
@@ -257,7 +413,7 @@ func RenderToolCall(
 		for _, tc := range msg.ToolCalls {
 			toolData += fmt.Sprintf(
 				"<div>%s [Executed tool call: %s]\n   Args: %v</div>                                           \n",
-				"🔧",
+				toolCallIcon,
 				tc.Function.Name,
 				tc.Function.Args)
 		}
@@ -268,18 +424,25 @@ func RenderToolCall(
 	}
 
 	content = cleanContent(content)
+	sources := GetToolCallSources(msg.ToolCalls)
 
 	if isVisualMode {
 		userMsg, _ := renderer.Render(content)
 		output := strings.TrimSpace(userMsg)
+		if len(sources) > 0 {
+			output += "\n\n" + RenderSources(sources, width, colors, false)
+		}
 		return lipgloss.NewStyle().Render(output + "\n")
 	}
 
 	aiResponse, _ := renderer.Render(content)
 	output := strings.TrimSpace(aiResponse)
+	if len(sources) > 0 {
+		output += "\n\n" + RenderSources(sources, width, colors, true)
+	}
 	return lipgloss.NewStyle().
 		BorderLeft(true).
-		BorderStyle(lipgloss.InnerHalfBlockBorder()).
+		BorderStyle(HalfBlockBorder()).
 		BorderLeftForeground(colors.HighlightColor).
 		Width(width - 1).
 		Render(output)
@@ -299,7 +462,7 @@ func RenderBotChunk(
 	output := strings.TrimSpace(userMsg)
 	return lipgloss.NewStyle().
 		BorderLeft(true).
-		BorderStyle(lipgloss.InnerHalfBlockBorder()).
+		BorderStyle(HalfBlockBorder()).
 		BorderLeftForeground(colors.ActiveTabBorderColor).
 		Width(width - 1).
 		Render(output)
@@ -311,7 +474,7 @@ func GetQuickChatDisclaimer(w int, colors SchemeColors) string {
 		colors.RendererThemeOption,
 	)
 
-	output, _ := renderer.Render(QuickChatWarning)
+	output, _ := renderer.Render(i18n.T("chat.quickChatWarning"))
 	return lipgloss.NewStyle().
 		MaxWidth(w).
 		Render(output)
@@ -323,14 +486,14 @@ func GetManual(w int, colors SchemeColors) string {
 		glamour.WithWordWrap(40),
 		colors.RendererThemeOption,
 	)
-	output, _ := renderer.Render(ManualContent)
+	output, _ := renderer.Render(i18n.Manual() + renderHelpSections(GetHelpSections()))
 	return lipgloss.NewStyle().
 		MaxWidth(w).
 		Render(output)
 }
 
 func StripAnsiCodes(str string) string {
-	ansiRegex := regexp.MustCompile(`\x1b\[[0-9;]*[mG]`)
+	ansiRegex := regexp.MustCompile(`\x1b\[[0-9;]*[mG]|\x1b\]8;[^\x1b\a]*(\x1b\\|\a)`)
 	return ansiRegex.ReplaceAllString(str, "")
 }
 
@@ -3,6 +3,9 @@ package util
 import (
 	"slices"
 	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 func RemoveDuplicates[T comparable](slice []T) []T {
@@ -38,3 +41,73 @@ func IsProcessingActive(state ProcessingState) bool {
 	}
 	return slices.Contains(processingStates, state)
 }
+
+// Rough token count estimation: ~4 characters per token.
+// Used when the provider did not report usage, e.g. for interrupted responses
+func EstimateTokens(text string) int {
+	chars := utf8.RuneCountInString(text)
+	return (chars + 3) / 4
+}
+
+// Compares semantic versions like v1.2.3 or 1.2.3-rc1.
+// A prerelease is older than the release with the same numbers
+func IsNewerVersion(current, latest string) bool {
+	currentParts, currentPre := parseVersion(current)
+	latestParts, latestPre := parseVersion(latest)
+
+	for i := range currentParts {
+		if latestParts[i] != currentParts[i] {
+			return latestParts[i] > currentParts[i]
+		}
+	}
+
+	if currentPre == latestPre {
+		return false
+	}
+	if latestPre == "" {
+		return true
+	}
+	if currentPre == "" {
+		return false
+	}
+	return latestPre > currentPre
+}
+
+func parseVersion(version string) ([3]int, string) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, prerelease, _ := strings.Cut(version, "-")
+
+	parts := [3]int{}
+	for i, part := range strings.SplitN(version, ".", 3) {
+		parts[i], _ = strconv.Atoi(part)
+	}
+	return parts, prerelease
+}
+
+// Formats text as a markdown blockquote. Lines are dedented first,
+// selected lines keep the indentation of the rendered response
+func QuoteText(text string) string {
+	lines := strings.Split(strings.Trim(text, "\n"), "\n")
+
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lineIndent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent == -1 || lineIndent < indent {
+			indent = lineIndent
+		}
+	}
+
+	quoted := make([]string, len(lines))
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			quoted[i] = ">"
+			continue
+		}
+		quoted[i] = "> " + line[indent:]
+	}
+	return strings.Join(quoted, "\n")
+}
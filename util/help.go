@@ -0,0 +1,86 @@
+package util
+
+import (
+	"strings"
+
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Implemented by panes, so the help screen and footer hints are built from live keybindings.
+// Descriptions of bindings are catalog keys, they are translated when rendered
+type HelpKeyMap interface {
+	ShortHelp() []key.Binding
+	FullHelp() []key.Binding
+}
+
+// A titled group of keybindings on the help screen
+type HelpSection struct {
+	Title    string
+	Bindings []key.Binding
+}
+
+var helpSections []HelpSection
+
+// Set once keybindings are final, i.e. after remapping from config
+func SetHelpSections(sections []HelpSection) {
+	helpSections = sections
+}
+
+func GetHelpSections() []HelpSection {
+	return helpSections
+}
+
+// Renders bindings as a single line of tips: "key desc • key desc"
+func RenderKeyHints(bindings []key.Binding) string {
+	hints := []string{}
+	for _, binding := range bindings {
+		if !binding.Enabled() || binding.Help().Key == "" {
+			continue
+		}
+		hints = append(hints, binding.Help().Key+" "+i18n.T(binding.Help().Desc))
+	}
+	return strings.Join(hints, TipsSeparator)
+}
+
+// Builds a key message from a key name as used in key bindings, e.g. "ctrl+w", "alt+n" or "y"
+func GetKeyMsg(name string) (tea.KeyMsg, bool) {
+	alt := strings.HasPrefix(name, "alt+") && name != "alt+"
+	name = strings.TrimPrefix(name, "alt+")
+
+	for keyType := tea.KeyType(-128); keyType <= tea.KeyBackspace; keyType++ {
+		if keyType != tea.KeyRunes && keyType.String() == name {
+			return tea.KeyMsg{Type: keyType, Alt: alt}, true
+		}
+	}
+
+	runes := []rune(name)
+	if len(runes) == 1 {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: runes, Alt: alt}, true
+	}
+
+	return tea.KeyMsg{}, false
+}
+
+func renderHelpSections(sections []HelpSection) string {
+	content := strings.Builder{}
+	for _, section := range sections {
+		rows := []string{}
+		for _, binding := range section.Bindings {
+			if !binding.Enabled() || binding.Help().Key == "" {
+				continue
+			}
+			rows = append(rows, "`"+binding.Help().Key+"` "+i18n.T(binding.Help().Desc))
+		}
+
+		if len(rows) == 0 {
+			continue
+		}
+
+		content.WriteString("\n# " + section.Title + "\n\n")
+		content.WriteString(strings.Join(rows, "\n <!------->\n"))
+		content.WriteString("\n")
+	}
+	return content.String()
+}
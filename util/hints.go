@@ -0,0 +1,193 @@
+package util
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+type HintKind int
+
+const (
+	LinkHint HintKind = iota
+	CodeBlockHint
+)
+
+// Home row keys go first, they are the easiest to type
+const hintAlphabet = "asdfghjklqwertyuiopzxcvbnm"
+
+// Long needles may be wrapped by the renderer, a prefix is enough to find an element
+const maxHintNeedleLength = 24
+
+var codeBlockContentRegex = regexp.MustCompile("(?s)```[^\\n]*\\n(.*?)```")
+
+// A link or a code block visible in the chat view. Value is the url or the code
+type Hint struct {
+	Label string
+	Kind  HintKind
+	Value string
+	line  int
+	col   int
+}
+
+type hintElement struct {
+	kind    HintKind
+	value   string
+	needles []string
+}
+
+// Finds links and code blocks of the messages on the visible lines of the chat view.
+// Elements are looked up by their text, so the ones scrolled out of view are skipped
+func FindHints(messages []LocalStoreMessage, lines []string) []Hint {
+	strippedLines := make([]string, len(lines))
+	for i, line := range lines {
+		strippedLines[i] = StripAnsiCodes(line)
+	}
+
+	hints := []Hint{}
+	used := map[[2]int]bool{}
+	for _, element := range getHintElements(messages) {
+		line, col, ok := findHintPosition(strippedLines, element.needles, used)
+		if !ok {
+			continue
+		}
+
+		used[[2]int{line, col}] = true
+		hints = append(hints, Hint{Kind: element.kind, Value: element.value, line: line, col: col})
+	}
+
+	// labels go from top to bottom, as the elements are on the screen
+	slices.SortStableFunc(hints, func(a, b Hint) int {
+		if a.line != b.line {
+			return a.line - b.line
+		}
+		return a.col - b.col
+	})
+
+	labels := getHintLabels(len(hints))
+	for i := range hints {
+		hints[i].Label = labels[i]
+	}
+	return hints
+}
+
+// Puts labels over the elements. Hints that don't match the typed prefix are hidden
+func RenderHints(lines []string, hints []Hint, typed string, colors SchemeColors) string {
+	labelStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(colors.MainColor).
+		Background(colors.AccentColor)
+
+	result := make([]string, len(lines))
+	copy(result, lines)
+	for _, hint := range hints {
+		if !strings.HasPrefix(hint.Label, typed) || hint.line >= len(result) {
+			continue
+		}
+
+		line := result[hint.line]
+		labelWidth := len(hint.Label)
+		result[hint.line] = ansi.Cut(line, 0, hint.col) +
+			labelStyle.Render(hint.Label) +
+			ansi.Cut(line, hint.col+labelWidth, ansi.StringWidth(line))
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// Labels are of the same length, so none of them is a prefix of another
+func getHintLabels(count int) []string {
+	labels := []string{}
+	if count <= len(hintAlphabet) {
+		for i := range count {
+			labels = append(labels, string(hintAlphabet[i]))
+		}
+		return labels
+	}
+
+	for _, first := range hintAlphabet {
+		for _, second := range hintAlphabet {
+			if len(labels) == count {
+				return labels
+			}
+			labels = append(labels, string(first)+string(second))
+		}
+	}
+	return labels
+}
+
+func getHintElements(messages []LocalStoreMessage) []hintElement {
+	elements := []hintElement{}
+	for _, message := range messages {
+		content, sources := SplitSources(message.Content)
+
+		for _, match := range codeBlockContentRegex.FindAllStringSubmatch(content, -1) {
+			code := strings.TrimRight(match[1], "\n")
+			firstLine := ""
+			for line := range strings.SplitSeq(code, "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					firstLine = line
+					break
+				}
+			}
+			if firstLine == "" {
+				continue
+			}
+			elements = append(elements, hintElement{
+				kind:    CodeBlockHint,
+				value:   code,
+				needles: []string{getHintNeedle(firstLine)},
+			})
+		}
+
+		for _, link := range ExtractUrls(content) {
+			elements = append(elements, hintElement{kind: LinkHint, value: link, needles: []string{getHintNeedle(link)}})
+		}
+
+		// With hyperlinks the sources show titles only
+		sources = append(sources, GetToolCallSources(message.ToolCalls)...)
+		for _, source := range sources {
+			title := source.Title
+			if title == "" {
+				title = getLinkHost(source.Url)
+			}
+			elements = append(elements, hintElement{
+				kind:    LinkHint,
+				value:   source.Url,
+				needles: []string{getHintNeedle(source.Url), getHintNeedle(title)},
+			})
+		}
+	}
+	return elements
+}
+
+func findHintPosition(lines []string, needles []string, used map[[2]int]bool) (int, int, bool) {
+	for _, needle := range needles {
+		if strings.TrimSpace(needle) == "" {
+			continue
+		}
+
+		for i, line := range lines {
+			index := strings.Index(line, needle)
+			if index == -1 {
+				continue
+			}
+
+			col := ansi.StringWidth(line[:index])
+			if !used[[2]int{i, col}] {
+				return i, col, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func getHintNeedle(text string) string {
+	if len(text) <= maxHintNeedleLength {
+		return text
+	}
+	return ansi.Truncate(text, maxHintNeedleLength, "")
+}
@@ -0,0 +1,131 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Header of the sources block the clients append to responses with citations
+const sourcesHeader = "\n`Sources`\n"
+
+// Clickable OSC 8 hyperlinks. Terminals without support print the text only,
+// so with hyperlinks disabled the sources are listed with full urls instead
+var hyperlinksEnabled = true
+
+var sourceLinkRegex = regexp.MustCompile(`^\s*>\s*\[(.*)\]\((\S+)\)\s*$`)
+
+type SourceLink struct {
+	Title string
+	Url   string
+}
+
+func SetHyperlinks(enabled bool) {
+	hyperlinksEnabled = enabled
+}
+
+// Wraps the text into an OSC 8 hyperlink
+func Hyperlink(link string, text string) string {
+	return ansi.SetHyperlink(link) + text + ansi.ResetHyperlink()
+}
+
+// Cuts the sources block off the end of a response.
+// The content is returned as is if the block has anything but links
+func SplitSources(content string) (string, []SourceLink) {
+	start := strings.LastIndex(content, sourcesHeader)
+	if start == -1 {
+		return content, nil
+	}
+
+	sources := []SourceLink{}
+	for line := range strings.SplitSeq(content[start+len(sourcesHeader):], "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		match := sourceLinkRegex.FindStringSubmatch(line)
+		if match == nil {
+			return content, nil
+		}
+		sources = append(sources, SourceLink{Title: strings.TrimSpace(match[1]), Url: match[2]})
+	}
+
+	return strings.TrimRight(content[:start], "\n"), sources
+}
+
+// Links of the pages the web_search tool has read
+func GetToolCallSources(toolCalls []ToolCall) []SourceLink {
+	sources := []SourceLink{}
+	seen := map[string]bool{}
+
+	for _, tc := range toolCalls {
+		if tc.Function.Name != "web_search" || tc.Result == nil {
+			continue
+		}
+
+		var results []struct {
+			Link string `json:"link"`
+		}
+		if err := json.Unmarshal([]byte(*tc.Result), &results); err != nil {
+			continue
+		}
+
+		for _, result := range results {
+			if result.Link == "" || seen[result.Link] {
+				continue
+			}
+			seen[result.Link] = true
+			sources = append(sources, SourceLink{Url: result.Link})
+		}
+	}
+
+	return sources
+}
+
+// Renders sources as a numbered footnote list. Titles are hyperlinks if supported,
+// otherwise every footnote is followed by its url
+func RenderSources(sources []SourceLink, width int, colors SchemeColors, withHyperlinks bool) string {
+	if len(sources) == 0 {
+		return ""
+	}
+
+	withHyperlinks = withHyperlinks && hyperlinksEnabled
+	maxWidth := max(width-WordWrapDelta, 10)
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(colors.AccentColor)
+	linkStyle := lipgloss.NewStyle().Foreground(colors.ActiveTabBorderColor)
+	urlStyle := lipgloss.NewStyle().Faint(true).Foreground(colors.HighlightColor)
+
+	lines := []string{headerStyle.Render(i18n.T("chat.sources"))}
+	for i, source := range sources {
+		number := fmt.Sprintf(" [%d] ", i+1)
+		title := source.Title
+		if title == "" {
+			title = getLinkHost(source.Url)
+		}
+		title = ansi.Truncate(title, maxWidth-len(number), "…")
+
+		if withHyperlinks {
+			lines = append(lines, number+Hyperlink(source.Url, linkStyle.Underline(true).Render(title)))
+			continue
+		}
+
+		lines = append(lines, number+linkStyle.Render(title))
+		lines = append(lines, strings.Repeat(" ", len(number))+urlStyle.Render(source.Url))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func getLinkHost(link string) string {
+	parsedUrl, err := url.Parse(link)
+	if err != nil || parsedUrl.Host == "" {
+		return link
+	}
+	return strings.TrimPrefix(parsedUrl.Host, "www.")
+}
@@ -0,0 +1,342 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// Math is not converted inside code: fences, unclosed fences of a streamed response and code spans
+	mathCodeRegex  = regexp.MustCompile("(?s)```.*?(?:```|$)|`[^`\\n]+`")
+	blockMathRegex = regexp.MustCompile(`(?s)\$\$(.+?)\$\$|\\\[(.+?)\\\]`)
+	// Blanks right inside the dollars are not allowed, so prices and shell variables stay as they are
+	inlineMathRegex = regexp.MustCompile(`\\\((.+?)\\\)|\$([^\s$](?:[^$\n]*[^\s$])?)\$`)
+)
+
+var latexSymbols = map[string]string{
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ϵ", "varepsilon": "ε",
+	"zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ", "iota": "ι", "kappa": "κ",
+	"lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ", "pi": "π", "varpi": "ϖ", "rho": "ρ",
+	"varrho": "ϱ", "sigma": "σ", "varsigma": "ς", "tau": "τ", "upsilon": "υ", "phi": "ϕ",
+	"varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π",
+	"Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+
+	"times": "×", "cdot": "·", "div": "÷", "pm": "±", "mp": "∓", "ast": "∗", "star": "⋆",
+	"circ": "∘", "bullet": "•", "oplus": "⊕", "otimes": "⊗",
+	"leq": "≤", "le": "≤", "geq": "≥", "ge": "≥", "neq": "≠", "ne": "≠", "approx": "≈",
+	"equiv": "≡", "sim": "∼", "simeq": "≃", "cong": "≅", "propto": "∝", "ll": "≪", "gg": "≫",
+	"to": "→", "rightarrow": "→", "leftarrow": "←", "leftrightarrow": "↔", "mapsto": "↦",
+	"Rightarrow": "⇒", "Leftarrow": "⇐", "Leftrightarrow": "⇔", "implies": "⟹", "iff": "⟺",
+	"uparrow": "↑", "downarrow": "↓",
+	"in": "∈", "notin": "∉", "ni": "∋", "subset": "⊂", "subseteq": "⊆", "supset": "⊃",
+	"supseteq": "⊇", "cup": "∪", "cap": "∩", "setminus": "∖", "emptyset": "∅", "varnothing": "∅",
+	"forall": "∀", "exists": "∃", "nexists": "∄", "neg": "¬", "lnot": "¬", "land": "∧",
+	"wedge": "∧", "lor": "∨", "vee": "∨",
+	"sum": "∑", "prod": "∏", "coprod": "∐", "int": "∫", "iint": "∬", "iiint": "∭", "oint": "∮",
+	"partial": "∂", "nabla": "∇", "infty": "∞", "prime": "′", "degree": "°", "angle": "∠",
+	"perp": "⊥", "parallel": "∥", "mid": "∣", "therefore": "∴", "because": "∵",
+	"ldots": "…", "cdots": "⋯", "dots": "…", "vdots": "⋮", "ddots": "⋱",
+	"hbar": "ℏ", "ell": "ℓ", "Re": "ℜ", "Im": "ℑ", "aleph": "ℵ",
+	"langle": "⟨", "rangle": "⟩", "lceil": "⌈", "rceil": "⌉", "lfloor": "⌊", "rfloor": "⌋",
+	"lbrace": "{", "rbrace": "}", "vert": "|", "Vert": "‖",
+	"quad": " ", "qquad": " ",
+	"sin": "sin", "cos": "cos", "tan": "tan", "cot": "cot", "sec": "sec", "csc": "csc",
+	"arcsin": "arcsin", "arccos": "arccos", "arctan": "arctan", "sinh": "sinh", "cosh": "cosh",
+	"tanh": "tanh", "log": "log", "ln": "ln", "lg": "lg", "exp": "exp", "lim": "lim",
+	"max": "max", "min": "min", "sup": "sup", "inf": "inf", "det": "det", "dim": "dim",
+	"gcd": "gcd", "deg": "deg", "arg": "arg", "ker": "ker", "Pr": "Pr",
+}
+
+// Font commands are dropped, their argument is kept as is
+var latexFontCommands = map[string]bool{
+	"text": true, "textrm": true, "textbf": true, "textit": true, "mathrm": true, "mathbf": true,
+	"mathit": true, "mathsf": true, "mathtt": true, "mathcal": true, "mathbb": true,
+	"operatorname": true, "boldsymbol": true, "displaystyle": true,
+}
+
+var numberSets = map[string]string{"N": "ℕ", "Z": "ℤ", "Q": "ℚ", "R": "ℝ", "C": "ℂ"}
+
+var latexAccents = map[string]string{
+	"hat": "̂", "widehat": "̂", "bar": "̄", "overline": "̅",
+	"vec": "⃗", "dot": "̇", "ddot": "̈", "tilde": "̃", "widetilde": "̃",
+}
+
+var superscripts = map[rune]rune{
+	'0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴', '5': '⁵', '6': '⁶', '7': '⁷', '8': '⁸', '9': '⁹',
+	'+': '⁺', '-': '⁻', '=': '⁼', '(': '⁽', ')': '⁾', 'a': 'ᵃ', 'b': 'ᵇ', 'c': 'ᶜ', 'd': 'ᵈ',
+	'e': 'ᵉ', 'f': 'ᶠ', 'g': 'ᵍ', 'h': 'ʰ', 'i': 'ⁱ', 'j': 'ʲ', 'k': 'ᵏ', 'l': 'ˡ', 'm': 'ᵐ',
+	'n': 'ⁿ', 'o': 'ᵒ', 'p': 'ᵖ', 'r': 'ʳ', 's': 'ˢ', 't': 'ᵗ', 'u': 'ᵘ', 'v': 'ᵛ', 'w': 'ʷ',
+	'x': 'ˣ', 'y': 'ʸ', 'z': 'ᶻ', 'T': 'ᵀ', '′': '′', '*': '*',
+}
+
+var subscripts = map[rune]rune{
+	'0': '₀', '1': '₁', '2': '₂', '3': '₃', '4': '₄', '5': '₅', '6': '₆', '7': '₇', '8': '₈', '9': '₉',
+	'+': '₊', '-': '₋', '=': '₌', '(': '₍', ')': '₎', 'a': 'ₐ', 'e': 'ₑ', 'h': 'ₕ', 'i': 'ᵢ',
+	'j': 'ⱼ', 'k': 'ₖ', 'l': 'ₗ', 'm': 'ₘ', 'n': 'ₙ', 'o': 'ₒ', 'p': 'ₚ', 'r': 'ᵣ', 's': 'ₛ',
+	't': 'ₜ', 'u': 'ᵤ', 'v': 'ᵥ', 'x': 'ₓ',
+}
+
+// Replaces $…$, $$…$$, \(…\) and \[…\] math with unicode approximations.
+// Inline math becomes a code span and block math a code block, so markdown leaves them alone
+func RenderLatex(content string) string {
+	if !strings.ContainsAny(content, "$\\") {
+		return content
+	}
+
+	result := strings.Builder{}
+	last := 0
+	for _, match := range mathCodeRegex.FindAllStringIndex(content, -1) {
+		result.WriteString(renderMathText(content[last:match[0]]))
+		result.WriteString(content[match[0]:match[1]])
+		last = match[1]
+	}
+	result.WriteString(renderMathText(content[last:]))
+	return result.String()
+}
+
+func renderMathText(text string) string {
+	text = blockMathRegex.ReplaceAllStringFunc(text, func(match string) string {
+		groups := blockMathRegex.FindStringSubmatch(match)
+		math := strings.TrimSpace(groups[1] + groups[2])
+		return "\n```\n" + ConvertLatex(math) + "\n```\n"
+	})
+
+	result := strings.Builder{}
+	last := 0
+	for _, match := range inlineMathRegex.FindAllStringSubmatchIndex(text, -1) {
+		start, end := match[0], match[1]
+		// $5 and $10, the closing dollar is followed by a price
+		if text[start] == '$' && end < len(text) && isWordByte(text[end]) {
+			continue
+		}
+
+		math := ""
+		if match[2] != -1 {
+			math = text[match[2]:match[3]]
+		} else {
+			math = text[match[4]:match[5]]
+		}
+
+		result.WriteString(text[last:start])
+		result.WriteString("`" + ConvertLatex(math) + "`")
+		last = end
+	}
+	result.WriteString(text[last:])
+	return result.String()
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// Converts a TeX formula to plain unicode text, e.g. \frac{a}{b} to a/b and x^2 to x²
+func ConvertLatex(tex string) string {
+	runes := []rune(tex)
+	result := strings.Builder{}
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch r {
+		case '\\':
+			name, next := readLatexCommand(runes, i)
+			converted, next := convertLatexCommand(name, runes, next)
+			result.WriteString(converted)
+			i = next
+
+		case '^', '_':
+			arg, next := readLatexArgument(runes, i+1)
+			result.WriteString(toScript(ConvertLatex(arg), r == '^'))
+			i = next
+
+		case '{':
+			arg, next := readLatexArgument(runes, i)
+			result.WriteString(ConvertLatex(arg))
+			i = next
+
+		case '}':
+			i++
+
+		case '&':
+			result.WriteString(" ")
+			i++
+
+		case '~':
+			result.WriteString(" ")
+			i++
+
+		default:
+			result.WriteRune(r)
+			i++
+		}
+	}
+	return collapseSpaces(result.String())
+}
+
+func convertLatexCommand(name string, runes []rune, i int) (string, int) {
+	if symbol, ok := latexSymbols[name]; ok {
+		return symbol, i
+	}
+	if latexFontCommands[name] {
+		arg, next := readLatexArgument(runes, i)
+		if set, ok := numberSets[arg]; ok && name == "mathbb" {
+			return set, next
+		}
+		if strings.HasPrefix(name, "text") || name == "operatorname" {
+			return arg, next
+		}
+		return ConvertLatex(arg), next
+	}
+	if accent, ok := latexAccents[name]; ok {
+		arg, next := readLatexArgument(runes, i)
+		return ConvertLatex(arg) + accent, next
+	}
+
+	switch name {
+	case "frac", "dfrac", "tfrac", "cfrac":
+		numerator, next := readLatexArgument(runes, i)
+		denominator, next := readLatexArgument(runes, next)
+		return wrapLatexTerm(ConvertLatex(numerator)) + "/" + wrapLatexTerm(ConvertLatex(denominator)), next
+
+	case "sqrt":
+		root := ""
+		if i < len(runes) && runes[i] == '[' {
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			root = string(runes[i+1 : min(end, len(runes))])
+			i = min(end+1, len(runes))
+		}
+		arg, next := readLatexArgument(runes, i)
+		sign := "√"
+		switch root {
+		case "":
+		case "3":
+			sign = "∛"
+		case "4":
+			sign = "∜"
+		default:
+			sign = toScript(root, true) + "√"
+		}
+		return sign + wrapLatexTerm(ConvertLatex(arg)), next
+
+	case "begin", "end":
+		_, next := readLatexArgument(runes, i)
+		return "", next
+
+	case "left", "right", "big", "Big", "bigg", "Bigg", "bigl", "bigr", "Bigl", "Bigr":
+		// \left. is an invisible delimiter
+		if i < len(runes) && runes[i] == '.' {
+			i++
+		}
+		return "", i
+
+	case "\\":
+		return "\n", i
+	case ",", ";", ":", " ":
+		return " ", i
+	case "!":
+		return "", i
+	}
+
+	// escaped characters like \{ and \$, unknown commands are printed by their names
+	return name, i
+}
+
+// Name of the command at the backslash: letters, or a single character like \{ or \\
+func readLatexCommand(runes []rune, i int) (string, int) {
+	start := i + 1
+	if start >= len(runes) {
+		return "", start
+	}
+	if !unicode.IsLetter(runes[start]) {
+		return string(runes[start]), start + 1
+	}
+
+	end := start
+	for end < len(runes) && unicode.IsLetter(runes[end]) {
+		end++
+	}
+	return string(runes[start:end]), end
+}
+
+// Argument of a command or a script: a group in braces, a command or a single character
+func readLatexArgument(runes []rune, i int) (string, int) {
+	for i < len(runes) && runes[i] == ' ' {
+		i++
+	}
+	if i >= len(runes) {
+		return "", i
+	}
+
+	switch runes[i] {
+	case '{':
+		depth := 0
+		for end := i; end < len(runes); end++ {
+			switch runes[end] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth == 0 {
+					return string(runes[i+1 : end]), end + 1
+				}
+			}
+		}
+		return string(runes[i+1:]), len(runes)
+
+	case '\\':
+		_, next := readLatexCommand(runes, i)
+		return string(runes[i:next]), next
+	}
+
+	return string(runes[i]), i + 1
+}
+
+// Super or subscript characters if all of the text has them, ^(…) or _(…) otherwise
+func toScript(text string, isSuperscript bool) string {
+	scripts, mark := subscripts, "_"
+	if isSuperscript {
+		scripts, mark = superscripts, "^"
+	}
+
+	result := []rune{}
+	for _, r := range text {
+		script, ok := scripts[r]
+		if !ok {
+			return mark + wrapLatexTerm(text)
+		}
+		result = append(result, script)
+	}
+	return string(result)
+}
+
+// Terms longer than a character are put in parentheses, so a+b over c stays (a+b)/c
+func wrapLatexTerm(term string) string {
+	term = strings.TrimSpace(term)
+	if len([]rune(term)) <= 1 || isLatexNumber(term) {
+		return term
+	}
+	if strings.HasPrefix(term, "(") && strings.HasSuffix(term, ")") {
+		return term
+	}
+	return "(" + term + ")"
+}
+
+func isLatexNumber(term string) bool {
+	for _, r := range term {
+		if !unicode.IsDigit(r) && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+func collapseSpaces(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.Join(lines, "\n")
+}
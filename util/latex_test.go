@@ -0,0 +1,84 @@
+package util
+
+import "testing"
+
+func TestConvertLatex(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Scripts",
+			input:    "x^2 + y_{i+1} = z^{n}",
+			expected: "x² + yᵢ₊₁ = zⁿ",
+		},
+		{
+			name:     "Script Without Unicode Characters",
+			input:    "e^{\\pi Q}",
+			expected: "e^(π Q)",
+		},
+		{
+			name:     "Fraction And Root",
+			input:    "\\frac{a+b}{2} \\leq \\sqrt{x}",
+			expected: "(a+b)/2 ≤ √x",
+		},
+		{
+			name:     "Sum With Limits",
+			input:    "\\sum_{i=1}^{n} i = \\frac{n(n+1)}{2}",
+			expected: "∑ᵢ₌₁ⁿ i = (n(n+1))/2",
+		},
+		{
+			name:     "Text And Sets",
+			input:    "x \\in \\mathbb{R} \\text{ if } x > 0",
+			expected: "x ∈ ℝ if x > 0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := ConvertLatex(tc.input)
+			if result != tc.expected {
+				t.Errorf("ConvertLatex(%q) = %q; expected %q", tc.input, result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestRenderLatex(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Inline Math",
+			input:    "Energy is $E = mc^2$ here",
+			expected: "Energy is `E = mc²` here",
+		},
+		{
+			name:     "Block Math",
+			input:    "Formula:\n$$\\alpha \\times \\beta$$",
+			expected: "Formula:\n\n```\nα × β\n```\n",
+		},
+		{
+			name:     "Prices Are Kept",
+			input:    "It costs $5 and $10 now",
+			expected: "It costs $5 and $10 now",
+		},
+		{
+			name:     "Code Is Kept",
+			input:    "Run `echo $HOME$` and\n```sh\necho $x$\n```",
+			expected: "Run `echo $HOME$` and\n```sh\necho $x$\n```",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := RenderLatex(tc.input)
+			if result != tc.expected {
+				t.Errorf("RenderLatex(%q) = %q; expected %q", tc.input, result, tc.expected)
+			}
+		})
+	}
+}
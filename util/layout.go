@@ -0,0 +1,69 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+)
+
+// Max width of rendered messages, zero means the width of the pane
+var contentMaxWidth int
+
+// Long lines of code blocks are wrapped and tables are squeezed to the pane width by default.
+// Without wrapping they keep their layout and the lines wider than the pane are scrolled horizontally
+var wrapCodeBlocks = true
+
+// Code fences and tables. An unclosed fence runs to the end, as it does while a response is streamed
+var wideBlockRegex = regexp.MustCompile("(?s:```[^\\n]*\\n.*?(?:```|$))|(?m:(?:^[ \\t]*\\|[^\\n]*\\|[ \\t]*(?:\\n|$)){2,})")
+
+func SetContentLayout(maxWidth int, wrapCode bool) {
+	contentMaxWidth = maxWidth
+	wrapCodeBlocks = wrapCode
+}
+
+func getContentWidth(paneWidth int) int {
+	if contentMaxWidth == 0 {
+		return paneWidth
+	}
+	return min(paneWidth, contentMaxWidth)
+}
+
+// Renders the markdown with the renderer. With code wrapping disabled code blocks
+// and tables are rendered separately by a renderer that does not wrap lines
+func renderMarkdown(renderer *glamour.TermRenderer, content string, colors SchemeColors) string {
+	if wrapCodeBlocks || !strings.ContainsAny(content, "`|") {
+		output, _ := renderer.Render(content)
+		return output
+	}
+
+	blockRenderer, _ := glamour.NewTermRenderer(
+		glamour.WithPreservedNewLines(),
+		glamour.WithWordWrap(0),
+		colors.RendererThemeOption,
+	)
+
+	parts := []string{}
+	render := func(r *glamour.TermRenderer, text string) {
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		output, _ := r.Render(text)
+		parts = append(parts, strings.Trim(output, "\n"))
+	}
+
+	last := 0
+	for _, match := range wideBlockRegex.FindAllStringIndex(content, -1) {
+		render(renderer, content[last:match[0]])
+		block := content[match[0]:match[1]]
+		render(blockRenderer, block)
+		// unlike code blocks, tables are rendered without the margin below
+		if !strings.HasPrefix(block, "```") {
+			parts[len(parts)-1] += "\n"
+		}
+		last = match[1]
+	}
+	render(renderer, content[last:])
+
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,14 @@
+package util
+
+// Spinners, the blinking cursor and the constant redraws are turned off in reduced motion mode,
+// the status is shown as static text. Useful for recording terminal clips and for motion
+// sensitive users. Set once on startup
+var reducedMotion bool
+
+func SetReducedMotion(enabled bool) {
+	reducedMotion = enabled
+}
+
+func IsReducedMotion() bool {
+	return reducedMotion
+}
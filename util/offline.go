@@ -0,0 +1,50 @@
+package util
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+var defaultOfflineHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+// Local-only mode: requests to hosts that are not allowlisted are blocked,
+// so no data leaves the machine when working with confidential material
+var (
+	offlineMode  bool
+	allowedHosts []string
+)
+
+func SetOfflineMode(enabled bool, allowlist []string) {
+	offlineMode = enabled
+	allowedHosts = append(slices.Clone(defaultOfflineHosts), allowlist...)
+}
+
+func IsOfflineMode() bool {
+	return offlineMode
+}
+
+// Returns an error if offline mode is on and the host of the url is not allowlisted
+func CheckHostAllowed(rawUrl string) error {
+	if !offlineMode {
+		return nil
+	}
+
+	parsedUrl, err := url.Parse(rawUrl)
+	if err != nil || parsedUrl.Hostname() == "" {
+		return fmt.Errorf("offline mode: blocked a request to an invalid url %q", rawUrl)
+	}
+
+	host := strings.ToLower(parsedUrl.Hostname())
+	for _, allowed := range allowedHosts {
+		if strings.EqualFold(host, allowed) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf(
+		"offline mode: blocked a request to %s. Allowed hosts: %s",
+		host,
+		strings.Join(allowedHosts, ", "))
+}
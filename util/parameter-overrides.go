@@ -0,0 +1,68 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var parameterPrefixRegex = regexp.MustCompile(`^\s*/(temp|top_p|max_tokens)[ \t]+(\S+)\s*`)
+
+// Request parameters set for a single prompt with prefixes like `/temp 0.2`
+type ParameterOverrides struct {
+	Temperature *float32
+	TopP        *float32
+	MaxTokens   *int
+}
+
+// Reads the parameter prefixes at the start of the prompt and returns the prompt without them.
+// Several prefixes can be combined, e.g. `/temp 0.2 /max_tokens 500 question`
+func ParseParameterOverrides(prompt string) (ParameterOverrides, string, error) {
+	overrides := ParameterOverrides{}
+	rest := prompt
+
+	for {
+		match := parameterPrefixRegex.FindStringSubmatch(rest)
+		if match == nil {
+			return overrides, rest, nil
+		}
+
+		name, value := match[1], match[2]
+		switch name {
+		case "temp":
+			if err := TemperatureValidator(value); err != nil {
+				return ParameterOverrides{}, prompt, fmt.Errorf("invalid /temp value %q, must be in %s", value, TemperatureRange)
+			}
+			temperature := parseFloat32(value)
+			overrides.Temperature = &temperature
+		case "top_p":
+			if err := TopPValidator(value); err != nil {
+				return ParameterOverrides{}, prompt, fmt.Errorf("invalid /top_p value %q, must be in %s", value, TopPRange)
+			}
+			topP := parseFloat32(value)
+			overrides.TopP = &topP
+		case "max_tokens":
+			maxTokens, err := strconv.Atoi(value)
+			if err != nil || maxTokens <= 0 {
+				return ParameterOverrides{}, prompt, fmt.Errorf("invalid /max_tokens value %q, must be a positive number", value)
+			}
+			overrides.MaxTokens = &maxTokens
+		}
+
+		rest = rest[len(match[0]):]
+	}
+}
+
+// Prompt as it is sent to the provider
+func StripParameterOverrides(prompt string) string {
+	_, rest, err := ParseParameterOverrides(prompt)
+	if err != nil {
+		return prompt
+	}
+	return rest
+}
+
+func parseFloat32(value string) float32 {
+	parsed, _ := strconv.ParseFloat(value, 32)
+	return float32(parsed)
+}
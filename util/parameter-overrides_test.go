@@ -0,0 +1,65 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseParameterOverrides(t *testing.T) {
+	temperature := float32(0.2)
+	topP := float32(0.9)
+	maxTokens := 500
+
+	testCases := []struct {
+		name      string
+		prompt    string
+		overrides ParameterOverrides
+		rest      string
+		isError   bool
+	}{
+		{
+			name:   "No Prefixes",
+			prompt: "what is /temp 0.2",
+			rest:   "what is /temp 0.2",
+		},
+		{
+			name:      "Temperature",
+			prompt:    "/temp 0.2 hello",
+			overrides: ParameterOverrides{Temperature: &temperature},
+			rest:      "hello",
+		},
+		{
+			name:      "Combined Prefixes",
+			prompt:    "/top_p 0.9 /max_tokens 500\nhello",
+			overrides: ParameterOverrides{TopP: &topP, MaxTokens: &maxTokens},
+			rest:      "hello",
+		},
+		{
+			name:    "Out Of Range",
+			prompt:  "/temp 3 hello",
+			rest:    "/temp 3 hello",
+			isError: true,
+		},
+		{
+			name:    "Invalid Max Tokens",
+			prompt:  "/max_tokens many hello",
+			rest:    "/max_tokens many hello",
+			isError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			overrides, rest, err := ParseParameterOverrides(tc.prompt)
+			if (err != nil) != tc.isError {
+				t.Fatalf("ParseParameterOverrides(%q) error = %v; want error %v", tc.prompt, err, tc.isError)
+			}
+			if rest != tc.rest {
+				t.Errorf("ParseParameterOverrides(%q) rest = %q; want %q", tc.prompt, rest, tc.rest)
+			}
+			if !reflect.DeepEqual(overrides, tc.overrides) {
+				t.Errorf("ParseParameterOverrides(%q) = %+v; want %+v", tc.prompt, overrides, tc.overrides)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Persona of the current session, responses are labeled with its name instead of the generic icon
+var currentPersona *Persona
+
+var personaColorRegex = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+func SetPersona(persona *Persona) {
+	currentPersona = persona
+}
+
+func IsValidPersonaColor(color string) bool {
+	return personaColorRegex.MatchString(color)
+}
+
+// Parses a persona definition from the input: the name optionally followed by its color, e.g. "Reviewer #ff8800"
+func ParsePersonaInput(input string) (string, string) {
+	input = strings.TrimSpace(input)
+	idx := strings.LastIndex(input, " ")
+	if idx == -1 || !IsValidPersonaColor(input[idx+1:]) {
+		return input, ""
+	}
+	return strings.TrimSpace(input[:idx]), input[idx+1:]
+}
+
+// Colors the persona name the markdown renderer put in place of the icon. The name is
+// rendered as plain text first, so the lines are wrapped with its real width
+func colorPersonaLabel(output string, colors SchemeColors) string {
+	var color lipgloss.TerminalColor = colors.AccentColor
+	if currentPersona.Color != "" {
+		color = lipgloss.Color(currentPersona.Color)
+	}
+
+	label := lipgloss.NewStyle().Bold(true).Foreground(color).Render(currentPersona.Name)
+	return strings.Replace(output, currentPersona.Name, label, 1)
+}
@@ -0,0 +1,57 @@
+package util
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+const maxPinnedPreviewWidth = 120
+
+var pinnedMarker = " 📌"
+
+// Only prompts and answers can be pinned, tool calls and empty messages have nothing to show
+func IsPinnable(msg LocalStoreMessage) bool {
+	return (msg.Role == "user" || msg.Role == "assistant") && strings.TrimSpace(msg.Content) != ""
+}
+
+// Index of the latest message that can be pinned, -1 if there is none
+func GetLastPinnableIndex(messages []LocalStoreMessage) int {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if IsPinnable(messages[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Index of the message a line of the rendered messages belongs to, -1 if the line is before them
+func GetMessageIndexAtLine(offsets []int, line int) int {
+	for i := len(offsets) - 1; i >= 0; i-- {
+		if offsets[i] <= line {
+			return i
+		}
+	}
+	return -1
+}
+
+// One line summary of a pinned message: role icon and the first non empty line of the content
+func GetPinnedPreview(msg LocalStoreMessage) string {
+	icon := assistantIcon
+	if msg.Role == "user" {
+		icon = userIcon
+	}
+	if icon != "" {
+		icon += " "
+	}
+
+	preview := ""
+	for line := range strings.SplitSeq(msg.Content, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			preview = line
+			break
+		}
+	}
+
+	return icon + ansi.Truncate(preview, maxPinnedPreviewWidth, "…")
+}
@@ -6,6 +6,7 @@ import (
 	"net/netip"
 	"net/url"
 	"slices"
+	"strconv"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -21,6 +22,33 @@ type LlmClient interface {
 	RequestModelsList(ctx context.Context) ProcessModelsResponse
 }
 
+type contextKey string
+
+const (
+	sessionIdKey contextKey = "sessionId"
+	requestIdKey contextKey = "requestId"
+)
+
+// Passes the session of a completion request to the client, e.g. to reuse files uploaded for the session
+func WithSessionId(ctx context.Context, id int) context.Context {
+	return context.WithValue(ctx, sessionIdKey, id)
+}
+
+func SessionIdFromContext(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(sessionIdKey).(int)
+	return id, ok
+}
+
+// Chunks carry the request they belong to, chunks of a cancelled or finished request are told apart from the current ones
+func WithRequestId(ctx context.Context, id int) context.Context {
+	return context.WithValue(ctx, requestIdKey, id)
+}
+
+func RequestIdFromContext(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(requestIdKey).(int)
+	return id, ok
+}
+
 // `Exclusion keywords` filter out models that contain any of the specified in their names
 // `Prefixes` allow models to be used in app IF model name starts with any of the specidied
 // Theses two can be used together, but `exclusion keywords` take presedence over `prefixes`
@@ -45,11 +73,17 @@ var (
 		"learnlm",
 	}
 	mistralExclusionKeywords = []string{"pixtral", "embed", "voxtral"}
+	xAiExclusionKeywords     = []string{"image"}
 )
 
+// Perplexity API has no models endpoint
+var perplexityModels = []string{"sonar", "sonar-pro", "sonar-reasoning", "sonar-reasoning-pro", "sonar-deep-research"}
+
 var (
 	openAiApiPrefixes  = []string{"api.openai.com"}
 	mistralApiPrefixes = []string{"api.mistral.ai"}
+	xAiApiPrefixes     = []string{"api.x.ai"}
+	perplexityPrefixes = []string{"api.perplexity.ai"}
 	localApiPrefixes   = []string{"localhost", "127.0.0.1", "::1", "192.168", "10.", "172."}
 )
 
@@ -57,6 +91,41 @@ const (
 	OpenAiProviderType     = "openai"
 	GeminiProviderType     = "gemini"
 	OpenrouterProviderType = "openrouter"
+	VertexProviderType     = "vertex"
+	BedrockProviderType    = "bedrock"
+)
+
+// Profiles of OpenAI compatible servers with streaming differences:
+// missing finish_reason, non standard finish reasons, no [DONE] at the end of a stream
+const (
+	LmStudioProfile = "lmstudio"
+	LlamaCppProfile = "llamacpp"
+)
+
+var ProviderProfiles = []string{LmStudioProfile, LlamaCppProfile}
+
+// Backends of the gemini provider: Gemini API with an API key or Vertex AI with Google Cloud credentials
+const (
+	GeminiApiBackend = "gemini"
+	VertexBackend    = "vertex"
+)
+
+// Gemini harm categories and block thresholds of the geminiSafetySettings config option
+const (
+	HarassmentCategory       = "harassment"
+	HateSpeechCategory       = "hateSpeech"
+	SexuallyExplicitCategory = "sexuallyExplicit"
+	DangerousContentCategory = "dangerousContent"
+
+	BlockNoneThreshold           = "none"
+	BlockOnlyHighThreshold       = "onlyHigh"
+	BlockMediumAndAboveThreshold = "mediumAndAbove"
+	BlockLowAndAboveThreshold    = "lowAndAbove"
+)
+
+var (
+	SafetyCategories = []string{HarassmentCategory, HateSpeechCategory, SexuallyExplicitCategory, DangerousContentCategory}
+	SafetyThresholds = []string{BlockNoneThreshold, BlockOnlyHighThreshold, BlockMediumAndAboveThreshold, BlockLowAndAboveThreshold}
 )
 
 type ApiProvider int
@@ -67,23 +136,33 @@ const (
 	Mistral
 	Gemini
 	Openrouter
+	Vertex
+	Bedrock
+	XAi
+	Perplexity
 )
 
-func GetNextProcessResultId(chatMsgs []LocalStoreMessage) int {
-	if len(chatMsgs) <= 1 {
-		return ChunkIndexStart
-	}
+type SamplingParam int
 
-	// 10 is arbitrary, just to increase ID for avoiding IDs overlapping and skipping chunks
-	return len(chatMsgs) + 10
-}
+const (
+	MaxTokensParam SamplingParam = iota
+	TemperatureParam
+	FrequencyParam
+	TopPParam
+)
 
 func GetFilteredModelList(providerType string, apiUrl string, models []string) []string {
 	var modelNames []string
 
 	switch providerType {
-	case OpenrouterProviderType:
+	case OpenrouterProviderType, BedrockProviderType:
 		return models
+	case VertexProviderType:
+		for _, model := range models {
+			if IsAnthropicModel(model) || isGeminiChatModel(model) {
+				modelNames = append(modelNames, model)
+			}
+		}
 	case OpenAiProviderType:
 		modelNames = filterOpenAiApiModels(apiUrl, models)
 	case GeminiProviderType:
@@ -112,12 +191,39 @@ func filterOpenAiApiModels(apiUrl string, models []string) []string {
 			if isMistralChatModel(model) {
 				modelNames = append(modelNames, model)
 			}
+		case XAi:
+			if !containsAny(model, xAiExclusionKeywords) {
+				modelNames = append(modelNames, model)
+			}
+		case Perplexity:
+			modelNames = append(modelNames, model)
 		}
 	}
 
 	return modelNames
 }
 
+// Ways to send the system prompt: with the system role or at the start of the first user message
+const (
+	SystemMessageAsSystem = "system"
+	SystemMessageAsUser   = "user"
+)
+
+var SystemMessageModes = []string{SystemMessageAsSystem, SystemMessageAsUser}
+
+// The mode set for the model in the config takes precedence over the detection.
+// An empty mode means the system prompt is not sent
+func GetSystemMessageMode(provider ApiProvider, model string, overrides map[string]string) string {
+	if mode, ok := overrides[model]; ok {
+		return mode
+	}
+
+	if IsSystemMessageSupported(provider, model) {
+		return SystemMessageAsSystem
+	}
+	return ""
+}
+
 func IsSystemMessageSupported(provider ApiProvider, model string) bool {
 
 	switch provider {
@@ -133,6 +239,14 @@ func IsSystemMessageSupported(provider ApiProvider, model string) bool {
 	return true
 }
 
+func IsSamplingParamSupported(provider ApiProvider, param SamplingParam) bool {
+	switch provider {
+	case Gemini, Vertex, Bedrock:
+		return param != FrequencyParam
+	}
+	return true
+}
+
 func TransformRequestHeaders(provider ApiProvider, params map[string]any) map[string]any {
 	switch provider {
 
@@ -160,6 +274,17 @@ func TransformRequestHeaders(provider ApiProvider, params map[string]any) map[st
 
 		return params
 	case Mistral:
+		return params
+	case XAi:
+		params["stream_options"] = map[string]any{
+			"include_usage": true,
+		}
+
+		// Reasoning models of xAI reject penalties
+		if isXAiReasoningModel(params["model"].(string)) {
+			delete(params, "frequency_penalty")
+		}
+
 		return params
 	}
 
@@ -172,6 +297,10 @@ func GetOpenAiInferenceProvider(providerType string, apiUrl string) ApiProvider
 		return Openrouter
 	case GeminiProviderType:
 		return Gemini
+	case VertexProviderType:
+		return Vertex
+	case BedrockProviderType:
+		return Bedrock
 	case OpenAiProviderType:
 		if slices.ContainsFunc(openAiApiPrefixes, func(p string) bool {
 			return strings.Contains(apiUrl, p)
@@ -185,6 +314,18 @@ func GetOpenAiInferenceProvider(providerType string, apiUrl string) ApiProvider
 			return Mistral
 		}
 
+		if slices.ContainsFunc(xAiApiPrefixes, func(p string) bool {
+			return strings.Contains(apiUrl, p)
+		}) {
+			return XAi
+		}
+
+		if slices.ContainsFunc(perplexityPrefixes, func(p string) bool {
+			return strings.Contains(apiUrl, p)
+		}) {
+			return Perplexity
+		}
+
 		if IsLocalProvider(apiUrl) {
 			return Local
 		}
@@ -235,6 +376,11 @@ func isGeminiChatModel(model string) bool {
 	return true
 }
 
+// Claude models, e.g. claude-sonnet-4@20250514 on Vertex AI or us.anthropic.claude-sonnet-4-20250514-v1:0 on Bedrock
+func IsAnthropicModel(model string) bool {
+	return strings.Contains(strings.ToLower(model), "claude")
+}
+
 func isOpenAiChatModel(model string) bool {
 	for _, keyword := range openAiExclusionKeywords {
 		if strings.Contains(model, keyword) {
@@ -261,6 +407,19 @@ func isMistralChatModel(model string) bool {
 	return true
 }
 
+func isXAiReasoningModel(model string) bool {
+	return (strings.HasPrefix(model, "grok-4") || strings.HasPrefix(model, "grok-3-mini")) &&
+		!strings.Contains(model, "non-reasoning")
+}
+
+// Returns the models of providers that can't list them
+func GetStaticModelList(provider ApiProvider) ([]string, bool) {
+	if provider == Perplexity {
+		return perplexityModels, true
+	}
+	return nil, false
+}
+
 func isOpenAiReasoningModel(model string) bool {
 	return strings.HasPrefix(model, "o")
 }
@@ -268,3 +427,41 @@ func isOpenAiReasoningModel(model string) bool {
 func isOpenAiGpt5Model(model string) bool {
 	return strings.HasPrefix(model, "gpt-5")
 }
+
+const ReasoningBudgetOff = "off"
+
+var ReasoningEfforts = []string{"low", "medium", "high"}
+
+// Thinking tokens used by providers that expect a number when the budget is set as an effort level
+var reasoningEffortTokens = map[string]int{
+	"low":    1024,
+	"medium": 8192,
+	"high":   24576,
+}
+
+// Reasoning budget of a preset is either an effort level or a number of thinking tokens.
+// Returns the effort level, a number of tokens is mapped to the closest one
+func GetReasoningEffort(budget string) string {
+	if slices.Contains(ReasoningEfforts, budget) {
+		return budget
+	}
+
+	tokens, _ := strconv.Atoi(budget)
+	switch {
+	case tokens <= reasoningEffortTokens["low"]:
+		return "low"
+	case tokens <= reasoningEffortTokens["medium"]:
+		return "medium"
+	}
+	return "high"
+}
+
+// Returns the number of thinking tokens, an effort level is mapped to a default budget
+func GetReasoningTokens(budget string) int {
+	if tokens, ok := reasoningEffortTokens[budget]; ok {
+		return tokens
+	}
+
+	tokens, _ := strconv.Atoi(budget)
+	return tokens
+}
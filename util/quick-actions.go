@@ -0,0 +1,30 @@
+package util
+
+import (
+	"github.com/BalanceBalls/nekot/i18n"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Canned follow-ups applied to the last response, sent as the next turn
+const (
+	SummarizeAction     = "summarize"
+	ExplainSimplyAction = "explainSimply"
+	TranslateAction     = "translate"
+	ShortenAction       = "shorten"
+)
+
+var QuickActions = []string{SummarizeAction, ExplainSimplyAction, TranslateAction, ShortenAction}
+
+func GetQuickActionTitle(action string) string {
+	return i18n.T("quickActions." + action)
+}
+
+type QuickActionChosen struct {
+	Action string
+}
+
+func SendQuickActionMsg(action string) tea.Cmd {
+	return func() tea.Msg {
+		return QuickActionChosen{Action: action}
+	}
+}
@@ -0,0 +1,36 @@
+package util
+
+import "strings"
+
+const (
+	RatingUp   = 1
+	RatingDown = -1
+)
+
+var ratingUpMarker = " 👍"
+var ratingDownMarker = " 👎"
+
+// Only answers can be rated, tool calls and empty messages have nothing to rate
+func IsRateable(msg LocalStoreMessage) bool {
+	return msg.Role == "assistant" && len(msg.ToolCalls) == 0 && strings.TrimSpace(msg.Content) != ""
+}
+
+// Index of the latest answer that can be rated, -1 if there is none
+func GetLastRateableIndex(messages []LocalStoreMessage) int {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if IsRateable(messages[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+func getRatingMarker(msg LocalStoreMessage) string {
+	switch msg.Rating {
+	case RatingUp:
+		return ratingUpMarker
+	case RatingDown:
+		return ratingDownMarker
+	}
+	return ""
+}
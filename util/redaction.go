@@ -0,0 +1,57 @@
+package util
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Formats masked in prompts when prompt redaction is enabled, on top of the API key formats
+var promptSecretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`(?i)(aws_secret_access_key\s*[=:]\s*["']?)[A-Za-z0-9/+=]{40}`),
+}
+
+// Masks secrets in a prompt before it leaves the machine: API keys, emails, AWS keys
+// and matches of the user patterns. Returns the prompt and the number of masked secrets
+func RedactPromptSecrets(prompt string, userPatterns []string) (string, int) {
+	count := 0
+	for _, envVar := range apiKeyEnvVars {
+		value := os.Getenv(envVar)
+		if len(value) >= 8 {
+			count += strings.Count(prompt, value)
+			prompt = strings.ReplaceAll(prompt, value, RedactedPlaceholder)
+		}
+	}
+
+	for _, pattern := range append(append([]*regexp.Regexp{}, secretPatterns...), promptSecretPatterns...) {
+		prompt = redactMatches(prompt, pattern, pattern.NumSubexp() > 0, &count)
+	}
+
+	for _, pattern := range userPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			Slog.Warn("skipping invalid redaction pattern", "pattern", pattern, "error", err.Error())
+			continue
+		}
+		prompt = redactMatches(prompt, re, false, &count)
+	}
+
+	return prompt, count
+}
+
+// Built-in patterns capture a prefix like `Bearer ` in the first group, it is kept so the redacted text stays readable
+func redactMatches(text string, pattern *regexp.Regexp, keepPrefix bool, count *int) string {
+	return pattern.ReplaceAllStringFunc(text, func(match string) string {
+		if strings.Contains(match, RedactedPlaceholder) {
+			return match
+		}
+
+		*count++
+		if keepPrefix {
+			return pattern.ReplaceAllString(match, "${1}"+RedactedPlaceholder)
+		}
+		return RedactedPlaceholder
+	})
+}
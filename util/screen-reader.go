@@ -0,0 +1,76 @@
+package util
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/BalanceBalls/nekot/i18n"
+)
+
+// Screen readers read what changes on the terminal line by line, spinners, borders and
+// colored labels are read as noise. In screen reader mode the state is shown as plain text
+// lines and the chat can be read as a linear log. The mode also turns on ASCII and reduced motion modes
+var screenReaderMode bool
+
+func SetScreenReaderMode(enabled bool) {
+	screenReaderMode = enabled
+}
+
+func IsScreenReaderMode() bool {
+	return screenReaderMode
+}
+
+// Messages as plain text, one after another: a numbered header with the speaker
+// followed by the content as the model wrote it. Tool results are left out
+func FormatMessageLog(messages []LocalStoreMessage) string {
+	entries := []LocalStoreMessage{}
+	for _, message := range messages {
+		if message.Role == "user" || message.Role == "assistant" {
+			entries = append(entries, message)
+		}
+	}
+
+	log := []string{}
+	for i, message := range entries {
+		lines := []string{i18n.Tf("log.header", i+1, len(entries), getLogSpeaker(message))}
+
+		if content := strings.TrimSpace(message.Content); content != "" {
+			lines = append(lines, content)
+		}
+
+		if len(message.Attachments) > 0 {
+			names := []string{}
+			for _, attachment := range message.Attachments {
+				names = append(names, filepath.Base(attachment.Path))
+			}
+			lines = append(lines, i18n.Tf("log.attachments", strings.Join(names, ", ")))
+		}
+
+		if len(message.ToolCalls) > 0 {
+			names := []string{}
+			for _, call := range message.ToolCalls {
+				names = append(names, call.Function.Name)
+			}
+			lines = append(lines, i18n.Tf("log.toolCalls", strings.Join(names, ", ")))
+		}
+
+		log = append(log, strings.Join(lines, "\n"))
+	}
+	return strings.Join(log, "\n\n")
+}
+
+func getLogSpeaker(message LocalStoreMessage) string {
+	if message.Role == "user" {
+		return userLabel
+	}
+
+	model := message.Model
+	if model == "" {
+		model = i18n.T("log.assistant")
+	}
+
+	if currentPersona != nil {
+		return currentPersona.Name + " (" + model + ")"
+	}
+	return model
+}
@@ -2,6 +2,7 @@ package util
 
 import (
 	_ "embed"
+	"strings"
 
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
@@ -83,6 +84,24 @@ type SchemeColors struct {
 	RendererThemeOption  glamour.TermRendererOption
 }
 
+// Markdown glyphs of the themes and their ASCII replacements
+var asciiThemeReplacer = strings.NewReplacer(
+	"→", "->",
+	"✓", "x",
+	"🠶", ">",
+	"•", "*",
+	"─", "-",
+	"│", "|",
+	"┼", "+",
+)
+
+func getRendererThemeOption(themeBytes []byte) glamour.TermRendererOption {
+	if asciiMode {
+		themeBytes = []byte(asciiThemeReplacer.Replace(string(themeBytes)))
+	}
+	return glamour.WithStylesFromJSONBytes(themeBytes)
+}
+
 func (s ColorScheme) GetColors() SchemeColors {
 	defaultThemeBytes := pinkThemeBytes
 	if !lipgloss.HasDarkBackground() {
@@ -96,7 +115,7 @@ func (s ColorScheme) GetColors() SchemeColors {
 		ErrorColor:           lipgloss.AdaptiveColor{Dark: pinkThemeRed, Light: pinkThemeRed},
 		NormalTabBorderColor: lipgloss.AdaptiveColor{Dark: pinkThemeLightGrey, Light: pinkThemeDarkGreyLight},
 		ActiveTabBorderColor: lipgloss.AdaptiveColor{Dark: pinkThemeSolidPink, Light: pinkThemeSolidPink},
-		RendererThemeOption:  glamour.WithStylesFromJSONBytes(defaultThemeBytes),
+		RendererThemeOption:  getRendererThemeOption(defaultThemeBytes),
 	}
 
 	switch s {
@@ -113,7 +132,7 @@ func (s ColorScheme) GetColors() SchemeColors {
 			ErrorColor:           lipgloss.AdaptiveColor{Dark: blueThemeRed, Light: blueThemeRed},
 			NormalTabBorderColor: lipgloss.AdaptiveColor{Dark: blueThemeSmoothBlue, Light: blueThemeSmoothBlue},
 			ActiveTabBorderColor: lipgloss.AdaptiveColor{Dark: blueThemePinkYellow, Light: blueThemePinkYellowLight},
-			RendererThemeOption:  glamour.WithStylesFromJSONBytes(themeBytes),
+			RendererThemeOption:  getRendererThemeOption(themeBytes),
 		}
 
 	case Groovebox:
@@ -129,7 +148,7 @@ func (s ColorScheme) GetColors() SchemeColors {
 			ErrorColor:           lipgloss.AdaptiveColor{Dark: grooveboxRed, Light: grooveboxRedLight},
 			NormalTabBorderColor: lipgloss.AdaptiveColor{Dark: grooveboxYellow, Light: grooveboxYellowLight},
 			ActiveTabBorderColor: lipgloss.AdaptiveColor{Dark: grooveboxGreen, Light: grooveboxGreenLight},
-			RendererThemeOption:  glamour.WithStylesFromJSONBytes(themeBytes),
+			RendererThemeOption:  getRendererThemeOption(themeBytes),
 		}
 
 	case OriginalPink:
@@ -137,7 +156,7 @@ func (s ColorScheme) GetColors() SchemeColors {
 		if !lipgloss.HasDarkBackground() {
 			themeBytes = pinkLightThemeBytes
 		}
-		defaultColors.RendererThemeOption = glamour.WithStylesFromJSONBytes(themeBytes)
+		defaultColors.RendererThemeOption = getRendererThemeOption(themeBytes)
 		return defaultColors
 
 	default:
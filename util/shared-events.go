@@ -1,6 +1,8 @@
 package util
 
 import (
+	"errors"
+	"net/http"
 	"slices"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -16,6 +18,7 @@ const (
 type Pane int
 type AsyncDependency int
 type Notification int
+type Severity int
 
 // fake enum to keep tab of the currently focused pane
 const (
@@ -37,6 +40,15 @@ const (
 	PresetSavedNotification
 	SessionSavedNotification
 	SessionExportedNotification
+	ConfigSavedNotification
+	NoteSavedNotification
+)
+
+const (
+	InfoSeverity Severity = iota
+	SuccessSeverity
+	WarningSeverity
+	ErrorSeverity
 )
 
 type ViewMode int
@@ -58,6 +70,7 @@ const (
 var (
 	NormalFocusPanes = []Pane{SettingsPane, SessionsPane, PromptPane, ChatPane}
 	ZenFocusPanes    = []Pane{PromptPane, ChatPane}
+	DrawerFocusPanes = []Pane{SettingsPane, SessionsPane, PromptPane}
 )
 
 func IsFocusAllowed(mode ViewMode, pane Pane, tw int) bool {
@@ -94,6 +107,9 @@ func getFocuesPanes(mode ViewMode, pane Pane, tw int) []Pane {
 		focusPanes = NormalFocusPanes
 		if tw < WidthMinScalingLimit {
 			focusPanes = ZenFocusPanes
+			if sidebarDrawerOpen {
+				focusPanes = DrawerFocusPanes
+			}
 		}
 	case ZenMode:
 		focusPanes = ZenFocusPanes
@@ -153,7 +169,11 @@ func MakeFocusMsg(v bool) tea.Msg {
 }
 
 type ErrorEvent struct {
-	Message string
+	Message     string
+	Recoverable bool
+	Details     *ApiError
+	// Set for errors of the active completion request, only they end the request and put the prompt back
+	IsRequestFailure bool
 }
 
 func MakeErrorMsg(v string) tea.Cmd {
@@ -163,6 +183,72 @@ func MakeErrorMsg(v string) tea.Cmd {
 	}
 }
 
+// Keeps provider request details for the error inspector, if the error has them
+func MakeErrorMsgFromErr(err error, model string) tea.Cmd {
+	Slog.Error(err.Error())
+
+	event := ErrorEvent{Message: err.Error()}
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
+		details := *apiErr
+		if details.Model == "" {
+			details.Model = model
+		}
+		event.Details = &details
+	}
+
+	return func() tea.Msg {
+		return event
+	}
+}
+
+// Makes an error that does not break the app state, it is shown as a toast
+func MakeRecoverableErrorMsg(v string) tea.Cmd {
+	Slog.Error(v)
+	return func() tea.Msg {
+		return ErrorEvent{Message: v, Recoverable: true}
+	}
+}
+
+// Errors returned by providers for a single request (rate limits, bad requests, outages)
+// do not break the app state, so the conversation can be continued
+func (e ErrorEvent) IsRecoverable() bool {
+	if e.Recoverable {
+		return true
+	}
+
+	if e.Details == nil || e.Details.StatusCode == 0 {
+		return false
+	}
+
+	code := e.Details.StatusCode
+	return code == http.StatusBadRequest ||
+		code == http.StatusNotFound ||
+		code == http.StatusRequestTimeout ||
+		code == http.StatusRequestEntityTooLarge ||
+		code == http.StatusTooManyRequests ||
+		code >= http.StatusInternalServerError
+}
+
+// Errors of a request without a response status, e.g. network failures or broken streams, are recoverable.
+// Errors with a status are recoverable depending on it, an invalid api key is not
+func MarkRequestFailure(event ErrorEvent) ErrorEvent {
+	event.IsRequestFailure = true
+	event.Recoverable = event.Details == nil || event.Details.StatusCode == 0
+	return event
+}
+
+// Puts a prompt back to the prompt pane, e.g. after a failed request
+type RestorePromptMsg struct {
+	Prompt string
+}
+
+func SendRestorePromptMsg(prompt string) tea.Cmd {
+	return func() tea.Msg {
+		return RestorePromptMsg{Prompt: prompt}
+	}
+}
+
 type NotificationMsg struct {
 	Notification Notification
 }
@@ -173,6 +259,18 @@ func SendNotificationMsg(notification Notification) tea.Cmd {
 	}
 }
 
+// A transient message with arbitrary text, shown as a toast and kept in the notifications history
+type ToastMsg struct {
+	Text     string
+	Severity Severity
+}
+
+func SendToastMsg(text string, severity Severity) tea.Cmd {
+	return func() tea.Msg {
+		return ToastMsg{Text: text, Severity: severity}
+	}
+}
+
 type CopyLastMsg struct{}
 
 func SendCopyLastMsg() tea.Msg {
@@ -185,6 +283,64 @@ func SendCopyAllMsgs() tea.Msg {
 	return CopyAllMsgs{}
 }
 
+// Replaces the prompt input with the text, e.g. a chosen follow-up suggestion
+type InsertPromptMsg struct {
+	Prompt string
+}
+
+func SendInsertPromptMsg(prompt string) tea.Cmd {
+	return func() tea.Msg {
+		return InsertPromptMsg{Prompt: prompt}
+	}
+}
+
+// Appends the text to the prompt as a quote to ask about it
+type QuotePromptMsg struct {
+	Text string
+}
+
+func SendQuotePromptMsg(text string) tea.Cmd {
+	return func() tea.Msg {
+		return QuotePromptMsg{Text: text}
+	}
+}
+
+// Links to choose from and open in the browser
+type OpenLinksMsg struct {
+	Urls []string
+}
+
+func SendOpenLinksMsg(urls []string) tea.Cmd {
+	return func() tea.Msg {
+		return OpenLinksMsg{Urls: urls}
+	}
+}
+
+type SaveToNotesMsg struct {
+	WholeSession bool
+	// A part of a response to save instead of the whole response, e.g. a code block
+	Text string
+}
+
+func SendSaveToNotesMsg(wholeSession bool) tea.Cmd {
+	return func() tea.Msg {
+		return SaveToNotesMsg{WholeSession: wholeSession}
+	}
+}
+
+func SendSaveTextToNotesMsg(text string) tea.Cmd {
+	return func() tea.Msg {
+		return SaveToNotesMsg{Text: text}
+	}
+}
+
+// Saves mermaid diagrams of the last response and renders them if a renderer is available
+type ExportDiagramsMsg struct{}
+
+func SendExportDiagramsMsg() tea.Msg {
+	return ExportDiagramsMsg{}
+}
+
 type ViewModeChanged struct {
 	Mode ViewMode
 }
@@ -195,6 +351,16 @@ func SendViewModeChangedMsg(mode ViewMode) tea.Cmd {
 	}
 }
 
+type MacroRecordingChanged struct {
+	IsRecording bool
+}
+
+func SendMacroRecordingChangedMsg(isRecording bool) tea.Cmd {
+	return func() tea.Msg {
+		return MacroRecordingChanged{IsRecording: isRecording}
+	}
+}
+
 type SwitchToPaneMsg struct {
 	Target Pane
 }
@@ -211,6 +377,117 @@ type OpenTextEditorMsg struct {
 	IsFocused bool
 }
 
+type ConfigEditorToggled struct {
+	IsOpen bool
+	Option string
+}
+
+func ToggleConfigEditor(isOpen bool) tea.Cmd {
+	return func() tea.Msg {
+		return ConfigEditorToggled{IsOpen: isOpen}
+	}
+}
+
+// Opens the config editor with the given option in edit mode
+func OpenConfigOption(option string) tea.Cmd {
+	return func() tea.Msg {
+		return ConfigEditorToggled{IsOpen: true, Option: option}
+	}
+}
+
+type CommandPaletteToggled struct {
+	IsOpen bool
+}
+
+func ToggleCommandPalette(isOpen bool) tea.Cmd {
+	return func() tea.Msg {
+		return CommandPaletteToggled{IsOpen: isOpen}
+	}
+}
+
+type PaletteCommandChosen struct {
+	Command PaletteCommand
+}
+
+func SendPaletteCommandChosenMsg(command PaletteCommand) tea.Cmd {
+	return func() tea.Msg {
+		return PaletteCommandChosen{Command: command}
+	}
+}
+
+type DashboardToggled struct {
+	IsOpen bool
+}
+
+func ToggleDashboard(isOpen bool) tea.Cmd {
+	return func() tea.Msg {
+		return DashboardToggled{IsOpen: isOpen}
+	}
+}
+
+type DashboardAction int
+
+const (
+	ResumeSessionAction DashboardAction = iota
+	NewChatAction
+	QuickChatAction
+	OpenSessionAction
+)
+
+// Item chosen on the dashboard, SessionId is set for OpenSessionAction
+type DashboardActionChosen struct {
+	Action    DashboardAction
+	SessionId int
+}
+
+func SendDashboardActionChosenMsg(action DashboardAction, sessionId int) tea.Cmd {
+	return func() tea.Msg {
+		return DashboardActionChosen{Action: action, SessionId: sessionId}
+	}
+}
+
+type NotificationHistoryToggled struct {
+	IsOpen bool
+}
+
+func ToggleNotificationHistory(isOpen bool) tea.Cmd {
+	return func() tea.Msg {
+		return NotificationHistoryToggled{IsOpen: isOpen}
+	}
+}
+
+type ErrorInspectorToggled struct {
+	IsOpen bool
+}
+
+func ToggleErrorInspector(isOpen bool) tea.Cmd {
+	return func() tea.Msg {
+		return ErrorInspectorToggled{IsOpen: isOpen}
+	}
+}
+
+// Carries tokens consumed by an interrupted response. Counts are estimated
+type InferenceCancelled struct {
+	Usage TokenUsage
+}
+
+func SendInferenceCancelledMsg(usage TokenUsage) tea.Cmd {
+	return func() tea.Msg {
+		return InferenceCancelled{Usage: usage}
+	}
+}
+
+// Requests the app to restart with another profile
+type ProfileSwitchRequested struct {
+	Profile string
+}
+
+func SendProfileSwitchRequestedMsg(profile string) tea.Cmd {
+	return func() tea.Msg {
+		return ProfileSwitchRequested{Profile: profile}
+	}
+}
+
 type SystemPromptUpdatedMsg struct {
 	SystemPrompt string
 }
@@ -221,6 +498,60 @@ func UpdateSystemPrompt(prompt string) tea.Cmd {
 	}
 }
 
+// Diff of a replaced system prompt against the current one, shown in the chat pane
+type SystemPromptDiffMsg struct {
+	Title string
+	Lines []DiffLine
+}
+
+// The chat pane is focused first, as losing focus closes the diff
+func ShowSystemPromptDiff(title string, lines []DiffLine) tea.Cmd {
+	return tea.Sequence(SwitchToPane(ChatPane), func() tea.Msg {
+		return SystemPromptDiffMsg{Title: title, Lines: lines}
+	})
+}
+
+// Answers of a preset comparison, shown in the chat pane
+type PresetComparisonMsg struct {
+	Comparison PresetComparison
+}
+
+func ShowPresetComparison(comparison PresetComparison) tea.Cmd {
+	return tea.Sequence(SwitchToPane(ChatPane), func() tea.Msg {
+		return PresetComparisonMsg{Comparison: comparison}
+	})
+}
+
+// The manual is shown in the chat pane, which is focused first as losing focus closes it
+type ManualMsg struct{}
+
+func ShowManual() tea.Cmd {
+	return tea.Sequence(SwitchToPane(ChatPane), func() tea.Msg {
+		return ManualMsg{}
+	})
+}
+
+type WebSearchToggleRequested struct{}
+
+// Toggles web search of the current preset, like the settings pane key
+func ToggleWebSearch() tea.Cmd {
+	return func() tea.Msg {
+		return WebSearchToggleRequested{}
+	}
+}
+
+// One of the compared answers is marked as better in the chat pane
+type ComparisonWinnerMarked struct {
+	ID     int
+	Winner int
+}
+
+func SendComparisonWinnerMarkedMsg(id, winner int) tea.Cmd {
+	return func() tea.Msg {
+		return ComparisonWinnerMarked{ID: id, Winner: winner}
+	}
+}
+
 func SwitchToEditor(content string, op Operation, isFocused bool) tea.Cmd {
 	openEditorMsg := func() tea.Msg {
 		return OpenTextEditorMsg{Content: content, Operation: op, IsFocused: isFocused}
@@ -249,3 +580,9 @@ func AddNewSession(isTemporary bool) tea.Cmd {
 		}
 	}
 }
+
+// Sent when a newer release is found on GitHub
+type UpdateAvailable struct {
+	Version string
+	Url     string
+}
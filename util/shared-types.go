@@ -1,6 +1,31 @@
 package util
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// An action listed in the command palette. Actions with a Cmd run it, other actions
+// press the first key of the binding, focusing the Target pane first if RequiresFocus is set
+type PaletteCommand struct {
+	Title         string
+	Binding       key.Binding
+	Target        Pane
+	RequiresFocus bool
+	Cmd           tea.Cmd
+}
+
+// Falls back to the binding description for actions without a title
+func (c PaletteCommand) GetTitle() string {
+	if c.Title != "" {
+		return c.Title
+	}
+	return i18n.T(c.Binding.Help().Desc)
+}
 
 type Settings struct {
 	ID               int
@@ -13,6 +38,75 @@ type Settings struct {
 	PresetName       string
 	WebSearchEnabled bool
 	HideReasoning    bool
+	SystemPromptId   *int
+	Provider         *int
+	// Effort level (low, medium, high) or a number of thinking tokens
+	ReasoningBudget *string
+}
+
+type Toast struct {
+	Id        int
+	Text      string
+	Severity  Severity
+	CreatedAt time.Time
+}
+
+// Details of a failed provider request. Wraps the original error
+type ApiError struct {
+	Provider   string
+	Model      string
+	StatusCode int
+	RequestId  string
+	Body       string
+	Err        error
+}
+
+func (e *ApiError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ApiError) Unwrap() error {
+	return e.Err
+}
+
+type SystemPrompt struct {
+	ID      int
+	Name    string
+	Content string
+}
+
+// System prompt of a preset as it was before it was replaced
+type SystemPromptVersion struct {
+	ID        int
+	Content   string
+	CreatedAt string
+}
+
+const NoComparisonWinner = -1
+
+// Answer of one of the presets compared with `/compare`
+type ComparisonAnswer struct {
+	Preset  string
+	Model   string
+	Content string
+}
+
+// The same prompt answered with two presets. Winner is the index of the answer marked as better
+type PresetComparison struct {
+	ID      int
+	Prompt  string
+	Answers [2]ComparisonAnswer
+	Winner  int
+}
+
+// Name and color replace the generic speaker label of responses. Empty model
+// and system prompt fall back to the ones of the preset
+type Persona struct {
+	ID           int
+	Name         string
+	Color        string
+	Model        string
+	SystemPrompt string
 }
 
 type LocalStoreMessage struct {
@@ -22,6 +116,12 @@ type LocalStoreMessage struct {
 	Resoning    string       `json:"reasoning"`
 	Attachments []Attachment `json:"attachments"`
 	ToolCalls   []ToolCall   `json:"tool_calls"`
+	// Number of secrets masked in the prompt before it was sent
+	RedactedSecrets int  `json:"redacted_secrets,omitempty"`
+	Pinned          bool `json:"pinned,omitempty"`
+	// RatingUp or RatingDown, zero for answers that are not rated
+	Rating     int    `json:"rating,omitempty"`
+	RatingNote string `json:"rating_note,omitempty"`
 }
 
 type Attachment struct {
@@ -42,6 +142,8 @@ type ToolCall struct {
 	Type     string       `json:"type"`
 	Function ToolFunction `json:"function"`
 	Result   *string      `json:"result"`
+	// Gemini thought signature or Claude thinking block signature, thinking models require it to be sent back with the tool call
+	Signature []byte `json:"signature,omitempty"`
 }
 
 type ToolFunction struct {
@@ -57,6 +159,14 @@ type CompletionChunk struct {
 	SystemFingerpint string      `json:"system_fingerprint"`
 	Choices          []Choice    `json:"choices"`
 	Usage            *TokenUsage `json:"usage"`
+	// Sources of Perplexity responses, every chunk has the full list
+	Citations     []string       `json:"citations,omitempty"`
+	SearchResults []SearchResult `json:"search_results,omitempty"`
+}
+
+type SearchResult struct {
+	Title string `json:"title"`
+	Url   string `json:"url"`
 }
 
 type TokenUsage struct {
@@ -81,11 +191,19 @@ type ModelsListResponse struct {
 	Data   []ModelDescription `json:"data"`
 }
 
+// A chunk of a streamed response. Chunks of a request are numbered from 0 in the order they are written,
+// the stream is over with the single Final chunk, a failed chunk is final as well
 type ProcessApiCompletionResponse struct {
-	ID     int
-	Result CompletionChunk // or whatever type you need
-	Err    error
-	Final  bool
+	RequestId int
+	Seq       int
+	Result    CompletionChunk
+	Err       error
+	Final     bool
+}
+
+// Chunks read from the response channel at once, they are processed in the order they were written
+type CompletionChunksBatch struct {
+	Chunks []ProcessApiCompletionResponse
 }
 
 type ProcessModelsResponse struct {
@@ -105,10 +223,51 @@ const (
 	Error
 )
 
-func WriteToResponseChannel(ctx context.Context, ch chan<- ProcessApiCompletionResponse, msg ProcessApiCompletionResponse) {
+// Writes chunks of a single completion request to the response channel and numbers them.
+// Writes after the final chunk are dropped, so a stream is always finished once
+type ResponseWriter struct {
+	ctx       context.Context
+	ch        chan<- ProcessApiCompletionResponse
+	requestId int
+	seq       int
+	isOver    bool
+}
+
+func NewResponseWriter(ctx context.Context, ch chan<- ProcessApiCompletionResponse) *ResponseWriter {
+	requestId, _ := RequestIdFromContext(ctx)
+	return &ResponseWriter{
+		ctx:       ctx,
+		ch:        ch,
+		requestId: requestId,
+	}
+}
+
+func (w *ResponseWriter) Write(msg ProcessApiCompletionResponse) {
+	if w.isOver {
+		Slog.Debug("stream is over, skipping write to channel", "request_id", w.requestId)
+		return
+	}
+
+	msg.RequestId = w.requestId
+	msg.Seq = w.seq
+	w.seq++
+	w.isOver = msg.Final || msg.Err != nil
+
 	select {
-	case ch <- msg:
-	case <-ctx.Done():
-		Slog.Debug("Context cancelled, skipping write to channel", "msg_id", msg.ID)
+	case w.ch <- msg:
+	case <-w.ctx.Done():
+		Slog.Debug("Context cancelled, skipping write to channel", "request_id", w.requestId, "seq", msg.Seq)
 	}
 }
+
+func (w *ResponseWriter) Send(chunk CompletionChunk) {
+	w.Write(ProcessApiCompletionResponse{Result: chunk})
+}
+
+func (w *ResponseWriter) Finish() {
+	w.Write(ProcessApiCompletionResponse{Final: true})
+}
+
+func (w *ResponseWriter) Fail(err error) {
+	w.Write(ProcessApiCompletionResponse{Err: err, Final: true})
+}
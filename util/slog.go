@@ -1,6 +1,7 @@
 package util
 
 import (
+	"io"
 	"log/slog"
 	"os"
 	"path"
@@ -9,11 +10,16 @@ import (
 
 var Slog *slog.Logger
 
+// Logs are discarded until InitLogger is called,
+// since the log location depends on startup flags
 func init() {
+	Slog = slog.New(slog.NewTextHandler(io.Discard, nil))
+}
 
+func InitLogger() error {
 	appPath, err := GetAppDataPath()
 	if err != nil {
-		panic(err)
+		return err
 	}
 	logFile, err := os.OpenFile(
 		filepath.Join(appPath, "debug.log"),
@@ -21,7 +27,7 @@ func init() {
 		0666,
 	)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
 	logLevel := slog.LevelWarn
@@ -45,4 +51,5 @@ func init() {
 	handler := slog.NewTextHandler(logFile, &opts)
 
 	Slog = slog.New(handler)
+	return nil
 }
@@ -0,0 +1,93 @@
+package util
+
+import (
+	"strings"
+)
+
+const (
+	defaultUserIcon      = "💁"
+	defaultAssistantIcon = "🤖"
+	asciiUserIcon        = ">"
+	asciiAssistantIcon   = "*"
+	defaultUserLabel     = "Prooompter"
+	// Config value that hides a speaker icon
+	NoSpeakerIcon = "none"
+)
+
+// Icons and the label messages are prefixed with. Emoji are wider than one cell in some terminals
+// and fonts, so they can be replaced with plain text or hidden
+var (
+	userIcon      = defaultUserIcon
+	assistantIcon = defaultAssistantIcon
+	userLabel     = defaultUserLabel
+)
+
+// Empty values restore the defaults, plain text icons are the defaults in ASCII mode
+func SetSpeakerLabels(user string, assistant string, label string) {
+	if asciiMode {
+		userIcon = resolveSpeakerIcon(user, asciiUserIcon)
+		assistantIcon = resolveSpeakerIcon(assistant, asciiAssistantIcon)
+	} else {
+		userIcon = resolveSpeakerIcon(user, defaultUserIcon)
+		assistantIcon = resolveSpeakerIcon(assistant, defaultAssistantIcon)
+	}
+	userLabel = label
+	if userLabel == "" {
+		userLabel = defaultUserLabel
+	}
+}
+
+func resolveSpeakerIcon(icon string, defaultIcon string) string {
+	switch icon {
+	case "":
+		return defaultIcon
+	case NoSpeakerIcon:
+		return ""
+	}
+	return icon
+}
+
+func GetUserIcon() string {
+	return userIcon
+}
+
+func GetAssistantIcon() string {
+	return assistantIcon
+}
+
+// Markdown of the icon followed by a space. Plain text icons like > or # would be parsed as markdown
+func getSpeakerPrefix(icon string) string {
+	if icon == "" {
+		return ""
+	}
+	return escapeMarkdown(icon) + " "
+}
+
+func getUserHeader() string {
+	return getSpeakerPrefix(userIcon) + "**[" + escapeMarkdown(userLabel) + "]**"
+}
+
+const markdownPunctuation = "\\`*_{}[]()#+-.!<>|~"
+
+func escapeMarkdown(text string) string {
+	escaped := strings.Builder{}
+	for _, r := range text {
+		if strings.ContainsRune(markdownPunctuation, r) {
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
+
+// Icons are not a part of messages, so they are cut from the start of lines copied in the visual mode
+func StripSpeakerIcon(line string) string {
+	content := strings.TrimLeft(line, " ")
+	indent := line[:len(line)-len(content)]
+	for _, icon := range []string{userIcon, assistantIcon} {
+		if icon != "" && strings.HasPrefix(content, icon+" ") {
+			return indent + strings.TrimPrefix(content, icon+" ")
+		}
+	}
+	return line
+}
@@ -5,9 +5,54 @@ import "github.com/charmbracelet/lipgloss"
 var SubduedColor = lipgloss.AdaptiveColor{Light: "#9B9B9B", Dark: "#5C5C5C"}
 var HelpStyle = lipgloss.NewStyle().Padding(0, 0, 0, 2).Foreground(SubduedColor)
 
-const ActiveDot = "■"
-const InactiveDot = "•"
+var ActiveDot = "■"
+var InactiveDot = "•"
 
-const ListHeadingDot = "■"
+var ListHeadingDot = "■"
 
-const TipsSeparator = " • "
+var TipsSeparator = " • "
+
+// Marker at the start of info bars
+var InfoBarMarker = "▐"
+
+// Terminals and fonts with poor glyph support render emoji, box drawing and block characters
+// misaligned or as placeholders. In ASCII mode they are replaced with plain characters.
+// Styles are built when panes are created, so the mode is set once on startup
+var asciiMode bool
+
+func SetAsciiMode(enabled bool) {
+	asciiMode = enabled
+	if !enabled {
+		return
+	}
+
+	ActiveDot = "*"
+	InactiveDot = "."
+	ListHeadingDot = "*"
+	TipsSeparator = " | "
+	InfoBarMarker = "|"
+	pinnedMarker = " [pinned]"
+	ratingUpMarker = " [+]"
+	ratingDownMarker = " [-]"
+	toolCallIcon = "[tool]"
+}
+
+func IsAsciiMode() bool {
+	return asciiMode
+}
+
+// Border of panes
+func ThickBorder() lipgloss.Border {
+	if asciiMode {
+		return lipgloss.ASCIIBorder()
+	}
+	return lipgloss.ThickBorder()
+}
+
+// Bar on the left of messages and labels
+func HalfBlockBorder() lipgloss.Border {
+	if asciiMode {
+		return lipgloss.ASCIIBorder()
+	}
+	return lipgloss.InnerHalfBlockBorder()
+}
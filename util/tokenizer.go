@@ -0,0 +1,48 @@
+package util
+
+import (
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Splits text the way BPE tokenizers of OpenAI models do before merging
+var pretokenizeRegex = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)| ?\p{L}+| ?\p{N}{1,3}| ?[^\s\p{L}\p{N}]+|\s+`)
+
+// Local token count approximation for providers that don't report usage in streamed responses.
+// Text is pre-tokenized into words, numbers and punctuation, then each piece is counted by its length:
+// common words are a single token, long words are split into ~5 character parts,
+// CJK characters are a token each
+func CountTokens(text string) int {
+	tokens := 0
+	for _, piece := range pretokenizeRegex.FindAllString(text, -1) {
+		tokens += countPieceTokens(piece)
+	}
+	return tokens
+}
+
+func countPieceTokens(piece string) int {
+	first, _ := utf8.DecodeRuneInString(piece)
+	if unicode.IsSpace(first) && len(piece) > 1 {
+		trimmed := piece[1:]
+		if next, _ := utf8.DecodeRuneInString(trimmed); !unicode.IsSpace(next) {
+			piece = trimmed
+		}
+	}
+
+	cjk := 0
+	for _, r := range piece {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			cjk++
+		}
+	}
+	if cjk > 0 {
+		return cjk + (utf8.RuneCountInString(piece)-cjk+3)/4
+	}
+
+	chars := utf8.RuneCountInString(piece)
+	if chars <= 6 {
+		return 1
+	}
+	return (chars + 4) / 5
+}
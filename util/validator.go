@@ -6,6 +6,7 @@ import (
 	"math"
 	"slices"
 	"strconv"
+	"strings"
 )
 
 const multiplier = 100000
@@ -14,6 +15,7 @@ const negativeSignCode = -9999999999
 const FrequencyRange = "[-2.0, 2.0)"
 const TemperatureRange = "[0.0, 2.0]"
 const TopPRange = "[0.0, 1.0]"
+const ReasoningBudgetRange = "(low, medium, high, tokens or off)"
 
 var EmptyValidator = func(input string) error {
 	return nil
@@ -34,6 +36,32 @@ var TemperatureValidator = func(input string) error {
 var TopPValidator = func(input string) error {
 	return validateRangedFloat(input, 0.0, 1.0, false, false)
 }
+var ReasoningBudgetValidator = func(input string) error {
+	if input == "" {
+		return nil
+	}
+
+	// Input is validated on every key press, so prefixes of the levels are allowed
+	for _, level := range append(ReasoningEfforts, ReasoningBudgetOff) {
+		if strings.HasPrefix(level, input) {
+			return nil
+		}
+	}
+
+	min := 0
+	max := 1_000_000
+	val, err := strconv.Atoi(input)
+	if err != nil {
+		return fmt.Errorf("reasoning budget must be one of %s", ReasoningBudgetRange)
+	}
+
+	if val < min || val > max {
+		logOutOfRange(val, min, max)
+		return fmt.Errorf("value %d out of range [%d, %d]", val, min, max)
+	}
+
+	return nil
+}
 var MaxTokensValidator = func(input string) error {
 	if input == "" {
 		return nil
@@ -0,0 +1,55 @@
+package views
+
+import (
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Prompt sent to the sessions marked in the sessions pane. Sessions get the prompt one after another,
+// each with its own history, system prompt and translation mode
+type broadcastRun struct {
+	prompt      string
+	attachments []util.Attachment
+	sessionIds  []int
+	originId    int
+	sent        int
+	// The sessions pane reloads the session once its response is complete,
+	// the next session is switched to after that, so the reload doesn't override the switch
+	isWaiting bool
+}
+
+func (m *MainView) startBroadcast(msg util.PromptReady, sessionIds []int) tea.Cmd {
+	m.broadcast = &broadcastRun{
+		prompt:      msg.Prompt,
+		attachments: msg.Attachments,
+		sessionIds:  sessionIds,
+		originId:    m.sessionOrchestrator.GetCurrentSessionId(),
+	}
+	return m.runNextBroadcastSession()
+}
+
+func (m *MainView) runNextBroadcastSession() tea.Cmd {
+	run := m.broadcast
+	run.isWaiting = false
+
+	if run.sent == len(run.sessionIds) {
+		m.stopBroadcast()
+		return tea.Batch(
+			m.sessionsPane.SwitchToSession(run.originId),
+			util.SendToastMsg(i18n.Tf("notification.broadcastFinished", run.sent), util.SuccessSeverity),
+		)
+	}
+
+	sessionId := run.sessionIds[run.sent]
+	run.sent++
+	return tea.Sequence(
+		m.sessionsPane.SwitchToSession(sessionId),
+		util.SendToastMsg(i18n.Tf("notification.broadcastStep", run.sent, len(run.sessionIds)), util.InfoSeverity),
+		util.SendPromptReadyMsg(run.prompt, run.attachments),
+	)
+}
+
+func (m *MainView) stopBroadcast() {
+	m.broadcast = nil
+}
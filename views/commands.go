@@ -0,0 +1,79 @@
+package views
+
+import (
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/sessions"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Every action of the app, built from live keybindings so remapped keys are shown and pressed
+func (m MainView) getPaletteCommands() []util.PaletteCommand {
+	commands := []util.PaletteCommand{
+		{Binding: m.keys.newSession},
+		{Binding: m.keys.quickChat},
+		{Binding: m.keys.saveQuickChat},
+		{Binding: m.keys.cancel},
+		{Binding: m.keys.zenMode},
+		{Binding: m.keys.editorMode, Target: util.PromptPane, RequiresFocus: true},
+		{Binding: m.keys.growChat},
+		{Binding: m.keys.shrinkChat},
+		{Binding: m.keys.growPrompt},
+		{Binding: m.keys.shrinkPrompt},
+		{Binding: m.keys.toggleDrawer},
+		{Binding: m.keys.notifications},
+		{Binding: m.keys.errorDetails},
+		{Binding: m.keys.recordMacro},
+		{Binding: m.keys.replayMacro},
+	}
+
+	commands = append(commands, m.settingsPane.PaletteCommands()...)
+	commands = append(commands, m.sessionsPane.PaletteCommands()...)
+	commands = append(commands, m.chatPane.PaletteCommands()...)
+	commands = append(commands, m.promptPane.PaletteCommands()...)
+
+	for _, action := range util.QuickActions {
+		commands = append(commands, util.PaletteCommand{
+			Title: i18n.T("quickActions.title") + ": " + util.GetQuickActionTitle(action),
+			Cmd:   util.SendQuickActionMsg(action),
+		})
+	}
+
+	return append(commands,
+		util.PaletteCommand{Title: i18n.T("command.openDashboard"), Cmd: util.ToggleDashboard(true)},
+		util.PaletteCommand{Title: i18n.T("command.comparePresets"), Cmd: util.SendInsertPromptMsg(sessions.CompareCommand + " ")},
+		util.PaletteCommand{Title: i18n.T("command.openConfig"), Cmd: util.ToggleConfigEditor(true)},
+		util.PaletteCommand{Title: i18n.T("command.changeTheme"), Cmd: util.OpenConfigOption("colorScheme")},
+		util.PaletteCommand{Title: i18n.T("command.changeLanguage"), Cmd: util.OpenConfigOption("language")},
+		util.PaletteCommand{Title: i18n.T("command.changeProvider"), Cmd: util.OpenConfigOption("provider")},
+		util.PaletteCommand{Binding: m.keys.quit, Cmd: tea.Quit},
+	)
+}
+
+// Runs the command as if its key was pressed. Commands of a pane that can't be focused
+// in the current view mode are not run, so keys don't end up in a wrong pane
+func (m *MainView) executePaletteCommand(command util.PaletteCommand) tea.Cmd {
+	if command.Cmd != nil {
+		return command.Cmd
+	}
+
+	if command.RequiresFocus && m.focused != command.Target {
+		m.handleFocusChange(command.Target, false)
+		if m.focused != command.Target {
+			return util.SendToastMsg(i18n.T("notification.commandUnavailable"), util.WarningSeverity)
+		}
+	}
+
+	keys := command.Binding.Keys()
+	if len(keys) == 0 {
+		return nil
+	}
+
+	keyMsg, ok := util.GetKeyMsg(keys[0])
+	if !ok {
+		util.Slog.Warn("failed to run palette command", "key", keys[0])
+		return nil
+	}
+
+	return func() tea.Msg { return keyMsg }
+}
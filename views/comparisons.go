@@ -0,0 +1,60 @@
+package views
+
+import (
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/sessions"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Sends the prompt of a `/compare` command with two presets. The answers are not added to the session,
+// they are shown in the chat pane and saved for the evaluation once both are received
+func (m *MainView) startComparison(command string) tea.Cmd {
+	if m.sessionOrchestrator.IsProcessing() {
+		return util.SendRestorePromptMsg(command)
+	}
+
+	presetA, presetB, prompt, err := sessions.ParseCompareCommand(command)
+	if err != nil {
+		return tea.Batch(
+			util.MakeRecoverableErrorMsg(err.Error()),
+			util.SendRestorePromptMsg(command),
+		)
+	}
+
+	compare, err := m.sessionOrchestrator.ComparePresets(presetA, presetB, prompt)
+	if err != nil {
+		return tea.Batch(
+			util.MakeRecoverableErrorMsg(err.Error()),
+			util.SendRestorePromptMsg(command),
+		)
+	}
+
+	return tea.Batch(
+		util.SendToastMsg(i18n.Tf("notification.comparisonStarted", presetA, presetB), util.InfoSeverity),
+		compare,
+	)
+}
+
+func (m *MainView) showComparison(msg sessions.PresetComparisonReady) tea.Cmd {
+	if msg.Err != nil {
+		util.Slog.Error("preset comparison failed", "error", msg.Err.Error())
+		return util.MakeRecoverableErrorMsg(msg.Err.Error())
+	}
+
+	id, err := m.sessionService.AddPresetComparison(msg.Comparison)
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+
+	msg.Comparison.ID = id
+	return util.ShowPresetComparison(msg.Comparison)
+}
+
+func (m *MainView) markComparisonWinner(msg util.ComparisonWinnerMarked) tea.Cmd {
+	err := m.sessionService.SetComparisonWinner(msg.ID, msg.Winner)
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+	return util.SendToastMsg(i18n.T("notification.comparisonWinnerSaved"), util.SuccessSeverity)
+}
@@ -0,0 +1,48 @@
+package views
+
+import (
+	"github.com/BalanceBalls/nekot/extensions/flows"
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Starts a flow in the current session. Each next step is sent once the response to the previous one is complete
+func (m *MainView) startFlow(command string) tea.Cmd {
+	if m.sessionOrchestrator.IsProcessing() {
+		return util.SendRestorePromptMsg(command)
+	}
+
+	run, err := flows.NewRun(command)
+	if err != nil {
+		return tea.Batch(
+			util.MakeRecoverableErrorMsg(err.Error()),
+			util.SendRestorePromptMsg(command),
+		)
+	}
+
+	m.flowRun = &run
+	return m.runNextFlowStep()
+}
+
+func (m *MainView) runNextFlowStep() tea.Cmd {
+	step, ok := m.flowRun.Next()
+	if !ok {
+		name := m.flowRun.Flow.Name
+		m.stopFlow()
+		return util.SendToastMsg(i18n.Tf("notification.flowFinished", name), util.SuccessSeverity)
+	}
+
+	m.sessionOrchestrator.WebSearchOverride = step.WebSearch
+	return tea.Batch(
+		util.SendToastMsg(
+			i18n.Tf("notification.flowStep", m.flowRun.Flow.Name, m.flowRun.Step, len(m.flowRun.Flow.Steps)),
+			util.InfoSeverity),
+		util.SendPromptReadyMsg(step.Prompt, []util.Attachment{}),
+	)
+}
+
+func (m *MainView) stopFlow() {
+	m.flowRun = nil
+	m.sessionOrchestrator.WebSearchOverride = nil
+}
@@ -0,0 +1,105 @@
+package views
+
+import (
+	"time"
+
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Replayed keys are spaced out, so commands started by a key deliver their results before the next one
+const macroKeyInterval = 50 * time.Millisecond
+
+// Keystrokes recorded across panes and replayed as if they were pressed again
+type macro struct {
+	keys        []tea.KeyMsg
+	isRecording bool
+	isReplaying bool
+}
+
+type replayMacroKey struct {
+	index int
+}
+
+func nextMacroKey(index int) tea.Cmd {
+	return tea.Tick(macroKeyInterval, func(time.Time) tea.Msg {
+		return replayMacroKey{index: index}
+	})
+}
+
+// Keys are recorded before panes handle them, so keys of popups and the command palette are recorded too
+func (m *MainView) recordMacroKey(msg tea.Msg) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !m.macro.isRecording {
+		return
+	}
+	m.macro.keys = append(m.macro.keys, keyMsg)
+}
+
+// Macro keys are plain characters, they are typed as text while an input has focus
+func (m MainView) canUseMacroKeys() bool {
+	return m.isFocusChangeAllowed(false) && !m.sessionsPane.IsFiltering()
+}
+
+func (m *MainView) toggleMacroRecording() tea.Cmd {
+	if m.macro.isReplaying {
+		return nil
+	}
+
+	if !m.macro.isRecording {
+		m.macro = macro{isRecording: true}
+		return tea.Batch(
+			util.SendMacroRecordingChangedMsg(true),
+			util.SendToastMsg(i18n.Tf("notification.macroRecording", m.keys.recordMacro.Help().Key), util.InfoSeverity),
+		)
+	}
+
+	// the key that stopped the recording is the last one recorded
+	m.macro.isRecording = false
+	m.macro.keys = m.macro.keys[:len(m.macro.keys)-1]
+	return tea.Batch(
+		util.SendMacroRecordingChangedMsg(false),
+		util.SendToastMsg(i18n.Tf("notification.macroRecorded", len(m.macro.keys)), util.SuccessSeverity),
+	)
+}
+
+func (m *MainView) replayMacro() tea.Cmd {
+	if m.macro.isReplaying {
+		return nil
+	}
+
+	if m.macro.isRecording {
+		return util.SendToastMsg(i18n.T("notification.macroReplayWhileRecording"), util.WarningSeverity)
+	}
+
+	if len(m.macro.keys) == 0 {
+		return util.SendToastMsg(i18n.Tf("notification.noMacro", m.keys.recordMacro.Help().Key), util.WarningSeverity)
+	}
+
+	m.macro.isReplaying = true
+	return nextMacroKey(0)
+}
+
+// Keys wait while a response is processed, keys pressed at that time are ignored by the panes
+func (m MainView) replayNextMacroKey(index int) (tea.Model, tea.Cmd) {
+	if !m.macro.isReplaying {
+		return m, nil
+	}
+
+	if index == len(m.macro.keys) {
+		m.stopMacroReplay()
+		return m, util.SendToastMsg(i18n.Tf("notification.macroReplayed", len(m.macro.keys)), util.SuccessSeverity)
+	}
+
+	if !m.viewReady || m.controlsLocked || m.sessionOrchestrator.IsProcessing() {
+		return m, nextMacroKey(index)
+	}
+
+	model, cmd := m.Update(m.macro.keys[index])
+	return model, tea.Batch(cmd, nextMacroKey(index+1))
+}
+
+func (m *MainView) stopMacroReplay() {
+	m.macro.isReplaying = false
+}
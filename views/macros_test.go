@@ -0,0 +1,39 @@
+package views
+
+import (
+	"context"
+	"testing"
+
+	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/panes"
+	"github.com/BalanceBalls/nekot/sessions"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestMarkKeyIsNotMacroKey(t *testing.T) {
+	ctx := config.WithConfig(context.Background(), &config.Config{})
+	chatPane := panes.NewChatPane(ctx, util.DefaultTerminalWidth, util.DefaultTerminalHeight)
+	chatPane, _ = chatPane.Update(util.FocusEvent{IsFocused: true})
+	chatPane, _ = chatPane.Update(sessions.ResponseChunkProcessed{
+		PreviousMsgArray: []util.LocalStoreMessage{{Role: "user", Content: "hi"}},
+		IsComplete:       true,
+	})
+
+	m := MainView{
+		keys:      defaultKeyMap,
+		focused:   util.ChatPane,
+		viewReady: true,
+		chatPane:  chatPane,
+		context:   ctx,
+	}
+
+	for _, keys := range []string{"m", "q"} {
+		model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(keys)})
+		m = model.(MainView)
+	}
+
+	if m.macro.isRecording {
+		t.Error("expected the key after m to set a mark, not to start macro recording")
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"database/sql"
 	"encoding/base64"
 	"fmt"
+	"math"
 	"os"
 	"runtime"
 	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -16,9 +19,13 @@ import (
 	zone "github.com/lrstanley/bubblezone"
 	"golang.org/x/term"
 
+	"github.com/BalanceBalls/nekot/components"
 	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/extensions/flows"
+	"github.com/BalanceBalls/nekot/i18n"
 	"github.com/BalanceBalls/nekot/panes"
 	"github.com/BalanceBalls/nekot/sessions"
+	"github.com/BalanceBalls/nekot/updater"
 	"github.com/BalanceBalls/nekot/util"
 )
 
@@ -27,56 +34,177 @@ const pulsarIntervalMs = 300
 var asyncDeps = []util.AsyncDependency{util.SettingsPaneModule, util.Orchestrator}
 
 type keyMap struct {
-	cancel        key.Binding
-	zenMode       key.Binding
-	editorMode    key.Binding
-	nextPane      key.Binding
-	previousPane  key.Binding
-	jumpToPane    key.Binding
-	newSession    key.Binding
-	quickChat     key.Binding
-	saveQuickChat key.Binding
-	quit          key.Binding
+	cancel         key.Binding
+	zenMode        key.Binding
+	editorMode     key.Binding
+	nextPane       key.Binding
+	previousPane   key.Binding
+	jumpToPane     key.Binding
+	newSession     key.Binding
+	quickChat      key.Binding
+	saveQuickChat  key.Binding
+	growChat       key.Binding
+	shrinkChat     key.Binding
+	growPrompt     key.Binding
+	shrinkPrompt   key.Binding
+	toggleDrawer   key.Binding
+	notifications  key.Binding
+	errorDetails   key.Binding
+	commandPalette key.Binding
+	recordMacro    key.Binding
+	replayMacro    key.Binding
+	quit           key.Binding
 }
 
 var defaultKeyMap = keyMap{
 	cancel: key.NewBinding(
 		key.WithKeys("ctrl+s", "ctrl+b"),
-		key.WithHelp("ctrl+b/ctrl+s", "stop inference"),
+		key.WithHelp("ctrl+b/ctrl+s", "keys.stopInference"),
 	),
 	zenMode: key.NewBinding(
 		key.WithKeys("ctrl+o"),
-		key.WithHelp("ctrl+o", "activate/deactivate zen mode"),
+		key.WithHelp("ctrl+o", "keys.zenMode"),
 	),
 	editorMode: key.NewBinding(
 		key.WithKeys("ctrl+e"),
-		key.WithHelp("ctrl+e", "enter/exit editor mode"),
+		key.WithHelp("ctrl+e", "keys.editorMode"),
 	),
-	quit: key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "quit app")),
+	quit: key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "keys.quit")),
 	quickChat: key.NewBinding(
 		key.WithKeys("ctrl+q"),
-		key.WithHelp("ctrl+q", "start quick chat"),
+		key.WithHelp("ctrl+q", "keys.startQuickChat"),
 	),
 	saveQuickChat: key.NewBinding(
 		key.WithKeys("ctrl+x"),
-		key.WithHelp("ctrl+x", "save quick chat"),
+		key.WithHelp("ctrl+x", "keys.saveQuickChat"),
 	),
 	jumpToPane: key.NewBinding(
 		key.WithKeys("1", "2", "3", "4"),
-		key.WithHelp("1,2,3,4", "jump to specific pane"),
+		key.WithHelp("1,2,3,4", "keys.jumpToPane"),
 	),
 	nextPane: key.NewBinding(
 		key.WithKeys(tea.KeyTab.String()),
-		key.WithHelp("TAB", "move to next pane"),
+		key.WithHelp("TAB", "keys.nextPane"),
 	),
 	previousPane: key.NewBinding(
 		key.WithKeys(tea.KeyShiftTab.String()),
-		key.WithHelp("SHIFT+TAB", "move to previous pane"),
+		key.WithHelp("SHIFT+TAB", "keys.previousPane"),
 	),
 	newSession: key.NewBinding(
 		key.WithKeys("ctrl+n"),
-		key.WithHelp("ctrl+n", "add new session"),
+		key.WithHelp("ctrl+n", "keys.addSession"),
 	),
+	growChat: key.NewBinding(
+		key.WithKeys("ctrl+right"),
+		key.WithHelp("ctrl+right", "keys.widenChat"),
+	),
+	shrinkChat: key.NewBinding(
+		key.WithKeys("ctrl+left"),
+		key.WithHelp("ctrl+left", "keys.narrowChat"),
+	),
+	growPrompt: key.NewBinding(
+		key.WithKeys("ctrl+up"),
+		key.WithHelp("ctrl+up", "keys.growPrompt"),
+	),
+	shrinkPrompt: key.NewBinding(
+		key.WithKeys("ctrl+down"),
+		key.WithHelp("ctrl+down", "keys.shrinkPrompt"),
+	),
+	toggleDrawer: key.NewBinding(
+		key.WithKeys("ctrl+g"),
+		key.WithHelp("ctrl+g", "keys.toggleSidebar"),
+	),
+	notifications: key.NewBinding(
+		key.WithKeys("ctrl+y"),
+		key.WithHelp("ctrl+y", "keys.showNotifications"),
+	),
+	errorDetails: key.NewBinding(
+		key.WithKeys("ctrl+f"),
+		key.WithHelp("ctrl+f", "keys.showError"),
+	),
+	commandPalette: key.NewBinding(
+		key.WithKeys("ctrl+k"),
+		key.WithHelp("ctrl+k", "keys.openPalette"),
+	),
+	recordMacro: key.NewBinding(
+		key.WithKeys("q"),
+		key.WithHelp("q", "keys.recordMacro"),
+	),
+	replayMacro: key.NewBinding(
+		key.WithKeys("@"),
+		key.WithHelp("@", "keys.replayMacro"),
+	),
+}
+
+// Global actions that can be remapped with the keyBindings config option
+func (k *keyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"cancel":         &k.cancel,
+		"zenMode":        &k.zenMode,
+		"editorMode":     &k.editorMode,
+		"nextPane":       &k.nextPane,
+		"previousPane":   &k.previousPane,
+		"newSession":     &k.newSession,
+		"quickChat":      &k.quickChat,
+		"saveQuickChat":  &k.saveQuickChat,
+		"growChat":       &k.growChat,
+		"shrinkChat":     &k.shrinkChat,
+		"growPrompt":     &k.growPrompt,
+		"shrinkPrompt":   &k.shrinkPrompt,
+		"toggleDrawer":   &k.toggleDrawer,
+		"notifications":  &k.notifications,
+		"errorDetails":   &k.errorDetails,
+		"commandPalette": &k.commandPalette,
+		"recordMacro":    &k.recordMacro,
+		"replayMacro":    &k.replayMacro,
+		"quit":           &k.quit,
+	}
+}
+
+func (k keyMap) FullHelp() []key.Binding {
+	return []key.Binding{
+		k.nextPane,
+		k.previousPane,
+		k.jumpToPane,
+		k.newSession,
+		k.quickChat,
+		k.saveQuickChat,
+		k.cancel,
+		k.zenMode,
+		k.editorMode,
+		k.growChat,
+		k.shrinkChat,
+		k.growPrompt,
+		k.shrinkPrompt,
+		k.toggleDrawer,
+		k.notifications,
+		k.errorDetails,
+		k.commandPalette,
+		k.recordMacro,
+		k.replayMacro,
+		k.quit,
+	}
+}
+
+// Replaces keys of global actions with the ones from config. Unknown actions are ignored
+func applyKeyBindings(keys keyMap, overrides map[string][]string) keyMap {
+	actions := keys.actions()
+	for action, boundKeys := range overrides {
+		binding, ok := actions[action]
+		if !ok {
+			util.Slog.Warn("unknown action in keyBindings config", "action", action)
+			continue
+		}
+
+		if len(boundKeys) == 0 {
+			util.Slog.Warn("no keys set for action in keyBindings config", "action", action)
+			continue
+		}
+
+		binding.SetKeys(boundKeys...)
+		binding.SetHelp(strings.Join(boundKeys, "/"), binding.Help().Desc)
+	}
+	return keys
 }
 
 type MainView struct {
@@ -86,16 +214,32 @@ type MainView struct {
 	viewMode         util.ViewMode
 	error            util.ErrorEvent
 	currentSessionID string
+	windowTitle      string
+	flowRun          *flows.Run
+	broadcast        *broadcastRun
+	macro            macro
+	promptDrag       *promptPaneDrag
 	keys             keyMap
 
-	chatPane         panes.ChatPane
-	promptPane       panes.PromptPane
-	sessionsPane     panes.SessionsPane
-	settingsPane     panes.SettingsPane
-	infoPane         panes.InfoPane
-	loadedDeps       []util.AsyncDependency
-	pendingToolCalls []util.ToolCall
-	initialPrompt    string
+	chatPane            panes.ChatPane
+	promptPane          panes.PromptPane
+	sessionsPane        panes.SessionsPane
+	settingsPane        panes.SettingsPane
+	infoPane            panes.InfoPane
+	configPane          panes.ConfigPane
+	notificationsPane   panes.NotificationsPane
+	errorPane           panes.ErrorPane
+	commandPalette      panes.CommandPalette
+	dashboardPane       panes.DashboardPane
+	isConfigOpen        bool
+	isNotificationsOpen bool
+	isErrorPaneOpen     bool
+	isPaletteOpen       bool
+	isDashboardOpen     bool
+	loadedDeps          []util.AsyncDependency
+	pendingToolCalls    []util.ToolCall
+	initialPrompt       string
+	profileToSwitch     string
 
 	flags               config.StartupFlags
 	config              config.Config
@@ -121,7 +265,7 @@ func dimensionsPulsar() tea.Msg {
 
 func NewMainView(db *sql.DB, ctx context.Context) MainView {
 	util.Slog.Debug("initializing main view")
-	promptPane := panes.NewPromptPane(ctx)
+	promptPane := panes.NewPromptPane(db, ctx)
 	sessionsPane := panes.NewSessionsPane(db, ctx)
 	settingsPane := panes.NewSettingsPane(db, ctx)
 	statusBarPane := panes.NewInfoPane(db, ctx)
@@ -148,8 +292,20 @@ func NewMainView(db *sql.DB, ctx context.Context) MainView {
 	}
 
 	util.Slog.Debug("config loaded", "values", config)
+	applyLayoutConfig(*config)
+
+	keys := applyKeyBindings(defaultKeyMap, config.KeyBindings)
+	util.SetHelpSections([]util.HelpSection{
+		{Title: i18n.T("help.global"), Bindings: keys.FullHelp()},
+		{Title: i18n.T("help.prompt"), Bindings: promptPane.FullHelp()},
+		{Title: i18n.T("help.chat"), Bindings: chatPane.FullHelp()},
+		{Title: i18n.T("help.selection"), Bindings: components.SelectionHelp()},
+		{Title: i18n.T("help.settings"), Bindings: settingsPane.FullHelp()},
+		{Title: i18n.T("help.sessions"), Bindings: sessionsPane.FullHelp()},
+	})
+
 	return MainView{
-		keys:                defaultKeyMap,
+		keys:                keys,
 		viewMode:            util.NormalMode,
 		focused:             util.PromptPane,
 		currentSessionID:    "",
@@ -159,6 +315,11 @@ func NewMainView(db *sql.DB, ctx context.Context) MainView {
 		sessionsPane:        sessionsPane,
 		settingsPane:        settingsPane,
 		infoPane:            statusBarPane,
+		configPane:          panes.NewConfigPane(ctx),
+		notificationsPane:   panes.NewNotificationsPane(ctx),
+		errorPane:           panes.NewErrorPane(ctx),
+		commandPalette:      panes.NewCommandPalette(ctx),
+		dashboardPane:       panes.NewDashboardPane(db, ctx),
 		chatPane:            chatPane,
 		config:              *config,
 		flags:               *flags,
@@ -168,13 +329,21 @@ func NewMainView(db *sql.DB, ctx context.Context) MainView {
 }
 
 func (m MainView) Init() tea.Cmd {
-	return tea.Sequence(
-		m.sessionOrchestrator.Init(),
-		m.sessionsPane.Init(),
-		m.settingsPane.Init(),
-		m.promptPane.Init(),
-		m.chatPane.Init(),
-		func() tea.Msg { return dimensionsPulsar() },
+	var updateCheck tea.Cmd
+	if m.config.CheckForUpdates && !util.IsOfflineMode() {
+		updateCheck = updater.CheckForUpdates(m.context)
+	}
+
+	return tea.Batch(
+		tea.Sequence(
+			m.sessionOrchestrator.Init(),
+			m.sessionsPane.Init(),
+			m.settingsPane.Init(),
+			m.promptPane.Init(),
+			m.chatPane.Init(),
+			func() tea.Msg { return dimensionsPulsar() },
+		),
+		updateCheck,
 	)
 }
 
@@ -184,6 +353,75 @@ func (m MainView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds []tea.Cmd
 	)
 
+	if errMsg, ok := msg.(util.ErrorEvent); ok && util.IsDemoMode() {
+		msg = errMsg.Redacted()
+	}
+
+	if replay, ok := msg.(replayMacroKey); ok {
+		return m.replayNextMacroKey(replay.index)
+	}
+	m.recordMacroKey(msg)
+
+	if m.isConfigOpen {
+		switch msg.(type) {
+		case tea.KeyMsg, tea.MouseMsg:
+			if keyMsg, ok := msg.(tea.KeyMsg); ok && key.Matches(keyMsg, m.keys.quit) {
+				return m, tea.Quit
+			}
+
+			m.configPane, cmd = m.configPane.Update(msg)
+			return m, cmd
+		}
+	}
+
+	if m.isNotificationsOpen {
+		switch msg.(type) {
+		case tea.KeyMsg, tea.MouseMsg:
+			if keyMsg, ok := msg.(tea.KeyMsg); ok && key.Matches(keyMsg, m.keys.quit) {
+				return m, tea.Quit
+			}
+
+			m.notificationsPane, cmd = m.notificationsPane.Update(msg)
+			return m, cmd
+		}
+	}
+
+	if m.isErrorPaneOpen {
+		switch msg.(type) {
+		case tea.KeyMsg, tea.MouseMsg:
+			if keyMsg, ok := msg.(tea.KeyMsg); ok && key.Matches(keyMsg, m.keys.quit) {
+				return m, tea.Quit
+			}
+
+			m.errorPane, cmd = m.errorPane.Update(msg)
+			return m, cmd
+		}
+	}
+
+	if m.isPaletteOpen {
+		switch msg.(type) {
+		case tea.KeyMsg, tea.MouseMsg:
+			if keyMsg, ok := msg.(tea.KeyMsg); ok && key.Matches(keyMsg, m.keys.quit) {
+				return m, tea.Quit
+			}
+
+			m.commandPalette, cmd = m.commandPalette.Update(msg)
+			return m, cmd
+		}
+	}
+
+	if m.isDashboardOpen {
+		switch msg.(type) {
+		case tea.KeyMsg, tea.MouseMsg:
+			if keyMsg, ok := msg.(tea.KeyMsg); ok && key.Matches(keyMsg, m.keys.quit) {
+				return m, tea.Quit
+			}
+
+			m.dashboardPane, cmd = m.dashboardPane.Update(msg)
+			return m, cmd
+		}
+	}
+
 	m.sessionOrchestrator, cmd = m.sessionOrchestrator.Update(msg)
 	cmds = append(cmds, cmd)
 
@@ -198,28 +436,135 @@ func (m MainView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 		m.settingsPane, cmd = m.settingsPane.Update(msg)
 		cmds = append(cmds, cmd)
+	} else {
+		m.sessionsPane, cmd = m.sessionsPane.UpdateWhileProcessing(msg)
+		cmds = append(cmds, cmd)
+		m.settingsPane, cmd = m.settingsPane.UpdateWhileProcessing(msg)
+		cmds = append(cmds, cmd)
 	}
 
 	switch msg := msg.(type) {
 
 	case util.ErrorEvent:
+		m.errorPane = m.errorPane.SetError(msg, time.Now())
+
+		// other errors are shown as toasts, a response may still be streamed
+		if msg.IsRecoverable() && !msg.IsRequestFailure {
+			break
+		}
+
+		m.stopFlow()
+		m.stopBroadcast()
+		m.stopMacroReplay()
 		m.sessionOrchestrator.ResponseProcessingState = util.Idle
-		m.error = msg
 		m.viewReady = true
 		m.controlsLocked = false
 		cmds = append(cmds, util.SendProcessingStateChangedMsg(util.Idle))
 
+		if m.config.ExitZenModeOnError && m.viewMode == util.ZenMode {
+			m.viewMode = util.NormalMode
+			cmds = append(cmds, util.SendViewModeChangedMsg(m.viewMode))
+		}
+
+		if !msg.IsRecoverable() {
+			m.error = msg
+			break
+		}
+
+		if prompt, ok := m.dropFailedPrompt(); ok {
+			cmds = append(cmds, util.SendRestorePromptMsg(prompt))
+		}
+
 	case checkDimensionsMsg:
 		if runtime.GOOS == "windows" {
 			w, h, _ := term.GetSize(int(os.Stdout.Fd()))
 			if m.terminalWidth != w || m.terminalHeight != h {
 				cmds = append(cmds, func() tea.Msg { return tea.WindowSizeMsg{Width: w, Height: h} })
 			}
-			cmds = append(cmds, dimensionsPulsar)
+			// without the constant checks in reduced motion mode, the size is checked on key presses
+			if !util.IsReducedMotion() {
+				cmds = append(cmds, dimensionsPulsar)
+			}
 		}
 
 	case util.ViewModeChanged:
 		m.viewMode = msg.Mode
+		m.configPane, _ = m.configPane.Update(msg)
+		m.notificationsPane, _ = m.notificationsPane.Update(msg)
+		m.errorPane, _ = m.errorPane.Update(msg)
+		m.commandPalette, _ = m.commandPalette.Update(msg)
+		m.dashboardPane, _ = m.dashboardPane.Update(msg)
+
+	case util.ConfigEditorToggled:
+		m.isConfigOpen = msg.IsOpen
+		if msg.Option != "" {
+			m.configPane, cmd = m.configPane.SelectOption(msg.Option)
+			cmds = append(cmds, cmd)
+		}
+
+	case util.CommandPaletteToggled:
+		m.isPaletteOpen = msg.IsOpen
+		if msg.IsOpen {
+			m.commandPalette, cmd = m.commandPalette.Open(m.getPaletteCommands())
+			cmds = append(cmds, cmd)
+		}
+
+	case util.QuickActionChosen:
+		cmds = append(cmds, m.runQuickAction(msg.Action))
+
+	case util.PaletteCommandChosen:
+		m.isPaletteOpen = false
+		cmds = append(cmds, m.executePaletteCommand(msg.Command))
+
+	case util.DashboardToggled:
+		m.isDashboardOpen = msg.IsOpen
+		if msg.IsOpen {
+			m.dashboardPane = m.dashboardPane.Open(
+				m.sessionOrchestrator.CurrentSessionID,
+				m.sessionOrchestrator.CurrentSessionName)
+		}
+
+	case util.DashboardActionChosen:
+		m.isDashboardOpen = false
+		switch msg.Action {
+		case util.NewChatAction:
+			cmds = append(cmds, m.InitiateNewSession(false))
+		case util.QuickChatAction:
+			cmds = append(cmds, m.InitiateNewSession(true))
+		case util.OpenSessionAction:
+			cmds = append(cmds, m.sessionsPane.SwitchToSession(msg.SessionId))
+		}
+
+	case util.NotificationHistoryToggled:
+		m.isNotificationsOpen = msg.IsOpen
+		if msg.IsOpen {
+			m.notificationsPane = m.notificationsPane.SetHistory(m.infoPane.NotificationHistory())
+		}
+
+	case util.ErrorInspectorToggled:
+		m.isErrorPaneOpen = msg.IsOpen && m.errorPane.HasError()
+
+	case config.ConfigUpdated:
+		if msg.Config.ColorScheme != m.config.ColorScheme {
+			m.applyColorScheme(msg.Config.ColorScheme)
+		}
+		m.config = msg.Config
+		applyLayoutConfig(m.config)
+		util.SetOfflineMode(m.config.OfflineMode, m.config.OfflineAllowlist)
+		if m.config.Hyperlinks != nil {
+			util.SetHyperlinks(*m.config.Hyperlinks)
+		}
+		if m.config.WrapCodeBlocks != nil {
+			util.SetContentLayout(m.config.ContentMaxWidth, *m.config.WrapCodeBlocks)
+		}
+		util.SetSpeakerLabels(m.config.UserIcon, m.config.AssistantIcon, m.config.UserLabel)
+		cmds = append(cmds, func() tea.Msg {
+			return tea.WindowSizeMsg{Width: m.terminalWidth, Height: m.terminalHeight}
+		})
+
+	case util.ProfileSwitchRequested:
+		m.profileToSwitch = msg.Profile
+		return m, tea.Quit
 
 	case util.SwitchToPaneMsg:
 		if util.IsFocusAllowed(m.viewMode, msg.Target, m.terminalWidth) {
@@ -233,11 +578,39 @@ func (m MainView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.initialPrompt = ""
 		}
 
+		if m.broadcast != nil && m.broadcast.isWaiting {
+			cmds = append(cmds, m.runNextBroadcastSession())
+		}
+
 	case util.ProcessingStateChanged:
 		if msg.State == util.Idle {
 			m.controlsLocked = false
+			if m.flowRun != nil {
+				cmds = append(cmds, m.runNextFlowStep())
+			}
+			if m.broadcast != nil {
+				m.broadcast.isWaiting = true
+			}
 		}
 
+	case sessions.SendToSessionCopy:
+		return m, tea.Sequence(
+			m.sessionsPane.DuplicateSession(m.sessionOrchestrator.GetCurrentSessionId()),
+			util.SendPromptReadyMsg(msg.Prompt, msg.Attachments),
+		)
+
+	case flows.RunFlowRequested:
+		cmds = append(cmds, m.startFlow(msg.Command))
+
+	case sessions.ComparisonRequested:
+		cmds = append(cmds, m.startComparison(msg.Command))
+
+	case sessions.PresetComparisonReady:
+		cmds = append(cmds, m.showComparison(msg))
+
+	case util.ComparisonWinnerMarked:
+		cmds = append(cmds, m.markComparisonWinner(msg))
+
 	case util.AsyncDependencyReady:
 		if !slices.Contains(m.loadedDeps, msg.Dependency) {
 			m.loadedDeps = append(m.loadedDeps, msg.Dependency)
@@ -256,6 +629,10 @@ func (m MainView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.viewReady = true
 				m.promptPane = m.promptPane.Enable()
 
+				if m.config.StartupDashboard && m.initialPrompt == "" && !m.flags.StartNewSession {
+					cmds = append(cmds, util.ToggleDashboard(true))
+				}
+
 				// if there is also a 'new session' flag - need to do it differently
 				if m.initialPrompt != "" && !m.flags.StartNewSession {
 					cmds = append(cmds, util.SendPromptReadyMsg(m.initialPrompt, []util.Attachment{}))
@@ -333,6 +710,14 @@ func (m MainView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case util.PromptReady:
 		m.error = util.ErrorEvent{}
 
+		if targets := m.sessionsPane.BroadcastTargets(); len(targets) != 0 && m.broadcast == nil && m.flowRun == nil {
+			return m, m.startBroadcast(msg, targets)
+		}
+
+		if m.sessionOrchestrator.CurrentSessionReadOnly {
+			return m, m.rejectReadOnlyPrompt(msg)
+		}
+
 		util.Slog.Debug("prompt ready message received", "msg", msg)
 
 		loadedAttachments := []util.Attachment{}
@@ -356,14 +741,22 @@ func (m MainView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		prompt, redactedSecrets := msg.Prompt, 0
+		if m.config.RedactPrompts {
+			prompt, redactedSecrets = util.RedactPromptSecrets(msg.Prompt, m.config.RedactionPatterns)
+		}
+
 		m.sessionOrchestrator.ArrayOfMessages = append(
 			m.sessionOrchestrator.ArrayOfMessages,
 			util.LocalStoreMessage{
-				Role:        "user",
-				Content:     msg.Prompt,
-				Attachments: loadedAttachments,
+				Role:            "user",
+				Content:         prompt,
+				Attachments:     loadedAttachments,
+				RedactedSecrets: redactedSecrets,
 			})
-		m.viewMode = util.NormalMode
+		if m.viewMode != util.TextEditMode || m.config.ShouldReturnFromEditorOnSend() {
+			m.viewMode = util.NormalMode
+		}
 		m.controlsLocked = true
 
 		m.setProcessingContext()
@@ -375,11 +768,20 @@ func (m MainView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.MouseMsg:
 		targetPane := m.focused
 
-		if m.controlsLocked {
+		// while a response is processed only the side panes can be clicked, to browse them
+		if m.controlsLocked && !zone.Get("settings_pane").InBounds(msg) && !zone.Get("sessions_pane").InBounds(msg) {
 			break
 		}
 
+		if dragCmd, ok := m.handlePromptPaneDrag(msg); ok {
+			return m, dragCmd
+		}
+
 		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			if clickCmd, ok := m.handleIndicatorClick(msg); ok {
+				return m, clickCmd
+			}
+
 			switch {
 			case zone.Get("chat_pane").InBounds(msg):
 				targetPane = util.ChatPane
@@ -406,10 +808,14 @@ func (m MainView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			break
 		}
 
+		if runtime.GOOS == "windows" && util.IsReducedMotion() {
+			cmds = append(cmds, func() tea.Msg { return checkDimensionsMsg(1) })
+		}
+
 		switch {
 
 		case key.Matches(msg, m.keys.saveQuickChat):
-			cmds = append(cmds, sessions.SendSaveQuickChatMsg())
+			cmds = append(cmds, m.startQuickChatSave())
 
 		case key.Matches(msg, m.keys.quickChat):
 			cmds = append(cmds, m.InitiateNewSession(true))
@@ -456,7 +862,48 @@ func (m MainView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			cmds = append(cmds, util.SendViewModeChangedMsg(m.viewMode))
 
+		case key.Matches(msg, m.keys.growChat):
+			cmds = append(cmds, m.resizeChatPane(util.ChatPaneWidthRatioStep))
+
+		case key.Matches(msg, m.keys.shrinkChat):
+			cmds = append(cmds, m.resizeChatPane(-util.ChatPaneWidthRatioStep))
+
+		case key.Matches(msg, m.keys.growPrompt):
+			cmds = append(cmds, m.resizePromptPane(util.PromptPaneRowsStep))
+
+		case key.Matches(msg, m.keys.shrinkPrompt):
+			cmds = append(cmds, m.resizePromptPane(-util.PromptPaneRowsStep))
+
+		case key.Matches(msg, m.keys.toggleDrawer):
+			cmds = append(cmds, m.toggleSidebarDrawer())
+
+		case key.Matches(msg, m.keys.notifications):
+			cmds = append(cmds, util.ToggleNotificationHistory(true))
+
+		case key.Matches(msg, m.keys.errorDetails):
+			cmds = append(cmds, util.ToggleErrorInspector(true))
+
+		case key.Matches(msg, m.keys.commandPalette):
+			cmds = append(cmds, util.ToggleCommandPalette(true))
+
+		case key.Matches(msg, m.keys.recordMacro):
+			if !m.canUseMacroKeys() {
+				break
+			}
+			cmds = append(cmds, m.toggleMacroRecording())
+
+		case key.Matches(msg, m.keys.replayMacro):
+			if !m.canUseMacroKeys() {
+				break
+			}
+			cmds = append(cmds, m.replayMacro())
+
 		case key.Matches(msg, m.keys.jumpToPane):
+			// numbers pick follow-up suggestions or the better compared answer while the chat pane shows them
+			if m.focused == util.ChatPane && (m.chatPane.HasFollowUps() || m.chatPane.IsComparing()) {
+				break
+			}
+
 			var targetPane util.Pane
 			switch msg.String() {
 			case "1":
@@ -471,20 +918,10 @@ func (m MainView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.handleFocusChange(targetPane, false)
 
 		case key.Matches(msg, m.keys.nextPane):
-			if !m.isFocusChangeAllowed(false) {
-				break
-			}
-
-			m.focused = util.GetNewFocusMode(m.viewMode, m.focused, m.terminalWidth, false)
-			m.resetFocus()
+			m.cycleFocus(false)
 
 		case key.Matches(msg, m.keys.previousPane):
-			if !m.isFocusChangeAllowed(false) {
-				break
-			}
-
-			m.focused = util.GetNewFocusMode(m.viewMode, m.focused, m.terminalWidth, true)
-			m.resetFocus()
+			m.cycleFocus(true)
 		}
 
 	case tea.WindowSizeMsg:
@@ -493,6 +930,16 @@ func (m MainView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		m.chatPane, cmd = m.chatPane.Update(msg)
 		cmds = append(cmds, cmd)
+		m.configPane, cmd = m.configPane.Update(msg)
+		cmds = append(cmds, cmd)
+		m.notificationsPane, cmd = m.notificationsPane.Update(msg)
+		cmds = append(cmds, cmd)
+		m.errorPane, cmd = m.errorPane.Update(msg)
+		cmds = append(cmds, cmd)
+		m.commandPalette, cmd = m.commandPalette.Update(msg)
+		cmds = append(cmds, cmd)
+		m.dashboardPane, cmd = m.dashboardPane.Update(msg)
+		cmds = append(cmds, cmd)
 		m.settingsPane, cmd = m.settingsPane.Update(msg)
 		cmds = append(cmds, cmd)
 		m.sessionsPane, cmd = m.sessionsPane.Update(msg)
@@ -501,11 +948,12 @@ func (m MainView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	m.chatPane, cmd = m.chatPane.Update(msg)
 	cmds = append(cmds, cmd)
+	cmds = append(cmds, m.updateWindowTitle())
 	return m, tea.Batch(cmds...)
 }
 
 func (m *MainView) handleFocusChange(targetPane util.Pane, isMouseEvent bool) {
-	if !m.isFocusChangeAllowed(isMouseEvent) {
+	if !m.isFocusChangeAllowed(isMouseEvent) || !m.isFocusAllowedWhileProcessing(targetPane) {
 		return
 	}
 
@@ -515,6 +963,40 @@ func (m *MainView) handleFocusChange(targetPane util.Pane, isMouseEvent bool) {
 	}
 }
 
+// Indicators of the info pane and the status bar. A click elsewhere in the info pane opens the manual
+func (m MainView) handleIndicatorClick(msg tea.MouseMsg) (tea.Cmd, bool) {
+	switch {
+	case zone.Get("info_web_search").InBounds(msg), zone.Get("status_web_search").InBounds(msg):
+		return util.ToggleWebSearch(), true
+
+	case zone.Get("info_notifications").InBounds(msg), zone.Get("status_notifications").InBounds(msg):
+		return util.ToggleNotificationHistory(true), true
+
+	case zone.Get("status_help").InBounds(msg), zone.Get("info_pane").InBounds(msg):
+		if !m.isFocusChangeAllowed(true) || !m.isFocusAllowedWhileProcessing(util.ChatPane) {
+			return nil, true
+		}
+		return util.ShowManual(), true
+	}
+
+	return nil, false
+}
+
+// The chat pane is skipped while a response is processed
+func (m *MainView) cycleFocus(isReverse bool) {
+	if !m.isFocusChangeAllowed(false) {
+		return
+	}
+
+	focused := util.GetNewFocusMode(m.viewMode, m.focused, m.terminalWidth, isReverse)
+	if !m.isFocusAllowedWhileProcessing(focused) {
+		focused = util.GetNewFocusMode(m.viewMode, focused, m.terminalWidth, isReverse)
+	}
+
+	m.focused = focused
+	m.resetFocus()
+}
+
 func (m MainView) View() string {
 	var windowViews string
 
@@ -530,11 +1012,40 @@ func (m MainView) View() string {
 		mainView = m.chatPane.DisplayError(m.error.Message)
 	}
 
+	if m.isConfigOpen {
+		mainView = m.configPane.View()
+	}
+
+	if m.isNotificationsOpen {
+		mainView = m.notificationsPane.View()
+	}
+
+	if m.isErrorPaneOpen {
+		mainView = m.errorPane.View()
+	}
+
+	if m.isPaletteOpen {
+		mainView = m.commandPalette.View()
+	}
+
+	if m.isDashboardOpen {
+		mainView = m.dashboardPane.View()
+	}
+
+	if m.viewMode == util.ZenMode {
+		mainView = lipgloss.PlaceHorizontal(m.terminalWidth, lipgloss.Center, mainView)
+	}
+
 	secondaryScreen := ""
 	if m.viewMode == util.NormalMode {
 		secondaryScreen = settingsAndSessionPanes
 	}
 
+	if m.isSidebarDrawerShown() && !m.isOverlayOpen() {
+		mainView = settingsAndSessionPanes
+		secondaryScreen = ""
+	}
+
 	windowViews = lipgloss.NewStyle().
 		Align(lipgloss.Right, lipgloss.Right).
 		Render(
@@ -546,16 +1057,53 @@ func (m MainView) View() string {
 		)
 
 	promptView := m.promptPane.View()
+	hints := ""
+	if !m.isOverlayOpen() {
+		hints = util.RenderKeyHints(m.getFocusedPaneHelp().ShortHelp())
+	}
+	statusBar := m.infoPane.StatusBarView(m.viewMode, m.focused, hints)
 
 	return zone.Scan(lipgloss.NewStyle().Render(
 		lipgloss.JoinVertical(
 			lipgloss.Left,
 			windowViews,
 			promptView,
+			statusBar,
 		),
 	))
 }
 
+// Overlays are shown in place of the chat pane and take all keyboard and mouse input
+func (m MainView) isOverlayOpen() bool {
+	return m.isConfigOpen || m.isNotificationsOpen || m.isErrorPaneOpen || m.isPaletteOpen || m.isDashboardOpen
+}
+
+func (m MainView) getFocusedPaneHelp() util.HelpKeyMap {
+	switch m.focused {
+	case util.ChatPane:
+		return m.chatPane
+	case util.SettingsPane:
+		return m.settingsPane
+	case util.SessionsPane:
+		return m.sessionsPane
+	default:
+		return m.promptPane
+	}
+}
+
+// A prompt without a response was not processed because of an error.
+// It is removed from the conversation, so it is not sent twice on retry
+func (m *MainView) dropFailedPrompt() (string, bool) {
+	messages := m.sessionOrchestrator.ArrayOfMessages
+	if len(messages) == 0 || messages[len(messages)-1].Role != "user" {
+		return "", false
+	}
+
+	failed := messages[len(messages)-1]
+	m.sessionOrchestrator.ArrayOfMessages = messages[:len(messages)-1]
+	return failed.Content, true
+}
+
 func (m *MainView) setProcessingContext() {
 	if m.processingCancel != nil {
 		m.processingCancel()
@@ -570,6 +1118,91 @@ func (m *MainView) resetFocus() {
 	m.promptPane, _ = m.promptPane.Update(util.MakeFocusMsg(m.focused == util.PromptPane))
 }
 
+func (m MainView) isSidebarDrawerShown() bool {
+	return m.viewMode == util.NormalMode &&
+		m.terminalWidth < util.WidthMinScalingLimit &&
+		util.IsSidebarDrawerOpen()
+}
+
+// In small terminals side panes are hidden.
+// The drawer shows them in place of the chat pane
+func (m *MainView) toggleSidebarDrawer() tea.Cmd {
+	if m.viewMode != util.NormalMode || m.terminalWidth >= util.WidthMinScalingLimit {
+		return nil
+	}
+
+	if !m.isFocusChangeAllowed(false) {
+		return nil
+	}
+
+	isOpen := !util.IsSidebarDrawerOpen()
+	util.SetSidebarDrawerOpen(isOpen)
+
+	m.focused = util.PromptPane
+	if isOpen {
+		m.focused = util.SettingsPane
+	}
+	m.resetFocus()
+
+	return func() tea.Msg {
+		return tea.WindowSizeMsg{Width: m.terminalWidth, Height: m.terminalHeight}
+	}
+}
+
+func applyLayoutConfig(cfg config.Config) {
+	util.SetZenModeMaxWidth(cfg.ZenModeMaxWidth)
+	util.SetChatPaneWidthRatio(cfg.GetChatPaneWidthRatio())
+	for name, mode := range util.PromptPaneModes {
+		util.SetPromptPaneRows(mode, cfg.PromptPaneRows[name])
+	}
+}
+
+// Re-styles the panes with the new scheme, the content is re-rendered on the following resize
+func (m *MainView) applyColorScheme(scheme util.ColorScheme) {
+	colors := scheme.GetColors()
+	m.chatPane = m.chatPane.SetColors(colors)
+	m.promptPane = m.promptPane.SetColors(colors)
+	m.infoPane = m.infoPane.SetColors(colors)
+	m.sessionsPane = m.sessionsPane.SetColors(colors)
+	m.settingsPane = m.settingsPane.SetColors(colors)
+	m.configPane = m.configPane.SetColors(colors)
+	m.notificationsPane = m.notificationsPane.SetColors(colors)
+	m.errorPane = m.errorPane.SetColors(colors)
+	m.commandPalette = m.commandPalette.SetColors(colors)
+	m.dashboardPane = m.dashboardPane.SetColors(colors)
+}
+
+// Changes the chat pane / side panes split and persists it to the config
+func (m *MainView) resizeChatPane(delta float64) tea.Cmd {
+	if m.viewMode != util.NormalMode || m.terminalWidth < util.WidthMinScalingLimit {
+		return nil
+	}
+
+	ratio := util.GetChatPaneWidthRatio() + delta
+	ratio = math.Round(ratio*100) / 100
+	if ratio < util.MinChatPaneWidthRatio || ratio > util.MaxChatPaneWidthRatio {
+		return nil
+	}
+
+	cfg, ok := config.FromContext(m.context)
+	if !ok {
+		return util.MakeErrorMsg("No config found in context")
+	}
+
+	option, _ := config.FindOption("chatPaneWidthRatio")
+	err := cfg.UpdateOption(option, strconv.FormatFloat(ratio, 'f', 2, 64))
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+
+	return config.SendConfigUpdatedMsg(*cfg)
+}
+
+// Returns the profile the app should be restarted with after exit, if any
+func (m MainView) ProfileToSwitch() string {
+	return m.profileToSwitch
+}
+
 func (m MainView) fileToBase64(filePath string) (string, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
@@ -606,8 +1239,7 @@ func (m MainView) isFocusChangeAllowed(isMouseEvent bool) bool {
 		!m.chatPane.AllowFocusChange(isMouseEvent) ||
 		!m.settingsPane.AllowFocusChange(isMouseEvent) ||
 		!m.sessionsPane.AllowFocusChange(isMouseEvent) ||
-		!m.viewReady ||
-		m.sessionOrchestrator.IsProcessing() {
+		!m.viewReady {
 		util.Slog.Warn(
 			"focus change not allowed.",
 			"processing mode",
@@ -619,6 +1251,50 @@ func (m MainView) isFocusChangeAllowed(isMouseEvent bool) bool {
 	return true
 }
 
+// The chat pane renders the response while it is processed. The other panes can be focused,
+// so the sessions and settings are browsed in the meantime
+func (m MainView) isFocusAllowedWhileProcessing(targetPane util.Pane) bool {
+	return targetPane != util.ChatPane || !m.sessionOrchestrator.IsProcessing()
+}
+
+// Prompts are not appended to read-only sessions. Broadcasts skip them,
+// otherwise the prompt pane offers to send the prompt to a copy of the session
+func (m *MainView) rejectReadOnlyPrompt(msg util.PromptReady) tea.Cmd {
+	toast := util.SendToastMsg(i18n.T("notification.readOnlyRejected"), util.WarningSeverity)
+
+	if m.broadcast != nil {
+		return tea.Batch(toast, m.runNextBroadcastSession())
+	}
+
+	m.stopFlow()
+	m.handleFocusChange(util.PromptPane, false)
+	return tea.Batch(toast, sessions.SendReadOnlyPromptRejectedMsg(msg.Prompt, msg.Attachments))
+}
+
+// The quick chat is named in the prompt pane while the model suggests a title.
+// If the prompt pane can't be focused, the chat is saved with its temporary name
+func (m *MainView) startQuickChatSave() tea.Cmd {
+	if !m.sessionOrchestrator.CurrentSessionIsTemporary {
+		return nil
+	}
+
+	if m.focused != util.PromptPane {
+		m.handleFocusChange(util.PromptPane, false)
+	}
+
+	if m.focused != util.PromptPane || m.sessionOrchestrator.IsProcessing() {
+		return sessions.SendSaveQuickChatMsg("")
+	}
+
+	return tea.Batch(
+		sessions.SendQuickChatNamingMsg(
+			m.sessionOrchestrator.CurrentSessionID,
+			m.sessionOrchestrator.CurrentSessionName,
+		),
+		m.sessionOrchestrator.SuggestSessionTitle(),
+	)
+}
+
 func (m *MainView) InitiateNewSession(isTemporary bool) tea.Cmd {
 	if util.IsFocusAllowed(m.viewMode, util.PromptPane, m.terminalWidth) {
 		if m.focused != util.SessionsPane {
@@ -639,7 +1315,10 @@ func (m *MainView) CancelProcessing() tea.Cmd {
 	m.sessionOrchestrator.Cancel()
 	m.chatPane.Cancel()
 	m.processingCancel()
+	m.stopFlow()
+	m.stopBroadcast()
 
+	usage := m.sessionOrchestrator.AccountCancelledResponse()
 	finalizeCmd := m.sessionOrchestrator.FinalizeResponseOnCancel()
 	if finalizeCmd != nil {
 		cmds = append(cmds, finalizeCmd)
@@ -647,6 +1326,6 @@ func (m *MainView) CancelProcessing() tea.Cmd {
 		cmds = append(cmds, util.SendProcessingStateChangedMsg(util.Idle))
 	}
 
-	cmds = append(cmds, util.SendNotificationMsg(util.CancelledNotification))
+	cmds = append(cmds, util.SendInferenceCancelledMsg(usage))
 	return tea.Batch(cmds...)
 }
@@ -0,0 +1,119 @@
+package views
+
+import (
+	"github.com/BalanceBalls/nekot/config"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+// Border between the chat and prompt panes dragged with the mouse. Rows are applied while dragging
+// and persisted once the button is released
+type promptPaneDrag struct {
+	startY    int
+	startRows int
+}
+
+func getPromptPaneModeName(mode util.ViewMode) (string, bool) {
+	for name, resizable := range util.PromptPaneModes {
+		if resizable == mode {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Changes rows of the prompt pane in the current view mode and persists them to the config
+func (m *MainView) resizePromptPane(delta int) tea.Cmd {
+	if !m.setPromptPaneRows(util.GetPromptPaneRows(m.viewMode) + delta) {
+		return nil
+	}
+	return m.persistPromptPaneRows()
+}
+
+// Rows are left as they are if the chat pane would get too small
+func (m *MainView) setPromptPaneRows(rows int) bool {
+	if _, ok := getPromptPaneModeName(m.viewMode); !ok || rows < 0 {
+		return false
+	}
+
+	previous := util.GetPromptPaneRows(m.viewMode)
+	if rows == previous {
+		return false
+	}
+
+	util.SetPromptPaneRows(m.viewMode, rows)
+	_, chatHeight := util.CalcChatPaneSize(m.terminalWidth, m.terminalHeight, m.viewMode)
+	if chatHeight < util.MinChatPaneHeight {
+		util.SetPromptPaneRows(m.viewMode, previous)
+		return false
+	}
+	return true
+}
+
+func (m MainView) persistPromptPaneRows() tea.Cmd {
+	name, _ := getPromptPaneModeName(m.viewMode)
+	cfg, ok := config.FromContext(m.context)
+	if !ok {
+		return util.MakeErrorMsg("No config found in context")
+	}
+
+	err := cfg.SetPromptPaneRows(name, util.GetPromptPaneRows(m.viewMode))
+	if err != nil {
+		return util.MakeErrorMsg(err.Error())
+	}
+
+	return config.SendConfigUpdatedMsg(*cfg)
+}
+
+// Pressing the top border of the prompt pane starts a drag, the following mouse events belong to it
+func (m *MainView) handlePromptPaneDrag(msg tea.MouseMsg) (tea.Cmd, bool) {
+	if m.promptDrag == nil {
+		if msg.Action != tea.MouseActionPress || msg.Button != tea.MouseButtonLeft || !m.isOnPromptPaneBorder(msg) {
+			return nil, false
+		}
+
+		if _, ok := getPromptPaneModeName(m.viewMode); !ok {
+			return nil, false
+		}
+
+		m.promptDrag = &promptPaneDrag{startY: msg.Y, startRows: util.GetPromptPaneRows(m.viewMode)}
+		return nil, true
+	}
+
+	switch msg.Action {
+	case tea.MouseActionMotion:
+		rows := m.promptDrag.startRows + m.promptDrag.startY - msg.Y
+		if !m.setPromptPaneRows(rows) {
+			return nil, true
+		}
+
+		return func() tea.Msg {
+			return tea.WindowSizeMsg{Width: m.terminalWidth, Height: m.terminalHeight}
+		}, true
+
+	case tea.MouseActionRelease:
+		drag := m.promptDrag
+		m.promptDrag = nil
+		if util.GetPromptPaneRows(m.viewMode) == drag.startRows {
+			return nil, true
+		}
+		return m.persistPromptPaneRows(), true
+	}
+
+	return nil, true
+}
+
+// Attachment chips are shown above the prompt input, the border is below them
+func (m MainView) isOnPromptPaneBorder(msg tea.MouseMsg) bool {
+	prompt := zone.Get("prompt_pane")
+	if !prompt.InBounds(msg) {
+		return false
+	}
+
+	borderY := prompt.StartY
+	if util.AreAttachmentChipsShown() {
+		borderY += util.AttachmentChipsHeight
+	}
+	return msg.Y == borderY
+}
@@ -0,0 +1,26 @@
+package views
+
+import (
+	"github.com/BalanceBalls/nekot/i18n"
+	"github.com/BalanceBalls/nekot/sessions"
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Sends the prompt of the action as the next turn of the current session
+func (m *MainView) runQuickAction(action string) tea.Cmd {
+	if m.sessionOrchestrator.IsProcessing() {
+		return util.SendToastMsg(i18n.T("notification.commandUnavailable"), util.WarningSeverity)
+	}
+
+	messages := m.sessionOrchestrator.ArrayOfMessages
+	if len(messages) == 0 || messages[len(messages)-1].Role != "assistant" {
+		return util.SendToastMsg(i18n.T("notification.noResponse"), util.WarningSeverity)
+	}
+
+	prompt := sessions.GetQuickActionPrompt(action, m.config)
+	if prompt == "" {
+		return nil
+	}
+	return util.SendPromptReadyMsg(prompt, []util.Attachment{})
+}
@@ -0,0 +1,49 @@
+package views
+
+import (
+	"github.com/BalanceBalls/nekot/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	windowTitlePrefix = "nekot"
+	busyIndicator     = "● "
+)
+
+// Sets the terminal window (or tmux pane) title to the current session name,
+// so the active chat is visible in the window manager. Only emits a command when the title changes
+func (m *MainView) updateWindowTitle() tea.Cmd {
+	title := ""
+	if m.config.WindowTitle {
+		title = m.getWindowTitle()
+	}
+
+	if title == m.windowTitle {
+		return nil
+	}
+
+	m.windowTitle = title
+	return tea.SetWindowTitle(title)
+}
+
+// Whether the app has changed the terminal title, so it can be cleared on exit
+func (m MainView) HasWindowTitle() bool {
+	return m.windowTitle != ""
+}
+
+func (m MainView) getWindowTitle() string {
+	sessionName := util.GetSessionName(
+		m.sessionOrchestrator.CurrentSessionID,
+		m.sessionOrchestrator.CurrentSessionName)
+
+	title := windowTitlePrefix
+	if sessionName != "" {
+		title += ": " + sessionName
+	}
+
+	if m.sessionOrchestrator.ResponseProcessingState != util.Idle {
+		title = busyIndicator + title
+	}
+
+	return title
+}